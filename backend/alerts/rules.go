@@ -0,0 +1,321 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/events"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+)
+
+// Params are the settings of a rule. Every kind honors the wallet and trade
+// filters; the timing fields only apply to the kinds that document them.
+type Params struct {
+	// Sport limits the rule to trades in the sport's markets by wallets
+	// tracked for it.
+	Sport string `json:"sport,omitempty"`
+	// TopRank limits the rule to the sport's top tracked wallets by
+	// leaderboard rank. It requires Sport.
+	TopRank   int      `json:"top_rank,omitempty"`
+	Wallets   []string `json:"wallets,omitempty"`
+	Watchlist string   `json:"watchlist,omitempty"`
+	Side      string   `json:"side,omitempty"`
+	MinUSD    float64  `json:"min_usd,omitempty"`
+
+	WithinMinutes int `json:"within_minutes,omitempty"`
+	MinWallets    int `json:"min_wallets,omitempty"`
+	WindowMinutes int `json:"window_minutes,omitempty"`
+}
+
+// Alert is one firing of a rule. Rules with the same DedupeKey are delivered
+// once.
+type Alert struct {
+	DedupeKey string
+	Summary   string
+	Trades    []events.TradeEvent
+	Game      *sports.Game
+}
+
+// Matcher evaluates one rule against the trade stream. It may keep state
+// across trades and is only called from one goroutine.
+type Matcher interface {
+	Match(env *Env, trade events.TradeEvent) []Alert
+}
+
+// Kind is a type of alert rule.
+type Kind struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// New validates a rule's params and returns a matcher for it.
+	New func(params Params) (Matcher, error) `json:"-"`
+}
+
+var (
+	kindsMu sync.RWMutex
+	kinds   = map[string]Kind{}
+)
+
+// RegisterKind adds a rule kind, replacing any kind with the same name.
+func RegisterKind(kind Kind) {
+	kindsMu.Lock()
+	defer kindsMu.Unlock()
+	kinds[kind.Name] = kind
+}
+
+// Kinds returns the registered rule kinds sorted by name.
+func Kinds() []Kind {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+	result := make([]Kind, 0, len(kinds))
+	for _, kind := range kinds {
+		result = append(result, kind)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func lookupKind(name string) (Kind, bool) {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+	kind, ok := kinds[name]
+	return kind, ok
+}
+
+// Compile decodes a rule's params and builds its matcher.
+func Compile(kindName string, raw json.RawMessage) (Params, Matcher, error) {
+	kind, ok := lookupKind(kindName)
+	if !ok {
+		return Params{}, nil, fmt.Errorf("unknown alert rule kind %q", kindName)
+	}
+	var params Params
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return Params{}, nil, fmt.Errorf("invalid params: %w", err)
+		}
+	}
+	params = params.normalize()
+	if params.TopRank > 0 && params.Sport == "" {
+		return Params{}, nil, fmt.Errorf("top_rank requires sport")
+	}
+	if params.Side != "" && params.Side != "BUY" && params.Side != "SELL" {
+		return Params{}, nil, fmt.Errorf("side must be BUY or SELL")
+	}
+	matcher, err := kind.New(params)
+	if err != nil {
+		return Params{}, nil, err
+	}
+	return params, matcher, nil
+}
+
+func (p Params) normalize() Params {
+	if p.Sport != "" {
+		p.Sport = sports.Normalize(p.Sport)
+	}
+	p.Side = strings.ToUpper(strings.TrimSpace(p.Side))
+	p.Watchlist = strings.TrimSpace(p.Watchlist)
+	for i, wallet := range p.Wallets {
+		p.Wallets[i] = strings.ToLower(strings.TrimSpace(wallet))
+	}
+	return p
+}
+
+// admits reports whether a trade passes the rule's wallet and trade
+// filters.
+func (p Params) admits(env *Env, event events.TradeEvent) bool {
+	trade := event.Trade
+	if len(p.Wallets) > 0 && !contains(p.Wallets, event.Wallet) {
+		return false
+	}
+	if p.Watchlist != "" && !contains(event.Watchlists, p.Watchlist) {
+		return false
+	}
+	if p.Sport != "" {
+		if !contains(event.Sports, p.Sport) || !sports.MatchesMarket(trade.ConditionID, trade.Title, trade.Slug, p.Sport) {
+			return false
+		}
+		if p.TopRank > 0 {
+			rank, ok := env.Rank(p.Sport, event.Wallet)
+			if !ok || rank > p.TopRank {
+				return false
+			}
+		}
+	}
+	if p.Side != "" && !strings.EqualFold(trade.Side, p.Side) {
+		return false
+	}
+	return trade.Size*trade.Price >= p.MinUSD
+}
+
+// Env is what matchers can look up beyond the trade itself.
+type Env struct {
+	ranks map[string]map[string]int
+	index *sports.Index
+}
+
+// Rank is a wallet's leaderboard rank among the sport's tracked wallets.
+func (e *Env) Rank(sport, wallet string) (int, bool) {
+	rank, ok := e.ranks[sport][strings.ToLower(wallet)]
+	return rank, ok
+}
+
+// Game is the indexed game a market belongs to.
+func (e *Env) Game(conditionID string) (sports.Game, bool) {
+	market, ok := e.index.Get(conditionID)
+	if !ok {
+		return sports.Game{}, false
+	}
+	return e.index.Game(market.EventID)
+}
+
+func init() {
+	RegisterKind(Kind{
+		Name:        "large_trade",
+		Description: "A matching wallet trades at least min_usd in one fill.",
+		New: func(params Params) (Matcher, error) {
+			return largeTrade{params: params}, nil
+		},
+	})
+	RegisterKind(Kind{
+		Name:        "pre_game_entry",
+		Description: "A matching wallet buys into a game market within within_minutes (default 30) before the scheduled start.",
+		New: func(params Params) (Matcher, error) {
+			if params.WithinMinutes <= 0 {
+				params.WithinMinutes = 30
+			}
+			return preGameEntry{params: params}, nil
+		},
+	})
+	RegisterKind(Kind{
+		Name:        "wallet_cluster",
+		Description: "At least min_wallets (default 3) distinct matching wallets trade the same side of the same outcome within window_minutes (default 60).",
+		New: func(params Params) (Matcher, error) {
+			if params.MinWallets <= 0 {
+				params.MinWallets = 3
+			}
+			if params.MinWallets < 2 {
+				return nil, fmt.Errorf("min_wallets must be at least 2")
+			}
+			if params.WindowMinutes <= 0 {
+				params.WindowMinutes = 60
+			}
+			return &walletCluster{
+				params: params,
+				window: time.Duration(params.WindowMinutes) * time.Minute,
+				recent: map[string][]events.TradeEvent{},
+				fired:  map[string]time.Time{},
+			}, nil
+		},
+	})
+}
+
+type largeTrade struct {
+	params Params
+}
+
+func (m largeTrade) Match(env *Env, event events.TradeEvent) []Alert {
+	if !m.params.admits(env, event) {
+		return nil
+	}
+	trade := event.Trade
+	return []Alert{{
+		DedupeKey: event.Key,
+		Summary: fmt.Sprintf("%s %s $%.0f of %s on %s", event.Wallet, strings.ToLower(trade.Side),
+			trade.Size*trade.Price, trade.Outcome, trade.Title),
+		Trades: []events.TradeEvent{event},
+	}}
+}
+
+type preGameEntry struct {
+	params Params
+}
+
+func (m preGameEntry) Match(env *Env, event events.TradeEvent) []Alert {
+	trade := event.Trade
+	if !strings.EqualFold(trade.Side, "BUY") || !m.params.admits(env, event) {
+		return nil
+	}
+	game, ok := env.Game(trade.ConditionID)
+	if !ok || game.ScheduledStart == nil {
+		return nil
+	}
+	lead := game.ScheduledStart.Sub(trade.Time())
+	if lead < 0 || lead > time.Duration(m.params.WithinMinutes)*time.Minute {
+		return nil
+	}
+	return []Alert{{
+		DedupeKey: event.Wallet + "|" + strings.ToLower(trade.ConditionID),
+		Summary: fmt.Sprintf("%s bought %s on %s %d minutes before %s starts", event.Wallet, trade.Outcome,
+			trade.Title, int(lead.Minutes()), game.Title),
+		Trades: []events.TradeEvent{event},
+		Game:   &game,
+	}}
+}
+
+type walletCluster struct {
+	params Params
+	window time.Duration
+	// recent holds the admitted trades within the window per outcome and
+	// side; fired when each last alerted.
+	recent map[string][]events.TradeEvent
+	fired  map[string]time.Time
+}
+
+func (m *walletCluster) Match(env *Env, event events.TradeEvent) []Alert {
+	if !m.params.admits(env, event) {
+		return nil
+	}
+	trade := event.Trade
+	at := trade.Time()
+	key := strings.ToLower(trade.ConditionID) + "|" + trade.Outcome + "|" + strings.ToUpper(trade.Side)
+
+	kept := m.recent[key][:0]
+	for _, prior := range m.recent[key] {
+		if at.Sub(prior.Trade.Time()) <= m.window {
+			kept = append(kept, prior)
+		}
+	}
+	kept = append(kept, event)
+	m.recent[key] = kept
+	for k, trades := range m.recent {
+		if k != key && at.Sub(trades[len(trades)-1].Trade.Time()) > m.window {
+			delete(m.recent, k)
+		}
+	}
+	for k, last := range m.fired {
+		if at.Sub(last) > m.window {
+			delete(m.fired, k)
+		}
+	}
+
+	wallets := map[string]bool{}
+	for _, prior := range kept {
+		wallets[prior.Wallet] = true
+	}
+	if len(wallets) < m.params.MinWallets {
+		return nil
+	}
+	if _, ok := m.fired[key]; ok {
+		return nil
+	}
+	m.fired[key] = at
+
+	return []Alert{{
+		DedupeKey: key + "|" + event.Key,
+		Summary: fmt.Sprintf("%d wallets %s %s on %s within %d minutes", len(wallets), strings.ToLower(trade.Side),
+			trade.Outcome, trade.Title, m.params.WindowMinutes),
+		Trades: append([]events.TradeEvent(nil), kept...),
+	}}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
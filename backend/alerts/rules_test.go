@@ -0,0 +1,216 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/events"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+)
+
+var start = time.Date(2026, 3, 1, 19, 0, 0, 0, time.UTC)
+
+func tradeEvent(key, wallet, side, outcome string, size, price float64, at time.Time) events.TradeEvent {
+	return events.TradeEvent{
+		Key:        key,
+		Wallet:     wallet,
+		Sports:     []string{"nba"},
+		Watchlists: []string{"sharps"},
+		Trade: polymarket.Trade{
+			Side:        side,
+			ConditionID: "M1",
+			Title:       "NBA: Lakers vs. Celtics",
+			Size:        size,
+			Price:       price,
+			Timestamp:   at.Unix(),
+			Outcome:     outcome,
+		},
+	}
+}
+
+func testEnv() *Env {
+	index := sports.NewIndex()
+	index.Add([]sports.Market{{ConditionID: "m1", Sport: "nba", EventID: "e1"}})
+	index.AddGames([]sports.Game{{ID: "e1", Sport: "nba", Title: "Lakers vs. Celtics", ScheduledStart: &start}})
+	return &Env{
+		ranks: map[string]map[string]int{"nba": {"0xa": 1, "0xb": 5}},
+		index: index,
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		params  string
+		want    Params
+		wantErr bool
+	}{
+		{name: "no params", kind: "large_trade", want: Params{}},
+		{
+			name:   "params normalized",
+			kind:   "large_trade",
+			params: `{"sport": " NBA ", "side": " buy ", "wallets": [" 0xABC "], "watchlist": " sharps ", "min_usd": 500}`,
+			want:   Params{Sport: "nba", Side: "BUY", Wallets: []string{"0xabc"}, Watchlist: "sharps", MinUSD: 500},
+		},
+		{name: "top rank with sport", kind: "large_trade", params: `{"sport": "nba", "top_rank": 10}`, want: Params{Sport: "nba", TopRank: 10}},
+		{name: "unknown kind", kind: "moon_shot", wantErr: true},
+		{name: "invalid params", kind: "large_trade", params: `{"min_usd": "lots"}`, wantErr: true},
+		{name: "top rank without sport", kind: "large_trade", params: `{"top_rank": 10}`, wantErr: true},
+		{name: "invalid side", kind: "large_trade", params: `{"side": "hold"}`, wantErr: true},
+		{name: "cluster of one", kind: "wallet_cluster", params: `{"min_wallets": 1}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, matcher, err := Compile(tt.kind, json.RawMessage(tt.params))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Compile(%s, %s) succeeded", tt.kind, tt.params)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if matcher == nil || !reflect.DeepEqual(params, tt.want) {
+				t.Errorf("Compile() = %+v, %v; want %+v and a matcher", params, matcher, tt.want)
+			}
+		})
+	}
+}
+
+func TestLargeTradeFilters(t *testing.T) {
+	env := testEnv()
+	// A $50 buy of Yes by 0xa, ranked first in nba and on the sharps list.
+	trade := tradeEvent("k1", "0xa", "BUY", "Yes", 100, 0.5, start)
+
+	tests := []struct {
+		name   string
+		params Params
+		event  events.TradeEvent
+		want   bool
+	}{
+		{name: "no filters", want: true},
+		{name: "wallet listed", params: Params{Wallets: []string{"0xb", "0xA"}}, want: true},
+		{name: "wallet not listed", params: Params{Wallets: []string{"0xb"}}},
+		{name: "on the watchlist", params: Params{Watchlist: "sharps"}, want: true},
+		{name: "not on the watchlist", params: Params{Watchlist: "fades"}},
+		{name: "sport", params: Params{Sport: "nba"}, want: true},
+		{name: "wallet not tracked for the sport", params: Params{Sport: "nfl"}},
+		{
+			name:   "market outside the sport",
+			params: Params{Sport: "nba"},
+			event: func() events.TradeEvent {
+				event := trade
+				event.Trade.ConditionID, event.Trade.Title = "m2", "Will it rain in Paris?"
+				return event
+			}(),
+		},
+		{name: "within top rank", params: Params{Sport: "nba", TopRank: 3}, want: true},
+		{name: "outside top rank", params: Params{Sport: "nba", TopRank: 3}, event: tradeEvent("k2", "0xb", "BUY", "Yes", 100, 0.5, start)},
+		{name: "unranked wallet", params: Params{Sport: "nba", TopRank: 3}, event: tradeEvent("k3", "0xc", "BUY", "Yes", 100, 0.5, start)},
+		{name: "side", params: Params{Side: "BUY"}, want: true},
+		{name: "other side", params: Params{Side: "SELL"}},
+		{name: "exactly min usd", params: Params{MinUSD: 50}, want: true},
+		{name: "below min usd", params: Params{MinUSD: 50.01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := tt.event
+			if event.Key == "" {
+				event = trade
+			}
+			alerts := largeTrade{params: tt.params}.Match(env, event)
+			if got := len(alerts) == 1; got != tt.want {
+				t.Fatalf("Match() = %+v, want alert %v", alerts, tt.want)
+			}
+			if tt.want && (alerts[0].DedupeKey != event.Key || len(alerts[0].Trades) != 1) {
+				t.Errorf("alert = %+v, want deduped on the trade key", alerts[0])
+			}
+		})
+	}
+}
+
+func TestPreGameEntry(t *testing.T) {
+	env := testEnv()
+	tests := []struct {
+		name          string
+		withinMinutes int
+		event         events.TradeEvent
+		want          bool
+	}{
+		{name: "buy shortly before the start", event: tradeEvent("k", "0xa", "BUY", "Yes", 10, 0.5, start.Add(-10*time.Minute)), want: true},
+		{name: "buy at the start", event: tradeEvent("k", "0xa", "BUY", "Yes", 10, 0.5, start), want: true},
+		{name: "buy too early", event: tradeEvent("k", "0xa", "BUY", "Yes", 10, 0.5, start.Add(-45*time.Minute))},
+		{name: "wider window", withinMinutes: 60, event: tradeEvent("k", "0xa", "BUY", "Yes", 10, 0.5, start.Add(-45*time.Minute)), want: true},
+		{name: "buy after the start", event: tradeEvent("k", "0xa", "BUY", "Yes", 10, 0.5, start.Add(time.Minute))},
+		{name: "sell before the start", event: tradeEvent("k", "0xa", "SELL", "Yes", 10, 0.5, start.Add(-10*time.Minute))},
+		{
+			name: "market without a game",
+			event: func() events.TradeEvent {
+				event := tradeEvent("k", "0xa", "BUY", "Yes", 10, 0.5, start.Add(-10*time.Minute))
+				event.Trade.ConditionID = "m9"
+				return event
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, matcher, err := Compile("pre_game_entry", json.RawMessage(fmt.Sprintf(`{"within_minutes": %d}`, tt.withinMinutes)))
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			alerts := matcher.Match(env, tt.event)
+			if got := len(alerts) == 1; got != tt.want {
+				t.Fatalf("Match() = %+v, want alert %v", alerts, tt.want)
+			}
+			if tt.want {
+				if alerts[0].DedupeKey != "0xa|m1" || alerts[0].Game == nil || alerts[0].Game.ID != "e1" {
+					t.Errorf("alert = %+v, want deduped per wallet and market with game e1", alerts[0])
+				}
+			}
+		})
+	}
+}
+
+func TestWalletCluster(t *testing.T) {
+	_, matcher, err := Compile("wallet_cluster", json.RawMessage(`{"min_wallets": 2, "window_minutes": 10}`))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	env := testEnv()
+
+	steps := []struct {
+		name   string
+		event  events.TradeEvent
+		trades int
+	}{
+		{name: "first wallet", event: tradeEvent("k1", "0xa", "BUY", "Yes", 10, 0.5, start)},
+		{name: "same wallet again", event: tradeEvent("k2", "0xa", "BUY", "Yes", 10, 0.5, start.Add(time.Minute))},
+		{name: "other outcome", event: tradeEvent("k3", "0xb", "BUY", "No", 10, 0.5, start.Add(90*time.Second))},
+		{name: "second wallet fires", event: tradeEvent("k4", "0xb", "BUY", "Yes", 10, 0.5, start.Add(2*time.Minute)), trades: 3},
+		{name: "third wallet within the window", event: tradeEvent("k5", "0xc", "BUY", "Yes", 10, 0.5, start.Add(3*time.Minute))},
+		{name: "other side", event: tradeEvent("k6", "0xd", "SELL", "Yes", 10, 0.5, start.Add(4*time.Minute))},
+		{name: "window passed", event: tradeEvent("k7", "0xa", "BUY", "Yes", 10, 0.5, start.Add(20*time.Minute))},
+		{name: "fires again", event: tradeEvent("k8", "0xb", "BUY", "Yes", 10, 0.5, start.Add(25*time.Minute)), trades: 2},
+	}
+	for _, step := range steps {
+		alerts := matcher.Match(env, step.event)
+		if step.trades == 0 {
+			if len(alerts) != 0 {
+				t.Errorf("%s: Match() = %+v, want none", step.name, alerts)
+			}
+			continue
+		}
+		if len(alerts) != 1 || len(alerts[0].Trades) != step.trades {
+			t.Fatalf("%s: Match() = %+v, want one alert with %d trades", step.name, alerts, step.trades)
+		}
+		if want := "m1|Yes|BUY|" + step.event.Key; alerts[0].DedupeKey != want {
+			t.Errorf("%s: DedupeKey = %q, want %q", step.name, alerts[0].DedupeKey, want)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/events"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+const (
+	rulesRefresh     = 30 * time.Second
+	dispatchInterval = 5 * time.Second
+	dispatchBatch    = 50
+	// MaxAttempts is how many times a delivery is tried before it is marked
+	// failed.
+	MaxAttempts  = 8
+	retryBase    = 30 * time.Second
+	retryCeiling = time.Hour
+)
+
+// Payload is the JSON body posted to a rule's webhook.
+type Payload struct {
+	RuleID      int64               `json:"rule_id"`
+	RuleName    string              `json:"rule_name"`
+	Kind        string              `json:"kind"`
+	Summary     string              `json:"summary"`
+	TriggeredAt time.Time           `json:"triggered_at"`
+	Trades      []events.TradeEvent `json:"trades"`
+	Game        *sports.Game        `json:"game,omitempty"`
+}
+
+type compiledRule struct {
+	rule      storage.AlertRule
+	matcher   Matcher
+	lastFired time.Time
+}
+
+// Service evaluates the enabled alert rules against trade events from the
+// bus, queues their alerts in the delivery log, publishes them back to the
+// bus and posts them to the rules' webhooks, retrying failures with backoff.
+type Service struct {
+	store  storage.Store
+	bus    *events.Bus
+	index  *sports.Index
+	http   *http.Client
+	wake   chan struct{}
+	reload atomic.Bool
+
+	// Owned by the evaluation goroutine.
+	rules       map[int64]*compiledRule
+	env         *Env
+	rulesLoaded time.Time
+}
+
+func NewService(store storage.Store, bus *events.Bus) *Service {
+	return &Service{
+		store: store,
+		bus:   bus,
+		index: sports.SharedIndex(),
+		http:  webhookClient(),
+		wake:  make(chan struct{}, 1),
+		rules: map[int64]*compiledRule{},
+		env:   &Env{index: sports.SharedIndex()},
+	}
+}
+
+// Start subscribes to the bus and runs the evaluation and delivery loops.
+func (s *Service) Start(ctx context.Context) {
+	sub := s.bus.Subscribe(1024)
+	go func() {
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				if event.Trade != nil {
+					s.evaluate(ctx, *event.Trade)
+				}
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(dispatchInterval)
+		defer ticker.Stop()
+		for {
+			s.dispatchWithLogging(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+		}
+	}()
+}
+
+// Refresh makes the next trade reload the rules, so REST changes apply
+// without waiting for the periodic reload.
+func (s *Service) Refresh() {
+	s.reload.Store(true)
+}
+
+func (s *Service) evaluate(ctx context.Context, trade events.TradeEvent) {
+	if s.reload.Swap(false) || time.Since(s.rulesLoaded) >= rulesRefresh {
+		if err := s.loadRules(ctx); err != nil {
+			log.Printf("alert rules reload failed: %v", err)
+		}
+	}
+
+	queued := false
+	now := time.Now().UTC()
+	for _, compiled := range s.rules {
+		for _, alert := range compiled.matcher.Match(s.env, trade) {
+			cooldown := time.Duration(compiled.rule.CooldownSeconds) * time.Second
+			if cooldown > 0 && now.Sub(compiled.lastFired) < cooldown {
+				continue
+			}
+			payload, err := json.Marshal(Payload{
+				RuleID:      compiled.rule.ID,
+				RuleName:    compiled.rule.Name,
+				Kind:        compiled.rule.Kind,
+				Summary:     alert.Summary,
+				TriggeredAt: now,
+				Trades:      alert.Trades,
+				Game:        alert.Game,
+			})
+			if err != nil {
+				log.Printf("alert rule %d payload failed: %v", compiled.rule.ID, err)
+				continue
+			}
+			delivery, inserted, err := s.store.InsertAlertDelivery(ctx, storage.AlertDelivery{
+				RuleID:    compiled.rule.ID,
+				DedupeKey: alert.DedupeKey,
+				Payload:   payload,
+			})
+			if err != nil {
+				log.Printf("alert rule %d enqueue failed: %v", compiled.rule.ID, err)
+				continue
+			}
+			if inserted {
+				compiled.lastFired = now
+				queued = true
+				s.bus.Publish(events.Event{Type: events.TypeAlert, Alert: &events.AlertEvent{
+					DeliveryID: delivery.ID,
+					RuleID:     compiled.rule.ID,
+					Summary:    alert.Summary,
+					Payload:    payload,
+				}})
+			}
+		}
+	}
+	if queued {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// loadRules compiles the enabled rules, keeping the matcher state of rules
+// that have not changed, and loads the ranks their filters need.
+func (s *Service) loadRules(ctx context.Context) error {
+	rules, err := s.store.ListAlertRules(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	next := make(map[int64]*compiledRule, len(rules))
+	rankSports := map[string]bool{}
+	for _, rule := range rules {
+		params, matcher, err := Compile(rule.Kind, rule.Params)
+		if err != nil {
+			log.Printf("alert rule %d skipped: %v", rule.ID, err)
+			continue
+		}
+		if params.TopRank > 0 {
+			rankSports[params.Sport] = true
+		}
+		if prior, ok := s.rules[rule.ID]; ok && prior.rule.UpdatedAt.Equal(rule.UpdatedAt) {
+			next[rule.ID] = prior
+			continue
+		}
+		next[rule.ID] = &compiledRule{rule: rule, matcher: matcher}
+	}
+
+	ranks := map[string]map[string]int{}
+	for sport := range rankSports {
+		wallets, err := s.store.ListTrackedWallets(ctx, sport)
+		if err != nil {
+			return err
+		}
+		ranks[sport] = make(map[string]int, len(wallets))
+		for _, wallet := range wallets {
+			ranks[sport][strings.ToLower(wallet.WalletAddress)] = wallet.SourceRank
+		}
+	}
+
+	s.rules = next
+	s.env = &Env{ranks: ranks, index: s.index}
+	s.rulesLoaded = time.Now()
+	return nil
+}
+
+func (s *Service) dispatchWithLogging(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	if err := s.dispatch(runCtx); err != nil {
+		log.Printf("alert delivery failed: %v", err)
+	}
+}
+
+// dispatch posts the due deliveries to their webhooks.
+func (s *Service) dispatch(ctx context.Context) error {
+	due, err := s.store.ListDueAlertDeliveries(ctx, time.Now().UTC(), dispatchBatch)
+	if err != nil || len(due) == 0 {
+		return err
+	}
+	rules, err := s.store.ListAlertRules(ctx, false)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]storage.AlertRule, len(rules))
+	for _, rule := range rules {
+		byID[rule.ID] = rule
+	}
+
+	for _, delivery := range due {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rule, ok := byID[delivery.RuleID]
+		if !ok {
+			continue
+		}
+		delivery = s.attempt(ctx, rule, delivery)
+		if err := s.store.SaveAlertDeliveryAttempt(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt posts one delivery and returns it with the outcome recorded.
+func (s *Service) attempt(ctx context.Context, rule storage.AlertRule, delivery storage.AlertDelivery) storage.AlertDelivery {
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.ResponseStatus = 0
+	delivery.LastError = ""
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rule.WebhookURL, bytes.NewReader(delivery.Payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "easy-arbitra-alerts/1.0")
+		req.Header.Set("X-Alert-Delivery", strconv.FormatInt(delivery.ID, 10))
+		req.Header.Set("X-Alert-Timestamp", timestamp)
+		req.Header.Set("X-Alert-Signature", "sha256="+Sign(rule.Secret, timestamp, delivery.Payload))
+
+		var resp *http.Response
+		resp, err = s.http.Do(req)
+		if err == nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			delivery.ResponseStatus = resp.StatusCode
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				err = fmt.Errorf("webhook returned %s", resp.Status)
+			}
+		}
+	}
+
+	if err == nil {
+		delivery.Status = storage.AlertDeliveryDelivered
+		delivery.DeliveredAt = &now
+		return delivery
+	}
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= MaxAttempts {
+		delivery.Status = storage.AlertDeliveryFailed
+		return delivery
+	}
+	delivery.Status = storage.AlertDeliveryPending
+	delivery.NextAttemptAt = now.Add(backoff(delivery.Attempts))
+	return delivery
+}
+
+// backoff doubles the wait after each failed attempt up to an hour.
+func backoff(attempts int) time.Duration {
+	wait := retryBase
+	for i := 1; i < attempts && wait < retryCeiling; i++ {
+		wait *= 2
+	}
+	if wait > retryCeiling {
+		wait = retryCeiling
+	}
+	return wait
+}
+
+// Sign is the hex HMAC-SHA256 of "timestamp.body" under the rule's secret,
+// sent as the X-Alert-Signature header.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a random webhook signing secret.
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Validate checks a rule before it is stored. Its webhook must not point at
+// a loopback, private or link-local address.
+func Validate(ctx context.Context, rule storage.AlertRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, _, err := Compile(rule.Kind, rule.Params); err != nil {
+		return err
+	}
+	if err := checkWebhookURL(ctx, rule.WebhookURL); err != nil {
+		return err
+	}
+	if rule.CooldownSeconds < 0 {
+		return fmt.Errorf("cooldown_seconds must not be negative")
+	}
+	return nil
+}
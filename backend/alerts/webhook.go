@@ -0,0 +1,96 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errBlockedDestination is returned for webhook URLs and connections that
+// would reach this host or its private network.
+var errBlockedDestination = errors.New("webhook destination is a loopback, private or link-local address")
+
+// sharedAddressSpace is the carrier-grade NAT range, private in practice
+// though netip does not count it as such.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// blockedAddr reports whether webhooks may not reach addr: loopback,
+// private, link-local (which holds cloud metadata endpoints), unspecified,
+// multicast or shared address space.
+func blockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// checkWebhookURL rejects a webhook URL that is not absolute http(s) or
+// whose host is, or resolves to, a blocked address.
+func checkWebhookURL(ctx context.Context, raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("webhook_url must be an absolute http or https URL")
+	}
+
+	host := target.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if blockedAddr(addr) {
+			return fmt.Errorf("webhook_url: %w", errBlockedDestination)
+		}
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("webhook_url: %w", errBlockedDestination)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("webhook_url host %s does not resolve: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if blockedAddr(addr) {
+			return fmt.Errorf("webhook_url host %s: %w", host, errBlockedDestination)
+		}
+	}
+	return nil
+}
+
+// webhookClient posts webhooks without a proxy and checks every address it
+// connects to, redirects included, so a host that resolves to a blocked
+// address after its rule was validated is still refused.
+func webhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || blockedAddr(addr) {
+				return errBlockedDestination
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
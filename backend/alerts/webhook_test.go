@@ -0,0 +1,77 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestBlockedAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "127.0.0.1", want: true},
+		{addr: "10.1.2.3", want: true},
+		{addr: "172.16.0.1", want: true},
+		{addr: "192.168.1.1", want: true},
+		{addr: "169.254.169.254", want: true},
+		{addr: "100.64.0.1", want: true},
+		{addr: "0.0.0.0", want: true},
+		{addr: "224.0.0.1", want: true},
+		{addr: "::1", want: true},
+		{addr: "::", want: true},
+		{addr: "fd00::1", want: true},
+		{addr: "fe80::1", want: true},
+		{addr: "ff02::1", want: true},
+		{addr: "::ffff:127.0.0.1", want: true},
+		{addr: "::ffff:10.0.0.1", want: true},
+		{addr: "8.8.8.8"},
+		{addr: "100.128.0.1"},
+		{addr: "2606:4700:4700::1111"},
+	}
+	for _, tt := range tests {
+		if got := blockedAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("blockedAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+	if !blockedAddr(netip.Addr{}) {
+		t.Error("blockedAddr(zero) = false, want true")
+	}
+}
+
+// TestCheckWebhookURL only uses IP literals and localhost names, which are
+// decided without a DNS lookup.
+func TestCheckWebhookURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		wantErr     bool
+		wantBlocked bool
+	}{
+		{name: "public IPv4", url: "https://8.8.8.8/hook"},
+		{name: "public IPv6 with port", url: "http://[2606:4700:4700::1111]:8080/hook"},
+		{name: "not http", url: "ftp://8.8.8.8/hook", wantErr: true},
+		{name: "relative", url: "/hook", wantErr: true},
+		{name: "no host", url: "https:///hook", wantErr: true},
+		{name: "unparseable", url: "http://[::1", wantErr: true},
+		{name: "loopback", url: "http://127.0.0.1:9000/hook", wantErr: true, wantBlocked: true},
+		{name: "IPv6 loopback", url: "http://[::1]/hook", wantErr: true, wantBlocked: true},
+		{name: "cloud metadata", url: "http://169.254.169.254/latest/meta-data", wantErr: true, wantBlocked: true},
+		{name: "mapped private", url: "http://[::ffff:192.168.0.10]/hook", wantErr: true, wantBlocked: true},
+		{name: "localhost", url: "http://localhost:8080/hook", wantErr: true, wantBlocked: true},
+		{name: "localhost subdomain", url: "https://api.localhost/hook", wantErr: true, wantBlocked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkWebhookURL(context.Background(), tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkWebhookURL(%q) error = %v, want error %v", tt.url, err, tt.wantErr)
+			}
+			if got := errors.Is(err, errBlockedDestination); got != tt.wantBlocked {
+				t.Errorf("checkWebhookURL(%q) error = %v, want blocked %v", tt.url, err, tt.wantBlocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,491 @@
+package arbitrage
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+)
+
+const (
+	Buy  = "buy"
+	Sell = "sell"
+
+	eventPageSize = 100
+)
+
+// MarketQuote is the top of book of one binary market in an event. Bid and
+// Ask are quoted for the first outcome; the second outcome trades at their
+// complements.
+type MarketQuote struct {
+	ConditionID string   `json:"condition_id"`
+	Question    string   `json:"question"`
+	Slug        string   `json:"slug"`
+	Title       string   `json:"title,omitempty"`
+	MarketType  string   `json:"market_type"`
+	Outcomes    []string `json:"outcomes"`
+	Bid         float64  `json:"bid"`
+	Ask         float64  `json:"ask"`
+	Liquidity   float64  `json:"liquidity"`
+	Line        float64  `json:"line"`
+}
+
+// EventGroup is the open markets of one event, the unit constraints check.
+// Skipped counts the event's open markets left out of Markets for being
+// inactive, not binary or without a two-sided quote.
+type EventGroup struct {
+	EventID string        `json:"event_id"`
+	Slug    string        `json:"slug"`
+	Title   string        `json:"title"`
+	NegRisk bool          `json:"neg_risk"`
+	Markets []MarketQuote `json:"markets"`
+	Skipped int           `json:"skipped"`
+}
+
+// Leg is one trade of a violation's hedge.
+type Leg struct {
+	ConditionID string  `json:"condition_id"`
+	Question    string  `json:"question"`
+	Outcome     string  `json:"outcome"`
+	Side        string  `json:"side"`
+	Price       float64 `json:"price"`
+}
+
+// Violation is a set of quotes that break a constraint. Edge is the
+// per-share profit of trading the legs at the quoted prices, and Liquidity
+// is the smallest Gamma liquidity among the legs' markets.
+type Violation struct {
+	Constraint  string  `json:"constraint"`
+	Description string  `json:"description"`
+	EventID     string  `json:"event_id"`
+	EventSlug   string  `json:"event_slug"`
+	EventTitle  string  `json:"event_title"`
+	Legs        []Leg   `json:"legs"`
+	Edge        float64 `json:"edge"`
+	Liquidity   float64 `json:"liquidity"`
+}
+
+// Constraint is a rule prices within one event must satisfy.
+type Constraint struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description"`
+	Check       func(EventGroup) []Violation `json:"-"`
+}
+
+// ConsistencyOptions tunes CheckConsistency. An empty Constraints list runs
+// every registered constraint.
+type ConsistencyOptions struct {
+	MinEdge      float64  `json:"min_edge"`
+	MinLiquidity float64  `json:"min_liquidity"`
+	Constraints  []string `json:"constraints,omitempty"`
+}
+
+var (
+	constraintsMu sync.RWMutex
+	constraints   = map[string]Constraint{}
+)
+
+func init() {
+	RegisterConstraint(Constraint{
+		Name:        "exhaustive_sum",
+		Description: "Yes prices of a mutually exclusive event's markets sum to 1",
+		Check:       checkExhaustiveSum,
+	})
+	RegisterConstraint(Constraint{
+		Name:        "spread_implies_moneyline",
+		Description: "A favorite covering implies it wins; an underdog winning implies it covers",
+		Check:       checkSpreadMoneyline,
+	})
+	RegisterConstraint(Constraint{
+		Name:        "spread_ladder",
+		Description: "Covering a larger handicap implies covering a smaller one",
+		Check:       checkSpreadLadder,
+	})
+	RegisterConstraint(Constraint{
+		Name:        "total_ladder",
+		Description: "Over a higher total implies over every lower total",
+		Check:       checkTotalLadder,
+	})
+}
+
+// RegisterConstraint makes a constraint available to CheckConsistency.
+// Registering an existing name replaces it.
+func RegisterConstraint(constraint Constraint) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+	constraints[constraint.Name] = constraint
+}
+
+// Constraints returns all registered constraints sorted by name.
+func Constraints() []Constraint {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+
+	list := make([]Constraint, 0, len(constraints))
+	for _, constraint := range constraints {
+		list = append(list, constraint)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// CheckConsistency runs the selected constraints over every group and
+// returns violations above the thresholds, by edge and then liquidity. An
+// implication and its contrapositive trade the same markets, so violations
+// are reported once per constraint and set of markets.
+func CheckConsistency(groups []EventGroup, opts ConsistencyOptions) []Violation {
+	seen := map[string]bool{}
+	selected := map[string]bool{}
+	for _, name := range opts.Constraints {
+		selected[strings.TrimSpace(name)] = true
+	}
+
+	violations := []Violation{}
+	for _, constraint := range Constraints() {
+		if len(selected) > 0 && !selected[constraint.Name] {
+			continue
+		}
+		for _, group := range groups {
+			for _, violation := range constraint.Check(group) {
+				if violation.Edge <= opts.MinEdge || violation.Liquidity < opts.MinLiquidity {
+					continue
+				}
+				ids := make([]string, 0, len(violation.Legs))
+				for _, leg := range violation.Legs {
+					ids = append(ids, leg.ConditionID)
+				}
+				sort.Strings(ids)
+				key := constraint.Name + "|" + strings.Join(ids, ",")
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				violation.Constraint = constraint.Name
+				violation.EventID = group.EventID
+				violation.EventSlug = group.Slug
+				violation.EventTitle = group.Title
+				violation.Edge = round(violation.Edge, 4)
+				violation.Liquidity = round(violation.Liquidity, 2)
+				violations = append(violations, violation)
+			}
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].Edge != violations[j].Edge {
+			return violations[i].Edge > violations[j].Edge
+		}
+		return violations[i].Liquidity > violations[j].Liquidity
+	})
+	return violations
+}
+
+// GroupFromEvent keeps the event's open binary markets that have a two-sided
+// quote and counts the open markets it leaves out.
+func GroupFromEvent(event polymarket.Event) EventGroup {
+	group := EventGroup{EventID: event.ID, Slug: event.Slug, Title: event.Title, NegRisk: event.NegRisk}
+	for _, market := range event.Markets {
+		if market.Closed {
+			continue
+		}
+		outcomes := market.OutcomeNames()
+		if !market.Active || len(outcomes) != 2 || market.BestBid <= 0 || market.BestAsk >= 1 || market.BestBid > market.BestAsk {
+			group.Skipped++
+			continue
+		}
+		group.Markets = append(group.Markets, MarketQuote{
+			ConditionID: strings.ToLower(market.ConditionID),
+			Question:    market.Question,
+			Slug:        market.Slug,
+			Title:       market.GroupItemTitle,
+			MarketType:  sports.MarketType(market),
+			Outcomes:    outcomes,
+			Bid:         market.BestBid,
+			Ask:         market.BestAsk,
+			Liquidity:   market.LiquidityNum,
+			Line:        market.Line,
+		})
+	}
+	return group
+}
+
+// FetchGroups reads the open events of every league tag of sport, up to
+// maxEvents, and groups their markets.
+func FetchGroups(ctx context.Context, client *polymarket.Client, sport string, maxEvents int) ([]EventGroup, error) {
+	tags, err := client.GetSportsTags()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []EventGroup{}
+	for _, league := range sports.LeagueTags(tags) {
+		entry, _ := sports.Lookup(league.Sport)
+		if entry.Key != sport && entry.Parent != sport {
+			continue
+		}
+		for offset := 0; len(groups) < maxEvents; offset += eventPageSize {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			events, err := client.GetActiveEvents(league.TagID, eventPageSize, offset)
+			if err != nil {
+				return nil, err
+			}
+			for _, event := range events {
+				if group := GroupFromEvent(event); len(group.Markets) > 0 && len(groups) < maxEvents {
+					groups = append(groups, group)
+				}
+			}
+			if len(events) < eventPageSize {
+				break
+			}
+		}
+	}
+	return groups, nil
+}
+
+// bid and ask return the best prices for outcome i of a binary market.
+func (q MarketQuote) bid(i int) float64 {
+	if i == 0 {
+		return q.Bid
+	}
+	return 1 - q.Ask
+}
+
+func (q MarketQuote) ask(i int) float64 {
+	if i == 0 {
+		return q.Ask
+	}
+	return 1 - q.Bid
+}
+
+func (q MarketQuote) leg(i int, side string) Leg {
+	price := q.ask(i)
+	if side == Sell {
+		price = q.bid(i)
+	}
+	return Leg{ConditionID: q.ConditionID, Question: q.Question, Outcome: q.Outcomes[i], Side: side, Price: round(price, 4)}
+}
+
+// position is one outcome of one market, used by the implication checks.
+type position struct {
+	quote   MarketQuote
+	outcome int
+	line    float64
+}
+
+// implication reports a violation when a implies b but a's bid is above
+// b's ask: selling a and buying b loses only if a happens without b.
+func implication(a, b position, description string) (Violation, bool) {
+	edge := a.quote.bid(a.outcome) - b.quote.ask(b.outcome)
+	if edge <= 0 {
+		return Violation{}, false
+	}
+	return Violation{
+		Description: description,
+		Legs:        []Leg{a.quote.leg(a.outcome, Sell), b.quote.leg(b.outcome, Buy)},
+		Edge:        edge,
+		Liquidity:   math.Min(a.quote.Liquidity, b.quote.Liquidity),
+	}, true
+}
+
+func checkExhaustiveSum(group EventGroup) []Violation {
+	if !group.NegRisk || len(group.Markets) < 2 {
+		return nil
+	}
+	sumAsk, sumBid, liquidity := 0.0, 0.0, math.Inf(1)
+	for _, quote := range group.Markets {
+		sumAsk += quote.Ask
+		sumBid += quote.Bid
+		liquidity = math.Min(liquidity, quote.Liquidity)
+	}
+
+	violations := []Violation{}
+	// Buying every Yes only pays 1 when no open outcome is missing from the
+	// group; selling every Yes owes at most 1 either way.
+	if sumAsk < 1 && group.Skipped == 0 {
+		violation := Violation{Description: "Yes asks sum to " + formatPrice(sumAsk) + "; buying every Yes pays 1", Edge: 1 - sumAsk, Liquidity: liquidity}
+		for _, quote := range group.Markets {
+			violation.Legs = append(violation.Legs, quote.leg(0, Buy))
+		}
+		violations = append(violations, violation)
+	}
+	if sumBid > 1 {
+		violation := Violation{Description: "Yes bids sum to " + formatPrice(sumBid) + "; selling every Yes owes 1", Edge: sumBid - 1, Liquidity: liquidity}
+		for _, quote := range group.Markets {
+			violation.Legs = append(violation.Legs, quote.leg(0, Sell))
+		}
+		violations = append(violations, violation)
+	}
+	return violations
+}
+
+func checkSpreadMoneyline(group EventGroup) []Violation {
+	violations := []Violation{}
+	for _, moneyline := range group.Markets {
+		if moneyline.MarketType != "moneyline" {
+			continue
+		}
+		for _, spread := range spreadPositions(group) {
+			team := spread.quote.Outcomes[spread.outcome]
+			win := outcomeIndex(moneyline.Outcomes, team)
+			if win < 0 {
+				continue
+			}
+			winner := position{quote: moneyline, outcome: win}
+			if spread.line < 0 {
+				if v, ok := implication(spread, winner, team+" covering "+formatLine(spread.line)+" implies "+team+" wins"); ok {
+					violations = append(violations, v)
+				}
+			} else if spread.line > 0 {
+				if v, ok := implication(winner, spread, team+" winning implies "+team+" covers "+formatLine(spread.line)); ok {
+					violations = append(violations, v)
+				}
+			}
+		}
+	}
+	return violations
+}
+
+func checkSpreadLadder(group EventGroup) []Violation {
+	byTeam := map[string][]position{}
+	for _, spread := range spreadPositions(group) {
+		team := strings.ToLower(spread.quote.Outcomes[spread.outcome])
+		byTeam[team] = append(byTeam[team], spread)
+	}
+
+	violations := []Violation{}
+	for _, ladder := range byTeam {
+		// Covering line L means margin + L > 0, so a lower line is stricter.
+		for _, a := range ladder {
+			for _, b := range ladder {
+				if a.line >= b.line || a.quote.ConditionID == b.quote.ConditionID {
+					continue
+				}
+				team := a.quote.Outcomes[a.outcome]
+				if v, ok := implication(a, b, team+" covering "+formatLine(a.line)+" implies covering "+formatLine(b.line)); ok {
+					violations = append(violations, v)
+				}
+			}
+		}
+	}
+	return violations
+}
+
+func checkTotalLadder(group EventGroup) []Violation {
+	overs := []position{}
+	for _, quote := range group.Markets {
+		if quote.MarketType != "total" {
+			continue
+		}
+		over := outcomeIndex(quote.Outcomes, "over")
+		line, ok := totalLine(quote)
+		if over < 0 || !ok {
+			continue
+		}
+		overs = append(overs, position{quote: quote, outcome: over, line: line})
+	}
+
+	violations := []Violation{}
+	for _, a := range overs {
+		for _, b := range overs {
+			if a.line <= b.line {
+				continue
+			}
+			if v, ok := implication(a, b, "Over "+formatPrice(a.line)+" implies over "+formatPrice(b.line)); ok {
+				violations = append(violations, v)
+			}
+		}
+	}
+	return violations
+}
+
+var (
+	spreadLinePattern = regexp.MustCompile(`^(?:.*?:\s*)?(.+?)\s*\(([+-]?\d+(?:\.\d+)?)\)`)
+	totalLinePattern  = regexp.MustCompile(`(?i)(?:o/u|over/under|total)\D*(\d+(?:\.\d+)?)`)
+)
+
+// spreadPositions returns both sides of every spread market in the group,
+// each with the handicap of its own team. Whole-number lines can push, which
+// breaks the implications, so only half-point lines are kept.
+func spreadPositions(group EventGroup) []position {
+	positions := []position{}
+	for _, quote := range group.Markets {
+		if quote.MarketType != "spread" {
+			continue
+		}
+		first, line, ok := spreadLine(quote)
+		if !ok || math.Mod(math.Abs(line), 1) != 0.5 {
+			continue
+		}
+		positions = append(positions,
+			position{quote: quote, outcome: first, line: line},
+			position{quote: quote, outcome: 1 - first, line: -line},
+		)
+	}
+	return positions
+}
+
+// spreadLine returns which outcome the quoted handicap belongs to and the
+// line. Questions look like "Spread: Lakers (-4.5)"; without a team in the
+// question the line is taken to be the first outcome's.
+func spreadLine(quote MarketQuote) (int, float64, bool) {
+	match := spreadLinePattern.FindStringSubmatch(quote.Question)
+	if match == nil {
+		if quote.Line != 0 {
+			return 0, quote.Line, true
+		}
+		return 0, 0, false
+	}
+	line, err := strconv.ParseFloat(match[2], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if i := outcomeIndex(quote.Outcomes, match[1]); i >= 0 {
+		return i, line, true
+	}
+	return 0, line, true
+}
+
+func totalLine(quote MarketQuote) (float64, bool) {
+	line := quote.Line
+	if line == 0 {
+		match := totalLinePattern.FindStringSubmatch(quote.Question + " " + quote.Title)
+		if match == nil {
+			return 0, false
+		}
+		parsed, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, false
+		}
+		line = parsed
+	}
+	return line, math.Mod(line, 1) == 0.5
+}
+
+func outcomeIndex(outcomes []string, name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, outcome := range outcomes {
+		if strings.ToLower(strings.TrimSpace(outcome)) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func formatPrice(value float64) string {
+	return strconv.FormatFloat(round(value, 4), 'f', -1, 64)
+}
+
+func formatLine(line float64) string {
+	if line > 0 {
+		return "+" + formatPrice(line)
+	}
+	return formatPrice(line)
+}
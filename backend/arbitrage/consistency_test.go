@@ -0,0 +1,260 @@
+package arbitrage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+)
+
+func yesNo(id string, bid, ask, liquidity float64) polymarket.Market {
+	return polymarket.Market{
+		ConditionID:  id,
+		Question:     "Will " + id + " win?",
+		Outcomes:     `["Yes", "No"]`,
+		Active:       true,
+		BestBid:      bid,
+		BestAsk:      ask,
+		LiquidityNum: liquidity,
+	}
+}
+
+func TestGroupFromEvent(t *testing.T) {
+	closed := yesNo("closed", 0.2, 0.3, 100)
+	closed.Closed = true
+	inactive := yesNo("inactive", 0.2, 0.3, 100)
+	inactive.Active = false
+	threeWay := yesNo("three", 0.2, 0.3, 100)
+	threeWay.Outcomes = `["A", "B", "Draw"]`
+
+	group := GroupFromEvent(polymarket.Event{
+		ID:      "e1",
+		NegRisk: true,
+		Markets: []polymarket.Market{
+			yesNo("0xKEPT", 0.2, 0.3, 100),
+			closed,
+			inactive,
+			threeWay,
+			yesNo("no-bid", 0, 0.3, 100),
+			yesNo("crossed", 0.4, 0.3, 100),
+		},
+	})
+	if len(group.Markets) != 1 || group.Markets[0].ConditionID != "0xkept" {
+		t.Errorf("Markets = %+v, want only 0xkept", group.Markets)
+	}
+	// The closed market is not open, so only the other four count.
+	if group.Skipped != 4 {
+		t.Errorf("Skipped = %d, want 4", group.Skipped)
+	}
+}
+
+func TestExhaustiveSum(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   polymarket.Event
+		opts    ConsistencyOptions
+		sides   []string
+		edges   []float64
+		liquids []float64
+	}{
+		{
+			name: "full group below 1",
+			event: polymarket.Event{ID: "e", NegRisk: true, Markets: []polymarket.Market{
+				yesNo("a", 0.25, 0.30, 500), yesNo("b", 0.25, 0.30, 200), yesNo("c", 0.25, 0.30, 900),
+			}},
+			sides:   []string{Buy},
+			edges:   []float64{0.1},
+			liquids: []float64{200},
+		},
+		{
+			// The unquoted outcome could win, leaving every Yes bought worthless.
+			name: "partial group below 1",
+			event: polymarket.Event{ID: "e", NegRisk: true, Markets: []polymarket.Market{
+				yesNo("a", 0.25, 0.30, 500), yesNo("b", 0.25, 0.30, 200), yesNo("c", 0.25, 0.30, 900), yesNo("d", 0, 0.05, 10),
+			}},
+		},
+		{
+			name: "partial group above 1",
+			event: polymarket.Event{ID: "e", NegRisk: true, Markets: []polymarket.Market{
+				yesNo("a", 0.40, 0.45, 500), yesNo("b", 0.35, 0.40, 200), yesNo("c", 0.30, 0.35, 900), yesNo("d", 0, 0.05, 10),
+			}},
+			sides:   []string{Sell},
+			edges:   []float64{0.05},
+			liquids: []float64{200},
+		},
+		{
+			name: "closed outcome does not block buying the rest",
+			event: polymarket.Event{ID: "e", NegRisk: true, Markets: []polymarket.Market{
+				yesNo("a", 0.40, 0.45, 500), yesNo("b", 0.40, 0.45, 200), func() polymarket.Market {
+					m := yesNo("c", 0, 0.01, 0)
+					m.Closed = true
+					return m
+				}(),
+			}},
+			sides:   []string{Buy},
+			edges:   []float64{0.1},
+			liquids: []float64{200},
+		},
+		{
+			name: "edge below the minimum",
+			event: polymarket.Event{ID: "e", NegRisk: true, Markets: []polymarket.Market{
+				yesNo("a", 0.25, 0.30, 500), yesNo("b", 0.25, 0.30, 200), yesNo("c", 0.25, 0.30, 900),
+			}},
+			opts: ConsistencyOptions{MinEdge: 0.11},
+		},
+		{
+			name: "not mutually exclusive",
+			event: polymarket.Event{ID: "e", Markets: []polymarket.Market{
+				yesNo("a", 0.25, 0.30, 500), yesNo("b", 0.25, 0.30, 200),
+			}},
+		},
+		{
+			name: "fair prices",
+			event: polymarket.Event{ID: "e", NegRisk: true, Markets: []polymarket.Market{
+				yesNo("a", 0.45, 0.52, 500), yesNo("b", 0.45, 0.52, 200),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Constraints = []string{"exhaustive_sum"}
+			group := GroupFromEvent(tt.event)
+			got := CheckConsistency([]EventGroup{group}, opts)
+			if len(got) != len(tt.sides) {
+				t.Fatalf("CheckConsistency() = %+v, want %d violations", got, len(tt.sides))
+			}
+			for i, violation := range got {
+				if violation.Constraint != "exhaustive_sum" || violation.EventID != "e" {
+					t.Errorf("violation %d = %s for %s, want exhaustive_sum for e", i, violation.Constraint, violation.EventID)
+				}
+				if math.Abs(violation.Edge-tt.edges[i]) > 1e-9 || violation.Liquidity != tt.liquids[i] {
+					t.Errorf("violation %d edge %v liquidity %v, want %v %v", i, violation.Edge, violation.Liquidity, tt.edges[i], tt.liquids[i])
+				}
+				if len(violation.Legs) != len(group.Markets) {
+					t.Errorf("violation %d has %d legs, want one per quoted market", i, len(violation.Legs))
+				}
+				for _, leg := range violation.Legs {
+					if leg.Side != tt.sides[i] || leg.Outcome != "Yes" {
+						t.Errorf("violation %d leg = %+v, want %s Yes", i, leg, tt.sides[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestImplicationConstraints(t *testing.T) {
+	quote := func(id, marketType, question string, outcomes []string, bid, ask, line float64) MarketQuote {
+		return MarketQuote{ConditionID: id, Question: question, MarketType: marketType, Outcomes: outcomes, Bid: bid, Ask: ask, Liquidity: 100, Line: line}
+	}
+	teams := []string{"Lakers", "Celtics"}
+	overUnder := []string{"Over", "Under"}
+
+	tests := []struct {
+		name       string
+		constraint string
+		markets    []MarketQuote
+		legs       []Leg
+		edge       float64
+	}{
+		{
+			// Both the favorite's implication and the underdog's trade these
+			// two markets, so it is reported once.
+			name:       "favorite covers above its moneyline",
+			constraint: "spread_implies_moneyline",
+			markets: []MarketQuote{
+				quote("ml", "moneyline", "Lakers vs. Celtics", teams, 0.55, 0.58, 0),
+				quote("sp", "spread", "Spread: Lakers (-4.5)", teams, 0.60, 0.62, 0),
+			},
+			legs: []Leg{
+				{ConditionID: "sp", Outcome: "Lakers", Side: Sell, Price: 0.60},
+				{ConditionID: "ml", Outcome: "Lakers", Side: Buy, Price: 0.58},
+			},
+			edge: 0.02,
+		},
+		{
+			// The line belongs to the second outcome, so the underdog's
+			// implication is the one reported.
+			name:       "underdog wins above its cover",
+			constraint: "spread_implies_moneyline",
+			markets: []MarketQuote{
+				quote("ml", "moneyline", "Lakers vs. Celtics", teams, 0.30, 0.32, 0),
+				quote("sp", "spread", "Spread: Celtics (+4.5)", teams, 0.40, 0.42, 0),
+			},
+			legs: []Leg{
+				{ConditionID: "ml", Outcome: "Celtics", Side: Sell, Price: 0.68},
+				{ConditionID: "sp", Outcome: "Celtics", Side: Buy, Price: 0.60},
+			},
+			edge: 0.08,
+		},
+		{
+			name:       "consistent spread and moneyline",
+			constraint: "spread_implies_moneyline",
+			markets: []MarketQuote{
+				quote("ml", "moneyline", "Lakers vs. Celtics", teams, 0.60, 0.62, 0),
+				quote("sp", "spread", "Spread: Lakers (-4.5)", teams, 0.45, 0.47, 0),
+			},
+			edge: -1,
+		},
+		{
+			name:       "whole-number lines can push",
+			constraint: "spread_implies_moneyline",
+			markets: []MarketQuote{
+				quote("ml", "moneyline", "Lakers vs. Celtics", teams, 0.55, 0.58, 0),
+				quote("sp", "spread", "Spread: Lakers (-4)", teams, 0.60, 0.62, 0),
+			},
+			edge: -1,
+		},
+		{
+			name:       "larger handicap priced above a smaller one",
+			constraint: "spread_ladder",
+			markets: []MarketQuote{
+				quote("sp6", "spread", "Spread: Lakers (-6.5)", teams, 0.50, 0.52, 0),
+				quote("sp3", "spread", "Spread: Lakers (-3.5)", teams, 0.43, 0.45, 0),
+			},
+			legs: []Leg{
+				{ConditionID: "sp6", Outcome: "Lakers", Side: Sell, Price: 0.50},
+				{ConditionID: "sp3", Outcome: "Lakers", Side: Buy, Price: 0.45},
+			},
+			edge: 0.05,
+		},
+		{
+			name:       "higher total priced above a lower one",
+			constraint: "total_ladder",
+			markets: []MarketQuote{
+				quote("o220", "total", "Lakers vs. Celtics: O/U 220.5", overUnder, 0.55, 0.57, 220.5),
+				quote("o215", "total", "Lakers vs. Celtics: O/U 215.5", overUnder, 0.48, 0.50, 0),
+			},
+			legs: []Leg{
+				{ConditionID: "o220", Outcome: "Over", Side: Sell, Price: 0.55},
+				{ConditionID: "o215", Outcome: "Over", Side: Buy, Price: 0.50},
+			},
+			edge: 0.05,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := EventGroup{EventID: "e", Markets: tt.markets}
+			got := CheckConsistency([]EventGroup{group}, ConsistencyOptions{Constraints: []string{tt.constraint}})
+			if tt.edge < 0 {
+				if len(got) != 0 {
+					t.Fatalf("CheckConsistency() = %+v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("CheckConsistency() = %+v, want one violation", got)
+			}
+			if math.Abs(got[0].Edge-tt.edge) > 1e-9 || len(got[0].Legs) != len(tt.legs) {
+				t.Fatalf("violation = %+v, want edge %v with %d legs", got[0], tt.edge, len(tt.legs))
+			}
+			for i, want := range tt.legs {
+				leg := got[0].Legs[i]
+				if leg.ConditionID != want.ConditionID || leg.Outcome != want.Outcome || leg.Side != want.Side || math.Abs(leg.Price-want.Price) > 1e-9 {
+					t.Errorf("leg %d = %+v, want %+v", i, leg, want)
+				}
+			}
+		})
+	}
+}
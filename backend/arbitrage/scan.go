@@ -0,0 +1,152 @@
+package arbitrage
+
+import (
+	"math"
+	"sort"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+)
+
+const (
+	BuyBoth  = "buy_both"
+	SellBoth = "sell_both"
+)
+
+// Options tunes Scan. FeeBps is a taker fee charged on notional: buying costs
+// price*(1+fee) and selling yields price*(1-fee). MinEdge is the smallest
+// per-share profit after fees worth reporting.
+type Options struct {
+	FeeBps  float64 `json:"fee_bps"`
+	MinEdge float64 `json:"min_edge"`
+}
+
+// Opportunity is a complementary-outcome mispricing in one binary market.
+// Buying one share of every outcome pays out exactly 1, so asks summing below
+// 1 lock in a profit; a minted pair costs 1, so bids summing above 1 do too.
+type Opportunity struct {
+	ConditionID string    `json:"condition_id"`
+	Question    string    `json:"question"`
+	Slug        string    `json:"slug"`
+	Kind        string    `json:"kind"`
+	Outcomes    []string  `json:"outcomes"`
+	BestPrices  []float64 `json:"best_prices"`
+	PriceSum    float64   `json:"price_sum"`
+	Edge        float64   `json:"edge"`
+	Size        float64   `json:"size"`
+	NotionalUSD float64   `json:"notional_usd"`
+	ProfitUSD   float64   `json:"profit_usd"`
+}
+
+// Scan checks every binary snapshot for buy-both and sell-both arbitrage and
+// walks the books to size each opportunity while its edge stays above
+// MinEdge. Results are sorted by profit.
+func Scan(snapshots []Snapshot, opts Options) []Opportunity {
+	fee := opts.FeeBps / 10000
+	opportunities := []Opportunity{}
+	for _, snapshot := range snapshots {
+		if len(snapshot.Books) != 2 {
+			continue
+		}
+		yes, no := snapshot.Books[0], snapshot.Books[1]
+
+		asksYes, asksNo := sortedLevels(yes.Asks, true), sortedLevels(no.Asks, true)
+		if opp, ok := walk(asksYes, asksNo, func(a, b float64) float64 { return 1 - (a+b)*(1+fee) }, opts.MinEdge); ok {
+			opp.Kind = BuyBoth
+			opportunities = append(opportunities, fill(opp, snapshot))
+		}
+
+		bidsYes, bidsNo := sortedLevels(yes.Bids, false), sortedLevels(no.Bids, false)
+		if opp, ok := walk(bidsYes, bidsNo, func(a, b float64) float64 { return (a+b)*(1-fee) - 1 }, opts.MinEdge); ok {
+			opp.Kind = SellBoth
+			opportunities = append(opportunities, fill(opp, snapshot))
+		}
+	}
+
+	sort.Slice(opportunities, func(i, j int) bool {
+		if opportunities[i].ProfitUSD != opportunities[j].ProfitUSD {
+			return opportunities[i].ProfitUSD > opportunities[j].ProfitUSD
+		}
+		return opportunities[i].ConditionID < opportunities[j].ConditionID
+	})
+	return opportunities
+}
+
+// walk pairs levels of two ladders, best first, for as long as the paired
+// edge exceeds minEdge.
+func walk(a, b []polymarket.OrderLevel, edge func(pa, pb float64) float64, minEdge float64) (Opportunity, bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return Opportunity{}, false
+	}
+	top := edge(a[0].Price, b[0].Price)
+	if top <= minEdge {
+		return Opportunity{}, false
+	}
+
+	opp := Opportunity{
+		BestPrices: []float64{a[0].Price, b[0].Price},
+		PriceSum:   round(a[0].Price+b[0].Price, 4),
+		Edge:       round(top, 4),
+	}
+	i, j := 0, 0
+	remainingA, remainingB := a[0].Size, b[0].Size
+	for i < len(a) && j < len(b) {
+		unit := edge(a[i].Price, b[j].Price)
+		if unit <= minEdge {
+			break
+		}
+		size := math.Min(remainingA, remainingB)
+		opp.Size += size
+		opp.NotionalUSD += size * (a[i].Price + b[j].Price)
+		opp.ProfitUSD += size * unit
+
+		remainingA -= size
+		remainingB -= size
+		if remainingA <= 0 {
+			i++
+			if i < len(a) {
+				remainingA = a[i].Size
+			}
+		}
+		if remainingB <= 0 {
+			j++
+			if j < len(b) {
+				remainingB = b[j].Size
+			}
+		}
+	}
+	opp.Size = round(opp.Size, 2)
+	opp.NotionalUSD = round(opp.NotionalUSD, 2)
+	opp.ProfitUSD = round(opp.ProfitUSD, 2)
+	return opp, opp.Size > 0
+}
+
+func fill(opp Opportunity, snapshot Snapshot) Opportunity {
+	opp.ConditionID = snapshot.ConditionID
+	opp.Question = snapshot.Question
+	opp.Slug = snapshot.Slug
+	opp.Outcomes = snapshot.Outcomes
+	return opp
+}
+
+// sortedLevels returns non-empty levels best first: lowest asks or highest
+// bids.
+func sortedLevels(levels []polymarket.OrderLevel, ascending bool) []polymarket.OrderLevel {
+	out := make([]polymarket.OrderLevel, 0, len(levels))
+	for _, level := range levels {
+		if level.Size > 0 && level.Price > 0 {
+			out = append(out, level)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if ascending {
+			return out[i].Price < out[j].Price
+		}
+		return out[i].Price > out[j].Price
+	})
+	return out
+}
+
+func round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
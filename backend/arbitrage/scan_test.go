@@ -0,0 +1,157 @@
+package arbitrage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+)
+
+func levels(pairs ...float64) []polymarket.OrderLevel {
+	out := make([]polymarket.OrderLevel, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		out = append(out, polymarket.OrderLevel{Price: pairs[i], Size: pairs[i+1]})
+	}
+	return out
+}
+
+func binary(id string, yes, no polymarket.OrderBook) Snapshot {
+	return Snapshot{ConditionID: id, Outcomes: []string{"Yes", "No"}, Books: []polymarket.OrderBook{yes, no}}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshot  Snapshot
+		opts      Options
+		want      []Opportunity
+		wantEmpty bool
+	}{
+		{
+			name:     "buy both at the top of book",
+			snapshot: binary("m", polymarket.OrderBook{Asks: levels(0.45, 100)}, polymarket.OrderBook{Asks: levels(0.50, 60)}),
+			want:     []Opportunity{{Kind: BuyBoth, BestPrices: []float64{0.45, 0.50}, PriceSum: 0.95, Edge: 0.05, Size: 60, NotionalUSD: 57, ProfitUSD: 3}},
+		},
+		{
+			name:     "fees shrink the edge",
+			snapshot: binary("m", polymarket.OrderBook{Asks: levels(0.45, 100)}, polymarket.OrderBook{Asks: levels(0.50, 60)}),
+			opts:     Options{FeeBps: 100},
+			// 1 - 0.95 * 1.01
+			want: []Opportunity{{Kind: BuyBoth, BestPrices: []float64{0.45, 0.50}, PriceSum: 0.95, Edge: 0.0405, Size: 60, NotionalUSD: 57, ProfitUSD: 2.43}},
+		},
+		{
+			name:      "fees erase the edge",
+			snapshot:  binary("m", polymarket.OrderBook{Asks: levels(0.45, 100)}, polymarket.OrderBook{Asks: levels(0.50, 60)}),
+			opts:      Options{FeeBps: 600},
+			wantEmpty: true,
+		},
+		{
+			// Levels arrive unsorted. The walk pairs 60 at 0.45+0.50, 40 at
+			// 0.45+0.51 and 100 at 0.48+0.51, then runs out of Yes asks.
+			name: "walks the ladder",
+			snapshot: binary("m",
+				polymarket.OrderBook{Asks: levels(0.48, 100, 0.45, 100)},
+				polymarket.OrderBook{Asks: levels(0.51, 200, 0.50, 60)}),
+			want: []Opportunity{{Kind: BuyBoth, BestPrices: []float64{0.45, 0.50}, PriceSum: 0.95, Edge: 0.05, Size: 200, NotionalUSD: 194.4, ProfitUSD: 5.6}},
+		},
+		{
+			name: "min edge stops the walk",
+			snapshot: binary("m",
+				polymarket.OrderBook{Asks: levels(0.48, 100, 0.45, 100)},
+				polymarket.OrderBook{Asks: levels(0.51, 200, 0.50, 60)}),
+			opts: Options{MinEdge: 0.02},
+			want: []Opportunity{{Kind: BuyBoth, BestPrices: []float64{0.45, 0.50}, PriceSum: 0.95, Edge: 0.05, Size: 100, NotionalUSD: 95.4, ProfitUSD: 4.6}},
+		},
+		{
+			name:      "min edge above the top of book",
+			snapshot:  binary("m", polymarket.OrderBook{Asks: levels(0.45, 100)}, polymarket.OrderBook{Asks: levels(0.50, 60)}),
+			opts:      Options{MinEdge: 0.06},
+			wantEmpty: true,
+		},
+		{
+			name: "sell both with fees",
+			snapshot: binary("m",
+				polymarket.OrderBook{Bids: levels(0.50, 100, 0.55, 50)},
+				polymarket.OrderBook{Bids: levels(0.50, 80)}),
+			opts: Options{FeeBps: 200},
+			// 50 at 1.05 * 0.98 - 1, then 1.00 * 0.98 - 1 < 0 stops the walk.
+			want: []Opportunity{{Kind: SellBoth, BestPrices: []float64{0.55, 0.50}, PriceSum: 1.05, Edge: 0.029, Size: 50, NotionalUSD: 52.5, ProfitUSD: 1.45}},
+		},
+		{
+			name:      "fair prices",
+			snapshot:  binary("m", polymarket.OrderBook{Asks: levels(0.52, 100), Bids: levels(0.48, 100)}, polymarket.OrderBook{Asks: levels(0.50, 100), Bids: levels(0.46, 100)}),
+			wantEmpty: true,
+		},
+		{
+			name:      "empty and zero-size levels are skipped",
+			snapshot:  binary("m", polymarket.OrderBook{Asks: levels(0.10, 0, 0, 50)}, polymarket.OrderBook{Asks: levels(0.50, 60)}),
+			wantEmpty: true,
+		},
+		{
+			name: "markets with more than two outcomes are skipped",
+			snapshot: Snapshot{ConditionID: "m", Books: []polymarket.OrderBook{
+				{Asks: levels(0.2, 10)}, {Asks: levels(0.2, 10)}, {Asks: levels(0.2, 10)},
+			}},
+			wantEmpty: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Scan([]Snapshot{tt.snapshot}, tt.opts)
+			if tt.wantEmpty {
+				if len(got) != 0 {
+					t.Fatalf("Scan() = %+v, want none", got)
+				}
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Scan() = %+v, want %d opportunities", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				assertOpportunity(t, got[i], want)
+				if got[i].ConditionID != tt.snapshot.ConditionID {
+					t.Errorf("ConditionID = %q, want %q", got[i].ConditionID, tt.snapshot.ConditionID)
+				}
+			}
+		})
+	}
+}
+
+func TestScanSortsByProfit(t *testing.T) {
+	snapshots := []Snapshot{
+		binary("small", polymarket.OrderBook{Asks: levels(0.45, 10)}, polymarket.OrderBook{Asks: levels(0.50, 10)}),
+		binary("large", polymarket.OrderBook{Asks: levels(0.40, 100)}, polymarket.OrderBook{Asks: levels(0.50, 100)}),
+		binary("both", polymarket.OrderBook{Asks: levels(0.45, 20), Bids: levels(0.60, 40)}, polymarket.OrderBook{Asks: levels(0.50, 20), Bids: levels(0.45, 40)}),
+	}
+	got := Scan(snapshots, Options{})
+
+	want := []struct {
+		id     string
+		kind   string
+		profit float64
+	}{
+		{id: "large", kind: BuyBoth, profit: 10},
+		{id: "both", kind: SellBoth, profit: 2},
+		{id: "both", kind: BuyBoth, profit: 1},
+		{id: "small", kind: BuyBoth, profit: 0.5},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Scan() = %+v, want %d opportunities", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].ConditionID != w.id || got[i].Kind != w.kind || got[i].ProfitUSD != w.profit {
+			t.Errorf("opportunity %d = %s %s %v, want %s %s %v", i, got[i].ConditionID, got[i].Kind, got[i].ProfitUSD, w.id, w.kind, w.profit)
+		}
+	}
+}
+
+func assertOpportunity(t *testing.T, got, want Opportunity) {
+	t.Helper()
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if got.Kind != want.Kind ||
+		len(got.BestPrices) != 2 || !near(got.BestPrices[0], want.BestPrices[0]) || !near(got.BestPrices[1], want.BestPrices[1]) ||
+		!near(got.PriceSum, want.PriceSum) || !near(got.Edge, want.Edge) || !near(got.Size, want.Size) ||
+		!near(got.NotionalUSD, want.NotionalUSD) || !near(got.ProfitUSD, want.ProfitUSD) {
+		t.Errorf("opportunity = %+v, want %+v", got, want)
+	}
+}
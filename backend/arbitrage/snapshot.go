@@ -0,0 +1,170 @@
+package arbitrage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+)
+
+const (
+	marketBatchSize = 50
+	bookWorkers     = 6
+)
+
+// Snapshot is the order books of every outcome token of one market at one
+// moment. Books are ordered like Outcomes.
+type Snapshot struct {
+	ConditionID string                 `json:"condition_id"`
+	Question    string                 `json:"question"`
+	Slug        string                 `json:"slug"`
+	Outcomes    []string               `json:"outcomes"`
+	TokenIDs    []string               `json:"token_ids"`
+	Books       []polymarket.OrderBook `json:"books"`
+	FetchedAt   time.Time              `json:"fetched_at"`
+}
+
+// Recording is the file format for recorded books replayed offline.
+type Recording struct {
+	RecordedAt time.Time  `json:"recorded_at"`
+	Sport      string     `json:"sport"`
+	Snapshots  []Snapshot `json:"snapshots"`
+}
+
+// SportMarketIDs returns the condition IDs of open markets in games of sport
+// that have not finished, from the sports index.
+func SportMarketIDs(index *sports.Index, sport string) []string {
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, game := range index.Games(sports.GameFilter{Sport: sport}) {
+		if game.Status == sports.GameFinal {
+			continue
+		}
+		for _, market := range game.Markets {
+			if market.Closed || seen[market.ConditionID] {
+				continue
+			}
+			seen[market.ConditionID] = true
+			ids = append(ids, market.ConditionID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ActiveMarkets loads market details for conditionIDs and keeps the active
+// ones with an order book per outcome.
+func ActiveMarkets(client *polymarket.Client, conditionIDs []string) ([]polymarket.Market, error) {
+	markets := []polymarket.Market{}
+	for start := 0; start < len(conditionIDs); start += marketBatchSize {
+		end := start + marketBatchSize
+		if end > len(conditionIDs) {
+			end = len(conditionIDs)
+		}
+		batch, err := client.GetMarkets(conditionIDs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, market := range batch {
+			if !market.Active || market.Closed {
+				continue
+			}
+			if tokens := market.TokenIDs(); len(tokens) < 2 || len(tokens) != len(market.OutcomeNames()) {
+				continue
+			}
+			markets = append(markets, market)
+		}
+	}
+	return markets, nil
+}
+
+// Fetch reads the order book of every outcome token of each market. Markets
+// whose books cannot be read are skipped; an error is returned only when no
+// market could be read.
+func Fetch(ctx context.Context, client *polymarket.Client, markets []polymarket.Market) ([]Snapshot, error) {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		snapshots []Snapshot
+		firstErr  error
+	)
+	slots := make(chan struct{}, bookWorkers)
+	for _, market := range markets {
+		wg.Add(1)
+		go func(market polymarket.Market) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if ctx.Err() != nil {
+				return
+			}
+
+			snapshot := Snapshot{
+				ConditionID: strings.ToLower(market.ConditionID),
+				Question:    market.Question,
+				Slug:        market.Slug,
+				Outcomes:    market.OutcomeNames(),
+				TokenIDs:    market.TokenIDs(),
+				FetchedAt:   time.Now().UTC(),
+			}
+			for _, tokenID := range snapshot.TokenIDs {
+				book, err := client.GetOrderBook(tokenID)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				snapshot.Books = append(snapshot.Books, *book)
+			}
+
+			mu.Lock()
+			snapshots = append(snapshots, snapshot)
+			mu.Unlock()
+		}(market)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ConditionID < snapshots[j].ConditionID })
+	return snapshots, nil
+}
+
+// LoadRecording reads recorded books for an offline scan.
+func LoadRecording(path string) (Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Recording{}, fmt.Errorf("read recorded books: %w", err)
+	}
+	var recording Recording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return Recording{}, fmt.Errorf("parse recorded books %s: %w", path, err)
+	}
+	return recording, nil
+}
+
+// SaveRecording writes books so a scan can be replayed offline.
+func SaveRecording(path string, recording Recording) error {
+	data, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode recorded books: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write recorded books: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,471 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+)
+
+const (
+	ModeFixed        = "fixed"
+	ModeProportional = "proportional"
+
+	SlippageHistory = "history"
+	SlippageNone    = "none"
+
+	// historyTolerance is how far from the copy time a history sample may
+	// be and still stand in for the fill price.
+	historyTolerance = time.Hour
+	minStakeUSD      = 0.01
+)
+
+// Strategy describes how the wallet is copied.
+type Strategy struct {
+	// Mode is ModeFixed (Stake USD per copied buy) or ModeProportional
+	// (Ratio times the wallet's own notional).
+	Mode  string  `json:"mode"`
+	Stake float64 `json:"stake_usd"`
+	Ratio float64 `json:"ratio"`
+	// DelaySeconds is how long after the wallet's trade the copy fills.
+	DelaySeconds int `json:"delay_seconds"`
+	// Slippage is SlippageHistory (fill at the token's CLOB price at copy
+	// time) or SlippageNone (fill at the wallet's price). SlippageBps is an
+	// extra adverse move applied on top of either.
+	Slippage    string  `json:"slippage"`
+	SlippageBps float64 `json:"slippage_bps"`
+	// MaxExposure caps the cost of open positions; zero means no cap.
+	MaxExposure float64 `json:"max_exposure_usd"`
+	Bankroll    float64 `json:"bankroll_usd"`
+	// The wallet's sells are copied by selling the same fraction of the
+	// position unless IgnoreSells is set, in which case copies are held to
+	// resolution.
+	IgnoreSells bool `json:"ignore_sells"`
+}
+
+// DefaultStrategy copies every buy and sell with $100 at CLOB prices.
+func DefaultStrategy() Strategy {
+	return Strategy{
+		Mode:     ModeFixed,
+		Stake:    100,
+		Ratio:    0.1,
+		Slippage: SlippageHistory,
+		Bankroll: 10000,
+	}
+}
+
+// Fill is one copied trade.
+type Fill struct {
+	Time        time.Time `json:"time"`
+	ConditionID string    `json:"condition_id"`
+	Question    string    `json:"question"`
+	Outcome     string    `json:"outcome"`
+	Side        string    `json:"side"`
+	WalletPrice float64   `json:"wallet_price"`
+	FillPrice   float64   `json:"fill_price"`
+	Shares      float64   `json:"shares"`
+	NotionalUSD float64   `json:"notional_usd"`
+}
+
+// EquityPoint is the copied portfolio after one fill or settlement. Open
+// positions are marked at their latest known price.
+type EquityPoint struct {
+	Time        time.Time `json:"time"`
+	Event       string    `json:"event"`
+	ConditionID string    `json:"condition_id"`
+	Cash        float64   `json:"cash"`
+	Exposure    float64   `json:"exposure"`
+	Equity      float64   `json:"equity"`
+}
+
+// Summary aggregates a run.
+type Summary struct {
+	WalletTrades   int            `json:"wallet_trades"`
+	Copied         int            `json:"copied"`
+	Skipped        map[string]int `json:"skipped"`
+	TotalStakedUSD float64        `json:"total_staked_usd"`
+	RealizedPnL    float64        `json:"realized_pnl"`
+	UnrealizedPnL  float64        `json:"unrealized_pnl"`
+	FinalEquity    float64        `json:"final_equity"`
+	ReturnPct      float64        `json:"return_pct"`
+	MaxDrawdownPct float64        `json:"max_drawdown_pct"`
+	MarketsSettled int            `json:"markets_settled"`
+	MarketsOpen    int            `json:"markets_open"`
+	WinRate        float64        `json:"win_rate"`
+	AvgBuySlippage float64        `json:"avg_buy_slippage"`
+	FirstTrade     *time.Time     `json:"first_trade,omitempty"`
+	LastTrade      *time.Time     `json:"last_trade,omitempty"`
+	HistoryHitRate float64        `json:"history_hit_rate"`
+}
+
+// Result is a full backtest.
+type Result struct {
+	Wallet   string        `json:"wallet"`
+	Sport    string        `json:"sport"`
+	Strategy Strategy      `json:"strategy"`
+	Summary  Summary       `json:"summary"`
+	Equity   []EquityPoint `json:"equity"`
+	Fills    []Fill        `json:"fills"`
+}
+
+type holding struct {
+	conditionID string
+	shares      float64
+	cost        float64
+	mark        float64
+}
+
+type event struct {
+	at     time.Time
+	settle bool
+	trade  polymarket.EnrichedTrade
+}
+
+// Run replays the input's trades in time order under strategy. Markets that
+// resolved are settled at their close time; positions still open at the end
+// are marked at the market's current price.
+func Run(input Input, strategy Strategy) Result {
+	strategy = WithDefaults(strategy)
+	delay := time.Duration(strategy.DelaySeconds) * time.Second
+
+	result := Result{
+		Wallet:   input.Wallet,
+		Sport:    input.Sport,
+		Strategy: strategy,
+		Summary:  Summary{WalletTrades: len(input.Trades), Skipped: map[string]int{}},
+		Equity:   []EquityPoint{},
+		Fills:    []Fill{},
+	}
+
+	events := []event{}
+	var lastTrade time.Time
+	for _, trade := range input.Trades {
+		at, err := time.Parse(time.RFC3339, trade.TradeTime)
+		if err != nil {
+			result.Summary.Skipped["bad_time"]++
+			continue
+		}
+		events = append(events, event{at: at.Add(delay), trade: trade})
+		if result.Summary.FirstTrade == nil || at.Before(*result.Summary.FirstTrade) {
+			first := at
+			result.Summary.FirstTrade = &first
+		}
+		if at.After(lastTrade) {
+			lastTrade = at
+		}
+	}
+	if !lastTrade.IsZero() {
+		last := lastTrade
+		result.Summary.LastTrade = &last
+	}
+
+	settledAt := map[string]time.Time{}
+	for conditionID, market := range input.Markets {
+		if _, ok := market.ResolvedPrices(); !ok {
+			continue
+		}
+		at, ok := sports.ParseGammaTime(market.ClosedTime)
+		if !ok {
+			// Without a close time settle once every copied trade is in.
+			at = lastTrade.Add(delay + time.Second)
+		}
+		settledAt[conditionID] = at
+		events = append(events, event{at: at, settle: true, trade: polymarket.EnrichedTrade{ConditionID: conditionID}})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return !events[i].settle && events[j].settle
+	})
+
+	cash := strategy.Bankroll
+	holdings := map[string]*holding{}
+	walletShares := map[string]float64{}
+	wins, settled, historyHits, historyLookups := 0, 0, 0, 0
+	slippageSum, slippageCount := 0.0, 0
+
+	record := func(at time.Time, kind, conditionID string) {
+		exposure, value := 0.0, 0.0
+		for _, h := range holdings {
+			exposure += h.cost
+			value += h.shares * h.mark
+		}
+		result.Equity = append(result.Equity, EquityPoint{
+			Time:        at,
+			Event:       kind,
+			ConditionID: conditionID,
+			Cash:        round(cash, 2),
+			Exposure:    round(exposure, 2),
+			Equity:      round(cash+value, 2),
+		})
+	}
+
+	for _, ev := range events {
+		trade := ev.trade
+		conditionID := strings.ToLower(trade.ConditionID)
+
+		if ev.settle {
+			prices, _ := input.Markets[conditionID].ResolvedPrices()
+			names := input.Markets[conditionID].OutcomeNames()
+			touched := false
+			for key, h := range holdings {
+				if h.conditionID != conditionID {
+					continue
+				}
+				payout := 0.0
+				if i := outcomeIndex(names, outcomeOf(key)); i >= 0 && i < len(prices) {
+					payout = h.shares * prices[i]
+				}
+				cash += payout
+				result.Summary.RealizedPnL += payout - h.cost
+				if payout > h.cost {
+					wins++
+				}
+				settled++
+				delete(holdings, key)
+				touched = true
+			}
+			if touched {
+				result.Summary.MarketsSettled++
+				record(ev.at, "settle", conditionID)
+			}
+			continue
+		}
+
+		key := conditionID + "|" + trade.Outcome
+		side := strings.ToUpper(trade.Side)
+		heldBefore := walletShares[key]
+		if side == "BUY" {
+			walletShares[key] += trade.Size
+		} else {
+			walletShares[key] = math.Max(heldBefore-trade.Size, 0)
+		}
+
+		if closeAt, ok := settledAt[conditionID]; ok && !ev.at.Before(closeAt) {
+			result.Summary.Skipped["resolved_before_copy"]++
+			continue
+		}
+
+		price := trade.Price
+		if strategy.Slippage == SlippageHistory {
+			historyLookups++
+			if p, ok := input.priceAt(conditionID, trade.Outcome, ev.at); ok {
+				price = p
+				historyHits++
+			}
+		}
+
+		switch side {
+		case "BUY":
+			price = math.Min(price*(1+strategy.SlippageBps/10000), 0.999)
+			if price <= 0 {
+				result.Summary.Skipped["no_price"]++
+				continue
+			}
+			stake := strategy.StakeFor(trade.Size * trade.Price)
+			if strategy.MaxExposure > 0 {
+				exposure := 0.0
+				for _, h := range holdings {
+					exposure += h.cost
+				}
+				if room := strategy.MaxExposure - exposure; stake > room {
+					stake = room
+					if stake < minStakeUSD {
+						result.Summary.Skipped["max_exposure"]++
+						continue
+					}
+				}
+			}
+			if stake > cash {
+				stake = cash
+			}
+			if stake < minStakeUSD {
+				result.Summary.Skipped["insufficient_cash"]++
+				continue
+			}
+
+			shares := stake / price
+			h, ok := holdings[key]
+			if !ok {
+				h = &holding{conditionID: conditionID}
+				holdings[key] = h
+			}
+			h.shares += shares
+			h.cost += stake
+			h.mark = price
+			cash -= stake
+			result.Summary.TotalStakedUSD += stake
+			slippageSum += price - trade.Price
+			slippageCount++
+			result.Fills = append(result.Fills, newFill(ev.at, trade, side, price, shares, stake))
+
+		case "SELL":
+			h, ok := holdings[key]
+			if strategy.IgnoreSells || !ok {
+				result.Summary.Skipped["sell_not_copied"]++
+				continue
+			}
+			price = math.Max(price*(1-strategy.SlippageBps/10000), 0)
+			// Sell the fraction the wallet sold; sells of shares bought
+			// before the trade window close the whole copied position.
+			fraction := 1.0
+			if heldBefore > 0 {
+				fraction = math.Min(trade.Size/heldBefore, 1)
+			}
+			shares := h.shares * fraction
+			cost := h.cost * fraction
+			proceeds := shares * price
+			cash += proceeds
+			result.Summary.RealizedPnL += proceeds - cost
+			h.shares -= shares
+			h.cost -= cost
+			h.mark = price
+			if h.shares <= 1e-9 {
+				delete(holdings, key)
+			}
+			result.Fills = append(result.Fills, newFill(ev.at, trade, side, price, shares, proceeds))
+
+		default:
+			result.Summary.Skipped["unknown_side"]++
+			continue
+		}
+
+		result.Summary.Copied++
+		record(ev.at, strings.ToLower(side), conditionID)
+	}
+
+	// Mark what is still open at the market's current price.
+	openMarkets := map[string]bool{}
+	for key, h := range holdings {
+		market, ok := input.Markets[h.conditionID]
+		if ok {
+			prices := market.Prices()
+			if i := outcomeIndex(market.OutcomeNames(), outcomeOf(key)); i >= 0 && i < len(prices) {
+				h.mark = prices[i]
+			}
+		}
+		result.Summary.UnrealizedPnL += h.shares*h.mark - h.cost
+		openMarkets[h.conditionID] = true
+	}
+	result.Summary.MarketsOpen = len(openMarkets)
+	if len(holdings) > 0 {
+		record(time.Now().UTC(), "mark", "")
+	}
+
+	final := cash
+	for _, h := range holdings {
+		final += h.shares * h.mark
+	}
+	summary := &result.Summary
+	summary.FinalEquity = round(final, 2)
+	summary.RealizedPnL = round(summary.RealizedPnL, 2)
+	summary.UnrealizedPnL = round(summary.UnrealizedPnL, 2)
+	summary.TotalStakedUSD = round(summary.TotalStakedUSD, 2)
+	if strategy.Bankroll > 0 {
+		summary.ReturnPct = round((final-strategy.Bankroll)/strategy.Bankroll*100, 2)
+	}
+	summary.MaxDrawdownPct = maxDrawdown(strategy.Bankroll, result.Equity)
+	if settled > 0 {
+		summary.WinRate = round(float64(wins)/float64(settled), 4)
+	}
+	if slippageCount > 0 {
+		summary.AvgBuySlippage = round(slippageSum/float64(slippageCount), 4)
+	}
+	if historyLookups > 0 {
+		summary.HistoryHitRate = round(float64(historyHits)/float64(historyLookups), 4)
+	}
+	return result
+}
+
+// StakeFor is the USD to put behind a copy of a buy worth walletNotional.
+func (s Strategy) StakeFor(walletNotional float64) float64 {
+	if s.Mode == ModeProportional {
+		return s.Ratio * walletNotional
+	}
+	return s.Stake
+}
+
+// WithDefaults fills unset or invalid fields from DefaultStrategy.
+func WithDefaults(strategy Strategy) Strategy {
+	defaults := DefaultStrategy()
+	strategy.Mode = strings.ToLower(strings.TrimSpace(strategy.Mode))
+	if strategy.Mode != ModeProportional {
+		strategy.Mode = ModeFixed
+	}
+	if strategy.Stake <= 0 {
+		strategy.Stake = defaults.Stake
+	}
+	if strategy.Ratio <= 0 {
+		strategy.Ratio = defaults.Ratio
+	}
+	if strategy.DelaySeconds < 0 {
+		strategy.DelaySeconds = 0
+	}
+	strategy.Slippage = strings.ToLower(strings.TrimSpace(strategy.Slippage))
+	if strategy.Slippage != SlippageNone {
+		strategy.Slippage = SlippageHistory
+	}
+	if strategy.SlippageBps < 0 {
+		strategy.SlippageBps = 0
+	}
+	if strategy.MaxExposure < 0 {
+		strategy.MaxExposure = 0
+	}
+	if strategy.Bankroll <= 0 {
+		strategy.Bankroll = defaults.Bankroll
+	}
+	return strategy
+}
+
+func newFill(at time.Time, trade polymarket.EnrichedTrade, side string, price, shares, notional float64) Fill {
+	return Fill{
+		Time:        at,
+		ConditionID: strings.ToLower(trade.ConditionID),
+		Question:    trade.MarketQuestion,
+		Outcome:     trade.Outcome,
+		Side:        side,
+		WalletPrice: trade.Price,
+		FillPrice:   round(price, 4),
+		Shares:      round(shares, 4),
+		NotionalUSD: round(notional, 2),
+	}
+}
+
+func maxDrawdown(start float64, curve []EquityPoint) float64 {
+	peak, worst := start, 0.0
+	for _, point := range curve {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+		if peak > 0 {
+			worst = math.Max(worst, (peak-point.Equity)/peak)
+		}
+	}
+	return round(worst*100, 2)
+}
+
+func outcomeOf(key string) string {
+	if i := strings.Index(key, "|"); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
+
+func outcomeIndex(names []string, outcome string) int {
+	for i, name := range names {
+		if strings.EqualFold(name, outcome) {
+			return i
+		}
+	}
+	return -1
+}
+
+func round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
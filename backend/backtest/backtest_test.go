@@ -0,0 +1,294 @@
+package backtest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+)
+
+var start = time.Date(2026, 1, 10, 18, 0, 0, 0, time.UTC)
+
+// testInput is a wallet that buys Yes in m1 at 0.40, buys No in m2 at 0.50,
+// then sells half its Yes at 0.60. m1 resolves Yes ten hours in; m2 is
+// still open with No at 0.40.
+func testInput() Input {
+	trade := func(hours int, conditionID, side, outcome string, size, price float64) polymarket.EnrichedTrade {
+		return polymarket.EnrichedTrade{
+			ConditionID: conditionID,
+			TradeTime:   start.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339),
+			Side:        side,
+			Outcome:     outcome,
+			Size:        size,
+			Price:       price,
+		}
+	}
+	return Input{
+		Wallet: "0xwallet",
+		Sport:  "nba",
+		Trades: []polymarket.EnrichedTrade{
+			trade(0, "m1", "BUY", "Yes", 100, 0.40),
+			trade(1, "m2", "BUY", "No", 50, 0.50),
+			trade(2, "m1", "SELL", "Yes", 50, 0.60),
+			{ConditionID: "m1", TradeTime: "not a time", Side: "BUY", Outcome: "Yes", Size: 1, Price: 0.5},
+		},
+		Markets: map[string]polymarket.Market{
+			"m1": {
+				ConditionID:   "m1",
+				Outcomes:      `["Yes", "No"]`,
+				OutcomePrices: `["1", "0"]`,
+				ClobTokenIDs:  `["m1-yes", "m1-no"]`,
+				Closed:        true,
+				ClosedTime:    start.Add(10 * time.Hour).Format(time.RFC3339),
+			},
+			"m2": {
+				ConditionID:   "m2",
+				Outcomes:      `["Yes", "No"]`,
+				OutcomePrices: `["0.6", "0.4"]`,
+				ClobTokenIDs:  `["m2-yes", "m2-no"]`,
+			},
+		},
+	}
+}
+
+func TestRunStrategies(t *testing.T) {
+	base := Strategy{Mode: ModeFixed, Stake: 100, Slippage: SlippageNone, Bankroll: 1000}
+
+	tests := []struct {
+		name       string
+		strategy   func(Strategy) Strategy
+		copied     int
+		skipped    map[string]int
+		staked     float64
+		realized   float64
+		unrealized float64
+		final      float64
+		returnPct  float64
+		settled    int
+		open       int
+		winRate    float64
+	}{
+		{
+			// 250 Yes shares, half sold for +25 and the rest paid out for
+			// +75; 200 No shares marked from 100 down to 80.
+			name:       "fixed stake",
+			strategy:   func(s Strategy) Strategy { return s },
+			copied:     3,
+			skipped:    map[string]int{"bad_time": 1},
+			staked:     200,
+			realized:   100,
+			unrealized: -20,
+			final:      1080,
+			returnPct:  8,
+			settled:    1,
+			open:       1,
+			winRate:    1,
+		},
+		{
+			name:       "proportional stake",
+			strategy:   func(s Strategy) Strategy { s.Mode, s.Ratio = ModeProportional, 0.5; return s },
+			copied:     3,
+			skipped:    map[string]int{"bad_time": 1},
+			staked:     32.5,
+			realized:   20,
+			unrealized: -2.5,
+			final:      1017.5,
+			returnPct:  1.75,
+			settled:    1,
+			open:       1,
+			winRate:    1,
+		},
+		{
+			name:       "sells ignored",
+			strategy:   func(s Strategy) Strategy { s.IgnoreSells = true; return s },
+			copied:     2,
+			skipped:    map[string]int{"bad_time": 1, "sell_not_copied": 1},
+			staked:     200,
+			realized:   150,
+			unrealized: -20,
+			final:      1130,
+			returnPct:  13,
+			settled:    1,
+			open:       1,
+			winRate:    1,
+		},
+		{
+			// The second buy only has 50 of room left under the cap.
+			name:       "max exposure",
+			strategy:   func(s Strategy) Strategy { s.MaxExposure = 150; return s },
+			copied:     3,
+			skipped:    map[string]int{"bad_time": 1},
+			staked:     150,
+			realized:   100,
+			unrealized: -10,
+			final:      1090,
+			returnPct:  9,
+			settled:    1,
+			open:       1,
+			winRate:    1,
+		},
+		{
+			// The second buy is capped by the 20 left in the bankroll.
+			name:       "bankroll runs out",
+			strategy:   func(s Strategy) Strategy { s.Bankroll = 120; return s },
+			copied:     3,
+			skipped:    map[string]int{"bad_time": 1},
+			staked:     120,
+			realized:   100,
+			unrealized: -4,
+			final:      216,
+			returnPct:  80,
+			settled:    1,
+			open:       1,
+			winRate:    1,
+		},
+		{
+			// Copies of m1 trades would land after it resolved.
+			name:       "delay past resolution",
+			strategy:   func(s Strategy) Strategy { s.DelaySeconds = 11 * 3600; return s },
+			copied:     1,
+			skipped:    map[string]int{"bad_time": 1, "resolved_before_copy": 2},
+			staked:     100,
+			unrealized: -20,
+			final:      980,
+			returnPct:  -2,
+			open:       1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Run(testInput(), tt.strategy(base))
+			s := result.Summary
+			if s.WalletTrades != 4 || s.Copied != tt.copied || !reflect.DeepEqual(s.Skipped, tt.skipped) {
+				t.Errorf("trades %d, copied %d, skipped %v; want 4, %d, %v", s.WalletTrades, s.Copied, s.Skipped, tt.copied, tt.skipped)
+			}
+			if s.TotalStakedUSD != tt.staked || s.RealizedPnL != tt.realized || s.UnrealizedPnL != tt.unrealized {
+				t.Errorf("staked %v, realized %v, unrealized %v; want %v, %v, %v", s.TotalStakedUSD, s.RealizedPnL, s.UnrealizedPnL, tt.staked, tt.realized, tt.unrealized)
+			}
+			if s.FinalEquity != tt.final || s.ReturnPct != tt.returnPct {
+				t.Errorf("final equity %v, return %v%%; want %v, %v%%", s.FinalEquity, s.ReturnPct, tt.final, tt.returnPct)
+			}
+			if s.MarketsSettled != tt.settled || s.MarketsOpen != tt.open || s.WinRate != tt.winRate {
+				t.Errorf("settled %d, open %d, win rate %v; want %d, %d, %v", s.MarketsSettled, s.MarketsOpen, s.WinRate, tt.settled, tt.open, tt.winRate)
+			}
+			if len(result.Fills) != tt.copied {
+				t.Errorf("%d fills, want %d", len(result.Fills), tt.copied)
+			}
+		})
+	}
+}
+
+func TestRunEquityCurve(t *testing.T) {
+	result := Run(testInput(), Strategy{Mode: ModeFixed, Stake: 100, Slippage: SlippageNone, Bankroll: 1000})
+
+	want := []struct {
+		event  string
+		cash   float64
+		equity float64
+	}{
+		{event: "buy", cash: 900, equity: 1000},
+		{event: "buy", cash: 800, equity: 1000},
+		{event: "sell", cash: 875, equity: 1050},
+		{event: "settle", cash: 1000, equity: 1100},
+		{event: "mark", cash: 1000, equity: 1080},
+	}
+	if len(result.Equity) != len(want) {
+		t.Fatalf("equity curve = %+v, want %d points", result.Equity, len(want))
+	}
+	for i, w := range want {
+		got := result.Equity[i]
+		if got.Event != w.event || got.Cash != w.cash || got.Equity != w.equity {
+			t.Errorf("point %d = %s cash %v equity %v, want %s cash %v equity %v", i, got.Event, got.Cash, got.Equity, w.event, w.cash, w.equity)
+		}
+	}
+	if got, want := result.Summary.MaxDrawdownPct, round(20.0/1100*100, 2); got != want {
+		t.Errorf("max drawdown = %v%%, want %v%%", got, want)
+	}
+	if first := result.Summary.FirstTrade; first == nil || !first.Equal(start) {
+		t.Errorf("first trade = %v, want %v", first, start)
+	}
+	if last := result.Summary.LastTrade; last == nil || !last.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("last trade = %v, want %v", last, start.Add(2*time.Hour))
+	}
+}
+
+func TestRunSlippage(t *testing.T) {
+	input := testInput()
+	input.History = map[string][]polymarket.PricePoint{
+		// Within the hour of the first buy, but two hours before the sell.
+		"m1-yes": {{T: start.Add(-30 * time.Minute).Unix(), P: 0.45}},
+	}
+
+	tests := []struct {
+		name       string
+		strategy   Strategy
+		fillPrices []float64
+		avgSlip    float64
+		hitRate    float64
+	}{
+		{
+			name:       "wallet prices",
+			strategy:   Strategy{Slippage: SlippageNone},
+			fillPrices: []float64{0.40, 0.50, 0.60},
+		},
+		{
+			name:       "fixed adverse move",
+			strategy:   Strategy{Slippage: SlippageNone, SlippageBps: 100},
+			fillPrices: []float64{0.404, 0.505, 0.594},
+			avgSlip:    0.0045,
+		},
+		{
+			// Only the first buy has a history sample close enough.
+			name:       "price history",
+			strategy:   Strategy{Slippage: SlippageHistory},
+			fillPrices: []float64{0.45, 0.50, 0.60},
+			avgSlip:    0.025,
+			hitRate:    0.3333,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Run(input, tt.strategy)
+			if len(result.Fills) != len(tt.fillPrices) {
+				t.Fatalf("fills = %+v, want %d", result.Fills, len(tt.fillPrices))
+			}
+			for i, want := range tt.fillPrices {
+				if math.Abs(result.Fills[i].FillPrice-want) > 1e-9 {
+					t.Errorf("fill %d price = %v, want %v", i, result.Fills[i].FillPrice, want)
+				}
+			}
+			if result.Summary.AvgBuySlippage != tt.avgSlip || result.Summary.HistoryHitRate != tt.hitRate {
+				t.Errorf("avg buy slippage %v, history hit rate %v; want %v, %v", result.Summary.AvgBuySlippage, result.Summary.HistoryHitRate, tt.avgSlip, tt.hitRate)
+			}
+		})
+	}
+}
+
+func TestWithDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Strategy
+		want Strategy
+	}{
+		{name: "zero", in: Strategy{}, want: DefaultStrategy()},
+		{
+			name: "invalid values",
+			in:   Strategy{Mode: "martingale", Stake: -5, Ratio: -1, DelaySeconds: -3, Slippage: "magic", SlippageBps: -10, MaxExposure: -1, Bankroll: -100},
+			want: DefaultStrategy(),
+		},
+		{
+			name: "kept and normalized",
+			in:   Strategy{Mode: " Proportional ", Stake: 5, Ratio: 0.25, DelaySeconds: 30, Slippage: "NONE", SlippageBps: 50, MaxExposure: 500, Bankroll: 2000, IgnoreSells: true},
+			want: Strategy{Mode: ModeProportional, Stake: 5, Ratio: 0.25, DelaySeconds: 30, Slippage: SlippageNone, SlippageBps: 50, MaxExposure: 500, Bankroll: 2000, IgnoreSells: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithDefaults(tt.in); got != tt.want {
+				t.Errorf("WithDefaults(%+v) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+)
+
+const (
+	tradePageSize   = 500
+	marketBatchSize = 50
+	historyWorkers  = 4
+)
+
+// Input is everything a run needs, so a fetched wallet can be saved and
+// replayed offline under different strategies.
+type Input struct {
+	RecordedAt time.Time                  `json:"recorded_at"`
+	Wallet     string                     `json:"wallet"`
+	Sport      string                     `json:"sport"`
+	Trades     []polymarket.EnrichedTrade `json:"trades"`
+	// Markets is keyed by lowercase condition ID.
+	Markets map[string]polymarket.Market `json:"markets"`
+	// History is CLOB price history keyed by outcome token ID.
+	History map[string][]polymarket.PricePoint `json:"history,omitempty"`
+}
+
+// Load reads up to tradeLimit of the wallet's most recent trades, keeps the
+// sport's, enriches them with market metadata and sorts them oldest first.
+func Load(ctx context.Context, client *polymarket.Client, wallet, sport string, tradeLimit int) (Input, error) {
+	input := Input{
+		RecordedAt: time.Now().UTC(),
+		Wallet:     strings.ToLower(wallet),
+		Sport:      sport,
+		Trades:     []polymarket.EnrichedTrade{},
+		Markets:    map[string]polymarket.Market{},
+	}
+
+	trades := []polymarket.Trade{}
+	for offset := 0; offset < tradeLimit; offset += tradePageSize {
+		if err := ctx.Err(); err != nil {
+			return Input{}, err
+		}
+		limit := tradePageSize
+		if tradeLimit-offset < limit {
+			limit = tradeLimit - offset
+		}
+		page, err := client.GetTrades(wallet, limit, offset)
+		if err != nil {
+			return Input{}, fmt.Errorf("failed to get trades: %w", err)
+		}
+		for _, trade := range page {
+			if sports.MatchesMarket(trade.ConditionID, trade.Title, trade.Slug, sport) {
+				trades = append(trades, trade)
+			}
+		}
+		if len(page) < limit {
+			break
+		}
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].Timestamp < trades[j].Timestamp })
+
+	seen := map[string]bool{}
+	conditionIDs := []string{}
+	for _, trade := range trades {
+		id := strings.ToLower(trade.ConditionID)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			conditionIDs = append(conditionIDs, id)
+		}
+	}
+	for start := 0; start < len(conditionIDs); start += marketBatchSize {
+		end := start + marketBatchSize
+		if end > len(conditionIDs) {
+			end = len(conditionIDs)
+		}
+		markets, err := client.GetMarkets(conditionIDs[start:end])
+		if err != nil {
+			return Input{}, err
+		}
+		for _, market := range markets {
+			input.Markets[strings.ToLower(market.ConditionID)] = market
+		}
+	}
+
+	for _, trade := range trades {
+		enriched := polymarket.EnrichedTrade{
+			ConditionID: strings.ToLower(trade.ConditionID),
+			TradeTime:   trade.Time().UTC().Format(time.RFC3339),
+			Side:        trade.Side,
+			Size:        trade.Size,
+			Price:       trade.Price,
+			Outcome:     trade.Outcome,
+		}
+		if market, ok := input.Markets[enriched.ConditionID]; ok {
+			enriched.MarketQuestion = market.Question
+			enriched.MarketVolume = market.VolumeNum
+			enriched.MarketStartTime = market.StartDate
+		}
+		input.Trades = append(input.Trades, enriched)
+	}
+	return input, nil
+}
+
+// LoadPriceHistory fetches the CLOB price history of every traded outcome
+// token over the span its copies would fill in. Tokens whose history cannot
+// be read fall back to the wallet's price; an error is returned only when no
+// history could be read at all.
+func LoadPriceHistory(ctx context.Context, client *polymarket.Client, input *Input, delay time.Duration) error {
+	type span struct{ from, to time.Time }
+	spans := map[string]*span{}
+	for _, trade := range input.Trades {
+		token, ok := input.tokenFor(trade.ConditionID, trade.Outcome)
+		if !ok {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, trade.TradeTime)
+		if err != nil {
+			continue
+		}
+		s, ok := spans[token]
+		if !ok {
+			spans[token] = &span{from: at, to: at.Add(delay)}
+			continue
+		}
+		if at.Before(s.from) {
+			s.from = at
+		}
+		if at.Add(delay).After(s.to) {
+			s.to = at.Add(delay)
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		history  = map[string][]polymarket.PricePoint{}
+		firstErr error
+	)
+	slots := make(chan struct{}, historyWorkers)
+	for token, s := range spans {
+		wg.Add(1)
+		go func(token string, from, to time.Time) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if ctx.Err() != nil {
+				return
+			}
+
+			from, to = from.Add(-historyTolerance), to.Add(historyTolerance)
+			points, err := client.GetPriceHistory(token, from.Unix(), to.Unix(), fidelityFor(to.Sub(from)))
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			sort.Slice(points, func(i, j int) bool { return points[i].T < points[j].T })
+			history[token] = points
+		}(token, s.from, s.to)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(history) == 0 && firstErr != nil {
+		return firstErr
+	}
+	input.History = history
+	return nil
+}
+
+// fidelityFor picks a sample interval in minutes that keeps long spans to a
+// reasonable number of points.
+func fidelityFor(span time.Duration) int {
+	switch {
+	case span <= 2*24*time.Hour:
+		return 1
+	case span <= 14*24*time.Hour:
+		return 5
+	default:
+		return 60
+	}
+}
+
+func (in Input) tokenFor(conditionID, outcome string) (string, bool) {
+	market, ok := in.Markets[strings.ToLower(conditionID)]
+	if !ok {
+		return "", false
+	}
+	tokens := market.TokenIDs()
+	i := outcomeIndex(market.OutcomeNames(), outcome)
+	if i < 0 || i >= len(tokens) {
+		return "", false
+	}
+	return tokens[i], true
+}
+
+// priceAt returns the outcome's prevailing CLOB price at the given time: the
+// last sample at or before it, or failing that the next one, within
+// historyTolerance.
+func (in Input) priceAt(conditionID, outcome string, at time.Time) (float64, bool) {
+	token, ok := in.tokenFor(conditionID, outcome)
+	if !ok {
+		return 0, false
+	}
+	points := in.History[token]
+	ts := at.Unix()
+	i := sort.Search(len(points), func(i int) bool { return points[i].T > ts })
+	tolerance := int64(historyTolerance / time.Second)
+	if i > 0 && ts-points[i-1].T <= tolerance {
+		return points[i-1].P, true
+	}
+	if i < len(points) && points[i].T-ts <= tolerance {
+		return points[i].P, true
+	}
+	return 0, false
+}
+
+// LoadInput reads a saved input for an offline run.
+func LoadInput(path string) (Input, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Input{}, fmt.Errorf("read backtest input: %w", err)
+	}
+	var input Input
+	if err := json.Unmarshal(data, &input); err != nil {
+		return Input{}, fmt.Errorf("parse backtest input %s: %w", path, err)
+	}
+	return input, nil
+}
+
+// SaveInput writes an input so runs can be replayed offline.
+func SaveInput(path string, input Input) error {
+	data, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode backtest input: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write backtest input: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package clustering
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Options controls a k-means run.
+type Options struct {
+	K             int
+	Restarts      int
+	MaxIterations int
+	Seed          int64
+}
+
+// Result is the best clustering found across restarts.
+type Result struct {
+	K           int         `json:"k"`
+	Centroids   [][]float64 `json:"centroids"`
+	Assignments []int       `json:"assignments"`
+	Distances   []float64   `json:"distances"`
+	Sizes       []int       `json:"sizes"`
+	Inertia     float64     `json:"inertia"`
+}
+
+// Scaler holds the per-feature mean and standard deviation used to
+// standardize vectors before clustering.
+type Scaler struct {
+	Mean []float64 `json:"mean"`
+	Std  []float64 `json:"std"`
+}
+
+// FitScaler computes z-score parameters for each feature column. Constant
+// columns get a standard deviation of 1 so they standardize to zero.
+func FitScaler(data [][]float64) Scaler {
+	if len(data) == 0 {
+		return Scaler{}
+	}
+	dims := len(data[0])
+	mean := make([]float64, dims)
+	std := make([]float64, dims)
+	for _, row := range data {
+		for j, value := range row {
+			mean[j] += value
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(len(data))
+	}
+	for _, row := range data {
+		for j, value := range row {
+			diff := value - mean[j]
+			std[j] += diff * diff
+		}
+	}
+	for j := range std {
+		std[j] = math.Sqrt(std[j] / float64(len(data)))
+		if std[j] == 0 {
+			std[j] = 1
+		}
+	}
+	return Scaler{Mean: mean, Std: std}
+}
+
+// Transform standardizes rows with the fitted parameters.
+func (s Scaler) Transform(data [][]float64) [][]float64 {
+	out := make([][]float64, len(data))
+	for i, row := range data {
+		out[i] = make([]float64, len(row))
+		for j, value := range row {
+			out[i][j] = (value - s.Mean[j]) / s.Std[j]
+		}
+	}
+	return out
+}
+
+// Inverse maps a standardized vector back to the original feature scale.
+func (s Scaler) Inverse(vector []float64) []float64 {
+	out := make([]float64, len(vector))
+	for j, value := range vector {
+		out[j] = value*s.Std[j] + s.Mean[j]
+	}
+	return out
+}
+
+// KMeans clusters data with k-means++ seeding and keeps the restart with the
+// lowest inertia.
+func KMeans(data [][]float64, opts Options) (Result, error) {
+	if opts.K <= 0 {
+		return Result{}, fmt.Errorf("k must be positive")
+	}
+	if len(data) < opts.K {
+		return Result{}, fmt.Errorf("need at least %d vectors for k=%d, got %d", opts.K, opts.K, len(data))
+	}
+	if opts.Restarts <= 0 {
+		opts.Restarts = 10
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 100
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	best := Result{Inertia: math.Inf(1)}
+	for restart := 0; restart < opts.Restarts; restart++ {
+		result := runKMeans(data, opts.K, opts.MaxIterations, rng)
+		if result.Inertia < best.Inertia {
+			best = result
+		}
+	}
+	return best, nil
+}
+
+func runKMeans(data [][]float64, k, maxIterations int, rng *rand.Rand) Result {
+	centroids := seedCentroids(data, k, rng)
+	assignments := make([]int, len(data))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+
+	for iter := 0; iter < maxIterations; iter++ {
+		changed := false
+		for i, row := range data {
+			nearest, _ := nearestCentroid(row, centroids)
+			if nearest != assignments[i] {
+				assignments[i] = nearest
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		centroids = recomputeCentroids(data, assignments, centroids, rng)
+	}
+
+	distances := make([]float64, len(data))
+	sizes := make([]int, k)
+	inertia := 0.0
+	for i, row := range data {
+		dist := euclidean(row, centroids[assignments[i]])
+		distances[i] = dist
+		sizes[assignments[i]]++
+		inertia += dist * dist
+	}
+
+	return Result{
+		K:           k,
+		Centroids:   centroids,
+		Assignments: assignments,
+		Distances:   distances,
+		Sizes:       sizes,
+		Inertia:     inertia,
+	}
+}
+
+func seedCentroids(data [][]float64, k int, rng *rand.Rand) [][]float64 {
+	centroids := make([][]float64, 0, k)
+	centroids = append(centroids, cloneVector(data[rng.Intn(len(data))]))
+
+	weights := make([]float64, len(data))
+	for len(centroids) < k {
+		total := 0.0
+		for i, row := range data {
+			_, dist := nearestCentroid(row, centroids)
+			weights[i] = dist * dist
+			total += weights[i]
+		}
+		if total == 0 {
+			centroids = append(centroids, cloneVector(data[rng.Intn(len(data))]))
+			continue
+		}
+
+		target := rng.Float64() * total
+		chosen := len(data) - 1
+		for i, weight := range weights {
+			target -= weight
+			if target <= 0 {
+				chosen = i
+				break
+			}
+		}
+		centroids = append(centroids, cloneVector(data[chosen]))
+	}
+	return centroids
+}
+
+func recomputeCentroids(data [][]float64, assignments []int, previous [][]float64, rng *rand.Rand) [][]float64 {
+	dims := len(data[0])
+	sums := make([][]float64, len(previous))
+	counts := make([]int, len(previous))
+	for c := range sums {
+		sums[c] = make([]float64, dims)
+	}
+	for i, row := range data {
+		c := assignments[i]
+		counts[c]++
+		for j, value := range row {
+			sums[c][j] += value
+		}
+	}
+
+	centroids := make([][]float64, len(previous))
+	for c := range sums {
+		if counts[c] == 0 {
+			// Re-seed empty clusters from a random point so k stays fixed.
+			centroids[c] = cloneVector(data[rng.Intn(len(data))])
+			continue
+		}
+		centroids[c] = make([]float64, dims)
+		for j := range sums[c] {
+			centroids[c][j] = sums[c][j] / float64(counts[c])
+		}
+	}
+	return centroids
+}
+
+func nearestCentroid(row []float64, centroids [][]float64) (int, float64) {
+	best := 0
+	bestDist := math.Inf(1)
+	for c, centroid := range centroids {
+		dist := euclidean(row, centroid)
+		if dist < bestDist {
+			best = c
+			bestDist = dist
+		}
+	}
+	return best, bestDist
+}
+
+func euclidean(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		diff := a[i] - b[i]
+		sum += diff * diff
+	}
+	return math.Sqrt(sum)
+}
+
+func cloneVector(v []float64) []float64 {
+	return append([]float64(nil), v...)
+}
@@ -0,0 +1,119 @@
+package clustering
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// blobs is three well separated groups of four points each.
+var blobs = [][]float64{
+	{0, 0}, {0.2, 0.1}, {0.1, 0.3}, {0.3, 0.2},
+	{10, 10}, {10.2, 9.9}, {9.8, 10.1}, {10.1, 10.3},
+	{0, 10}, {0.3, 9.8}, {-0.2, 10.1}, {0.1, 10.2},
+}
+
+func TestKMeansSeparatesBlobs(t *testing.T) {
+	for _, seed := range []int64{1, 7, 42, 2026} {
+		result, err := KMeans(blobs, Options{K: 3, Seed: seed})
+		if err != nil {
+			t.Fatalf("seed %d: KMeans() error = %v", seed, err)
+		}
+		for group := 0; group < 3; group++ {
+			want := result.Assignments[group*4]
+			for i := group*4 + 1; i < group*4+4; i++ {
+				if result.Assignments[i] != want {
+					t.Errorf("seed %d: point %d in cluster %d, want %d with the rest of its blob", seed, i, result.Assignments[i], want)
+				}
+			}
+		}
+		if !reflect.DeepEqual(result.Sizes, []int{4, 4, 4}) {
+			t.Errorf("seed %d: Sizes = %v, want [4 4 4]", seed, result.Sizes)
+		}
+		if result.Inertia > 1 {
+			t.Errorf("seed %d: Inertia = %v, want the tight blob fit", seed, result.Inertia)
+		}
+
+		inertia := 0.0
+		for i, row := range blobs {
+			dist := euclidean(row, result.Centroids[result.Assignments[i]])
+			if math.Abs(dist-result.Distances[i]) > 1e-9 {
+				t.Errorf("seed %d: Distances[%d] = %v, want %v", seed, i, result.Distances[i], dist)
+			}
+			inertia += dist * dist
+		}
+		if math.Abs(inertia-result.Inertia) > 1e-9 {
+			t.Errorf("seed %d: Inertia = %v, want the sum of squared distances %v", seed, result.Inertia, inertia)
+		}
+	}
+}
+
+func TestKMeansIsDeterministicPerSeed(t *testing.T) {
+	first, err := KMeans(blobs, Options{K: 4, Seed: 99, Restarts: 3})
+	if err != nil {
+		t.Fatalf("KMeans() error = %v", err)
+	}
+	second, err := KMeans(blobs, Options{K: 4, Seed: 99, Restarts: 3})
+	if err != nil {
+		t.Fatalf("KMeans() error = %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("KMeans with the same seed differs:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestKMeansErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data [][]float64
+		k    int
+	}{
+		{name: "zero k", data: blobs, k: 0},
+		{name: "negative k", data: blobs, k: -1},
+		{name: "fewer vectors than k", data: blobs[:2], k: 3},
+		{name: "no vectors", data: nil, k: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := KMeans(tt.data, Options{K: tt.k, Seed: 1}); err == nil {
+				t.Errorf("KMeans(k=%d, %d vectors) succeeded", tt.k, len(tt.data))
+			}
+		})
+	}
+}
+
+func TestKMeansIdenticalPoints(t *testing.T) {
+	data := [][]float64{{1, 1}, {1, 1}, {1, 1}}
+	result, err := KMeans(data, Options{K: 2, Seed: 3})
+	if err != nil {
+		t.Fatalf("KMeans() error = %v", err)
+	}
+	if result.Inertia != 0 || len(result.Centroids) != 2 {
+		t.Errorf("KMeans(identical points) = %+v, want 2 centroids and zero inertia", result)
+	}
+}
+
+func TestScaler(t *testing.T) {
+	data := [][]float64{{1, 5}, {3, 5}, {5, 5}}
+	scaler := FitScaler(data)
+
+	wantStd := math.Sqrt(8.0 / 3)
+	if !reflect.DeepEqual(scaler.Mean, []float64{3, 5}) || math.Abs(scaler.Std[0]-wantStd) > 1e-12 || scaler.Std[1] != 1 {
+		t.Fatalf("FitScaler() = %+v, want mean [3 5] and std [%v 1]", scaler, wantStd)
+	}
+	scaled := scaler.Transform(data)
+	for i, row := range scaled {
+		if row[1] != 0 {
+			t.Errorf("constant column standardized to %v, want 0", row[1])
+		}
+		back := scaler.Inverse(row)
+		for j := range back {
+			if math.Abs(back[j]-data[i][j]) > 1e-12 {
+				t.Errorf("Inverse(Transform(%v)) = %v", data[i], back)
+			}
+		}
+	}
+	if got := FitScaler(nil); got.Mean != nil || got.Std != nil {
+		t.Errorf("FitScaler(nil) = %+v, want zero", got)
+	}
+}
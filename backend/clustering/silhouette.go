@@ -0,0 +1,63 @@
+package clustering
+
+// Silhouette returns the mean silhouette coefficient over all points and the
+// mean per cluster. Points in singleton clusters score zero.
+func Silhouette(data [][]float64, assignments []int, k int) (float64, []float64) {
+	perCluster := make([]float64, k)
+	if len(data) == 0 || k < 2 {
+		return 0, perCluster
+	}
+
+	counts := make([]int, k)
+	for _, c := range assignments {
+		counts[c]++
+	}
+
+	total := 0.0
+	sums := make([]float64, k)
+	for i, row := range data {
+		own := assignments[i]
+		if counts[own] <= 1 {
+			continue
+		}
+
+		distSums := make([]float64, k)
+		for j, other := range data {
+			if i == j {
+				continue
+			}
+			distSums[assignments[j]] += euclidean(row, other)
+		}
+
+		a := distSums[own] / float64(counts[own]-1)
+		b := -1.0
+		for c := 0; c < k; c++ {
+			if c == own || counts[c] == 0 {
+				continue
+			}
+			mean := distSums[c] / float64(counts[c])
+			if b < 0 || mean < b {
+				b = mean
+			}
+		}
+		if b < 0 {
+			continue
+		}
+
+		score := 0.0
+		if a < b {
+			score = 1 - a/b
+		} else if a > b {
+			score = b/a - 1
+		}
+		total += score
+		sums[own] += score
+	}
+
+	for c := range perCluster {
+		if counts[c] > 0 {
+			perCluster[c] = sums[c] / float64(counts[c])
+		}
+	}
+	return total / float64(len(data)), perCluster
+}
@@ -0,0 +1,92 @@
+package clustering
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSilhouette(t *testing.T) {
+	line := [][]float64{{0}, {1}, {10}, {11}}
+	// Each point sits 1 from its neighbour and 10 or 11 from the other pair.
+	pairScore := func(b float64) float64 { return 1 - 1/b }
+	pairs := (pairScore(10.5)*2 + pairScore(9.5)*2) / 4
+
+	tests := []struct {
+		name           string
+		data           [][]float64
+		assignments    []int
+		k              int
+		wantMean       float64
+		wantPerCluster []float64
+	}{
+		{
+			name:           "two tight pairs",
+			data:           line,
+			assignments:    []int{0, 0, 1, 1},
+			k:              2,
+			wantMean:       pairs,
+			wantPerCluster: []float64{(pairScore(10.5) + pairScore(9.5)) / 2, (pairScore(9.5) + pairScore(10.5)) / 2},
+		},
+		{
+			// Point 1 (at 1) is mixed in with the far pair: a = 9.5, b = 1.
+			name:           "misassigned point scores negative",
+			data:           line,
+			assignments:    []int{0, 1, 1, 1},
+			k:              2,
+			wantMean:       (0 + (1/9.5 - 1) + (1 - 5/10.0) + (1 - 5.5/11)) / 4,
+			wantPerCluster: []float64{0, ((1/9.5 - 1) + (1 - 5/10.0) + (1 - 5.5/11)) / 3},
+		},
+		{
+			name:           "singleton cluster scores zero",
+			data:           [][]float64{{0}, {1}, {10}},
+			assignments:    []int{0, 0, 1},
+			k:              2,
+			wantMean:       (pairScore(10) + pairScore(9)) / 3,
+			wantPerCluster: []float64{(pairScore(10) + pairScore(9)) / 2, 0},
+		},
+		{
+			name:           "one cluster",
+			data:           line,
+			assignments:    []int{0, 0, 0, 0},
+			k:              1,
+			wantPerCluster: []float64{0},
+		},
+		{
+			name:           "no data",
+			k:              3,
+			wantPerCluster: []float64{0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, perCluster := Silhouette(tt.data, tt.assignments, tt.k)
+			if math.Abs(mean-tt.wantMean) > 1e-9 {
+				t.Errorf("mean = %v, want %v", mean, tt.wantMean)
+			}
+			if len(perCluster) != len(tt.wantPerCluster) {
+				t.Fatalf("per cluster = %v, want %v", perCluster, tt.wantPerCluster)
+			}
+			for c := range perCluster {
+				if math.Abs(perCluster[c]-tt.wantPerCluster[c]) > 1e-9 {
+					t.Errorf("cluster %d = %v, want %v", c, perCluster[c], tt.wantPerCluster[c])
+				}
+			}
+		})
+	}
+}
+
+func TestSilhouetteOfKMeansBlobs(t *testing.T) {
+	result, err := KMeans(blobs, Options{K: 3, Seed: 5})
+	if err != nil {
+		t.Fatalf("KMeans() error = %v", err)
+	}
+	mean, perCluster := Silhouette(blobs, result.Assignments, result.K)
+	if mean < 0.9 {
+		t.Errorf("mean silhouette = %v, want above 0.9 for separated blobs", mean)
+	}
+	for c, score := range perCluster {
+		if score < 0.9 {
+			t.Errorf("cluster %d silhouette = %v, want above 0.9", c, score)
+		}
+	}
+}
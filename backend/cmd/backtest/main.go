@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/backtest"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/tools"
+)
+
+func main() {
+	defaults := backtest.DefaultStrategy()
+	var (
+		wallet      = flag.String("wallet", "", "wallet address, profile URL or @slug to copy")
+		sport       = flag.String("sport", sports.Default, "sport whose trades to replay")
+		limit       = flag.Int("limit", 3000, "maximum number of recent trades to scan")
+		mode        = flag.String("mode", defaults.Mode, "stake mode: fixed or proportional")
+		stake       = flag.Float64("stake", defaults.Stake, "USD per copied buy in fixed mode")
+		ratio       = flag.Float64("ratio", defaults.Ratio, "fraction of the wallet's notional per copied buy in proportional mode")
+		delay       = flag.Int("delay", 0, "seconds between the wallet's trade and the copy")
+		slippage    = flag.String("slippage", defaults.Slippage, "fill price model: history or none")
+		slippageBps = flag.Float64("slippage-bps", 0, "extra adverse slippage in basis points")
+		maxExposure = flag.Float64("max-exposure", 0, "cap on the cost of open positions in USD, 0 for none")
+		bankroll    = flag.Float64("bankroll", defaults.Bankroll, "starting cash in USD")
+		ignoreSells = flag.Bool("ignore-sells", false, "hold copies to resolution instead of copying sells")
+		replay      = flag.String("input", "", "replay a recorded input from this file instead of calling the APIs")
+		record      = flag.String("record", "", "write the fetched input to this file for later offline runs")
+		curve       = flag.Int("curve", 20, "equity curve points to print, 0 for none")
+		jsonOutput  = flag.Bool("json", false, "print machine-readable JSON")
+	)
+	flag.Parse()
+
+	strategy := backtest.Strategy{
+		Mode:         *mode,
+		Stake:        *stake,
+		Ratio:        *ratio,
+		DelaySeconds: *delay,
+		Slippage:     *slippage,
+		SlippageBps:  *slippageBps,
+		MaxExposure:  *maxExposure,
+		Bankroll:     *bankroll,
+		IgnoreSells:  *ignoreSells,
+	}
+
+	var input backtest.Input
+	if *replay != "" {
+		var err error
+		input, err = backtest.LoadInput(*replay)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !*jsonOutput {
+			fmt.Printf("Replaying %d recorded trades of %s from %s\n", len(input.Trades), input.Wallet, input.RecordedAt.Format(time.RFC3339))
+		}
+	} else {
+		if *wallet == "" {
+			log.Fatal("-wallet or -input is required")
+		}
+		ctx := context.Background()
+		if !*jsonOutput {
+			ctx = tools.WithToolLogWriter(ctx, func(line string) { log.Println(line) })
+		}
+		var err error
+		input, err = tools.LoadBacktestInput(ctx, polymarket.NewClient(), tools.BacktestQuery{
+			Input:      *wallet,
+			Sport:      *sport,
+			TradeLimit: *limit,
+			Strategy:   strategy,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *record != "" {
+		if err := backtest.SaveInput(*record, input); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	result := backtest.Run(input, strategy)
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(result)
+		return
+	}
+
+	summary := result.Summary
+	fmt.Printf("Copying %s on %s: %s mode, %ds delay, %s slippage +%.0f bps\n\n",
+		result.Wallet, sports.DisplayName(result.Sport), result.Strategy.Mode, result.Strategy.DelaySeconds,
+		result.Strategy.Slippage, result.Strategy.SlippageBps)
+	fmt.Printf("Wallet trades     %d (copied %d)\n", summary.WalletTrades, summary.Copied)
+	if len(summary.Skipped) > 0 {
+		reasons := make([]string, 0, len(summary.Skipped))
+		for reason := range summary.Skipped {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		for _, reason := range reasons {
+			fmt.Printf("  skipped %-18s %d\n", reason, summary.Skipped[reason])
+		}
+	}
+	fmt.Printf("Total staked      $%.2f\n", summary.TotalStakedUSD)
+	fmt.Printf("Realized PnL      $%.2f\n", summary.RealizedPnL)
+	fmt.Printf("Unrealized PnL    $%.2f (%d open markets)\n", summary.UnrealizedPnL, summary.MarketsOpen)
+	fmt.Printf("Final equity      $%.2f (%+.2f%%)\n", summary.FinalEquity, summary.ReturnPct)
+	fmt.Printf("Max drawdown      %.2f%%\n", summary.MaxDrawdownPct)
+	fmt.Printf("Win rate          %.1f%% (%d markets settled)\n", summary.WinRate*100, summary.MarketsSettled)
+	fmt.Printf("Avg buy slippage  %+.4f\n", summary.AvgBuySlippage)
+	if result.Strategy.Slippage == backtest.SlippageHistory {
+		fmt.Printf("History coverage  %.1f%%\n", summary.HistoryHitRate*100)
+	}
+
+	if *curve > 0 && len(result.Equity) > 0 {
+		fmt.Printf("\nEquity curve\n")
+		step := (len(result.Equity) + *curve - 1) / *curve
+		for i := 0; i < len(result.Equity); i += step {
+			point := result.Equity[i]
+			fmt.Printf("  %s  %-6s  equity $%10.2f  exposure $%9.2f\n", point.Time.Format(time.RFC3339), point.Event, point.Equity, point.Exposure)
+		}
+		if (len(result.Equity)-1)%step != 0 {
+			point := result.Equity[len(result.Equity)-1]
+			fmt.Printf("  %s  %-6s  equity $%10.2f  exposure $%9.2f\n", point.Time.Format(time.RFC3339), point.Event, point.Equity, point.Exposure)
+		}
+	}
+	if *record != "" {
+		fmt.Printf("\nRecorded input to %s\n", *record)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/brucexwang/easy-arbitra/backend/clustering"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+	"github.com/brucexwang/easy-arbitra/backend/styles"
+)
+
+var features = []string{"entry_timing_hours", "size_ratio_pct", "conviction"}
+
+type report struct {
+	Run         storage.StyleClusterRun `json:"run"`
+	Clusters    []storage.StyleCluster  `json:"clusters"`
+	Assignments []storage.WalletCluster `json:"assignments"`
+	Candidates  []candidateScore        `json:"candidates,omitempty"`
+}
+
+type candidateScore struct {
+	K          int     `json:"k"`
+	Silhouette float64 `json:"silhouette"`
+	Inertia    float64 `json:"inertia"`
+}
+
+func main() {
+	var (
+		k          = flag.Int("k", 0, "number of clusters; 0 picks the best silhouette between -k-min and -k-max")
+		kMin       = flag.Int("k-min", 2, "smallest k to try when -k is 0")
+		kMax       = flag.Int("k-max", 8, "largest k to try when -k is 0")
+		sport      = flag.String("sport", sports.Default, "sport whose wallet profiles to cluster")
+		minTrades  = flag.Int("min-trades", 5, "minimum sport trades for a wallet to be clustered")
+		restarts   = flag.Int("restarts", 10, "k-means restarts per k")
+		seed       = flag.Int64("seed", 1, "random seed")
+		dryRun     = flag.Bool("dry-run", false, "print clusters without writing them to Postgres")
+		jsonOutput = flag.Bool("json", false, "print machine-readable JSON")
+		styleRules = flag.String("style-rules", os.Getenv("STYLE_LABEL_RULES"), "YAML or JSON style label rules file used to name clusters")
+	)
+	flag.Parse()
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+	if *styleRules != "" {
+		rules, err := styles.Load(*styleRules)
+		if err != nil {
+			log.Fatal(err)
+		}
+		styles.SetActive(rules)
+	}
+
+	ctx := context.Background()
+	store, err := storage.Open(ctx, databaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	sportKey := sports.Normalize(*sport)
+	vectors, err := store.ListProfileVectors(ctx, storage.ProfileFilter{Sport: sportKey, MinTrades: *minTrades})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	raw := make([][]float64, 0, len(vectors))
+	for _, vector := range vectors {
+		raw = append(raw, []float64{vector.EntryTimingHours, vector.SizeRatioPct, vector.Conviction})
+	}
+	scaler := clustering.FitScaler(raw)
+	data := scaler.Transform(raw)
+
+	kValues := []int{*k}
+	if *k <= 0 {
+		kValues = kValues[:0]
+		for candidate := *kMin; candidate <= *kMax; candidate++ {
+			kValues = append(kValues, candidate)
+		}
+	}
+
+	var (
+		best       clustering.Result
+		bestScore  float64
+		bestPer    []float64
+		candidates []candidateScore
+	)
+	for _, candidate := range kValues {
+		if candidate > len(data) {
+			break
+		}
+		result, err := clustering.KMeans(data, clustering.Options{
+			K:        candidate,
+			Restarts: *restarts,
+			Seed:     *seed,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		score, perCluster := clustering.Silhouette(data, result.Assignments, result.K)
+		candidates = append(candidates, candidateScore{K: candidate, Silhouette: score, Inertia: result.Inertia})
+		if best.K == 0 || score > bestScore {
+			best, bestScore, bestPer = result, score, perCluster
+		}
+	}
+	if best.K == 0 {
+		log.Fatalf("not enough wallets to cluster: %d vectors", len(data))
+	}
+
+	out := report{
+		Run: storage.StyleClusterRun{
+			Sport:       sportKey,
+			K:           best.K,
+			Features:    features,
+			Silhouette:  bestScore,
+			Inertia:     best.Inertia,
+			WalletCount: len(data),
+		},
+		Candidates: candidates,
+	}
+	for c, centroid := range best.Centroids {
+		values := scaler.Inverse(centroid)
+		centroidMap := map[string]float64{}
+		for j, name := range features {
+			centroidMap[name] = values[j]
+		}
+		nearest := styles.Active().Classify(styles.FromStyleMetrics(values[0], values[1], values[2], 0))
+		out.Clusters = append(out.Clusters, storage.StyleCluster{
+			ClusterID:  c,
+			Label:      fmt.Sprintf("Cluster %d: %s", c+1, nearest),
+			Centroid:   centroidMap,
+			Size:       best.Sizes[c],
+			Silhouette: bestPer[c],
+		})
+	}
+	for i, vector := range vectors {
+		out.Assignments = append(out.Assignments, storage.WalletCluster{
+			WalletAddress: vector.WalletAddress,
+			ClusterID:     best.Assignments[i],
+			Distance:      best.Distances[i],
+		})
+	}
+
+	if !*dryRun {
+		runID, err := store.SaveStyleClusterRun(ctx, out.Run, out.Clusters, out.Assignments)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out.Run.ID = runID
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(out)
+		return
+	}
+
+	fmt.Printf("Clustered %d %s wallets into k=%d (silhouette %.3f)\n", len(data), sports.DisplayName(sportKey), best.K, bestScore)
+	for _, candidate := range candidates {
+		fmt.Printf("   k=%d silhouette=%.3f inertia=%.2f\n", candidate.K, candidate.Silhouette, candidate.Inertia)
+	}
+	fmt.Println()
+	for _, cluster := range out.Clusters {
+		fmt.Printf("%s (%d wallets, silhouette %.3f)\n", cluster.Label, cluster.Size, cluster.Silhouette)
+		fmt.Printf("   entry timing: %.1fh | size ratio: %.4f%% | conviction: %.2f\n\n",
+			cluster.Centroid["entry_timing_hours"], cluster.Centroid["size_ratio_pct"], cluster.Centroid["conviction"])
+	}
+	if *dryRun {
+		fmt.Println("Dry run: clusters were not saved")
+	} else {
+		fmt.Printf("Saved cluster run %d\n", out.Run.ID)
+	}
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/brucexwang/easy-arbitra/backend/discovery"
 	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/styles"
 )
 
 func main() {
@@ -21,13 +23,22 @@ func main() {
 		perWalletLimit  = flag.Int("wallet-limit", 500, "trade history limit when scoring each wallet")
 		candidateLimit  = flag.Int("candidates", 20, "number of candidate wallets to inspect deeply")
 		outputLimit     = flag.Int("output", 10, "number of top wallets to print")
-		minRecentTrades = flag.Int("min-recent-trades", 2, "minimum recent NBA trades to consider a wallet")
-		sport           = flag.String("sport", "nba", "sport to search for")
+		minRecentTrades = flag.Int("min-recent-trades", 2, "minimum recent sport trades to consider a wallet")
+		sport           = flag.String("sport", sports.Default, "sport to search for (nba, nfl, mlb, nhl, epl, tennis, mma, ...)")
 		jsonOutput      = flag.Bool("json", false, "print machine-readable JSON")
 		walletsFile     = flag.String("wallets-file", "", "newline-delimited wallet list to score directly")
+		styleRules      = flag.String("style-rules", os.Getenv("STYLE_LABEL_RULES"), "YAML or JSON style label rules file")
 	)
 	flag.Parse()
 
+	if *styleRules != "" {
+		rules, err := styles.Load(*styleRules)
+		if err != nil {
+			log.Fatal(err)
+		}
+		styles.SetActive(rules)
+	}
+
 	client := polymarket.NewClient()
 	opts := discovery.Options{
 		Sport:           *sport,
@@ -48,9 +59,9 @@ func main() {
 		if readErr != nil {
 			log.Fatal(readErr)
 		}
-		results, err = discovery.ScoreWallets(context.Background(), client, wallets, opts)
+		results, err = discovery.ScoreWallets(context.Background(), client, nil, wallets, opts)
 	} else {
-		results, err = discovery.DiscoverFromRecent(context.Background(), client, opts)
+		results, err = discovery.DiscoverFromRecent(context.Background(), client, nil, opts)
 	}
 	if err != nil {
 		log.Fatal(err)
@@ -63,10 +74,11 @@ func main() {
 		return
 	}
 
-	fmt.Printf("Top %d %s wallets for demo\n\n", len(results), strings.ToUpper(*sport))
+	sportName := sports.DisplayName(*sport)
+	fmt.Printf("Top %d %s wallets for demo\n\n", len(results), sportName)
 	for idx, result := range results {
 		fmt.Printf("%d. %s (%s)\n", idx+1, result.DisplayName, result.Wallet)
-		fmt.Printf("   NBA trades: %d | recent sample hits: %d | recent markets: %d\n", result.NbaTrades, result.RecentTrades, result.RecentMarkets)
+		fmt.Printf("   %s trades: %d | recent sample hits: %d | recent markets: %d\n", sportName, result.SportTrades, result.RecentTrades, result.RecentMarkets)
 		fmt.Printf("   Style: %s | conviction: %.2f | size ratio: %.4f%% | entry timing: %.1fh\n", result.StyleLabel, result.Conviction, result.SizeRatioPct, result.EntryTimingHours)
 		fmt.Printf("   Demo reason: %s\n\n", result.Reason)
 	}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+func main() {
+	var (
+		to         = flag.Int("to", 0, "with up, apply migrations only up to this version; 0 applies all")
+		steps      = flag.Int("steps", 1, "with down, number of applied migrations to roll back")
+		jsonOutput = flag.Bool("json", false, "print machine-readable JSON")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: migrate [flags] up|down|status\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	ctx := context.Background()
+	store, err := storage.Connect(ctx, databaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	switch command := flag.Arg(0); command {
+	case "up":
+		applied, err := store.MigrateUp(ctx, *to)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printMigrations("applied", applied, *jsonOutput)
+	case "down":
+		rolledBack, err := store.MigrateDown(ctx, *steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printMigrations("rolled back", rolledBack, *jsonOutput)
+	case "status":
+		statuses, err := store.MigrationStatuses(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *jsonOutput {
+			encode(statuses)
+			return
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			name := status.Name
+			if status.Unknown {
+				name = "(unknown to this build)"
+			}
+			fmt.Printf("%04d  %-30s %s\n", status.Version, name, state)
+		}
+	default:
+		log.Fatalf("unknown command %q; want up, down or status", command)
+	}
+}
+
+func printMigrations(verb string, migrations []storage.Migration, jsonOutput bool) {
+	if jsonOutput {
+		type migrated struct {
+			Version int    `json:"version"`
+			Name    string `json:"name"`
+		}
+		out := make([]migrated, 0, len(migrations))
+		for _, migration := range migrations {
+			out = append(out, migrated{Version: migration.Version, Name: migration.Name})
+		}
+		encode(out)
+		return
+	}
+	if len(migrations) == 0 {
+		fmt.Printf("Nothing %s\n", verb)
+		return
+	}
+	for _, migration := range migrations {
+		fmt.Printf("%s %04d_%s\n", verb, migration.Version, migration.Name)
+	}
+}
+
+func encode(value any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(value); err != nil {
+		log.Fatal(err)
+	}
+}
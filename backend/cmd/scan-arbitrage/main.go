@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/arbitrage"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+func main() {
+	var (
+		sport      = flag.String("sport", sports.Default, "sport whose open game markets to scan")
+		feeBps     = flag.Float64("fee-bps", 0, "taker fee in basis points applied to every fill")
+		minEdge    = flag.Float64("min-edge", 0, "smallest per-share profit after fees to report")
+		maxMarkets = flag.Int("max-markets", 200, "maximum number of markets to read books for")
+		indexPages = flag.Int("index-pages", 3, "pages of 100 events per league tag to index when DATABASE_URL is not set")
+		books      = flag.String("books", "", "replay recorded books from this file instead of calling the CLOB")
+		record     = flag.String("record", "", "write the fetched books to this file for later offline replay")
+		jsonOutput = flag.Bool("json", false, "print machine-readable JSON")
+	)
+	flag.Parse()
+
+	ctx := context.Background()
+	sportKey := sports.Normalize(*sport)
+	opts := arbitrage.Options{FeeBps: *feeBps, MinEdge: *minEdge}
+
+	var snapshots []arbitrage.Snapshot
+	if *books != "" {
+		recording, err := arbitrage.LoadRecording(*books)
+		if err != nil {
+			log.Fatal(err)
+		}
+		snapshots = recording.Snapshots
+		if !*jsonOutput {
+			fmt.Printf("Replaying %d recorded markets from %s\n", len(snapshots), recording.RecordedAt.Format(time.RFC3339))
+		}
+	} else {
+		var err error
+		snapshots, err = fetchLive(ctx, sportKey, *maxMarkets, *indexPages)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *record != "" {
+		if err := arbitrage.SaveRecording(*record, arbitrage.Recording{
+			RecordedAt: time.Now().UTC(),
+			Sport:      sportKey,
+			Snapshots:  snapshots,
+		}); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	opportunities := arbitrage.Scan(snapshots, opts)
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]any{
+			"sport":           sportKey,
+			"options":         opts,
+			"markets_scanned": len(snapshots),
+			"opportunities":   opportunities,
+		})
+		return
+	}
+
+	fmt.Printf("Scanned %d %s markets: %d opportunities (fee %.0f bps, min edge %.4f)\n\n",
+		len(snapshots), sports.DisplayName(sportKey), len(opportunities), *feeBps, *minEdge)
+	for idx, opp := range opportunities {
+		fmt.Printf("%d. %s [%s]\n", idx+1, opp.Question, opp.Kind)
+		fmt.Printf("   %s @ %.4f + %s @ %.4f = %.4f | edge %.4f/share\n",
+			opp.Outcomes[0], opp.BestPrices[0], opp.Outcomes[1], opp.BestPrices[1], opp.PriceSum, opp.Edge)
+		fmt.Printf("   size %.2f shares | notional $%.2f | profit $%.2f\n\n", opp.Size, opp.NotionalUSD, opp.ProfitUSD)
+	}
+	if *record != "" {
+		fmt.Printf("Recorded books to %s\n", *record)
+	}
+}
+
+// fetchLive builds the sports index from Postgres when available, otherwise
+// from Gamma, and reads the books of the sport's open game markets.
+func fetchLive(ctx context.Context, sport string, maxMarkets, indexPages int) ([]arbitrage.Snapshot, error) {
+	client := polymarket.NewClient()
+
+	var store storage.Store
+	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
+		var err error
+		store, err = storage.Open(ctx, databaseURL)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+	}
+
+	index := sports.NewIndex()
+	indexer := sports.NewIndexer(client, store, index, 0, indexPages)
+	if store != nil {
+		if err := indexer.Load(ctx); err != nil {
+			return nil, err
+		}
+	}
+	ids := arbitrage.SportMarketIDs(index, sport)
+	if len(ids) == 0 {
+		if _, err := indexer.RefreshSports(ctx, sport); err != nil {
+			return nil, err
+		}
+		ids = arbitrage.SportMarketIDs(index, sport)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no open %s game markets found", strings.ToUpper(sport))
+	}
+	if len(ids) > maxMarkets {
+		ids = ids[:maxMarkets]
+	}
+
+	markets, err := arbitrage.ActiveMarkets(client, ids)
+	if err != nil {
+		return nil, err
+	}
+	return arbitrage.Fetch(ctx, client, markets)
+}
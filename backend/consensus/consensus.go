@@ -0,0 +1,304 @@
+package consensus
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+const (
+	positionPageSize = 500
+	marketBatchSize  = 50
+	fetchWorkers     = 4
+)
+
+type Options struct {
+	// TopWallets is how many of the best-ranked tracked wallets vote.
+	TopWallets int
+	// Horizon limits games to those starting within this window.
+	Horizon time.Duration
+	// MinWallets is the fewest voting wallets a market needs to be reported.
+	MinWallets int
+	// PositionPages caps the pages of positions read per wallet.
+	PositionPages int
+}
+
+type vote struct {
+	wallet   storage.TrackedWallet
+	outcome  string
+	stakeUSD float64
+	avgPrice float64
+}
+
+// Compute aggregates the open positions of the top tracked wallets into a
+// consensus per upcoming game market.
+//
+// Each wallet votes for the outcome it has the most USD behind. Votes are
+// weighted by that stake times 1 + the wallet's ROI (losing wallets count at
+// stake only), and the consensus probability of an outcome is its share of
+// the total weight. The agreement ratio is the unweighted share of wallets
+// on the consensus side, and the divergence is the consensus probability
+// minus the outcome's current price.
+func Compute(ctx context.Context, client *polymarket.Client, wallets []storage.TrackedWallet, games []sports.Game, opts Options) ([]storage.MarketConsensus, error) {
+	opts = withDefaults(opts)
+
+	now := time.Now().UTC()
+	gameByMarket := map[string]sports.Game{}
+	for _, game := range games {
+		if game.Status != sports.GameScheduled || game.ScheduledStart == nil {
+			continue
+		}
+		start := *game.ScheduledStart
+		if start.Before(now) || start.After(now.Add(opts.Horizon)) {
+			continue
+		}
+		for _, market := range game.Markets {
+			if !market.Closed {
+				gameByMarket[market.ConditionID] = game
+			}
+		}
+	}
+	if len(gameByMarket) == 0 || len(wallets) == 0 {
+		return []storage.MarketConsensus{}, nil
+	}
+
+	if len(wallets) > opts.TopWallets {
+		sorted := append([]storage.TrackedWallet(nil), wallets...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].SourceRank < sorted[j].SourceRank })
+		wallets = sorted[:opts.TopWallets]
+	}
+
+	votes, err := collectVotes(ctx, client, wallets, gameByMarket, opts.PositionPages)
+	if err != nil {
+		return nil, err
+	}
+
+	conditionIDs := make([]string, 0, len(votes))
+	for conditionID, marketVotes := range votes {
+		if len(marketVotes) >= opts.MinWallets {
+			conditionIDs = append(conditionIDs, conditionID)
+		}
+	}
+	sort.Strings(conditionIDs)
+
+	markets, err := fetchMarkets(client, conditionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.MarketConsensus, 0, len(conditionIDs))
+	for _, conditionID := range conditionIDs {
+		market, ok := markets[conditionID]
+		if !ok {
+			continue
+		}
+		row, ok := marketConsensus(market, votes[conditionID])
+		if !ok {
+			continue
+		}
+		game := gameByMarket[conditionID]
+		row.Sport = game.Sport
+		row.ConditionID = conditionID
+		row.GameID = game.ID
+		row.GameTitle = game.Title
+		row.ScheduledStart = game.ScheduledStart
+		row.ComputedAt = now
+		result = append(result, row)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := math.Abs(result[i].Divergence), math.Abs(result[j].Divergence)
+		if a != b {
+			return a > b
+		}
+		return result[i].StakeUSD > result[j].StakeUSD
+	})
+	return result, nil
+}
+
+func withDefaults(opts Options) Options {
+	if opts.TopWallets <= 0 {
+		opts.TopWallets = 50
+	}
+	if opts.Horizon <= 0 {
+		opts.Horizon = 7 * 24 * time.Hour
+	}
+	if opts.MinWallets <= 0 {
+		opts.MinWallets = 2
+	}
+	if opts.PositionPages <= 0 {
+		opts.PositionPages = 2
+	}
+	return opts
+}
+
+// collectVotes reads every wallet's positions and keeps, per upcoming market,
+// the outcome each wallet has the most USD behind.
+func collectVotes(ctx context.Context, client *polymarket.Client, wallets []storage.TrackedWallet, gameByMarket map[string]sports.Game, pages int) (map[string][]vote, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		votes    = map[string][]vote{}
+		fetched  int
+		firstErr error
+	)
+	slots := make(chan struct{}, fetchWorkers)
+	for _, wallet := range wallets {
+		wg.Add(1)
+		go func(wallet storage.TrackedWallet) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			type stake struct{ usd, shares float64 }
+			byMarket := map[string]map[string]*stake{}
+			for page := 0; page < pages; page++ {
+				if ctx.Err() != nil {
+					return
+				}
+				positions, err := client.GetPositions(wallet.WalletAddress, positionPageSize, page*positionPageSize)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				for _, position := range positions {
+					conditionID := strings.ToLower(position.ConditionID)
+					if _, ok := gameByMarket[conditionID]; !ok || position.Size <= 0 {
+						continue
+					}
+					outcomes, ok := byMarket[conditionID]
+					if !ok {
+						outcomes = map[string]*stake{}
+						byMarket[conditionID] = outcomes
+					}
+					entry, ok := outcomes[position.Outcome]
+					if !ok {
+						entry = &stake{}
+						outcomes[position.Outcome] = entry
+					}
+					entry.usd += position.Size * position.AvgPrice
+					entry.shares += position.Size
+				}
+				if len(positions) < positionPageSize {
+					break
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			fetched++
+			for conditionID, outcomes := range byMarket {
+				var best vote
+				for outcome, entry := range outcomes {
+					if entry.usd > best.stakeUSD {
+						best = vote{wallet: wallet, outcome: outcome, stakeUSD: entry.usd, avgPrice: entry.usd / entry.shares}
+					}
+				}
+				if best.stakeUSD > 0 {
+					votes[conditionID] = append(votes[conditionID], best)
+				}
+			}
+		}(wallet)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if fetched == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return votes, nil
+}
+
+func fetchMarkets(client *polymarket.Client, conditionIDs []string) (map[string]polymarket.Market, error) {
+	markets := map[string]polymarket.Market{}
+	for start := 0; start < len(conditionIDs); start += marketBatchSize {
+		end := start + marketBatchSize
+		if end > len(conditionIDs) {
+			end = len(conditionIDs)
+		}
+		batch, err := client.GetMarkets(conditionIDs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, market := range batch {
+			markets[strings.ToLower(market.ConditionID)] = market
+		}
+	}
+	return markets, nil
+}
+
+func marketConsensus(market polymarket.Market, votes []vote) (storage.MarketConsensus, bool) {
+	weights := map[string]float64{}
+	total, stake := 0.0, 0.0
+	for _, v := range votes {
+		weight := v.stakeUSD * (1 + math.Max(v.wallet.ROI(), 0))
+		weights[v.outcome] += weight
+		total += weight
+		stake += v.stakeUSD
+	}
+	if total <= 0 {
+		return storage.MarketConsensus{}, false
+	}
+
+	outcome, best := "", 0.0
+	for name, weight := range weights {
+		if weight > best || (weight == best && name < outcome) {
+			outcome, best = name, weight
+		}
+	}
+
+	agree, sideStake, sideCost := 0, 0.0, 0.0
+	for _, v := range votes {
+		if v.outcome != outcome {
+			continue
+		}
+		agree++
+		sideStake += v.stakeUSD
+		sideCost += v.stakeUSD * v.avgPrice
+	}
+
+	price := -1.0
+	names, prices := market.OutcomeNames(), market.Prices()
+	for i, name := range names {
+		if name == outcome && i < len(prices) {
+			price = prices[i]
+		}
+	}
+	if price < 0 {
+		return storage.MarketConsensus{}, false
+	}
+
+	probability := best / total
+	row := storage.MarketConsensus{
+		Question:             market.Question,
+		ConsensusOutcome:     outcome,
+		ConsensusProbability: round(probability, 4),
+		MarketPrice:          round(price, 4),
+		Divergence:           round(probability-price, 4),
+		AgreementRatio:       round(float64(agree)/float64(len(votes)), 4),
+		Wallets:              len(votes),
+		StakeUSD:             round(stake, 2),
+	}
+	if sideStake > 0 {
+		row.AvgEntryPrice = round(sideCost/sideStake, 4)
+	}
+	return row, true
+}
+
+func round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
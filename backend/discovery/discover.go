@@ -2,13 +2,21 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/brucexwang/easy-arbitra/backend/metrics"
 	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+	"github.com/brucexwang/easy-arbitra/backend/styles"
 	"github.com/brucexwang/easy-arbitra/backend/tools"
 )
 
+// ErrNoSportTrades means a wallet has no trades in the sport's markets
+// within the scanned history.
+var ErrNoSportTrades = errors.New("no sport trades")
+
 type Options struct {
 	Sport           string
 	RecentLimit     int
@@ -21,10 +29,11 @@ type Options struct {
 
 type Candidate struct {
 	Wallet            string  `json:"wallet"`
+	Sport             string  `json:"sport"`
 	DisplayName       string  `json:"display_name"`
 	RecentTrades      int     `json:"recent_trades"`
 	RecentMarkets     int     `json:"recent_markets"`
-	NbaTrades         int     `json:"nba_trades"`
+	SportTrades       int     `json:"sport_trades"`
 	EntryTimingHours  float64 `json:"entry_timing_hours"`
 	SizeRatioPct      float64 `json:"size_ratio_pct"`
 	Conviction        float64 `json:"conviction"`
@@ -35,20 +44,25 @@ type Candidate struct {
 
 type walletSeed struct {
 	Wallet          string
+	DisplayName     string
 	RecentTrades    int
 	UniqueMarkets   map[string]bool
 	RecentBuyVolume float64
 }
 
-func DiscoverFromRecent(ctx context.Context, client *polymarket.Client, opts Options) ([]Candidate, error) {
+// DiscoverFromRecent scores the wallets active in the sport's recent trades.
+// Wallet histories are read through store when it is set.
+func DiscoverFromRecent(ctx context.Context, client *polymarket.Client, store storage.Store, opts Options) ([]Candidate, error) {
+	opts.Sport = sports.Normalize(opts.Sport)
 	seeds, err := discoverSeeds(client, opts.Sport, opts.RecentLimit, opts.RecentPages, opts.MinRecentTrades)
 	if err != nil {
 		return nil, err
 	}
-	return scoreSeeds(ctx, client, seeds, opts), nil
+	return scoreSeeds(ctx, client, store, seeds, opts), nil
 }
 
-func ScoreWallets(ctx context.Context, client *polymarket.Client, wallets []string, opts Options) ([]Candidate, error) {
+func ScoreWallets(ctx context.Context, client *polymarket.Client, store storage.Store, wallets []string, opts Options) ([]Candidate, error) {
+	opts.Sport = sports.Normalize(opts.Sport)
 	seeds := make([]walletSeed, 0, len(wallets))
 	seen := map[string]bool{}
 	for _, wallet := range wallets {
@@ -62,7 +76,7 @@ func ScoreWallets(ctx context.Context, client *polymarket.Client, wallets []stri
 			UniqueMarkets: map[string]bool{},
 		})
 	}
-	return scoreSeeds(ctx, client, seeds, opts), nil
+	return scoreSeeds(ctx, client, store, seeds, opts), nil
 }
 
 func discoverSeeds(client *polymarket.Client, sport string, limit, pages, minRecentTrades int) ([]walletSeed, error) {
@@ -79,7 +93,7 @@ func discoverSeeds(client *polymarket.Client, sport string, limit, pages, minRec
 		}
 
 		for _, trade := range trades {
-			if !isSportText(trade.Title, sport) {
+			if !sports.MatchesMarket(trade.ConditionID, trade.Title, trade.Slug, sport) {
 				continue
 			}
 			seed, ok := seeds[trade.ProxyWallet]
@@ -109,51 +123,18 @@ func discoverSeeds(client *polymarket.Client, sport string, limit, pages, minRec
 	return list, nil
 }
 
-func scoreSeeds(ctx context.Context, client *polymarket.Client, seeds []walletSeed, opts Options) []Candidate {
+func scoreSeeds(ctx context.Context, client *polymarket.Client, store storage.Store, seeds []walletSeed, opts Options) []Candidate {
 	if len(seeds) > opts.CandidateLimit && opts.CandidateLimit > 0 {
 		seeds = seeds[:opts.CandidateLimit]
 	}
 
 	results := make([]Candidate, 0, len(seeds))
 	for _, seed := range seeds {
-		profile, _ := client.GetPublicProfile(seed.Wallet)
-		displayName := shortWallet(seed.Wallet)
-		if profile != nil {
-			if profile.Pseudonym != "" {
-				displayName = profile.Pseudonym
-			} else if profile.Name != "" {
-				displayName = profile.Name
-			}
-		}
-
-		fetchResult, err := tools.FetchSportsTradesData(ctx, client, seed.Wallet, opts.Sport, opts.WalletLimit)
-		if err != nil || fetchResult.TotalTrades == 0 {
+		candidate, err := scoreSeed(ctx, client, store, seed, opts)
+		if err != nil {
 			continue
 		}
-
-		entryTiming := metrics.EntryTimingHours(fetchResult.Trades)
-		sizeRatio := metrics.SizeRatioPct(fetchResult.Trades)
-		conviction := metrics.Conviction(fetchResult.Trades)
-		uniqueMarkets := countUniqueMarkets(fetchResult.Trades)
-		styleLabel := tools.DetermineStyleLabel(
-			max(0, 1-entryTiming/24),
-			minFloat(1, sizeRatio/10),
-			conviction,
-		)
-
-		results = append(results, Candidate{
-			Wallet:            seed.Wallet,
-			DisplayName:       displayName,
-			RecentTrades:      seed.RecentTrades,
-			RecentMarkets:     len(seed.UniqueMarkets),
-			NbaTrades:         fetchResult.TotalTrades,
-			EntryTimingHours:  entryTiming,
-			SizeRatioPct:      sizeRatio,
-			Conviction:        conviction,
-			StyleLabel:        styleLabel,
-			PresentationScore: presentationScore(fetchResult.TotalTrades, uniqueMarkets, conviction, sizeRatio),
-			Reason:            buildReason(fetchResult.TotalTrades, uniqueMarkets, conviction, sizeRatio),
-		})
+		results = append(results, candidate)
 	}
 
 	sortCandidates(results)
@@ -163,19 +144,79 @@ func scoreSeeds(ctx context.Context, client *polymarket.Client, seeds []walletSe
 	return results
 }
 
+// ScoreWallet scores one wallet from its sport trades. It returns
+// ErrNoSportTrades when the wallet has none in the scanned history.
+func ScoreWallet(ctx context.Context, client *polymarket.Client, store storage.Store, wallet string, opts Options) (Candidate, error) {
+	opts.Sport = sports.Normalize(opts.Sport)
+	return scoreSeed(ctx, client, store, walletSeed{Wallet: strings.TrimSpace(wallet), UniqueMarkets: map[string]bool{}}, opts)
+}
+
+// ScoreNamedWallet is ScoreWallet for a wallet whose display name is
+// already known, such as a leaderboard entry, so its public profile is not
+// looked up.
+func ScoreNamedWallet(ctx context.Context, client *polymarket.Client, store storage.Store, wallet, displayName string, opts Options) (Candidate, error) {
+	opts.Sport = sports.Normalize(opts.Sport)
+	return scoreSeed(ctx, client, store, walletSeed{Wallet: strings.TrimSpace(wallet), DisplayName: displayName, UniqueMarkets: map[string]bool{}}, opts)
+}
+
+func scoreSeed(ctx context.Context, client *polymarket.Client, store storage.Store, seed walletSeed, opts Options) (Candidate, error) {
+	displayName := seed.DisplayName
+	if displayName == "" {
+		displayName = shortWallet(seed.Wallet)
+		if profile, _ := client.GetPublicProfile(seed.Wallet); profile != nil {
+			if profile.Pseudonym != "" {
+				displayName = profile.Pseudonym
+			} else if profile.Name != "" {
+				displayName = profile.Name
+			}
+		}
+	}
+
+	fetchResult, err := tools.FetchSportsTradesData(ctx, client, store, seed.Wallet, opts.Sport, opts.WalletLimit)
+	if err != nil {
+		return Candidate{}, err
+	}
+	if fetchResult.TotalTrades == 0 {
+		return Candidate{}, ErrNoSportTrades
+	}
+
+	entryTiming := metrics.EntryTimingHours(fetchResult.Trades)
+	sizeRatio := metrics.SizeRatioPct(fetchResult.Trades)
+	conviction := metrics.Conviction(fetchResult.Trades)
+	uniqueMarkets := countUniqueMarkets(fetchResult.Trades)
+	styleLabel := styles.Active().Classify(
+		styles.FromStyleMetrics(entryTiming, sizeRatio, conviction, fetchResult.TotalTrades),
+	)
+
+	return Candidate{
+		Wallet:            seed.Wallet,
+		Sport:             fetchResult.Sport,
+		DisplayName:       displayName,
+		RecentTrades:      seed.RecentTrades,
+		RecentMarkets:     len(seed.UniqueMarkets),
+		SportTrades:       fetchResult.TotalTrades,
+		EntryTimingHours:  entryTiming,
+		SizeRatioPct:      sizeRatio,
+		Conviction:        conviction,
+		StyleLabel:        styleLabel,
+		PresentationScore: presentationScore(fetchResult.TotalTrades, uniqueMarkets, conviction, sizeRatio),
+		Reason:            buildReason(fetchResult.Sport, fetchResult.TotalTrades, uniqueMarkets, conviction, sizeRatio),
+	}, nil
+}
+
 func sortCandidates(results []Candidate) {
 	for i := 0; i < len(results); i++ {
 		for j := i + 1; j < len(results); j++ {
 			if results[j].PresentationScore > results[i].PresentationScore ||
-				(results[j].PresentationScore == results[i].PresentationScore && results[j].NbaTrades > results[i].NbaTrades) {
+				(results[j].PresentationScore == results[i].PresentationScore && results[j].SportTrades > results[i].SportTrades) {
 				results[i], results[j] = results[j], results[i]
 			}
 		}
 	}
 }
 
-func presentationScore(nbaTrades, uniqueMarkets int, conviction, sizeRatio float64) float64 {
-	score := float64(min(nbaTrades, 40))*2.5 + float64(min(uniqueMarkets, 12))*3
+func presentationScore(sportTrades, uniqueMarkets int, conviction, sizeRatio float64) float64 {
+	score := float64(min(sportTrades, 40))*2.5 + float64(min(uniqueMarkets, 12))*3
 	if conviction >= 0.35 && conviction <= 0.8 {
 		score += 8
 	}
@@ -185,10 +226,11 @@ func presentationScore(nbaTrades, uniqueMarkets int, conviction, sizeRatio float
 	return score
 }
 
-func buildReason(nbaTrades, uniqueMarkets int, conviction, sizeRatio float64) string {
+func buildReason(sport string, sportTrades, uniqueMarkets int, conviction, sizeRatio float64) string {
+	name := sports.DisplayName(sport)
 	reasons := []string{}
-	if nbaTrades >= 20 {
-		reasons = append(reasons, "large NBA sample")
+	if sportTrades >= 20 {
+		reasons = append(reasons, "large "+name+" sample")
 	}
 	if uniqueMarkets >= 5 {
 		reasons = append(reasons, "diverse market coverage")
@@ -200,7 +242,7 @@ func buildReason(nbaTrades, uniqueMarkets int, conviction, sizeRatio float64) st
 		reasons = append(reasons, "visible position sizing")
 	}
 	if len(reasons) == 0 {
-		return "worth checking manually; enough NBA activity to show on the dashboard"
+		return "worth checking manually; enough " + name + " activity to show on the dashboard"
 	}
 	return strings.Join(reasons, ", ")
 }
@@ -222,14 +264,6 @@ func shortWallet(wallet string) string {
 	return wallet[:6] + "..." + wallet[len(wallet)-4:]
 }
 
-func isSportText(text, sport string) bool {
-	value := strings.ToLower(text)
-	if strings.Contains(value, sport) {
-		return true
-	}
-	return sport == "nba" && strings.Contains(value, "basketball")
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -237,13 +271,6 @@ func min(a, b int) int {
 	return b
 }
 
-func max(a, b float64) float64 {
-	if a > b {
-		return a
-	}
-	return b
-}
-
 func minFloat(a, b float64) float64 {
 	if a < b {
 		return a
@@ -0,0 +1,211 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+)
+
+const (
+	TypeTrade   = "trade"
+	TypeAlert   = "alert"
+	TypeSync    = "sync"
+	TypeProfile = "profile"
+	TypeJob     = "job"
+)
+
+// historySize is how many recent events the bus keeps for subscribers that
+// resume from an earlier event ID.
+const historySize = 1000
+
+// Event is one message on the bus; exactly one of the payload fields is set,
+// matching Type. IDs increase by one per published event and start from the
+// clock, so they keep increasing across restarts.
+type Event struct {
+	ID      int64         `json:"id"`
+	Type    string        `json:"type"`
+	Time    time.Time     `json:"time"`
+	Trade   *TradeEvent   `json:"trade,omitempty"`
+	Alert   *AlertEvent   `json:"alert,omitempty"`
+	Sync    *SyncEvent    `json:"sync,omitempty"`
+	Profile *ProfileEvent `json:"profile,omitempty"`
+	Job     *JobEvent     `json:"job,omitempty"`
+}
+
+// TradeEvent is a new trade by a watched wallet, with the sports the wallet
+// is tracked for and the watchlists it is on.
+type TradeEvent struct {
+	Key        string           `json:"key"`
+	Wallet     string           `json:"wallet"`
+	Sports     []string         `json:"sports,omitempty"`
+	Watchlists []string         `json:"watchlists,omitempty"`
+	Trade      polymarket.Trade `json:"trade"`
+}
+
+// AlertEvent is an alert rule firing. Payload is the body posted to the
+// rule's webhook.
+type AlertEvent struct {
+	DeliveryID int64           `json:"delivery_id"`
+	RuleID     int64           `json:"rule_id"`
+	Summary    string          `json:"summary"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// SyncEvent reports the progress of a leaderboard wallet sync. Done and
+// Total count wallets during the profiles stage.
+type SyncEvent struct {
+	Sport   string `json:"sport,omitempty"`
+	Stage   string `json:"stage"`
+	Message string `json:"message,omitempty"`
+	Done    int    `json:"done,omitempty"`
+	Total   int    `json:"total,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ProfileEvent is a wallet profile that the sync created or whose rank or
+// style label changed.
+type ProfileEvent struct {
+	Wallet             string `json:"wallet"`
+	Sport              string `json:"sport"`
+	DisplayName        string `json:"display_name"`
+	SourceRank         int    `json:"source_rank"`
+	PreviousRank       int    `json:"previous_rank,omitempty"`
+	StyleLabel         string `json:"style_label"`
+	PreviousStyleLabel string `json:"previous_style_label,omitempty"`
+	New                bool   `json:"new"`
+}
+
+// JobEvent is a background job changing status or reporting progress.
+type JobEvent struct {
+	JobID   int64  `json:"job_id"`
+	Kind    string `json:"kind"`
+	Status  string `json:"status"`
+	Stage   string `json:"stage,omitempty"`
+	Message string `json:"message,omitempty"`
+	Done    int    `json:"done,omitempty"`
+	Total   int    `json:"total,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Bus fans published events out to every subscriber and keeps the latest
+// ones for replay. Publishing never blocks: a subscriber whose buffer is
+// full misses the event and its drop count goes up.
+type Bus struct {
+	mu      sync.RWMutex
+	nextID  int64
+	subs    map[*Subscription]struct{}
+	history []Event
+	start   int
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		nextID: time.Now().UnixMicro(),
+		subs:   map[*Subscription]struct{}{},
+	}
+}
+
+// Publish assigns the event its ID, and its time when unset, then delivers
+// it to the current subscribers.
+func (b *Bus) Publish(event Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event.ID = b.nextID
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if len(b.history) < historySize {
+		b.history = append(b.history, event)
+	} else {
+		b.history[b.start] = event
+		b.start = (b.start + 1) % historySize
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+	return event
+}
+
+// Subscribe registers a subscriber with room for buffer undelivered events.
+// Callers must Close it when done.
+func (b *Bus) Subscribe(buffer int) *Subscription {
+	sub, _ := b.SubscribeFrom(-1, buffer)
+	return sub
+}
+
+// SubscribeFrom registers a subscriber and returns the retained events
+// after lastID, so a client that reconnects resumes without gaps as long as
+// it missed fewer events than the bus keeps. A negative lastID replays
+// nothing.
+func (b *Bus) SubscribeFrom(lastID int64, buffer int) (*Subscription, []Event) {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, ch: ch, bus: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[sub] = struct{}{}
+
+	replay := []Event{}
+	if lastID < 0 {
+		return sub, replay
+	}
+	for i := range b.history {
+		event := b.history[(b.start+i)%len(b.history)]
+		if event.ID > lastID {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay
+}
+
+// Subscribers is the number of open subscriptions.
+func (b *Bus) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// LastID is the ID of the latest published event.
+func (b *Bus) LastID() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.nextID
+}
+
+type Subscription struct {
+	// C receives the published events and is closed by Close.
+	C <-chan Event
+
+	ch      chan Event
+	bus     *Bus
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// Dropped is how many events this subscriber missed because its buffer was
+// full.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		close(s.ch)
+		s.bus.mu.Unlock()
+	})
+}
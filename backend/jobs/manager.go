@@ -0,0 +1,411 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/events"
+)
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Done reports whether the status is final.
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+// ErrQueueFull is returned by Submit when every worker is busy and the
+// queue has no room left.
+var ErrQueueFull = errors.New("job queue is full")
+
+// ErrStopped is returned by Submit once the manager's context is done.
+var ErrStopped = errors.New("job manager is stopped")
+
+// retainedJobs is how many finished jobs are kept for polling.
+const retainedJobs = 500
+
+// progressInterval throttles how often progress reports are published on
+// the event bus; status changes are always published.
+const progressInterval = time.Second
+
+// Progress is the latest progress a running job reported. Done and Total
+// are optional counters for the current stage.
+type Progress struct {
+	Stage   string `json:"stage,omitempty"`
+	Message string `json:"message,omitempty"`
+	Done    int    `json:"done,omitempty"`
+	Total   int    `json:"total,omitempty"`
+}
+
+// Job is a snapshot of a submitted job. Result is the JSON of the value the
+// job's function returned.
+type Job struct {
+	ID         int64           `json:"id"`
+	Kind       string          `json:"kind"`
+	Key        string          `json:"key,omitempty"`
+	Status     Status          `json:"status"`
+	Progress   Progress        `json:"progress"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// Func is the work of one job. It should return promptly once ctx is
+// canceled and may call Report to publish progress.
+type Func func(ctx context.Context) (any, error)
+
+type entry struct {
+	job          Job
+	fn           Func
+	ctx          context.Context
+	cancel       context.CancelFunc
+	done         chan struct{}
+	lastProgress time.Time
+}
+
+// Manager runs submitted jobs on a fixed pool of workers. At most one job
+// per kind and key is queued or running at a time: submitting a duplicate
+// returns the job already in flight.
+type Manager struct {
+	workers int
+	queue   chan *entry
+	bus     *events.Bus
+
+	mu       sync.Mutex
+	ctx      context.Context
+	stopped  bool
+	nextID   int64
+	jobs     map[int64]*entry
+	inFlight map[string]*entry
+}
+
+func NewManager(workers, queueSize int) *Manager {
+	if workers <= 0 {
+		workers = 2
+	}
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+	return &Manager{
+		workers:  workers,
+		queue:    make(chan *entry, queueSize),
+		ctx:      context.Background(),
+		nextID:   time.Now().UnixMilli(),
+		jobs:     map[int64]*entry{},
+		inFlight: map[string]*entry{},
+	}
+}
+
+// SetEventBus makes the manager publish job status and progress events.
+func (m *Manager) SetEventBus(bus *events.Bus) {
+	m.bus = bus
+}
+
+// Start launches the workers. Jobs run under ctx, so canceling it cancels
+// every running job; jobs still queued then are canceled without running.
+func (m *Manager) Start(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.stop()
+	}()
+
+	for i := 0; i < m.workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case e := <-m.queue:
+					m.run(e)
+				}
+			}
+		}()
+	}
+}
+
+// Submit queues fn as a job of the given kind. Jobs with the same kind and
+// key are single-flight: while one is queued or running, Submit returns it
+// with created false instead of queueing another.
+func (m *Manager) Submit(kind, key string, fn Func) (Job, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped {
+		return Job{}, false, ErrStopped
+	}
+	flightKey := kind + "\x00" + key
+	if existing, ok := m.inFlight[flightKey]; ok {
+		return existing.job, false, nil
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.nextID++
+	e := &entry{
+		job: Job{
+			ID:        m.nextID,
+			Kind:      kind,
+			Key:       key,
+			Status:    StatusQueued,
+			CreatedAt: time.Now().UTC(),
+		},
+		fn:     fn,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	e.ctx = withReporter(ctx, m, e)
+
+	select {
+	case m.queue <- e:
+	default:
+		cancel()
+		return Job{}, false, ErrQueueFull
+	}
+	m.jobs[e.job.ID] = e
+	m.inFlight[flightKey] = e
+	m.prune()
+	m.publish(e.job)
+	return e.job, true, nil
+}
+
+// stop refuses further jobs and cancels every queued one, releasing its
+// kind and key for the next process.
+func (m *Manager) stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stopped = true
+	for {
+		select {
+		case e := <-m.queue:
+			if e.job.Status == StatusQueued {
+				m.finish(e, StatusCanceled, nil, context.Canceled)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Get returns a job by ID.
+func (m *Manager) Get(id int64) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// List returns jobs newest first, optionally filtered by kind and status.
+func (m *Manager) List(kind string, status Status, limit int) []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		if kind != "" && e.job.Kind != kind {
+			continue
+		}
+		if status != "" && e.job.Status != status {
+			continue
+		}
+		jobs = append(jobs, e.job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs
+}
+
+// Cancel cancels a queued or running job. A queued job is marked canceled
+// at once; a running one when its function returns.
+func (m *Manager) Cancel(id int64) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	if e.job.Status == StatusQueued {
+		m.finish(e, StatusCanceled, nil, context.Canceled)
+	}
+	e.cancel()
+	return e.job, true
+}
+
+// Wait blocks until the job finishes or ctx is done and returns the job as
+// of that moment.
+func (m *Manager) Wait(ctx context.Context, id int64) (Job, error) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return Job{}, fmt.Errorf("job %d not found", id)
+	}
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		job, _ := m.Get(id)
+		return job, ctx.Err()
+	}
+	job, _ := m.Get(id)
+	return job, nil
+}
+
+func (m *Manager) run(e *entry) {
+	m.mu.Lock()
+	if e.job.Status != StatusQueued {
+		m.mu.Unlock()
+		return
+	}
+	now := time.Now().UTC()
+	e.job.Status = StatusRunning
+	e.job.StartedAt = &now
+	m.publish(e.job)
+	m.mu.Unlock()
+
+	result, err := m.call(e)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := StatusSucceeded
+	switch {
+	case err != nil && errors.Is(err, context.Canceled):
+		status = StatusCanceled
+	case err != nil:
+		status = StatusFailed
+	}
+	m.finish(e, status, result, err)
+}
+
+// call runs the job's function, turning a panic into a job failure so one
+// bad job cannot take a worker down.
+func (m *Manager) call(e *entry) (result any, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("job %d (%s) panicked: %v", e.job.ID, e.job.Kind, recovered)
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return e.fn(e.ctx)
+}
+
+// finish records a job's outcome; the caller holds m.mu.
+func (m *Manager) finish(e *entry, status Status, result any, err error) {
+	now := time.Now().UTC()
+	e.job.Status = status
+	e.job.FinishedAt = &now
+	if err != nil {
+		e.job.Error = err.Error()
+	}
+	if result != nil && err == nil {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			e.job.Status = StatusFailed
+			e.job.Error = fmt.Sprintf("encode job result: %v", marshalErr)
+		} else {
+			e.job.Result = data
+		}
+	}
+
+	flightKey := e.job.Kind + "\x00" + e.job.Key
+	if m.inFlight[flightKey] == e {
+		delete(m.inFlight, flightKey)
+	}
+	e.cancel()
+	close(e.done)
+	m.publish(e.job)
+}
+
+// prune drops the oldest finished jobs beyond retainedJobs; the caller
+// holds m.mu.
+func (m *Manager) prune() {
+	finished := make([]int64, 0, len(m.jobs))
+	for id, e := range m.jobs {
+		if e.job.Status.Done() {
+			finished = append(finished, id)
+		}
+	}
+	if len(finished) <= retainedJobs {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i] < finished[j] })
+	for _, id := range finished[:len(finished)-retainedJobs] {
+		delete(m.jobs, id)
+	}
+}
+
+func (m *Manager) report(e *entry, progress Progress) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stageChanged := progress.Stage != e.job.Progress.Stage
+	e.job.Progress = progress
+	if stageChanged || time.Since(e.lastProgress) >= progressInterval {
+		e.lastProgress = time.Now()
+		m.publish(e.job)
+	}
+}
+
+// publish sends a job event; the caller holds m.mu.
+func (m *Manager) publish(job Job) {
+	if m.bus == nil {
+		return
+	}
+	m.bus.Publish(events.Event{Type: events.TypeJob, Job: &events.JobEvent{
+		JobID:   job.ID,
+		Kind:    job.Kind,
+		Status:  string(job.Status),
+		Stage:   job.Progress.Stage,
+		Message: job.Progress.Message,
+		Done:    job.Progress.Done,
+		Total:   job.Progress.Total,
+		Error:   job.Error,
+	}})
+}
+
+type reporterKey struct{}
+
+type reporter struct {
+	manager *Manager
+	entry   *entry
+}
+
+func withReporter(ctx context.Context, m *Manager, e *entry) context.Context {
+	return context.WithValue(ctx, reporterKey{}, reporter{manager: m, entry: e})
+}
+
+// Report records progress for the job running under ctx. Outside a job it
+// does nothing, so shared code can report unconditionally.
+func Report(ctx context.Context, progress Progress) {
+	r, ok := ctx.Value(reporterKey{}).(reporter)
+	if !ok {
+		return
+	}
+	r.manager.report(r.entry, progress)
+}
@@ -0,0 +1,185 @@
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+// Elector keeps one instance the holder of a named Postgres lease. The
+// holder renews the lease at a third of its TTL; when it stops renewing,
+// because it crashed or lost the database, another instance takes the lease
+// once it expires.
+type Elector struct {
+	store      storage.Store
+	name       string
+	instanceID string
+	ttl        time.Duration
+	leader     atomic.Bool
+	onAcquire  func(ctx context.Context, previous string)
+	done       chan struct{}
+
+	mu         sync.Mutex
+	leadership context.Context
+	resign     context.CancelFunc
+}
+
+func NewElector(store storage.Store, name, instanceID string, ttl time.Duration) *Elector {
+	if instanceID == "" {
+		instanceID = DefaultInstanceID()
+	}
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	lost, resign := context.WithCancel(context.Background())
+	resign()
+	return &Elector{
+		store:      store,
+		name:       name,
+		instanceID: instanceID,
+		ttl:        ttl,
+		done:       make(chan struct{}),
+		leadership: lost,
+		resign:     resign,
+	}
+}
+
+// DefaultInstanceID is the hostname and process ID plus a random suffix, so
+// a restarted container never reuses its predecessor's lease.
+func DefaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "instance"
+	}
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Start campaigns for the lease until ctx is done, then releases it if
+// held and closes Done. The first attempt finishes before Start returns, so
+// schedulers started next already know whether they lead.
+func (e *Elector) Start(ctx context.Context) {
+	e.campaign(ctx)
+
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				e.release()
+				return
+			case <-ticker.C:
+				e.campaign(ctx)
+			}
+		}
+	}()
+}
+
+// OnAcquire registers fn to run each time this instance takes the lease,
+// with the holder it took the lease from, or "" when the lease was free or
+// released. Call it before Start.
+func (e *Elector) OnAcquire(fn func(ctx context.Context, previous string)) {
+	e.onAcquire = fn
+}
+
+// Done is closed once the elector has stopped and released the lease.
+func (e *Elector) Done() <-chan struct{} {
+	return e.done
+}
+
+// Leadership returns a context that lasts while this instance keeps the
+// lease: it is canceled when a renewal fails, the lease is lost or
+// released, or the context passed to Start is done. Work that only the
+// leader may do should run under it. When this instance does not lead, the
+// context is already canceled.
+func (e *Elector) Leadership() context.Context {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leadership
+}
+
+// IsLeader reports whether this instance held the lease at its last
+// renewal.
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *Elector) InstanceID() string {
+	return e.instanceID
+}
+
+func (e *Elector) Name() string {
+	return e.name
+}
+
+// Current returns the lease as stored, whoever holds it.
+func (e *Elector) Current(ctx context.Context) (storage.SchedulerLease, bool, error) {
+	return e.store.GetLease(ctx, e.name)
+}
+
+func (e *Elector) campaign(ctx context.Context) {
+	callCtx, cancel := context.WithTimeout(ctx, e.ttl/3)
+	defer cancel()
+
+	// Look up the current holder before a takeover, so OnAcquire can clean
+	// up after it.
+	previous := ""
+	if !e.leader.Load() {
+		if current, ok, err := e.store.GetLease(callCtx, e.name); err == nil && ok && current.Holder != e.instanceID {
+			previous = current.Holder
+		}
+	}
+
+	lease, held, err := e.store.AcquireLease(callCtx, e.name, e.instanceID, e.ttl)
+	if err != nil {
+		// Without a renewal the lease may expire and pass to another
+		// instance, so stop acting as leader until one succeeds.
+		held = false
+		log.Printf("%s lease: %v", e.name, err)
+	}
+
+	was := e.leader.Swap(held)
+	switch {
+	case held && !was:
+		e.mu.Lock()
+		e.leadership, e.resign = context.WithCancel(ctx)
+		e.mu.Unlock()
+		log.Printf("%s lease acquired by %s, expires %s", e.name, e.instanceID, lease.ExpiresAt.Format(time.RFC3339))
+		if e.onAcquire != nil {
+			e.onAcquire(ctx, previous)
+		}
+	case !held && was:
+		e.stepDown()
+		log.Printf("%s lease lost by %s", e.name, e.instanceID)
+	}
+}
+
+// stepDown cancels the current leadership context.
+func (e *Elector) stepDown() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.resign()
+}
+
+func (e *Elector) release() {
+	if !e.leader.Swap(false) {
+		return
+	}
+	e.stepDown()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.store.ReleaseLease(ctx, e.name, e.instanceID); err != nil {
+		log.Printf("%s lease: %v", e.name, err)
+	}
+}
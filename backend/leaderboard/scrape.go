@@ -5,17 +5,21 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/sports"
 )
 
-const nbaLeaderboardURL = "https://r.jina.ai/https://polymarketanalytics.com/traders?overallCategory=NBA&sortBy=rank&sortDesc=false"
+const leaderboardURLFormat = "https://r.jina.ai/https://polymarketanalytics.com/traders?overallCategory=%s&sortBy=rank&sortDesc=false"
 
 var rowPattern = regexp.MustCompile(`^\|\s*\|\s*(\d+)\s*\|\s*\[([^\]]+)\]\(https://polymarketanalytics\.com/traders/(0x[a-f0-9]{40})\)\s*\|\s*([0-9,]+)\s*\|\s*([0-9,]+)\s*\|\s*\$([0-9,.\-]+)\s*\|\s*\$([0-9,.\-]+)\s*\|\s*([0-9.]+)%\s*\|\s*\$([0-9,.\-]+)\s*\|\s*\$([0-9,.\-]+)\s*\|$`)
 
 type Entry struct {
+	Sport            string
 	Rank             int
 	DisplayName      string
 	WalletAddress    string
@@ -29,8 +33,21 @@ type Entry struct {
 	FetchedAt        time.Time
 }
 
-func FetchNBALeaderboard(ctx context.Context, limit int) ([]Entry, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, nbaLeaderboardURL, nil)
+// Source returns the tracked_wallets source tag for a sport's leaderboard.
+func Source(sport string) string {
+	return "polymarketanalytics_" + sports.Normalize(sport)
+}
+
+// FetchLeaderboard scrapes the Polymarket Analytics trader leaderboard for the
+// sport's leaderboard category.
+func FetchLeaderboard(ctx context.Context, sport string, limit int) ([]Entry, error) {
+	info, _ := sports.Lookup(sport)
+	if info.LeaderboardCategory == "" {
+		return nil, fmt.Errorf("no leaderboard source for sport %q", info.Key)
+	}
+
+	leaderboardURL := fmt.Sprintf(leaderboardURLFormat, url.QueryEscape(info.LeaderboardCategory))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, leaderboardURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("build leaderboard request: %w", err)
 	}
@@ -59,6 +76,7 @@ func FetchNBALeaderboard(ctx context.Context, limit int) ([]Entry, error) {
 		if err != nil {
 			continue
 		}
+		entry.Sport = info.Key
 		entries = append(entries, entry)
 		if limit > 0 && len(entries) >= limit {
 			break
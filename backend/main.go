@@ -2,37 +2,71 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/brucexwang/easy-arbitra/backend/alerts"
+	"github.com/brucexwang/easy-arbitra/backend/consensus"
 	"github.com/brucexwang/easy-arbitra/backend/discovery"
+	"github.com/brucexwang/easy-arbitra/backend/events"
+	"github.com/brucexwang/easy-arbitra/backend/jobs"
+	"github.com/brucexwang/easy-arbitra/backend/leader"
+	"github.com/brucexwang/easy-arbitra/backend/paper"
 	"github.com/brucexwang/easy-arbitra/backend/polymarket"
 	"github.com/brucexwang/easy-arbitra/backend/profileai"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
 	"github.com/brucexwang/easy-arbitra/backend/storage"
+	"github.com/brucexwang/easy-arbitra/backend/styles"
 	profilesync "github.com/brucexwang/easy-arbitra/backend/sync"
 	"github.com/brucexwang/easy-arbitra/backend/tools"
+	"github.com/brucexwang/easy-arbitra/backend/watcher"
+	"github.com/gorilla/websocket"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
 	client := polymarket.NewClient()
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if rulesPath := os.Getenv("STYLE_LABEL_RULES"); rulesPath != "" {
+		rules, err := styles.Load(rulesPath)
+		if err != nil {
+			log.Fatalf("failed to load style label rules: %v", err)
+		}
+		styles.SetActive(rules)
+		log.Printf("loaded style label rules from %s", rulesPath)
+	}
 
 	var (
-		store       *storage.Store
-		syncService *profilesync.Service
+		store        storage.Store
+		syncService  *profilesync.Service
+		syncElector  *leader.Elector
+		tradeWatcher *watcher.Service
+		alertService *alerts.Service
+		bus          = events.NewBus()
+		jobManager   = jobs.NewManager(parseIntEnv("JOB_WORKERS", 2), parseIntEnv("JOB_QUEUE_SIZE", 100))
 	)
+	jobManager.SetEventBus(bus)
+	jobManager.Start(ctx)
 	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
 		var err error
 		store, err = storage.Open(ctx, databaseURL)
 		if err != nil {
-			log.Fatalf("failed to connect postgres: %v", err)
+			log.Fatalf("failed to open wallet catalog: %v", err)
 		}
 		defer store.Close()
 
@@ -40,16 +74,46 @@ func main() {
 			client,
 			store,
 			profileai.NewFromEnv(),
+			sports.ParseList(fallbackString(os.Getenv("LEADERBOARD_SPORTS"), sports.Default)),
 			parseDurationEnv("LEADERBOARD_SYNC_INTERVAL", 4*time.Hour),
 			parseIntEnv("LEADERBOARD_TOP_LIMIT", 100),
 			parseIntEnv("WALLET_ANALYSIS_LIMIT", 3000),
 		)
+		syncService.SetConsensusOptions(consensus.Options{
+			TopWallets: parseIntEnv("CONSENSUS_TOP_WALLETS", 50),
+			Horizon:    parseDurationEnv("CONSENSUS_HORIZON", 7*24*time.Hour),
+		})
+		syncService.SetEventBus(bus)
+		syncService.SetJobManager(jobManager)
+		syncElector = leader.NewElector(store, "wallet_sync", os.Getenv("INSTANCE_ID"), parseDurationEnv("SYNC_LEASE_TTL", time.Minute))
+		syncService.SetElector(syncElector)
+		syncElector.Start(ctx)
 		syncService.Start(ctx)
-		log.Println("leaderboard sync enabled")
+		log.Printf("leaderboard sync enabled for %s", strings.Join(syncService.Sports(), ", "))
+
+		paper.NewService(client, store, parseDurationEnv("PAPER_POLL_INTERVAL", time.Minute)).Start(ctx)
+
+		tradeWatcher = watcher.NewService(client, store, bus, watcher.Options{
+			Interval:       parseDurationEnv("WATCHER_INTERVAL", 15*time.Second),
+			RecentLimit:    parseIntEnv("WATCHER_RECENT_LIMIT", 500),
+			WalletsPerPoll: parseIntEnv("WATCHER_WALLETS_PER_POLL", 20),
+		})
+		tradeWatcher.Start(ctx)
+
+		alertService = alerts.NewService(store, bus)
+		alertService.Start(ctx)
 	} else {
 		log.Println("DATABASE_URL not set; leaderboard sync disabled")
 	}
 
+	sports.NewIndexer(
+		client,
+		store,
+		sports.SharedIndex(),
+		parseDurationEnv("SPORTS_INDEX_INTERVAL", 6*time.Hour),
+		parseIntEnv("SPORTS_INDEX_PAGES", 20),
+	).Start(ctx)
+
 	// Create MCP Server
 	mcpServer := server.NewMCPServer(
 		"SportStyle",
@@ -58,13 +122,13 @@ func main() {
 	)
 
 	// Register tools
-	registerTools(mcpServer, client)
+	registerTools(mcpServer, client, store)
 
 	// Start SSE server on :8081
 	sseServer := server.NewSSEServer(mcpServer)
 	go func() {
 		log.Println("MCP SSE Server starting on :8081")
-		if err := sseServer.Start(":8081"); err != nil {
+		if err := sseServer.Start(":8081"); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Printf("SSE server error: %v", err)
 			os.Exit(1)
 		}
@@ -72,20 +136,79 @@ func main() {
 
 	// Start REST bridge on :8082
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/tools/call", corsMiddleware(restBridge(client)))
-	mux.HandleFunc("/api/tools/call-stream", corsMiddleware(restBridgeStream(client)))
-	mux.HandleFunc("/api/discover-wallets", corsMiddleware(discoverWalletsHandler(client)))
+	mux.HandleFunc("/api/tools/call", corsMiddleware(restBridge(client, store)))
+	mux.HandleFunc("/api/tools/call-stream", corsMiddleware(restBridgeStream(client, store)))
+	mux.HandleFunc("/api/discover-wallets", corsMiddleware(discoverWalletsHandler(client, store, jobManager)))
 	mux.HandleFunc("/api/style-wallets", corsMiddleware(styleWalletsHandler(store)))
 	mux.HandleFunc("/api/style-wallets/sync", corsMiddleware(syncStyleWalletsHandler(syncService)))
+	mux.HandleFunc("/api/style-wallets/sync/status", corsMiddleware(syncStatusHandler(syncService, syncElector, store)))
+	mux.HandleFunc("/api/style-wallets/sync/runs", corsMiddleware(syncRunsHandler(store)))
+	mux.HandleFunc("/api/style-wallets/sync/runs/{id}", corsMiddleware(syncRunHandler(store)))
+	mux.HandleFunc("/api/style-wallets/history", corsMiddleware(walletHistoryHandler(client, store)))
+	mux.HandleFunc("/api/style-wallets/movers", corsMiddleware(walletMoversHandler(store)))
+	mux.HandleFunc("/api/wallets", corsMiddleware(walletsHandler(store)))
+	mux.HandleFunc("/api/wallets/{address}", corsMiddleware(walletHandler(store)))
+	mux.HandleFunc("/api/style-labels", corsMiddleware(styleLabelsHandler))
+	mux.HandleFunc("/api/sports", corsMiddleware(sportsHandler))
+	mux.HandleFunc("/api/similar-wallets", corsMiddleware(similarWalletsHandler(client, store)))
+	mux.HandleFunc("/api/compare-wallets", corsMiddleware(compareWalletsHandler(client, store)))
+	mux.HandleFunc("/api/games", corsMiddleware(gamesHandler))
+	mux.HandleFunc("/api/games/{id}", corsMiddleware(gameHandler(client, store)))
+	mux.HandleFunc("/api/market-smart-money", corsMiddleware(marketSmartMoneyHandler(client, store)))
+	mux.HandleFunc("/api/consensus", corsMiddleware(consensusHandler(store)))
+	mux.HandleFunc("/api/arbitrage", corsMiddleware(arbitrageHandler(client)))
+	mux.HandleFunc("/api/consistency", corsMiddleware(consistencyHandler(client)))
+	mux.HandleFunc("/api/paper/portfolios", corsMiddleware(paperPortfoliosHandler(client, store)))
+	mux.HandleFunc("/api/paper/portfolios/{id}", corsMiddleware(paperPortfolioHandler(store)))
+	mux.HandleFunc("/api/watchlists", corsMiddleware(watchlistsHandler(client, store, tradeWatcher)))
+	mux.HandleFunc("/api/watchlists/{name}/wallets/{wallet}", corsMiddleware(watchlistWalletHandler(store, tradeWatcher)))
+	mux.HandleFunc("/api/watcher/status", corsMiddleware(watcherStatusHandler(tradeWatcher)))
+	mux.HandleFunc("/api/alerts/kinds", corsMiddleware(alertKindsHandler))
+	mux.HandleFunc("/api/alerts/rules", corsMiddleware(alertRulesHandler(client, store, alertService)))
+	mux.HandleFunc("/api/alerts/rules/{id}", corsMiddleware(alertRuleHandler(client, store, alertService)))
+	mux.HandleFunc("/api/alerts/deliveries", corsMiddleware(alertDeliveriesHandler(store)))
+	mux.HandleFunc("/api/alerts/deliveries/{id}/retry", corsMiddleware(retryAlertDeliveryHandler(store)))
+	mux.HandleFunc("/api/events", corsMiddleware(eventStreamHandler(bus)))
+	mux.HandleFunc("/api/events/ws", corsMiddleware(eventSocketHandler(bus)))
+	mux.HandleFunc("/api/jobs", corsMiddleware(jobsHandler(client, store, syncService, jobManager)))
+	mux.HandleFunc("/api/jobs/{id}", corsMiddleware(jobHandler(jobManager)))
+	mux.HandleFunc("/api/jobs/{id}/cancel", corsMiddleware(cancelJobHandler(jobManager)))
 	mux.HandleFunc("/api/health", corsMiddleware(healthHandler))
 
-	log.Println("REST bridge starting on :8082")
-	if err := http.ListenAndServe(":8082", mux); err != nil {
-		log.Fatalf("REST server error: %v", err)
+	// Requests, including event streams, end when shutdown starts.
+	restServer := &http.Server{
+		Addr:        ":8082",
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		log.Println("REST bridge starting on :8082")
+		if err := restServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("REST server error: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := restServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("REST server shutdown: %v", err)
+	}
+	if err := sseServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("SSE server shutdown: %v", err)
+	}
+	// The elector releases the scheduler lease once ctx is done, so another
+	// instance can take over without waiting for it to expire.
+	if syncElector != nil {
+		select {
+		case <-syncElector.Done():
+		case <-shutdownCtx.Done():
+		}
 	}
 }
 
-func registerTools(s *server.MCPServer, client *polymarket.Client) {
+func registerTools(s *server.MCPServer, client *polymarket.Client, store storage.Store) {
 	// 1. resolve_wallet_target
 	s.AddTool(mcp.NewTool("resolve_wallet_target",
 		mcp.WithDescription("Resolve a wallet address or Polymarket profile URL to a standardized wallet target with display name and profile image."),
@@ -103,12 +226,12 @@ func registerTools(s *server.MCPServer, client *polymarket.Client) {
 			mcp.Required(),
 		),
 		mcp.WithString("sport",
-			mcp.Description("Sport to filter trades by (e.g., 'nba')"),
+			mcp.Description("Sport to filter trades by (e.g., 'nba', 'nfl', 'mlb', 'nhl', 'epl', 'tennis', 'mma')"),
 		),
 		mcp.WithNumber("limit",
 			mcp.Description("Maximum number of trades to fetch (default 500)"),
 		),
-	), tools.FetchSportsTrades(client))
+	), tools.FetchSportsTrades(client, store))
 
 	// 3. calculate_style_metrics
 	s.AddTool(mcp.NewTool("calculate_style_metrics",
@@ -121,6 +244,9 @@ func registerTools(s *server.MCPServer, client *polymarket.Client) {
 			mcp.Description("JSON array of enriched trades from fetch_sports_trades"),
 			mcp.Required(),
 		),
+		mcp.WithString("sport",
+			mcp.Description("Sport the trades belong to; defaults to the sport in trades_json or 'nba'"),
+		),
 	), tools.CalculateStyleMetrics())
 
 	// 4. build_report_payload
@@ -137,7 +263,171 @@ func registerTools(s *server.MCPServer, client *polymarket.Client) {
 		mcp.WithString("trades_summary",
 			mcp.Description("Optional JSON string of trades summary for additional context"),
 		),
+		mcp.WithString("sport",
+			mcp.Description("Sport shown on the wallet card; defaults to the sport in metrics_json or 'nba'"),
+		),
 	), tools.BuildReportPayload())
+
+	s.AddTool(mcp.NewTool("find_similar_wallets",
+		mcp.WithDescription("Find the tracked wallets whose normalized style metrics are closest to a given wallet, with distances and the metrics driving the similarity."),
+		mcp.WithString("wallet",
+			mcp.Description("Standardized wallet address (0x...)"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("k",
+			mcp.Description("Number of similar wallets to return (default 10)"),
+		),
+		mcp.WithString("sport",
+			mcp.Description("Sport whose profiles to search (default 'nba')"),
+		),
+		mcp.WithNumber("min_trades",
+			mcp.Description("Minimum sport trades a candidate must have"),
+		),
+		mcp.WithNumber("min_pnl_usd",
+			mcp.Description("Minimum leaderboard PnL in USD a candidate must have"),
+		),
+		mcp.WithNumber("max_pnl_usd",
+			mcp.Description("Maximum leaderboard PnL in USD a candidate may have"),
+		),
+	), tools.FindSimilarWallets(client, store))
+
+	s.AddTool(mcp.NewTool("compare_wallets",
+		mcp.WithDescription("Compare two or more traders head to head: per-wallet metrics and radar charts, shared markets where they took the same or opposite sides, and who got the better price."),
+		mcp.WithArray("inputs",
+			mcp.Description("Wallet addresses (0x...) or Polymarket profile URLs to compare"),
+			mcp.Items(map[string]any{"type": "string"}),
+			mcp.Required(),
+		),
+		mcp.WithString("sport",
+			mcp.Description("Sport to filter trades by (default 'nba')"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of trades to scan per wallet (default 3000)"),
+		),
+	), tools.CompareWallets(client, store))
+
+	s.AddTool(mcp.NewTool("get_game_activity",
+		mcp.WithDescription("Fetch one game (matchup) with all of its markets - winner, spread, total and props - and what every tracked leaderboard wallet traded in them."),
+		mcp.WithString("game",
+			mcp.Description("Gamma event ID or event slug of the game"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of trades to scan per market (default 1000)"),
+		),
+	), tools.GetGameActivity(client, store))
+
+	s.AddTool(mcp.NewTool("market_smart_money",
+		mcp.WithDescription("Show which tracked leaderboard wallets hold or traded a market, on which side and at what average price, with ROI-weighted net flow per outcome and over time."),
+		mcp.WithString("market",
+			mcp.Description("Market condition ID (0x...) or event slug; a slug covers every market of the event"),
+			mcp.Required(),
+		),
+		mcp.WithString("sport",
+			mcp.Description("Sport whose tracked wallets to match (defaults to the market's indexed sport, else 'nba')"),
+		),
+		mcp.WithString("bucket",
+			mcp.Description("Flow time bucket: 'hour' (default) or 'day'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of trades to scan per market (default 2000)"),
+		),
+	), tools.MarketSmartMoney(client, store))
+
+	s.AddTool(mcp.NewTool("scan_arbitrage",
+		mcp.WithDescription("Scan CLOB order books of a sport's open game markets for complementary-outcome arbitrage: best asks summing below 1 or best bids above 1 after fees, sized by walking the books."),
+		mcp.WithString("sport",
+			mcp.Description("Sport whose open game markets to scan (default 'nba')"),
+		),
+		mcp.WithNumber("fee_bps",
+			mcp.Description("Taker fee in basis points applied to every fill (default 0)"),
+		),
+		mcp.WithNumber("min_edge",
+			mcp.Description("Smallest per-share profit after fees to report (default 0)"),
+		),
+		mcp.WithNumber("max_markets",
+			mcp.Description("Maximum number of markets to read books for (default 200)"),
+		),
+	), tools.ScanArbitrage(client))
+
+	s.AddTool(mcp.NewTool("check_consistency",
+		mcp.WithDescription("Group a sport's open markets by event and check declared consistency constraints (mutually exclusive outcomes summing to 1, spread and moneyline implications, spread and total ladders); violations are ranked by edge and liquidity."),
+		mcp.WithString("sport",
+			mcp.Description("Sport whose open events to check (default 'nba')"),
+		),
+		mcp.WithArray("constraints",
+			mcp.Description("Constraint names to run (default all registered)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("min_edge",
+			mcp.Description("Smallest per-share edge to report (default 0)"),
+		),
+		mcp.WithNumber("min_liquidity",
+			mcp.Description("Smallest market liquidity in USD among a violation's legs (default 0)"),
+		),
+		mcp.WithNumber("max_events",
+			mcp.Description("Maximum number of open events to check (default 300)"),
+		),
+	), tools.CheckConsistency(client))
+
+	s.AddTool(mcp.NewTool("backtest_copy_trading",
+		mcp.WithDescription("Backtest copying a wallet: replays its sport trades in time order under a copy strategy, settles positions when their markets resolve and returns an equity curve, every copied fill and summary stats (PnL, return, max drawdown, win rate, slippage)."),
+		mcp.WithString("input",
+			mcp.Description("Wallet address (0x...), Polymarket profile URL or @slug to copy"),
+			mcp.Required(),
+		),
+		mcp.WithString("sport",
+			mcp.Description("Sport to filter trades by (default 'nba')"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of recent trades to scan (default 3000)"),
+		),
+		mcp.WithString("mode",
+			mcp.Description("'fixed' stakes stake_usd per copied buy (default); 'proportional' stakes ratio times the wallet's notional"),
+		),
+		mcp.WithNumber("stake_usd",
+			mcp.Description("USD per copied buy in fixed mode (default 100)"),
+		),
+		mcp.WithNumber("ratio",
+			mcp.Description("Fraction of the wallet's notional per copied buy in proportional mode (default 0.1)"),
+		),
+		mcp.WithNumber("delay_seconds",
+			mcp.Description("Seconds between the wallet's trade and the copy (default 0)"),
+		),
+		mcp.WithString("slippage",
+			mcp.Description("'history' fills at the token's CLOB price at copy time (default); 'none' fills at the wallet's price"),
+		),
+		mcp.WithNumber("slippage_bps",
+			mcp.Description("Extra adverse slippage in basis points on every fill (default 0)"),
+		),
+		mcp.WithNumber("max_exposure_usd",
+			mcp.Description("Cap on the cost of open positions; 0 means no cap (default 0)"),
+		),
+		mcp.WithNumber("bankroll_usd",
+			mcp.Description("Starting cash (default 10000)"),
+		),
+		mcp.WithBoolean("ignore_sells",
+			mcp.Description("Hold copies to resolution instead of copying the wallet's sells (default false)"),
+		),
+	), tools.BacktestCopyTrading(client))
+}
+
+type toolHandler func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+func toolHandlers(client *polymarket.Client, store storage.Store) map[string]toolHandler {
+	return map[string]toolHandler{
+		"resolve_wallet_target":   tools.ResolveWalletTarget(client),
+		"fetch_sports_trades":     tools.FetchSportsTrades(client, store),
+		"calculate_style_metrics": tools.CalculateStyleMetrics(),
+		"build_report_payload":    tools.BuildReportPayload(),
+		"find_similar_wallets":    tools.FindSimilarWallets(client, store),
+		"compare_wallets":         tools.CompareWallets(client, store),
+		"get_game_activity":       tools.GetGameActivity(client, store),
+		"market_smart_money":      tools.MarketSmartMoney(client, store),
+		"scan_arbitrage":          tools.ScanArbitrage(client),
+		"check_consistency":       tools.CheckConsistency(client),
+		"backtest_copy_trading":   tools.BacktestCopyTrading(client),
+	}
 }
 
 // REST bridge handler
@@ -146,13 +436,8 @@ type ToolCallRequest struct {
 	Args map[string]interface{} `json:"args"`
 }
 
-func restBridge(client *polymarket.Client) http.HandlerFunc {
-	handlers := map[string]func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error){
-		"resolve_wallet_target":   tools.ResolveWalletTarget(client),
-		"fetch_sports_trades":     tools.FetchSportsTrades(client),
-		"calculate_style_metrics": tools.CalculateStyleMetrics(),
-		"build_report_payload":    tools.BuildReportPayload(),
-	}
+func restBridge(client *polymarket.Client, store storage.Store) http.HandlerFunc {
+	handlers := toolHandlers(client, store)
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -187,13 +472,8 @@ func restBridge(client *polymarket.Client) http.HandlerFunc {
 	}
 }
 
-func restBridgeStream(client *polymarket.Client) http.HandlerFunc {
-	handlers := map[string]func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error){
-		"resolve_wallet_target":   tools.ResolveWalletTarget(client),
-		"fetch_sports_trades":     tools.FetchSportsTrades(client),
-		"calculate_style_metrics": tools.CalculateStyleMetrics(),
-		"build_report_payload":    tools.BuildReportPayload(),
-	}
+func restBridgeStream(client *polymarket.Client, store storage.Store) http.HandlerFunc {
+	handlers := toolHandlers(client, store)
 
 	type streamEvent struct {
 		Type      string              `json:"type"`
@@ -282,7 +562,7 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
-func styleWalletsHandler(store *storage.Store) http.HandlerFunc {
+func styleWalletsHandler(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -294,7 +574,14 @@ func styleWalletsHandler(store *storage.Store) http.HandlerFunc {
 		}
 
 		limit := fallbackInt(parseQueryInt(r, "limit_per_group"), 6)
-		groups, err := store.ListStyleGroups(r.Context(), limit)
+		groupBy := storage.GroupBy(fallbackString(r.URL.Query().Get("group_by"), string(storage.GroupByStyleLabel)))
+		if groupBy != storage.GroupByStyleLabel && groupBy != storage.GroupByCluster {
+			http.Error(w, fmt.Sprintf("unsupported group_by: %s", groupBy), http.StatusBadRequest)
+			return
+		}
+
+		sport := sports.Normalize(r.URL.Query().Get("sport"))
+		groups, err := store.ListStyleGroups(r.Context(), sport, limit, groupBy)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("list style wallets error: %v", err), http.StatusInternalServerError)
 			return
@@ -302,151 +589,1659 @@ func styleWalletsHandler(store *storage.Store) http.HandlerFunc {
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]any{
-			"groups": groups,
+			"sport":    sport,
+			"group_by": groupBy,
+			"groups":   groups,
 		})
 	}
 }
 
-func syncStyleWalletsHandler(service *profilesync.Service) http.HandlerFunc {
+func styleLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"labels":  styles.Active().Labels(),
+		"metrics": styles.Metrics(),
+	})
+}
+
+func walletHistoryHandler(client *polymarket.Client, store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
+		if r.Method != http.MethodGet {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-		if service == nil {
-			http.Error(w, "style wallet sync is unavailable", http.StatusServiceUnavailable)
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		input := r.URL.Query().Get("wallet")
+		if input == "" {
+			http.Error(w, "wallet query parameter is required", http.StatusBadRequest)
+			return
+		}
+		resolved, err := tools.ResolveWalletTargetData(r.Context(), client, input)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("resolve wallet %s: %v", input, err), http.StatusBadRequest)
 			return
 		}
 
-		if err := service.RunOnce(r.Context()); err != nil {
-			http.Error(w, fmt.Sprintf("sync style wallets error: %v", err), http.StatusInternalServerError)
+		sport := sports.Normalize(r.URL.Query().Get("sport"))
+		days := fallbackInt(parseQueryInt(r, "days"), 90)
+		since := time.Now().UTC().AddDate(0, 0, -days)
+		snapshots, err := store.ListWalletSnapshots(r.Context(), resolved.WalletAddress, sport, since, parseQueryInt(r, "limit"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("list wallet history error: %v", err), http.StatusInternalServerError)
 			return
 		}
+		if snapshots == nil {
+			snapshots = []storage.WalletSnapshot{}
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]any{
-			"ok": true,
+			"wallet":    resolved.WalletAddress,
+			"sport":     sport,
+			"since":     since,
+			"snapshots": snapshots,
 		})
 	}
 }
 
-type DiscoverWalletsRequest struct {
-	Mode            string   `json:"mode"`
-	Sport           string   `json:"sport"`
-	RecentLimit     int      `json:"recent_limit"`
-	RecentPages     int      `json:"recent_pages"`
-	CandidateLimit  int      `json:"candidate_limit"`
-	OutputLimit     int      `json:"output_limit"`
-	MinRecentTrades int      `json:"min_recent_trades"`
-	WalletLimit     int      `json:"wallet_limit"`
-	Wallets         []string `json:"wallets"`
-}
-
-func discoverWalletsHandler(client *polymarket.Client) http.HandlerFunc {
+func walletMoversHandler(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
+		if r.Method != http.MethodGet {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
 
-		var req DiscoverWalletsRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		metric := storage.MoverMetric(fallbackString(r.URL.Query().Get("by"), string(storage.MoverByRank)))
+		if metric != storage.MoverByRank && metric != storage.MoverByPnl {
+			http.Error(w, fmt.Sprintf("unsupported by: %s", metric), http.StatusBadRequest)
 			return
 		}
 
-		opts := discovery.Options{
-			Sport:           fallbackString(req.Sport, "nba"),
-			RecentLimit:     fallbackInt(req.RecentLimit, 400),
-			RecentPages:     fallbackInt(req.RecentPages, 4),
-			CandidateLimit:  fallbackInt(req.CandidateLimit, 20),
-			OutputLimit:     fallbackInt(req.OutputLimit, 10),
-			MinRecentTrades: fallbackInt(req.MinRecentTrades, 2),
-			WalletLimit:     fallbackInt(req.WalletLimit, 500),
+		sport := sports.Normalize(r.URL.Query().Get("sport"))
+		days := fallbackInt(parseQueryInt(r, "days"), 7)
+		since := time.Now().UTC().AddDate(0, 0, -days)
+		movers, err := store.ListWalletMovers(r.Context(), storage.MoverFilter{
+			Sport:  sport,
+			Since:  since,
+			Metric: metric,
+			Limit:  fallbackInt(parseQueryInt(r, "limit"), 20),
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("list wallet movers error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if movers == nil {
+			movers = []storage.WalletMover{}
 		}
 
-		var (
-			results []discovery.Candidate
-			err     error
-		)
-		switch req.Mode {
-		case "wallets":
-			results, err = discovery.ScoreWallets(r.Context(), client, req.Wallets, opts)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"sport":  sport,
+			"by":     metric,
+			"since":  since,
+			"movers": movers,
+		})
+	}
+}
+
+// maxWalletPageSize caps the limit of one /api/wallets page.
+const maxWalletPageSize = 200
+
+func walletsHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		params := r.URL.Query()
+		sortBy := storage.WalletSort(fallbackString(params.Get("sort"), string(storage.WalletSortRank)))
+		if !sortBy.Valid() {
+			http.Error(w, fmt.Sprintf("unsupported sort: %s", sortBy), http.StatusBadRequest)
+			return
+		}
+		descending := sortBy.DefaultDescending()
+		switch order := params.Get("order"); order {
+		case "":
+		case "asc":
+			descending = false
+		case "desc":
+			descending = true
 		default:
-			results, err = discovery.DiscoverFromRecent(r.Context(), client, opts)
+			http.Error(w, fmt.Sprintf("unsupported order: %s", order), http.StatusBadRequest)
+			return
+		}
+
+		// An omitted sport lists every sport rather than the default one.
+		sport := ""
+		if raw := params.Get("sport"); strings.TrimSpace(raw) != "" {
+			sport = sports.Normalize(raw)
+		}
+		query := storage.WalletQuery{
+			Sport:      sport,
+			StyleLabel: params.Get("style"),
+			Search:     strings.TrimSpace(params.Get("q")),
+			MinTrades:  parseQueryInt(r, "min_trades"),
+			MinPnlUSD:  parseQueryFloat(r, "min_pnl_usd"),
+			MaxPnlUSD:  parseQueryFloat(r, "max_pnl_usd"),
+			MinWinRate: parseQueryFloat(r, "min_win_rate"),
+			MaxWinRate: parseQueryFloat(r, "max_win_rate"),
+			Sort:       sortBy,
+			Descending: descending,
+			Limit:      min(fallbackInt(parseQueryInt(r, "limit"), 50), maxWalletPageSize),
+		}
+		if value := params.Get("analyzed_since"); value != "" {
+			since, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid analyzed_since: %v", err), http.StatusBadRequest)
+				return
+			}
+			query.AnalyzedSince = since
+		}
+		if token := params.Get("cursor"); token != "" {
+			cursor, err := storage.DecodeWalletCursor(token)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid cursor: %v", err), http.StatusBadRequest)
+				return
+			}
+			if cursor.Sort != sortBy || cursor.Descending != descending {
+				http.Error(w, "cursor was issued for a different sort or order", http.StatusBadRequest)
+				return
+			}
+			query.After = &cursor
 		}
+
+		wallets, next, err := store.QueryWallets(r.Context(), query)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("discover wallets error: %v", err), http.StatusInternalServerError)
+			http.Error(w, fmt.Sprintf("query wallets error: %v", err), http.StatusInternalServerError)
 			return
 		}
+		if wallets == nil {
+			wallets = []storage.CatalogWallet{}
+		}
+		nextCursor := ""
+		if next != nil {
+			nextCursor = next.Encode()
+		}
 
+		order := "asc"
+		if descending {
+			order = "desc"
+		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]any{
-			"mode":    req.Mode,
-			"results": results,
+			"sport":       query.Sport,
+			"sort":        sortBy,
+			"order":       order,
+			"wallets":     wallets,
+			"next_cursor": nextCursor,
 		})
 	}
 }
 
-func fallbackString(value, defaultValue string) string {
-	if value == "" {
-		return defaultValue
-	}
-	return value
+// walletDetailEntry is one sport of a wallet in the /api/wallets/{address}
+// response: its catalog row and its most recent snapshot.
+type walletDetailEntry struct {
+	storage.CatalogWallet
+	LatestSnapshot *storage.WalletSnapshot `json:"latest_snapshot,omitempty"`
 }
 
-func fallbackInt(value, defaultValue int) int {
-	if value <= 0 {
-		return defaultValue
-	}
-	return value
-}
+func walletHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
 
-func parseIntEnv(key string, fallback int) int {
-	value := os.Getenv(key)
-	if value == "" {
-		return fallback
-	}
-	parsed, err := strconv.Atoi(value)
-	if err != nil || parsed <= 0 {
-		return fallback
-	}
-	return parsed
-}
+		address := strings.ToLower(r.PathValue("address"))
+		entries, err := store.GetCatalogWallet(r.Context(), address)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("get wallet error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if len(entries) == 0 {
+			http.Error(w, fmt.Sprintf("wallet %s is not in the catalog", address), http.StatusNotFound)
+			return
+		}
 
-func parseDurationEnv(key string, fallback time.Duration) time.Duration {
-	value := os.Getenv(key)
-	if value == "" {
-		return fallback
-	}
-	duration, err := time.ParseDuration(value)
-	if err != nil || duration <= 0 {
-		return fallback
-	}
-	return duration
-}
+		detail := make([]walletDetailEntry, 0, len(entries))
+		for _, entry := range entries {
+			snapshots, err := store.ListWalletSnapshots(r.Context(), entry.WalletAddress, entry.Sport, time.Time{}, 1)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("list wallet history error: %v", err), http.StatusInternalServerError)
+				return
+			}
+			item := walletDetailEntry{CatalogWallet: entry}
+			if len(snapshots) > 0 {
+				item.LatestSnapshot = &snapshots[len(snapshots)-1]
+			}
+			detail = append(detail, item)
+		}
 
-func parseQueryInt(r *http.Request, key string) int {
-	value := r.URL.Query().Get(key)
-	if value == "" {
-		return 0
-	}
-	parsed, err := strconv.Atoi(value)
-	if err != nil {
-		return 0
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"wallet_address": entries[0].WalletAddress,
+			"sports":         detail,
+		})
 	}
-	return parsed
 }
 
-func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
+func similarWalletsHandler(client *polymarket.Client, store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
-			return
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		wallet := r.URL.Query().Get("wallet")
+		if wallet == "" {
+			http.Error(w, "wallet query parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		result, err := tools.FindSimilarWalletsData(r.Context(), client, store, tools.SimilarWalletsQuery{
+			Wallet:    wallet,
+			K:         fallbackInt(parseQueryInt(r, "k"), 10),
+			Sport:     sports.Normalize(r.URL.Query().Get("sport")),
+			MinTrades: parseQueryInt(r, "min_trades"),
+			MinPnlUSD: parseQueryFloat(r, "min_pnl_usd"),
+			MaxPnlUSD: parseQueryFloat(r, "max_pnl_usd"),
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("find similar wallets error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func gamesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sport := sports.Normalize(r.URL.Query().Get("sport"))
+	games := sports.SharedIndex().Games(sports.GameFilter{
+		Sport:  sport,
+		Status: r.URL.Query().Get("status"),
+		Limit:  fallbackInt(parseQueryInt(r, "limit"), 50),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"sport": sport,
+		"games": games,
+	})
+}
+
+func gameHandler(client *polymarket.Client, store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := r.PathValue("id")
+		if _, ok := sports.SharedIndex().Game(id); !ok {
+			http.Error(w, fmt.Sprintf("game not found: %s", id), http.StatusNotFound)
+			return
+		}
+
+		result, err := tools.GameActivityData(r.Context(), client, store, id, fallbackInt(parseQueryInt(r, "limit"), 1000))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("game activity error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func marketSmartMoneyHandler(client *polymarket.Client, store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		market := r.URL.Query().Get("market")
+		if market == "" {
+			http.Error(w, "market query parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		result, err := tools.MarketSmartMoneyData(r.Context(), client, store, tools.SmartMoneyQuery{
+			Market:     market,
+			Sport:      r.URL.Query().Get("sport"),
+			Bucket:     r.URL.Query().Get("bucket"),
+			TradeLimit: parseQueryInt(r, "limit"),
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("market smart money error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func consensusHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		sport := sports.Normalize(r.URL.Query().Get("sport"))
+		markets, err := store.ListMarketConsensus(r.Context(), sports.WithLeagues(sport), fallbackInt(parseQueryInt(r, "limit"), 25))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("list consensus error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"sport":   sport,
+			"markets": markets,
+		})
+	}
+}
+
+func arbitrageHandler(client *polymarket.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := tools.ArbitrageQuery{
+			Sport:      sports.Normalize(r.URL.Query().Get("sport")),
+			MaxMarkets: parseQueryInt(r, "max_markets"),
+		}
+		if v := parseQueryFloat(r, "fee_bps"); v != nil {
+			query.FeeBps = *v
+		}
+		if v := parseQueryFloat(r, "min_edge"); v != nil {
+			query.MinEdge = *v
+		}
+
+		result, err := tools.ScanArbitrageData(r.Context(), client, query)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("scan arbitrage error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func consistencyHandler(client *polymarket.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := tools.ConsistencyQuery{
+			Sport:     sports.Normalize(r.URL.Query().Get("sport")),
+			MaxEvents: parseQueryInt(r, "max_events"),
+		}
+		if v := parseQueryFloat(r, "min_edge"); v != nil {
+			query.MinEdge = *v
+		}
+		if v := parseQueryFloat(r, "min_liquidity"); v != nil {
+			query.MinLiquidity = *v
+		}
+		if v := r.URL.Query().Get("constraints"); v != "" {
+			query.Constraints = strings.Split(v, ",")
+		}
+
+		result, err := tools.CheckConsistencyData(r.Context(), client, query)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("check consistency error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+type CreatePaperPortfolioRequest struct {
+	Name         string   `json:"name"`
+	Sport        string   `json:"sport"`
+	Wallets      []string `json:"wallets"`
+	Mode         string   `json:"mode"`
+	StakeUSD     float64  `json:"stake_usd"`
+	Ratio        float64  `json:"ratio"`
+	DelaySeconds int      `json:"delay_seconds"`
+	SlippageBps  float64  `json:"slippage_bps"`
+	MaxExposure  float64  `json:"max_exposure_usd"`
+	BankrollUSD  float64  `json:"bankroll_usd"`
+	IgnoreSells  bool     `json:"ignore_sells"`
+}
+
+func paperPortfoliosHandler(client *polymarket.Client, store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			portfolios, err := store.ListPaperPortfolios(r.Context())
+			if err != nil {
+				http.Error(w, fmt.Sprintf("list paper portfolios error: %v", err), http.StatusInternalServerError)
+				return
+			}
+			reports := make([]paper.Report, 0, len(portfolios))
+			for _, portfolio := range portfolios {
+				report, err := paper.LoadReport(r.Context(), store, portfolio, false, 0, 0)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("paper portfolio error: %v", err), http.StatusInternalServerError)
+					return
+				}
+				reports = append(reports, report)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"portfolios": reports})
+			return
+		}
+
+		var req CreatePaperPortfolioRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(req.Name) == "" || len(req.Wallets) == 0 {
+			http.Error(w, "name and at least one wallet are required", http.StatusBadRequest)
+			return
+		}
+
+		wallets := make([]string, 0, len(req.Wallets))
+		for _, input := range req.Wallets {
+			resolved, err := tools.ResolveWalletTargetData(r.Context(), client, input)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("resolve wallet %s: %v", input, err), http.StatusBadRequest)
+				return
+			}
+			wallets = append(wallets, resolved.WalletAddress)
+		}
+
+		portfolio, err := store.CreatePaperPortfolio(r.Context(), paper.Normalize(storage.PaperPortfolio{
+			Name:         req.Name,
+			Sport:        req.Sport,
+			Wallets:      wallets,
+			Mode:         req.Mode,
+			StakeUSD:     req.StakeUSD,
+			Ratio:        req.Ratio,
+			DelaySeconds: req.DelaySeconds,
+			SlippageBps:  req.SlippageBps,
+			MaxExposure:  req.MaxExposure,
+			BankrollUSD:  req.BankrollUSD,
+			IgnoreSells:  req.IgnoreSells,
+		}))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("create paper portfolio error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(portfolio)
+	}
+}
+
+func paperPortfolioHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "portfolio id must be a number", http.StatusBadRequest)
+			return
+		}
+		portfolio, ok, err := store.GetPaperPortfolio(r.Context(), id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("paper portfolio error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, fmt.Sprintf("paper portfolio not found: %d", id), http.StatusNotFound)
+			return
+		}
+
+		report, err := paper.LoadReport(r.Context(), store, portfolio, true,
+			fallbackInt(parseQueryInt(r, "orders"), 100), fallbackInt(parseQueryInt(r, "equity"), 500))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("paper portfolio error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+type AddWatchlistRequest struct {
+	Name    string   `json:"name"`
+	Wallets []string `json:"wallets"`
+}
+
+func watchlistsHandler(client *polymarket.Client, store storage.Store, tradeWatcher *watcher.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			watchlists, err := store.ListWatchlists(r.Context())
+			if err != nil {
+				http.Error(w, fmt.Sprintf("list watchlists error: %v", err), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"watchlists": watchlists})
+			return
+		}
+
+		var req AddWatchlistRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if name == "" || len(req.Wallets) == 0 {
+			http.Error(w, "name and at least one wallet are required", http.StatusBadRequest)
+			return
+		}
+
+		wallets := make([]string, 0, len(req.Wallets))
+		for _, input := range req.Wallets {
+			resolved, err := tools.ResolveWalletTargetData(r.Context(), client, input)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("resolve wallet %s: %v", input, err), http.StatusBadRequest)
+				return
+			}
+			wallets = append(wallets, resolved.WalletAddress)
+		}
+		if err := store.AddWatchlistWallets(r.Context(), name, wallets); err != nil {
+			http.Error(w, fmt.Sprintf("add watchlist wallets error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if tradeWatcher != nil {
+			tradeWatcher.Refresh()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"name": name, "added": wallets})
+	}
+}
+
+func watchlistWalletHandler(store storage.Store, tradeWatcher *watcher.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		name, wallet := r.PathValue("name"), r.PathValue("wallet")
+		removed, err := store.RemoveWatchlistWallet(r.Context(), name, wallet)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("remove watchlist wallet error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !removed {
+			http.Error(w, fmt.Sprintf("wallet %s is not on watchlist %s", wallet, name), http.StatusNotFound)
+			return
+		}
+		if tradeWatcher != nil {
+			tradeWatcher.Refresh()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func watcherStatusHandler(tradeWatcher *watcher.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if tradeWatcher == nil {
+			http.Error(w, "trade watcher is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tradeWatcher.Status())
+	}
+}
+
+const (
+	// eventStreamBuffer is how many events a stream client may fall behind
+	// before it is disconnected to resume from its last event ID.
+	eventStreamBuffer = 256
+	eventHeartbeat    = 15 * time.Second
+)
+
+// eventSubscription subscribes a stream client from the Last-Event-ID
+// header or last_event_id query parameter and returns the events it should
+// receive first, filtered by the optional comma-separated types parameter.
+func eventSubscription(r *http.Request, bus *events.Bus) (*events.Subscription, []events.Event, func(events.Event) bool) {
+	types := map[string]bool{}
+	for _, value := range strings.Split(r.URL.Query().Get("types"), ",") {
+		if value = strings.TrimSpace(strings.ToLower(value)); value != "" {
+			types[value] = true
+		}
+	}
+	wanted := func(event events.Event) bool {
+		return len(types) == 0 || types[event.Type]
+	}
+
+	lastID := int64(-1)
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	if parsed, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64); err == nil && parsed >= 0 {
+		lastID = parsed
+	}
+
+	sub, replay := bus.SubscribeFrom(lastID, eventStreamBuffer)
+	return sub, replay, wanted
+}
+
+func eventStreamHandler(bus *events.Bus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		sub, replay, wanted := eventSubscription(r, bus)
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		writeEvent := func(event events.Event) {
+			if !wanted(event) {
+				return
+			}
+			payload, _ := json.Marshal(event)
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload)
+		}
+
+		fmt.Fprintf(w, "retry: 3000\n\n")
+		for _, event := range replay {
+			writeEvent(event)
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(eventHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprintf(w, ": ping\n\n")
+				flusher.Flush()
+			case event, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				writeEvent(event)
+				flusher.Flush()
+			}
+			// A client that fell behind reconnects with its last event ID
+			// and resumes from the bus history.
+			if sub.Dropped() > 0 {
+				return
+			}
+		}
+	}
+}
+
+var eventSocketUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func eventSocketHandler(bus *events.Bus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		conn, err := eventSocketUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		sub, replay, wanted := eventSubscription(r, bus)
+		defer sub.Close()
+
+		// Read until the client goes away; incoming messages are ignored.
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		writeEvent := func(event events.Event) error {
+			if !wanted(event) {
+				return nil
+			}
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			return conn.WriteJSON(event)
+		}
+		for _, event := range replay {
+			if err := writeEvent(event); err != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(eventHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-closed:
+				return
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					return
+				}
+			case event, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				if err := writeEvent(event); err != nil {
+					return
+				}
+			}
+			if sub.Dropped() > 0 {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fell behind; reconnect with last_event_id"),
+					time.Now().Add(time.Second))
+				return
+			}
+		}
+	}
+}
+
+func alertKindsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"kinds": alerts.Kinds()})
+}
+
+type AlertRuleRequest struct {
+	Name            string          `json:"name"`
+	Kind            string          `json:"kind"`
+	Params          json.RawMessage `json:"params"`
+	WebhookURL      string          `json:"webhook_url"`
+	Secret          string          `json:"secret"`
+	CooldownSeconds int             `json:"cooldown_seconds"`
+	Enabled         *bool           `json:"enabled"`
+}
+
+// alertRuleFromRequest builds a rule from a request, resolving the wallet
+// inputs in its params to addresses.
+func alertRuleFromRequest(ctx context.Context, client *polymarket.Client, req AlertRuleRequest) (storage.AlertRule, error) {
+	rule := storage.AlertRule{
+		Name:            strings.TrimSpace(req.Name),
+		Kind:            strings.TrimSpace(req.Kind),
+		Params:          req.Params,
+		WebhookURL:      strings.TrimSpace(req.WebhookURL),
+		Secret:          req.Secret,
+		CooldownSeconds: req.CooldownSeconds,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+	}
+	if len(rule.Params) == 0 {
+		rule.Params = json.RawMessage(`{}`)
+	}
+
+	var params alerts.Params
+	if err := json.Unmarshal(rule.Params, &params); err != nil {
+		return rule, fmt.Errorf("invalid params: %w", err)
+	}
+	if len(params.Wallets) > 0 {
+		for i, input := range params.Wallets {
+			resolved, err := tools.ResolveWalletTargetData(ctx, client, input)
+			if err != nil {
+				return rule, fmt.Errorf("resolve wallet %s: %w", input, err)
+			}
+			params.Wallets[i] = resolved.WalletAddress
+		}
+		data, err := json.Marshal(params)
+		if err != nil {
+			return rule, err
+		}
+		rule.Params = data
+	}
+	return rule, alerts.Validate(ctx, rule)
+}
+
+func alertRulesHandler(client *polymarket.Client, store storage.Store, alertService *alerts.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			rules, err := store.ListAlertRules(r.Context(), false)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("list alert rules error: %v", err), http.StatusInternalServerError)
+				return
+			}
+			for i := range rules {
+				rules[i].Secret = ""
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"rules": rules})
+			return
+		}
+
+		var req AlertRuleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		rule, err := alertRuleFromRequest(r.Context(), client, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if rule.Secret == "" {
+			if rule.Secret, err = alerts.NewSecret(); err != nil {
+				http.Error(w, fmt.Sprintf("alert secret error: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		created, err := store.CreateAlertRule(r.Context(), rule)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("create alert rule error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if alertService != nil {
+			alertService.Refresh()
+		}
+
+		// The secret is only returned when the rule is created.
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+	}
+}
+
+func alertRuleHandler(client *polymarket.Client, store storage.Store, alertService *alerts.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "rule id must be a number", http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			deleted, err := store.DeleteAlertRule(r.Context(), id)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("delete alert rule error: %v", err), http.StatusInternalServerError)
+				return
+			}
+			if !deleted {
+				http.Error(w, fmt.Sprintf("alert rule not found: %d", id), http.StatusNotFound)
+				return
+			}
+			if alertService != nil {
+				alertService.Refresh()
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		existing, ok, err := store.GetAlertRule(r.Context(), id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("alert rule error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, fmt.Sprintf("alert rule not found: %d", id), http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			existing.Secret = ""
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(existing)
+			return
+		}
+
+		var req AlertRuleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		rule, err := alertRuleFromRequest(r.Context(), client, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rule.ID = id
+		if rule.Secret == "" {
+			rule.Secret = existing.Secret
+		}
+
+		updated, ok, err := store.UpdateAlertRule(r.Context(), rule)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("update alert rule error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, fmt.Sprintf("alert rule not found: %d", id), http.StatusNotFound)
+			return
+		}
+		if alertService != nil {
+			alertService.Refresh()
+		}
+
+		updated.Secret = ""
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+	}
+}
+
+func alertDeliveriesHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		deliveries, err := store.ListAlertDeliveries(r.Context(),
+			int64(parseQueryInt(r, "rule_id")),
+			strings.TrimSpace(r.URL.Query().Get("status")),
+			fallbackInt(parseQueryInt(r, "limit"), 100),
+		)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("list alert deliveries error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"deliveries": deliveries})
+	}
+}
+
+func retryAlertDeliveryHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet catalog is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "delivery id must be a number", http.StatusBadRequest)
+			return
+		}
+		ok, err := store.RetryAlertDelivery(r.Context(), id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("retry alert delivery error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, fmt.Sprintf("alert delivery not found: %d", id), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+type CompareWalletsRequest struct {
+	Inputs []string `json:"inputs"`
+	Sport  string   `json:"sport"`
+	Limit  int      `json:"limit"`
+}
+
+func compareWalletsHandler(client *polymarket.Client, store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req CompareWalletsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(req.Inputs) < 2 {
+			http.Error(w, "inputs must contain at least two wallets", http.StatusBadRequest)
+			return
+		}
+
+		result, err := tools.CompareWalletsData(
+			r.Context(),
+			client,
+			store,
+			req.Inputs,
+			sports.Normalize(req.Sport),
+			fallbackInt(req.Limit, 3000),
+		)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("compare wallets error: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func sportsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	index := sports.SharedIndex()
+	status := map[string]any{"markets": index.Len(), "games": index.GameCount()}
+	if refreshed := index.RefreshedAt(); !refreshed.IsZero() {
+		status["refreshed_at"] = refreshed
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"default": sports.Default,
+		"sports":  sports.All(),
+		"index":   status,
+	})
+}
+
+func syncStyleWalletsHandler(service *profilesync.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if service == nil {
+			http.Error(w, "style wallet sync is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		job, created, err := service.Submit(profilesync.TriggerManual)
+		if err != nil {
+			writeJobSubmitError(w, err)
+			return
+		}
+		writeJobAccepted(w, job, created)
+	}
+}
+
+func syncStatusHandler(service *profilesync.Service, elector *leader.Elector, store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if service == nil || store == nil {
+			http.Error(w, "style wallet sync is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		recent, err := store.ListSyncRuns(r.Context(), "", 10)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("list sync runs error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		var lastRun *storage.SyncRun
+		for i := range recent {
+			if recent[i].Status != storage.SyncRunRunning {
+				lastRun = &recent[i]
+				break
+			}
+		}
+		successes, err := store.ListSyncRuns(r.Context(), storage.SyncRunCompleted, 1)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("list sync runs error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		var lastSuccess *storage.SyncRun
+		if len(successes) > 0 {
+			lastSuccess = &successes[0]
+		}
+
+		lease, held, err := elector.Current(r.Context())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("get sync lease error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		var currentLeader *storage.SchedulerLease
+		if held && lease.ExpiresAt.After(time.Now()) {
+			currentLeader = &lease
+		}
+
+		status := service.Status()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"sports":       service.Sports(),
+			"instance_id":  elector.InstanceID(),
+			"is_leader":    elector.IsLeader(),
+			"leader":       currentLeader,
+			"running":      status.Running,
+			"interval":     status.Interval,
+			"next_run_at":  status.NextRunAt,
+			"last_run":     lastRun,
+			"last_success": lastSuccess,
+		})
+	}
+}
+
+func syncRunsHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet sync is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		runs, err := store.ListSyncRuns(r.Context(), r.URL.Query().Get("status"), fallbackInt(parseQueryInt(r, "limit"), 20))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("list sync runs error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if runs == nil {
+			runs = []storage.SyncRun{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"runs": runs,
+		})
+	}
+}
+
+func syncRunHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if store == nil {
+			http.Error(w, "style wallet sync is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid sync run id", http.StatusBadRequest)
+			return
+		}
+		run, ok, err := store.GetSyncRun(r.Context(), id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("get sync run error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, "sync run not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(run)
+	}
+}
+
+type DiscoverWalletsRequest struct {
+	Mode            string   `json:"mode"`
+	Sport           string   `json:"sport"`
+	RecentLimit     int      `json:"recent_limit"`
+	RecentPages     int      `json:"recent_pages"`
+	CandidateLimit  int      `json:"candidate_limit"`
+	OutputLimit     int      `json:"output_limit"`
+	MinRecentTrades int      `json:"min_recent_trades"`
+	WalletLimit     int      `json:"wallet_limit"`
+	Wallets         []string `json:"wallets"`
+}
+
+func discoverWalletsHandler(client *polymarket.Client, store storage.Store, manager *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req DiscoverWalletsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		job, created, err := manager.Submit(jobKindDiscoverWallets, jobKey(req), discoverWalletsJob(client, store, req))
+		if err != nil {
+			writeJobSubmitError(w, err)
+			return
+		}
+		if r.URL.Query().Get("async") == "true" {
+			writeJobAccepted(w, job, created)
+			return
+		}
+
+		// Wait for the job so existing callers keep getting the results
+		// inline; a caller that goes away cancels the job it started.
+		job, err = manager.Wait(r.Context(), job.ID)
+		if err != nil {
+			if created {
+				manager.Cancel(job.ID)
+			}
+			return
+		}
+		if job.Status != jobs.StatusSucceeded {
+			http.Error(w, fmt.Sprintf("discover wallets error: %s", job.Error), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(job.Result)
+	}
+}
+
+func discoverWalletsJob(client *polymarket.Client, store storage.Store, req DiscoverWalletsRequest) jobs.Func {
+	return func(ctx context.Context) (any, error) {
+		opts := discovery.Options{
+			Sport:           sports.Normalize(req.Sport),
+			RecentLimit:     fallbackInt(req.RecentLimit, 400),
+			RecentPages:     fallbackInt(req.RecentPages, 4),
+			CandidateLimit:  fallbackInt(req.CandidateLimit, 20),
+			OutputLimit:     fallbackInt(req.OutputLimit, 10),
+			MinRecentTrades: fallbackInt(req.MinRecentTrades, 2),
+			WalletLimit:     fallbackInt(req.WalletLimit, 500),
+		}
+
+		var (
+			results []discovery.Candidate
+			err     error
+		)
+		switch req.Mode {
+		case "wallets":
+			jobs.Report(ctx, jobs.Progress{Stage: "scoring", Total: len(req.Wallets)})
+			results, err = discovery.ScoreWallets(ctx, client, store, req.Wallets, opts)
+		default:
+			jobs.Report(ctx, jobs.Progress{Stage: "discovering", Message: opts.Sport})
+			results, err = discovery.DiscoverFromRecent(ctx, client, store, opts)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"mode":    req.Mode,
+			"results": results,
+		}, nil
+	}
+}
+
+func fallbackString(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func fallbackInt(value, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func parseIntEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
+
+func parseDurationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
+
+func parseQueryInt(r *http.Request, key string) int {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return 0
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
+
+func parseQueryFloat(r *http.Request, key string) *float64 {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
 		}
 		next(w, r)
 	}
 }
+
+const (
+	jobKindDiscoverWallets = "discover_wallets"
+	jobKindToolCall        = "tool_call"
+)
+
+// JobRequest submits a job of a given kind. Params is the body the kind's
+// synchronous endpoint takes: a DiscoverWalletsRequest for discover_wallets
+// and a ToolCallRequest for tool_call; style_wallet_sync takes none.
+type JobRequest struct {
+	Kind   string          `json:"kind"`
+	Params json.RawMessage `json:"params"`
+}
+
+func jobsHandler(client *polymarket.Client, store storage.Store, syncService *profilesync.Service, manager *jobs.Manager) http.HandlerFunc {
+	handlers := toolHandlers(client, store)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			list := manager.List(r.URL.Query().Get("kind"), jobs.Status(r.URL.Query().Get("status")), fallbackInt(parseQueryInt(r, "limit"), 50))
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"jobs": list,
+			})
+		case http.MethodPost:
+			var req JobRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+				return
+			}
+
+			var (
+				job     jobs.Job
+				created bool
+				err     error
+			)
+			switch req.Kind {
+			case profilesync.JobKind:
+				if syncService == nil {
+					http.Error(w, "style wallet sync is unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				job, created, err = syncService.Submit(profilesync.TriggerManual)
+			case jobKindDiscoverWallets:
+				var params DiscoverWalletsRequest
+				if err := decodeJobParams(req.Params, &params); err != nil {
+					http.Error(w, fmt.Sprintf("invalid params: %v", err), http.StatusBadRequest)
+					return
+				}
+				job, created, err = manager.Submit(req.Kind, jobKey(params), discoverWalletsJob(client, store, params))
+			case jobKindToolCall:
+				var params ToolCallRequest
+				if err := decodeJobParams(req.Params, &params); err != nil {
+					http.Error(w, fmt.Sprintf("invalid params: %v", err), http.StatusBadRequest)
+					return
+				}
+				handler, ok := handlers[params.Tool]
+				if !ok {
+					http.Error(w, fmt.Sprintf("unknown tool: %s", params.Tool), http.StatusBadRequest)
+					return
+				}
+				job, created, err = manager.Submit(req.Kind, jobKey(params), toolCallJob(handler, params))
+			default:
+				http.Error(w, fmt.Sprintf("unsupported job kind: %s", req.Kind), http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				writeJobSubmitError(w, err)
+				return
+			}
+			writeJobAccepted(w, job, created)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func jobHandler(manager *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid job id", http.StatusBadRequest)
+			return
+		}
+		job, ok := manager.Get(id)
+		if !ok {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	}
+}
+
+func cancelJobHandler(manager *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid job id", http.StatusBadRequest)
+			return
+		}
+		job, ok := manager.Cancel(id)
+		if !ok {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	}
+}
+
+func toolCallJob(handler toolHandler, req ToolCallRequest) jobs.Func {
+	return func(ctx context.Context) (any, error) {
+		mcpReq := mcp.CallToolRequest{}
+		mcpReq.Params.Name = req.Tool
+		mcpReq.Params.Arguments = req.Args
+
+		jobs.Report(ctx, jobs.Progress{Stage: "running", Message: req.Tool})
+		result, err := handler(ctx, mcpReq)
+		if err != nil {
+			return nil, err
+		}
+		if result.IsError {
+			return nil, fmt.Errorf("%s: %s", req.Tool, toolResultText(result))
+		}
+		return result, nil
+	}
+}
+
+// toolResultText joins the text content of a tool result.
+func toolResultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func decodeJobParams(raw json.RawMessage, target any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, target)
+}
+
+// jobKey identifies a job's parameters, so identical submissions share one
+// job while different ones run side by side.
+func jobKey(params any) string {
+	data, _ := json.Marshal(params)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func writeJobAccepted(w http.ResponseWriter, job jobs.Job, created bool) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]any{
+		"job":     job,
+		"created": created,
+	})
+}
+
+func writeJobSubmitError(w http.ResponseWriter, err error) {
+	if errors.Is(err, jobs.ErrQueueFull) || errors.Is(err, jobs.ErrStopped) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, fmt.Sprintf("submit job error: %v", err), http.StatusInternalServerError)
+}
@@ -0,0 +1,301 @@
+package paper
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/backtest"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+// Strategy is a portfolio's sizing rules in backtest form, with defaults
+// filled in.
+func Strategy(portfolio storage.PaperPortfolio) backtest.Strategy {
+	return backtest.WithDefaults(backtest.Strategy{
+		Mode:         portfolio.Mode,
+		Stake:        portfolio.StakeUSD,
+		Ratio:        portfolio.Ratio,
+		DelaySeconds: portfolio.DelaySeconds,
+		SlippageBps:  portfolio.SlippageBps,
+		MaxExposure:  portfolio.MaxExposure,
+		Bankroll:     portfolio.BankrollUSD,
+		IgnoreSells:  portfolio.IgnoreSells,
+	})
+}
+
+// Normalize fills a new portfolio's unset sizing rules with the backtest
+// defaults so stored portfolios always carry the rules they run with.
+func Normalize(portfolio storage.PaperPortfolio) storage.PaperPortfolio {
+	strategy := Strategy(portfolio)
+	portfolio.Name = strings.TrimSpace(portfolio.Name)
+	if portfolio.Sport != "" {
+		portfolio.Sport = sports.Normalize(portfolio.Sport)
+	}
+	portfolio.Mode = strategy.Mode
+	portfolio.StakeUSD = strategy.Stake
+	portfolio.Ratio = strategy.Ratio
+	portfolio.DelaySeconds = strategy.DelaySeconds
+	portfolio.SlippageBps = strategy.SlippageBps
+	portfolio.MaxExposure = strategy.MaxExposure
+	portfolio.BankrollUSD = strategy.Bankroll
+	return portfolio
+}
+
+// Book is a portfolio's cash and positions while a poll applies fills and
+// marks. Positions are keyed by condition ID and outcome.
+type Book struct {
+	Cash      float64
+	Positions map[string]*storage.PaperPosition
+	Strategy  backtest.Strategy
+}
+
+// Fill executes a due order at price plus the strategy's slippage and
+// returns it filled or skipped with a reason.
+func (b *Book) Fill(order storage.PaperOrder, price float64, now time.Time) storage.PaperOrder {
+	key := positionKey(order.ConditionID, order.Outcome)
+	position := b.Positions[key]
+
+	switch order.Side {
+	case "BUY":
+		price = math.Min(price*(1+b.Strategy.SlippageBps/10000), 0.999)
+		if price <= 0 {
+			return skip(order, "no_price")
+		}
+		stake := b.Strategy.StakeFor(order.WalletSize * order.WalletPrice)
+		if b.Strategy.MaxExposure > 0 {
+			if room := b.Strategy.MaxExposure - b.Exposure(); stake > room {
+				stake = room
+			}
+			if stake < minStakeUSD {
+				return skip(order, "max_exposure")
+			}
+		}
+		if stake > b.Cash {
+			stake = b.Cash
+		}
+		if stake < minStakeUSD {
+			return skip(order, "insufficient_cash")
+		}
+
+		if position == nil {
+			position = &storage.PaperPosition{
+				PortfolioID: order.PortfolioID,
+				ConditionID: strings.ToLower(order.ConditionID),
+				Outcome:     order.Outcome,
+			}
+			b.Positions[key] = position
+		}
+		if position.Status != storage.PaperPositionOpen {
+			position.Status = storage.PaperPositionOpen
+			position.OpenedAt = now
+		}
+		shares := stake / price
+		position.TokenID = order.TokenID
+		position.Question = order.Question
+		position.Shares += shares
+		position.CostUSD += stake
+		position.SourceShares += order.WalletSize
+		position.MarkPrice = price
+		b.Cash -= stake
+		return filled(order, price, shares, stake, now)
+
+	case "SELL":
+		if b.Strategy.IgnoreSells {
+			return skip(order, "ignore_sells")
+		}
+		if position == nil || position.Status != storage.PaperPositionOpen || position.Shares <= 0 {
+			return skip(order, "no_position")
+		}
+		price = math.Max(price*(1-b.Strategy.SlippageBps/10000), 0)
+		// Sell the fraction of its mirrored shares the wallet sold.
+		fraction := 1.0
+		if position.SourceShares > 0 {
+			fraction = math.Min(order.WalletSize/position.SourceShares, 1)
+		}
+		shares := position.Shares * fraction
+		cost := position.CostUSD * fraction
+		proceeds := shares * price
+		b.Cash += proceeds
+		position.RealizedPnL += proceeds - cost
+		position.Shares -= shares
+		position.CostUSD -= cost
+		position.SourceShares = math.Max(position.SourceShares-order.WalletSize, 0)
+		position.MarkPrice = price
+		if position.Shares <= 1e-9 {
+			position.Status = storage.PaperPositionClosed
+			position.Shares, position.CostUSD, position.SourceShares = 0, 0, 0
+		}
+		return filled(order, price, shares, proceeds, now)
+	}
+	return skip(order, "unknown_side")
+}
+
+// Mark values an open position at the market's current price, or settles it
+// when the market has resolved. It reports whether the position settled.
+func (b *Book) Mark(position *storage.PaperPosition, market polymarket.Market) bool {
+	i := -1
+	for idx, name := range market.OutcomeNames() {
+		if strings.EqualFold(name, position.Outcome) {
+			i = idx
+		}
+	}
+
+	if prices, ok := market.ResolvedPrices(); ok {
+		payout := 0.0
+		if i >= 0 && i < len(prices) {
+			payout = position.Shares * prices[i]
+			position.MarkPrice = prices[i]
+		}
+		b.Cash += payout
+		position.RealizedPnL += payout - position.CostUSD
+		position.Status = storage.PaperPositionSettled
+		position.Shares, position.CostUSD, position.SourceShares = 0, 0, 0
+		return true
+	}
+
+	if prices := market.Prices(); i >= 0 && i < len(prices) {
+		position.MarkPrice = prices[i]
+	}
+	return false
+}
+
+// Exposure is the cost of open positions.
+func (b *Book) Exposure() float64 {
+	total := 0.0
+	for _, position := range b.Positions {
+		if position.Status == storage.PaperPositionOpen {
+			total += position.CostUSD
+		}
+	}
+	return total
+}
+
+// PositionsValue is the marked value of open positions.
+func (b *Book) PositionsValue() float64 {
+	total := 0.0
+	for _, position := range b.Positions {
+		if position.Status == storage.PaperPositionOpen {
+			total += position.Shares * position.MarkPrice
+		}
+	}
+	return total
+}
+
+// Performance summarizes a portfolio from its stored positions and orders.
+type Performance struct {
+	Cash           float64 `json:"cash"`
+	PositionsValue float64 `json:"positions_value"`
+	Equity         float64 `json:"equity"`
+	ExposureUSD    float64 `json:"exposure_usd"`
+	RealizedPnL    float64 `json:"realized_pnl"`
+	UnrealizedPnL  float64 `json:"unrealized_pnl"`
+	ReturnPct      float64 `json:"return_pct"`
+	OpenPositions  int     `json:"open_positions"`
+	OrdersFilled   int     `json:"orders_filled"`
+	OrdersSkipped  int     `json:"orders_skipped"`
+	OrdersPending  int     `json:"orders_pending"`
+}
+
+func Summarize(portfolio storage.PaperPortfolio, positions []storage.PaperPosition, orders []storage.PaperOrder) Performance {
+	perf := Performance{Cash: round(portfolio.Cash, 2)}
+	value := 0.0
+	for _, position := range positions {
+		perf.RealizedPnL += position.RealizedPnL
+		if position.Status != storage.PaperPositionOpen {
+			continue
+		}
+		perf.OpenPositions++
+		value += position.Shares * position.MarkPrice
+		perf.ExposureUSD += position.CostUSD
+		perf.UnrealizedPnL += position.Shares*position.MarkPrice - position.CostUSD
+	}
+	for _, order := range orders {
+		switch order.Status {
+		case storage.PaperOrderFilled:
+			perf.OrdersFilled++
+		case storage.PaperOrderSkipped:
+			perf.OrdersSkipped++
+		case storage.PaperOrderPending:
+			perf.OrdersPending++
+		}
+	}
+
+	equity := portfolio.Cash + value
+	perf.PositionsValue = round(value, 2)
+	perf.Equity = round(equity, 2)
+	perf.ExposureUSD = round(perf.ExposureUSD, 2)
+	perf.RealizedPnL = round(perf.RealizedPnL, 2)
+	perf.UnrealizedPnL = round(perf.UnrealizedPnL, 2)
+	if portfolio.BankrollUSD > 0 {
+		perf.ReturnPct = round((equity-portfolio.BankrollUSD)/portfolio.BankrollUSD*100, 2)
+	}
+	return perf
+}
+
+// Report is a portfolio with its performance, positions, recent orders and
+// equity curve.
+type Report struct {
+	Portfolio   storage.PaperPortfolio     `json:"portfolio"`
+	Performance Performance                `json:"performance"`
+	Positions   []storage.PaperPosition    `json:"positions,omitempty"`
+	Orders      []storage.PaperOrder       `json:"orders,omitempty"`
+	Equity      []storage.PaperEquityPoint `json:"equity,omitempty"`
+}
+
+// LoadReport reads a portfolio's positions and orders and summarizes them.
+// With detail set it also includes the positions, the latest orderLimit
+// orders and the latest equityLimit equity points.
+func LoadReport(ctx context.Context, store storage.Store, portfolio storage.PaperPortfolio, detail bool, orderLimit, equityLimit int) (Report, error) {
+	positions, err := store.ListPaperPositions(ctx, portfolio.ID, false)
+	if err != nil {
+		return Report{}, err
+	}
+	orders, err := store.ListPaperOrders(ctx, portfolio.ID, "", 0)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Portfolio: portfolio, Performance: Summarize(portfolio, positions, orders)}
+	if !detail {
+		return report, nil
+	}
+
+	report.Positions = positions
+	if orderLimit > 0 && len(orders) > orderLimit {
+		orders = orders[:orderLimit]
+	}
+	report.Orders = orders
+	report.Equity, err = store.ListPaperEquity(ctx, portfolio.ID, equityLimit)
+	if err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+func skip(order storage.PaperOrder, reason string) storage.PaperOrder {
+	order.Status = storage.PaperOrderSkipped
+	order.Reason = reason
+	return order
+}
+
+func filled(order storage.PaperOrder, price, shares, notional float64, now time.Time) storage.PaperOrder {
+	order.Status = storage.PaperOrderFilled
+	order.FillPrice = round(price, 4)
+	order.Shares = round(shares, 4)
+	order.NotionalUSD = round(notional, 2)
+	order.FilledAt = &now
+	return order
+}
+
+func positionKey(conditionID, outcome string) string {
+	return strings.ToLower(conditionID) + "|" + outcome
+}
+
+func round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,262 @@
+package paper
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/backtest"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+func order(side, conditionID, outcome string, walletSize, walletPrice float64) storage.PaperOrder {
+	return storage.PaperOrder{
+		PortfolioID: 1,
+		ConditionID: conditionID,
+		Outcome:     outcome,
+		Side:        side,
+		WalletSize:  walletSize,
+		WalletPrice: walletPrice,
+		Status:      storage.PaperOrderPending,
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestBookFillSequence(t *testing.T) {
+	now := time.Date(2026, 2, 1, 20, 0, 0, 0, time.UTC)
+	book := &Book{
+		Cash:      250,
+		Positions: map[string]*storage.PaperPosition{},
+		Strategy:  backtest.WithDefaults(backtest.Strategy{Mode: backtest.ModeFixed, Stake: 100}),
+	}
+
+	steps := []struct {
+		name     string
+		order    storage.PaperOrder
+		price    float64
+		status   string
+		reason   string
+		shares   float64
+		notional float64
+		cash     float64
+	}{
+		{name: "first buy", order: order("BUY", "M1", "Yes", 200, 0.40), price: 0.40, status: storage.PaperOrderFilled, shares: 250, notional: 100, cash: 150},
+		{name: "sell without a position", order: order("SELL", "m2", "Yes", 10, 0.5), price: 0.5, status: storage.PaperOrderSkipped, reason: "no_position", cash: 150},
+		{name: "add to the position", order: order("BUY", "m1", "Yes", 200, 0.50), price: 0.50, status: storage.PaperOrderFilled, shares: 200, notional: 100, cash: 50},
+		// The wallet sold 100 of its 400 shares, so a quarter of 450 goes.
+		{name: "partial sell", order: order("SELL", "m1", "Yes", 100, 0.60), price: 0.60, status: storage.PaperOrderFilled, shares: 112.5, notional: 67.5, cash: 117.5},
+		{name: "buy another market", order: order("BUY", "m2", "No", 10, 0.25), price: 0.25, status: storage.PaperOrderFilled, shares: 400, notional: 100, cash: 17.5},
+		{name: "stake capped by cash", order: order("BUY", "m3", "Yes", 10, 0.5), price: 0.5, status: storage.PaperOrderFilled, shares: 35, notional: 17.5, cash: 0},
+		{name: "no cash left", order: order("BUY", "m3", "Yes", 10, 0.5), price: 0.5, status: storage.PaperOrderSkipped, reason: "insufficient_cash", cash: 0},
+		{name: "sell more than the wallet held", order: order("SELL", "m1", "Yes", 500, 0.60), price: 0.60, status: storage.PaperOrderFilled, shares: 337.5, notional: 202.5, cash: 202.5},
+		{name: "sell a closed position", order: order("SELL", "m1", "Yes", 10, 0.60), price: 0.60, status: storage.PaperOrderSkipped, reason: "no_position", cash: 202.5},
+		{name: "unknown side", order: order("HOLD", "m1", "Yes", 10, 0.60), price: 0.60, status: storage.PaperOrderSkipped, reason: "unknown_side", cash: 202.5},
+		{name: "no price", order: order("BUY", "m4", "Yes", 10, 0.5), price: 0, status: storage.PaperOrderSkipped, reason: "no_price", cash: 202.5},
+	}
+	for _, step := range steps {
+		got := book.Fill(step.order, step.price, now)
+		if got.Status != step.status || got.Reason != step.reason {
+			t.Errorf("%s: status %s %q, want %s %q", step.name, got.Status, got.Reason, step.status, step.reason)
+		}
+		if !near(got.Shares, step.shares) || !near(got.NotionalUSD, step.notional) {
+			t.Errorf("%s: %v shares for %v, want %v for %v", step.name, got.Shares, got.NotionalUSD, step.shares, step.notional)
+		}
+		if got.Status == storage.PaperOrderFilled && (got.FilledAt == nil || !got.FilledAt.Equal(now)) {
+			t.Errorf("%s: filled at %v, want %v", step.name, got.FilledAt, now)
+		}
+		if !near(book.Cash, step.cash) {
+			t.Errorf("%s: cash %v, want %v", step.name, book.Cash, step.cash)
+		}
+	}
+
+	position := book.Positions[positionKey("m1", "Yes")]
+	if position.Status != storage.PaperPositionClosed || position.Shares != 0 || position.CostUSD != 0 {
+		t.Errorf("m1 position = %+v, want closed and empty", position)
+	}
+	// 67.5 - 50 on the partial sell and 202.5 - 150 on the rest.
+	if !near(position.RealizedPnL, 70) {
+		t.Errorf("m1 realized PnL = %v, want 70", position.RealizedPnL)
+	}
+	if position.ConditionID != "m1" {
+		t.Errorf("m1 position condition ID = %q, want lower case", position.ConditionID)
+	}
+	if !near(book.Exposure(), 117.5) || !near(book.PositionsValue(), 400*0.25+35*0.5) {
+		t.Errorf("exposure %v, positions value %v", book.Exposure(), book.PositionsValue())
+	}
+}
+
+func TestBookFillStrategies(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name      string
+		strategy  backtest.Strategy
+		held      *storage.PaperPosition
+		order     storage.PaperOrder
+		price     float64
+		status    string
+		reason    string
+		fillPrice float64
+		notional  float64
+	}{
+		{
+			name:      "proportional stake",
+			strategy:  backtest.Strategy{Mode: backtest.ModeProportional, Ratio: 0.1},
+			order:     order("BUY", "m", "Yes", 400, 0.5),
+			price:     0.5,
+			status:    storage.PaperOrderFilled,
+			fillPrice: 0.5,
+			notional:  20,
+		},
+		{
+			name:      "buy slippage",
+			strategy:  backtest.Strategy{Stake: 100, SlippageBps: 200},
+			order:     order("BUY", "m", "Yes", 10, 0.5),
+			price:     0.5,
+			status:    storage.PaperOrderFilled,
+			fillPrice: 0.51,
+			notional:  100,
+		},
+		{
+			name:      "buy price capped below 1",
+			strategy:  backtest.Strategy{Stake: 100, SlippageBps: 500},
+			order:     order("BUY", "m", "Yes", 10, 0.98),
+			price:     0.98,
+			status:    storage.PaperOrderFilled,
+			fillPrice: 0.999,
+			notional:  100,
+		},
+		{
+			name:      "sell slippage",
+			strategy:  backtest.Strategy{Stake: 100, SlippageBps: 200},
+			held:      &storage.PaperPosition{Status: storage.PaperPositionOpen, Shares: 100, CostUSD: 50, SourceShares: 10},
+			order:     order("SELL", "m", "Yes", 10, 0.5),
+			price:     0.5,
+			status:    storage.PaperOrderFilled,
+			fillPrice: 0.49,
+			notional:  49,
+		},
+		{
+			name:     "sells ignored",
+			strategy: backtest.Strategy{Stake: 100, IgnoreSells: true},
+			held:     &storage.PaperPosition{Status: storage.PaperPositionOpen, Shares: 100, CostUSD: 50, SourceShares: 10},
+			order:    order("SELL", "m", "Yes", 10, 0.5),
+			price:    0.5,
+			status:   storage.PaperOrderSkipped,
+			reason:   "ignore_sells",
+		},
+		{
+			name:      "stake capped by max exposure",
+			strategy:  backtest.Strategy{Stake: 100, MaxExposure: 80},
+			held:      &storage.PaperPosition{Status: storage.PaperPositionOpen, Shares: 100, CostUSD: 50, SourceShares: 10},
+			order:     order("BUY", "m", "Yes", 10, 0.5),
+			price:     0.5,
+			status:    storage.PaperOrderFilled,
+			fillPrice: 0.5,
+			notional:  30,
+		},
+		{
+			name:     "no room under max exposure",
+			strategy: backtest.Strategy{Stake: 100, MaxExposure: 50},
+			held:     &storage.PaperPosition{Status: storage.PaperPositionOpen, Shares: 100, CostUSD: 50, SourceShares: 10},
+			order:    order("BUY", "m", "Yes", 10, 0.5),
+			price:    0.5,
+			status:   storage.PaperOrderSkipped,
+			reason:   "max_exposure",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &Book{Cash: 1000, Positions: map[string]*storage.PaperPosition{}, Strategy: backtest.WithDefaults(tt.strategy)}
+			if tt.held != nil {
+				book.Positions[positionKey("m", "Yes")] = tt.held
+			}
+			got := book.Fill(tt.order, tt.price, now)
+			if got.Status != tt.status || got.Reason != tt.reason {
+				t.Fatalf("status %s %q, want %s %q", got.Status, got.Reason, tt.status, tt.reason)
+			}
+			if !near(got.FillPrice, tt.fillPrice) || !near(got.NotionalUSD, tt.notional) {
+				t.Errorf("filled at %v for %v, want %v for %v", got.FillPrice, got.NotionalUSD, tt.fillPrice, tt.notional)
+			}
+		})
+	}
+}
+
+func TestBookMark(t *testing.T) {
+	market := func(closed bool, prices string) polymarket.Market {
+		return polymarket.Market{ConditionID: "m", Outcomes: `["Yes", "No"]`, OutcomePrices: prices, Closed: closed}
+	}
+	tests := []struct {
+		name     string
+		outcome  string
+		market   polymarket.Market
+		settled  bool
+		mark     float64
+		cash     float64
+		realized float64
+		status   string
+	}{
+		{name: "open market", outcome: "Yes", market: market(false, `["0.7", "0.3"]`), mark: 0.7, cash: 0, status: storage.PaperPositionOpen},
+		{name: "outcome matched case-insensitively", outcome: "no", market: market(false, `["0.7", "0.3"]`), mark: 0.3, cash: 0, status: storage.PaperPositionOpen},
+		{name: "closed but unresolved", outcome: "Yes", market: market(true, `["0.5", "0.5"]`), mark: 0.5, cash: 0, status: storage.PaperPositionOpen},
+		{name: "resolved in favour", outcome: "Yes", market: market(true, `["1", "0"]`), settled: true, mark: 1, cash: 100, realized: 60, status: storage.PaperPositionSettled},
+		{name: "resolved against", outcome: "No", market: market(true, `["1", "0"]`), settled: true, mark: 0, cash: 0, realized: -40, status: storage.PaperPositionSettled},
+		{name: "outcome missing from the market", outcome: "Draw", market: market(true, `["1", "0"]`), settled: true, mark: 0.4, cash: 0, realized: -40, status: storage.PaperPositionSettled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &Book{Positions: map[string]*storage.PaperPosition{}}
+			position := &storage.PaperPosition{ConditionID: "m", Outcome: tt.outcome, Status: storage.PaperPositionOpen, Shares: 100, CostUSD: 40, SourceShares: 10, MarkPrice: 0.4}
+			if settled := book.Mark(position, tt.market); settled != tt.settled {
+				t.Errorf("Mark() = %v, want %v", settled, tt.settled)
+			}
+			if !near(position.MarkPrice, tt.mark) || !near(book.Cash, tt.cash) || !near(position.RealizedPnL, tt.realized) || position.Status != tt.status {
+				t.Errorf("position %s marked %v realized %v, cash %v; want %s %v %v %v", position.Status, position.MarkPrice, position.RealizedPnL, book.Cash, tt.status, tt.mark, tt.realized, tt.cash)
+			}
+			if tt.settled && (position.Shares != 0 || position.CostUSD != 0) {
+				t.Errorf("settled position still holds %v shares costing %v", position.Shares, position.CostUSD)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	portfolio := storage.PaperPortfolio{BankrollUSD: 1000, Cash: 700}
+	positions := []storage.PaperPosition{
+		{Status: storage.PaperPositionOpen, Shares: 200, CostUSD: 100, MarkPrice: 0.6, RealizedPnL: 5},
+		{Status: storage.PaperPositionOpen, Shares: 100, CostUSD: 50, MarkPrice: 0.3},
+		{Status: storage.PaperPositionClosed, RealizedPnL: 20},
+		{Status: storage.PaperPositionSettled, RealizedPnL: -15},
+	}
+	orders := []storage.PaperOrder{
+		{Status: storage.PaperOrderFilled},
+		{Status: storage.PaperOrderFilled},
+		{Status: storage.PaperOrderSkipped},
+		{Status: storage.PaperOrderPending},
+	}
+
+	got := Summarize(portfolio, positions, orders)
+	want := Performance{
+		Cash:           700,
+		PositionsValue: 150,
+		Equity:         850,
+		ExposureUSD:    150,
+		RealizedPnL:    10,
+		UnrealizedPnL:  0,
+		ReturnPct:      -15,
+		OpenPositions:  2,
+		OrdersFilled:   2,
+		OrdersSkipped:  1,
+		OrdersPending:  1,
+	}
+	if got != want {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+
+	if empty := Summarize(storage.PaperPortfolio{}, nil, nil); empty != (Performance{}) {
+		t.Errorf("Summarize(empty) = %+v, want zero", empty)
+	}
+}
@@ -0,0 +1,301 @@
+package paper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+const (
+	// walletPageSize is how many of a followed wallet's latest trades each
+	// poll reads; more fills than this between polls are missed.
+	walletPageSize  = 100
+	marketBatchSize = 50
+	// equityInterval is how often an unchanged portfolio records an equity
+	// point.
+	equityInterval = 15 * time.Minute
+	minStakeUSD    = 0.01
+)
+
+// Service polls the wallets followed by paper portfolios and mirrors their
+// new fills into simulated orders.
+type Service struct {
+	client   *polymarket.Client
+	store    storage.Store
+	interval time.Duration
+
+	mu         sync.Mutex
+	lastEquity map[int64]time.Time
+}
+
+func NewService(client *polymarket.Client, store storage.Store, interval time.Duration) *Service {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &Service{
+		client:     client,
+		store:      store,
+		interval:   interval,
+		lastEquity: map[int64]time.Time{},
+	}
+}
+
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		s.pollWithLogging(ctx)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.pollWithLogging(ctx)
+			}
+		}
+	}()
+}
+
+// PollOnce reads each followed wallet's latest trades once and advances
+// every portfolio: new fills become pending orders, due orders fill, and
+// open positions are marked or settled.
+func (s *Service) PollOnce(ctx context.Context) error {
+	portfolios, err := s.store.ListPaperPortfolios(ctx)
+	if err != nil {
+		return err
+	}
+	if len(portfolios) == 0 {
+		return nil
+	}
+
+	var errs []error
+	trades := map[string][]polymarket.Trade{}
+	for _, portfolio := range portfolios {
+		for _, wallet := range portfolio.Wallets {
+			if _, ok := trades[wallet]; ok {
+				continue
+			}
+			page, err := s.client.GetTrades(wallet, walletPageSize, 0)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("trades of %s: %w", wallet, err))
+				page = nil
+			}
+			trades[wallet] = page
+		}
+	}
+
+	markets := &marketCache{client: s.client, byID: map[string]polymarket.Market{}}
+	for _, portfolio := range portfolios {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.pollPortfolio(ctx, portfolio, trades, markets); err != nil {
+			errs = append(errs, fmt.Errorf("portfolio %d: %w", portfolio.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) pollPortfolio(ctx context.Context, portfolio storage.PaperPortfolio, trades map[string][]polymarket.Trade, markets *marketCache) error {
+	strategy := Strategy(portfolio)
+	delay := time.Duration(strategy.DelaySeconds) * time.Second
+
+	// Only fills after the portfolio was created are mirrored.
+	orders := []storage.PaperOrder{}
+	for _, wallet := range portfolio.Wallets {
+		for _, trade := range trades[wallet] {
+			at := trade.Time().UTC()
+			if !at.After(portfolio.CreatedAt) {
+				continue
+			}
+			if portfolio.Sport != "" && !sports.MatchesMarket(trade.ConditionID, trade.Title, trade.Slug, portfolio.Sport) {
+				continue
+			}
+			orders = append(orders, storage.PaperOrder{
+				PortfolioID:    portfolio.ID,
+				SourceWallet:   wallet,
+				SourceTradeKey: trade.Key(),
+				SourceTime:     at,
+				ConditionID:    strings.ToLower(trade.ConditionID),
+				TokenID:        trade.Asset,
+				Question:       trade.Title,
+				Outcome:        trade.Outcome,
+				Side:           strings.ToUpper(trade.Side),
+				WalletSize:     trade.Size,
+				WalletPrice:    trade.Price,
+				FillAfter:      at.Add(delay),
+			})
+		}
+	}
+	if _, err := s.store.InsertPaperOrders(ctx, orders); err != nil {
+		return err
+	}
+
+	pending, err := s.store.ListPaperOrders(ctx, portfolio.ID, storage.PaperOrderPending, 0)
+	if err != nil {
+		return err
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].FillAfter.Before(pending[j].FillAfter) })
+	stored, err := s.store.ListPaperPositions(ctx, portfolio.ID, false)
+	if err != nil {
+		return err
+	}
+	positions := map[string]*storage.PaperPosition{}
+	for i := range stored {
+		positions[positionKey(stored[i].ConditionID, stored[i].Outcome)] = &stored[i]
+	}
+
+	now := time.Now().UTC()
+	ids := []string{}
+	for _, order := range pending {
+		if !order.FillAfter.After(now) {
+			ids = append(ids, order.ConditionID)
+		}
+	}
+	for _, position := range stored {
+		if position.Status == storage.PaperPositionOpen {
+			ids = append(ids, position.ConditionID)
+		}
+	}
+	if err := markets.load(ids); err != nil {
+		return err
+	}
+
+	book := &Book{Cash: portfolio.Cash, Positions: positions, Strategy: strategy}
+	poll := storage.PaperPoll{PortfolioID: portfolio.ID, PolledAt: now}
+	changed := map[string]bool{}
+	for _, order := range pending {
+		if order.FillAfter.After(now) {
+			continue
+		}
+		market, known := markets.byID[order.ConditionID]
+		if _, resolved := market.ResolvedPrices(); known && resolved {
+			order.Status, order.Reason = storage.PaperOrderSkipped, "market_resolved"
+		} else {
+			order = book.Fill(order, s.bestPrice(order), now)
+			if order.Status == storage.PaperOrderFilled {
+				changed[positionKey(order.ConditionID, order.Outcome)] = true
+			}
+		}
+		poll.Orders = append(poll.Orders, order)
+	}
+
+	settled := 0
+	for key, position := range positions {
+		if position.Status != storage.PaperPositionOpen {
+			continue
+		}
+		market, ok := markets.byID[position.ConditionID]
+		if !ok {
+			continue
+		}
+		if book.Mark(position, market) {
+			settled++
+		}
+		changed[key] = true
+	}
+	for key := range changed {
+		poll.Positions = append(poll.Positions, *positions[key])
+	}
+	poll.Cash = book.Cash
+
+	s.mu.Lock()
+	last := s.lastEquity[portfolio.ID]
+	if len(poll.Orders) > 0 || settled > 0 || now.Sub(last) >= equityInterval {
+		value := book.PositionsValue()
+		poll.Equity = &storage.PaperEquityPoint{
+			RecordedAt:     now,
+			Cash:           round(book.Cash, 2),
+			PositionsValue: round(value, 2),
+			Equity:         round(book.Cash+value, 2),
+		}
+		s.lastEquity[portfolio.ID] = now
+	}
+	s.mu.Unlock()
+
+	return s.store.SavePaperPoll(ctx, poll)
+}
+
+// bestPrice is the price a copy would take right now: the best ask for a
+// buy or the best bid for a sell, falling back to the wallet's own price
+// when the book cannot be read or that side is empty.
+func (s *Service) bestPrice(order storage.PaperOrder) float64 {
+	if order.TokenID == "" {
+		return order.WalletPrice
+	}
+	book, err := s.client.GetOrderBook(order.TokenID)
+	if err != nil {
+		return order.WalletPrice
+	}
+
+	best := 0.0
+	if order.Side == "BUY" {
+		for _, level := range book.Asks {
+			if level.Size > 0 && level.Price > 0 && (best == 0 || level.Price < best) {
+				best = level.Price
+			}
+		}
+	} else {
+		for _, level := range book.Bids {
+			if level.Size > 0 && level.Price > best {
+				best = level.Price
+			}
+		}
+	}
+	if best == 0 {
+		return order.WalletPrice
+	}
+	return best
+}
+
+func (s *Service) pollWithLogging(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	if err := s.PollOnce(runCtx); err != nil {
+		log.Printf("paper trading poll failed: %v", err)
+	}
+}
+
+type marketCache struct {
+	client *polymarket.Client
+	byID   map[string]polymarket.Market
+}
+
+// load fetches the markets not cached yet.
+func (c *marketCache) load(conditionIDs []string) error {
+	missing := []string{}
+	seen := map[string]bool{}
+	for _, id := range conditionIDs {
+		id = strings.ToLower(id)
+		if _, ok := c.byID[id]; ok || seen[id] || id == "" {
+			continue
+		}
+		seen[id] = true
+		missing = append(missing, id)
+	}
+	for start := 0; start < len(missing); start += marketBatchSize {
+		end := start + marketBatchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+		markets, err := c.client.GetMarkets(missing[start:end])
+		if err != nil {
+			return err
+		}
+		for _, market := range markets {
+			c.byID[strings.ToLower(market.ConditionID)] = market
+		}
+	}
+	return nil
+}
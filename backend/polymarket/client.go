@@ -8,6 +8,7 @@ import (
 const (
 	GammaBase = "https://gamma-api.polymarket.com"
 	DataBase  = "https://data-api.polymarket.com"
+	ClobBase  = "https://clob.polymarket.com"
 )
 
 // Client wraps HTTP calls to Polymarket APIs.
@@ -15,6 +16,7 @@ type Client struct {
 	HTTP      *http.Client
 	GammaBase string
 	DataBase  string
+	ClobBase  string
 }
 
 // NewClient creates a Polymarket API client with a 10-second timeout.
@@ -25,5 +27,6 @@ func NewClient() *Client {
 		},
 		GammaBase: GammaBase,
 		DataBase:  DataBase,
+		ClobBase:  ClobBase,
 	}
 }
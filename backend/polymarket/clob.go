@@ -0,0 +1,55 @@
+package polymarket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// GetOrderBook fetches the CLOB order book for one outcome token.
+func (c *Client) GetOrderBook(tokenID string) (*OrderBook, error) {
+	u := fmt.Sprintf("%s/book?token_id=%s", c.ClobBase, url.QueryEscape(tokenID))
+	resp, err := c.HTTP.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("order book request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("order book API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var book OrderBook
+	if err := json.NewDecoder(resp.Body).Decode(&book); err != nil {
+		return nil, fmt.Errorf("decode order book: %w", err)
+	}
+	return &book, nil
+}
+
+// GetPriceHistory fetches an outcome token's price history between two unix
+// times, sampled every fidelity minutes.
+func (c *Client) GetPriceHistory(tokenID string, startTs, endTs int64, fidelity int) ([]PricePoint, error) {
+	u := fmt.Sprintf("%s/prices-history?market=%s&startTs=%d&endTs=%d&fidelity=%d",
+		c.ClobBase, url.QueryEscape(tokenID), startTs, endTs, fidelity)
+	resp, err := c.HTTP.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("price history request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("price history API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var payload struct {
+		History []PricePoint `json:"history"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decode price history: %w", err)
+	}
+	return payload.History, nil
+}
@@ -51,3 +51,69 @@ func (c *Client) GetRecentTrades(limit, offset int) ([]Trade, error) {
 	}
 	return trades, nil
 }
+
+// GetMarketTrades fetches trades in one market (condition ID) with pagination.
+func (c *Client) GetMarketTrades(conditionID string, limit, offset int) ([]Trade, error) {
+	u := fmt.Sprintf("%s/trades?market=%s&limit=%d&offset=%d",
+		c.DataBase, url.QueryEscape(conditionID), limit, offset)
+	resp, err := c.HTTP.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("market trades request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("market trades API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var trades []Trade
+	if err := json.NewDecoder(resp.Body).Decode(&trades); err != nil {
+		return nil, fmt.Errorf("decode market trades: %w", err)
+	}
+	return trades, nil
+}
+
+// GetHolders fetches the top holders of each outcome token in a market.
+func (c *Client) GetHolders(conditionID string, limit int) ([]MarketHolders, error) {
+	u := fmt.Sprintf("%s/holders?market=%s&limit=%d",
+		c.DataBase, url.QueryEscape(conditionID), limit)
+	resp, err := c.HTTP.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("holders request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("holders API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var holders []MarketHolders
+	if err := json.NewDecoder(resp.Body).Decode(&holders); err != nil {
+		return nil, fmt.Errorf("decode holders: %w", err)
+	}
+	return holders, nil
+}
+
+// GetPositions fetches a wallet's open positions with pagination.
+func (c *Client) GetPositions(user string, limit, offset int) ([]Position, error) {
+	u := fmt.Sprintf("%s/positions?user=%s&limit=%d&offset=%d",
+		c.DataBase, url.QueryEscape(user), limit, offset)
+	resp, err := c.HTTP.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("positions request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("positions API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var positions []Position
+	if err := json.NewDecoder(resp.Body).Decode(&positions); err != nil {
+		return nil, fmt.Errorf("decode positions: %w", err)
+	}
+	return positions, nil
+}
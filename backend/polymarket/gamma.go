@@ -51,10 +51,10 @@ func (c *Client) GetSportsTags() ([]Tag, error) {
 	return tags, nil
 }
 
-// GetEvents fetches events for a given tag ID with pagination.
+// GetEvents fetches events for a given tag ID with pagination, newest first.
 // Does not filter by active/closed status so historical events are included.
 func (c *Client) GetEvents(tagID string, limit, offset int) ([]Event, error) {
-	u := fmt.Sprintf("%s/events?tag=%s&limit=%d&offset=%d",
+	u := fmt.Sprintf("%s/events?tag_id=%s&limit=%d&offset=%d&order=id&ascending=false",
 		c.GammaBase, url.QueryEscape(tagID), limit, offset)
 	resp, err := c.HTTP.Get(u)
 	if err != nil {
@@ -74,6 +74,54 @@ func (c *Client) GetEvents(tagID string, limit, offset int) ([]Event, error) {
 	return events, nil
 }
 
+// GetActiveEvents fetches the open events for a tag ID with pagination,
+// soonest ending first.
+func (c *Client) GetActiveEvents(tagID string, limit, offset int) ([]Event, error) {
+	u := fmt.Sprintf("%s/events?tag_id=%s&limit=%d&offset=%d&active=true&closed=false&order=endDate&ascending=true",
+		c.GammaBase, url.QueryEscape(tagID), limit, offset)
+	resp, err := c.HTTP.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("events request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("events API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var events []Event
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, fmt.Errorf("decode events: %w", err)
+	}
+	return events, nil
+}
+
+// GetEventBySlug fetches one event and its markets by slug. It returns nil
+// when no event has that slug.
+func (c *Client) GetEventBySlug(slug string) (*Event, error) {
+	u := fmt.Sprintf("%s/events?slug=%s", c.GammaBase, url.QueryEscape(slug))
+	resp, err := c.HTTP.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("event request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("event API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var events []Event
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, fmt.Errorf("decode event: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return &events[0], nil
+}
+
 // GetMarkets fetches markets by condition IDs (comma-separated).
 func (c *Client) GetMarkets(conditionIDs []string) ([]Market, error) {
 	if len(conditionIDs) == 0 {
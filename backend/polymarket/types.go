@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -18,36 +19,47 @@ type Profile struct {
 
 // Trade represents a single trade from Data API.
 type Trade struct {
-	ID          string  `json:"id"`
-	ProxyWallet string  `json:"proxyWallet"`
-	Side        string  `json:"side"`        // "BUY" or "SELL"
-	Asset       string  `json:"asset"`       // token ID
-	ConditionID string  `json:"conditionId"` // market condition ID
-	Slug        string  `json:"slug"`
-	Size        float64 `json:"size"`
-	Price       float64 `json:"price"`
-	Timestamp   int64   `json:"timestamp"`
-	Title       string  `json:"title"`
-	Outcome     string  `json:"outcome"` // "Yes" or "No"
+	ID              string  `json:"id"`
+	TransactionHash string  `json:"transactionHash"`
+	ProxyWallet     string  `json:"proxyWallet"`
+	Side            string  `json:"side"`        // "BUY" or "SELL"
+	Asset           string  `json:"asset"`       // token ID
+	ConditionID     string  `json:"conditionId"` // market condition ID
+	Slug            string  `json:"slug"`
+	Size            float64 `json:"size"`
+	Price           float64 `json:"price"`
+	Timestamp       int64   `json:"timestamp"`
+	Title           string  `json:"title"`
+	Outcome         string  `json:"outcome"` // "Yes" or "No"
 }
 
 func (t Trade) Time() time.Time {
 	return time.Unix(t.Timestamp, 0)
 }
 
+// Key identifies a fill across polls. The Data API has no trade ID, so one
+// is built from the transaction and the fill's details when ID is empty.
+func (t Trade) Key() string {
+	if t.ID != "" {
+		return t.ID
+	}
+	return fmt.Sprintf("%s:%s:%s:%s:%g:%g", t.TransactionHash, strings.ToLower(t.ProxyWallet), t.Asset, t.Side, t.Size, t.Price)
+}
+
 func (t *Trade) UnmarshalJSON(data []byte) error {
 	type rawTrade struct {
-		ID          string          `json:"id"`
-		ProxyWallet string          `json:"proxyWallet"`
-		Side        string          `json:"side"`
-		Asset       string          `json:"asset"`
-		ConditionID string          `json:"conditionId"`
-		Slug        string          `json:"slug"`
-		Size        json.RawMessage `json:"size"`
-		Price       json.RawMessage `json:"price"`
-		Timestamp   int64           `json:"timestamp"`
-		Title       string          `json:"title"`
-		Outcome     string          `json:"outcome"`
+		ID              string          `json:"id"`
+		TransactionHash string          `json:"transactionHash"`
+		ProxyWallet     string          `json:"proxyWallet"`
+		Side            string          `json:"side"`
+		Asset           string          `json:"asset"`
+		ConditionID     string          `json:"conditionId"`
+		Slug            string          `json:"slug"`
+		Size            json.RawMessage `json:"size"`
+		Price           json.RawMessage `json:"price"`
+		Timestamp       int64           `json:"timestamp"`
+		Title           string          `json:"title"`
+		Outcome         string          `json:"outcome"`
 	}
 
 	var raw rawTrade
@@ -66,6 +78,7 @@ func (t *Trade) UnmarshalJSON(data []byte) error {
 	}
 
 	t.ID = raw.ID
+	t.TransactionHash = raw.TransactionHash
 	t.ProxyWallet = raw.ProxyWallet
 	t.Side = raw.Side
 	t.Asset = raw.Asset
@@ -81,30 +94,137 @@ func (t *Trade) UnmarshalJSON(data []byte) error {
 
 // Market represents a Polymarket market from Gamma API.
 type Market struct {
-	ID          string  `json:"id"`
-	Question    string  `json:"question"`
-	ConditionID string  `json:"conditionId"`
-	Slug        string  `json:"slug"`
-	VolumeNum   float64 `json:"volumeNum"`
-	StartDate   string  `json:"startDateIso"`
-	EndDate     string  `json:"endDateIso"`
-	Active      bool    `json:"active"`
-	Closed      bool    `json:"closed"`
-}
-
-// Event represents a Polymarket event from Gamma API.
+	ID               string  `json:"id"`
+	Question         string  `json:"question"`
+	ConditionID      string  `json:"conditionId"`
+	Slug             string  `json:"slug"`
+	VolumeNum        float64 `json:"volumeNum"`
+	LiquidityNum     float64 `json:"liquidityNum"`
+	BestBid          float64 `json:"bestBid"` // first outcome
+	BestAsk          float64 `json:"bestAsk"` // first outcome
+	Line             float64 `json:"line"`    // spread or total line, when set
+	StartDate        string  `json:"startDateIso"`
+	EndDate          string  `json:"endDateIso"`
+	ClosedTime       string  `json:"closedTime"`
+	Active           bool    `json:"active"`
+	Closed           bool    `json:"closed"`
+	GroupItemTitle   string  `json:"groupItemTitle"`
+	SportsMarketType string  `json:"sportsMarketType"`
+	GameStartTime    string  `json:"gameStartTime"`
+	Outcomes         string  `json:"outcomes"`      // JSON-encoded list, e.g. "[\"Yes\", \"No\"]"
+	OutcomePrices    string  `json:"outcomePrices"` // JSON-encoded list of decimal strings
+	ClobTokenIDs     string  `json:"clobTokenIds"`  // JSON-encoded list of outcome token IDs
+}
+
+// TokenIDs decodes the market's CLOB token IDs, indexed like OutcomeNames.
+func (m Market) TokenIDs() []string {
+	var ids []string
+	if err := json.Unmarshal([]byte(m.ClobTokenIDs), &ids); err != nil {
+		return nil
+	}
+	return ids
+}
+
+// Prices decodes the market's last outcome prices, indexed like
+// OutcomeNames.
+func (m Market) Prices() []float64 {
+	var raw []string
+	if err := json.Unmarshal([]byte(m.OutcomePrices), &raw); err != nil {
+		return nil
+	}
+	prices := make([]float64, 0, len(raw))
+	for _, value := range raw {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil
+		}
+		prices = append(prices, price)
+	}
+	return prices
+}
+
+// ResolvedPrices returns a closed market's payout per outcome once one
+// outcome has resolved to 1.
+func (m Market) ResolvedPrices() ([]float64, bool) {
+	if !m.Closed {
+		return nil, false
+	}
+	prices := m.Prices()
+	for _, price := range prices {
+		if price >= 0.99 {
+			return prices, true
+		}
+	}
+	return nil, false
+}
+
+// OutcomeNames decodes the market's outcome list, indexed by outcome index.
+func (m Market) OutcomeNames() []string {
+	var names []string
+	if err := json.Unmarshal([]byte(m.Outcomes), &names); err != nil {
+		return nil
+	}
+	return names
+}
+
+// Event represents a Polymarket event from Gamma API. Sports events also
+// carry the game's start time, live state and score.
 type Event struct {
-	ID      string   `json:"id"`
-	Slug    string   `json:"slug"`
-	Title   string   `json:"title"`
-	Markets []Market `json:"markets"`
+	ID        string   `json:"id"`
+	Slug      string   `json:"slug"`
+	Title     string   `json:"title"`
+	StartTime string   `json:"startTime"`
+	EndDate   string   `json:"endDate"`
+	Score     string   `json:"score"`
+	Period    string   `json:"period"`
+	Live      bool     `json:"live"`
+	Ended     bool     `json:"ended"`
+	Closed    bool     `json:"closed"`
+	NegRisk   bool     `json:"negRisk"` // markets are mutually exclusive outcomes
+	Markets   []Market `json:"markets"`
 }
 
-// Tag represents a sport/category tag from Gamma API.
+// Holder is one wallet holding an outcome token, from the Data API.
+type Holder struct {
+	ProxyWallet  string  `json:"proxyWallet"`
+	Name         string  `json:"name"`
+	Pseudonym    string  `json:"pseudonym"`
+	Asset        string  `json:"asset"`
+	Amount       float64 `json:"amount"`
+	OutcomeIndex int     `json:"outcomeIndex"`
+}
+
+// Position is a wallet's open position in one outcome, from the Data API.
+type Position struct {
+	ProxyWallet  string  `json:"proxyWallet"`
+	Asset        string  `json:"asset"`
+	ConditionID  string  `json:"conditionId"`
+	Size         float64 `json:"size"`
+	AvgPrice     float64 `json:"avgPrice"`
+	InitialValue float64 `json:"initialValue"`
+	CurrentValue float64 `json:"currentValue"`
+	CurPrice     float64 `json:"curPrice"`
+	Title        string  `json:"title"`
+	Slug         string  `json:"slug"`
+	EventSlug    string  `json:"eventSlug"`
+	Outcome      string  `json:"outcome"`
+	OutcomeIndex int     `json:"outcomeIndex"`
+}
+
+// MarketHolders lists the top holders of one outcome token.
+type MarketHolders struct {
+	Token   string   `json:"token"`
+	Holders []Holder `json:"holders"`
+}
+
+// Tag represents a sport/category tag from Gamma API. Entries from the
+// /sports endpoint carry a sport code and the tag IDs used to query its events.
 type Tag struct {
-	ID    string `json:"id"`
-	Label string `json:"label"`
-	Slug  string `json:"slug"`
+	ID     string   `json:"id"`
+	Label  string   `json:"label"`
+	Slug   string   `json:"slug"`
+	Sport  string   `json:"sport,omitempty"`
+	TagIDs []string `json:"tag_ids,omitempty"`
 }
 
 func (t *Tag) UnmarshalJSON(data []byte) error {
@@ -112,6 +232,8 @@ func (t *Tag) UnmarshalJSON(data []byte) error {
 		ID    json.RawMessage `json:"id"`
 		Label string          `json:"label"`
 		Slug  string          `json:"slug"`
+		Sport string          `json:"sport"`
+		Tags  json.RawMessage `json:"tags"`
 	}
 
 	var raw rawTag
@@ -121,6 +243,8 @@ func (t *Tag) UnmarshalJSON(data []byte) error {
 
 	t.Label = raw.Label
 	t.Slug = raw.Slug
+	t.Sport = raw.Sport
+	t.TagIDs = parseIDList(raw.Tags)
 
 	if len(raw.ID) == 0 || string(raw.ID) == "null" {
 		return nil
@@ -139,6 +263,40 @@ func (t *Tag) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("unsupported tag id: %s", string(raw.ID))
 }
 
+// parseIDList accepts either a comma-separated string or a JSON array of
+// string or numeric IDs.
+func parseIDList(data json.RawMessage) []string {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+
+	var csv string
+	if err := json.Unmarshal(data, &csv); err == nil {
+		ids := []string{}
+		for _, part := range strings.Split(csv, ",") {
+			if trimmed := strings.TrimSpace(part); trimmed != "" {
+				ids = append(ids, trimmed)
+			}
+		}
+		return ids
+	}
+
+	var list []json.Number
+	if err := json.Unmarshal(data, &list); err == nil {
+		ids := make([]string, 0, len(list))
+		for _, id := range list {
+			ids = append(ids, id.String())
+		}
+		return ids
+	}
+
+	var strs []string
+	if err := json.Unmarshal(data, &strs); err == nil {
+		return strs
+	}
+	return nil
+}
+
 func parseFlexibleFloat(data json.RawMessage) (float64, error) {
 	if len(data) == 0 || string(data) == "null" {
 		return 0, nil
@@ -161,6 +319,51 @@ func parseFlexibleFloat(data json.RawMessage) (float64, error) {
 	return 0, fmt.Errorf("unsupported numeric value: %s", string(data))
 }
 
+// OrderBook is a CLOB order book for one outcome token.
+type OrderBook struct {
+	Market    string       `json:"market"`
+	AssetID   string       `json:"asset_id"`
+	Bids      []OrderLevel `json:"bids"`
+	Asks      []OrderLevel `json:"asks"`
+	Timestamp string       `json:"timestamp"`
+}
+
+// OrderLevel is one price level of an order book.
+type OrderLevel struct {
+	Price float64 `json:"price"`
+	Size  float64 `json:"size"`
+}
+
+func (l *OrderLevel) UnmarshalJSON(data []byte) error {
+	type rawLevel struct {
+		Price json.RawMessage `json:"price"`
+		Size  json.RawMessage `json:"size"`
+	}
+
+	var raw rawLevel
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	price, err := parseFlexibleFloat(raw.Price)
+	if err != nil {
+		return fmt.Errorf("parse order price: %w", err)
+	}
+	size, err := parseFlexibleFloat(raw.Size)
+	if err != nil {
+		return fmt.Errorf("parse order size: %w", err)
+	}
+	l.Price = price
+	l.Size = size
+	return nil
+}
+
+// PricePoint is one sample of an outcome token's CLOB price history.
+type PricePoint struct {
+	T int64   `json:"t"` // unix seconds
+	P float64 `json:"p"`
+}
+
 // EnrichedTrade is a trade enriched with market metadata.
 type EnrichedTrade struct {
 	ConditionID     string  `json:"condition_id"`
@@ -9,18 +9,10 @@ import (
 	"os"
 	"strings"
 	"time"
-)
 
-var allowedLabels = []string{
-	"Quick Scout",
-	"Heavy Hitter",
-	"Favorite Backer",
-	"Contrarian Hunter",
-	"Early Whale",
-	"Late Whale",
-	"Early Bird",
-	"Steady Player",
-}
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/styles"
+)
 
 type Client struct {
 	baseURL    string
@@ -32,11 +24,12 @@ type Client struct {
 
 type Input struct {
 	Wallet                  string
+	Sport                   string
 	DisplayName             string
 	SourceRank              int
 	WinRate                 float64
 	PnlUSD                  float64
-	NbaTrades               int
+	SportTrades             int
 	RecentMarkets           int
 	EntryTimingHours        float64
 	SizeRatioPct            float64
@@ -89,7 +82,7 @@ func (c *Client) Classify(ctx context.Context, input Input) (Result, error) {
 		"messages": []map[string]string{
 			{
 				"role":    "system",
-				"content": buildSystemPrompt(),
+				"content": buildSystemPrompt(input.Sport),
 			},
 			{
 				"role":    "user",
@@ -145,7 +138,7 @@ func (c *Client) Classify(ctx context.Context, input Input) (Result, error) {
 	if parsed.StyleLabel == "" {
 		parsed.StyleLabel = input.DeterministicStyleLabel
 	}
-	if !isAllowedLabel(parsed.StyleLabel) {
+	if !styles.Active().Has(parsed.StyleLabel) {
 		parsed.StyleLabel = input.DeterministicStyleLabel
 	}
 	if parsed.Summary == "" {
@@ -160,11 +153,23 @@ func (c *Client) Classify(ctx context.Context, input Input) (Result, error) {
 	}, nil
 }
 
-func buildSystemPrompt() string {
-	return fmt.Sprintf(`You are classifying public Polymarket NBA trader behavior.
+func buildSystemPrompt(sport string) string {
+	labels := styles.Active().Labels()
+	descriptions := make([]string, 0, len(labels))
+	for _, label := range labels {
+		descriptions = append(descriptions, fmt.Sprintf("- %s: %s", label.Name, label.Description))
+	}
+
+	return fmt.Sprintf(`You are classifying public Polymarket %s trader behavior.
 Return JSON only with keys "style_label" and "style_summary".
 Choose style_label from this exact set: %s.
-style_summary must be one sentence, under 28 words, grounded only in the metrics provided.`, strings.Join(allowedLabels, ", "))
+Label meanings:
+%s
+style_summary must be one sentence, under 28 words, grounded only in the metrics provided.`,
+		sports.DisplayName(sport),
+		strings.Join(styles.Active().Names(), ", "),
+		strings.Join(descriptions, "\n"),
+	)
 }
 
 func buildUserPrompt(input Input) string {
@@ -189,15 +194,6 @@ func extractJSONObject(content string) string {
 	return content
 }
 
-func isAllowedLabel(label string) bool {
-	for _, allowed := range allowedLabels {
-		if label == allowed {
-			return true
-		}
-	}
-	return false
-}
-
 func parseTimeout(value string, fallback time.Duration) time.Duration {
 	if value == "" {
 		return fallback
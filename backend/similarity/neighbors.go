@@ -0,0 +1,106 @@
+package similarity
+
+import (
+	"math"
+	"sort"
+)
+
+// Features are the normalized style dimensions used for distance. They match
+// the radar chart axes so "similar" means "similar chart".
+var Features = []string{"entry_timing", "size_ratio", "conviction"}
+
+// sharedTraitThreshold is the largest per-feature gap still reported as a
+// shared trait.
+const sharedTraitThreshold = 0.1
+
+// Vector is one wallet's position in style space.
+type Vector struct {
+	ID     string
+	Values map[string]float64
+}
+
+// Contribution explains how one feature affects a pair's distance.
+type Contribution struct {
+	Metric         string  `json:"metric"`
+	TargetValue    float64 `json:"target_value"`
+	CandidateValue float64 `json:"candidate_value"`
+	AbsDifference  float64 `json:"abs_difference"`
+	DistanceShare  float64 `json:"distance_share"`
+	SharedTrait    bool    `json:"shared_trait"`
+}
+
+// Neighbor is a candidate ranked by closeness to the target.
+type Neighbor struct {
+	ID            string         `json:"id"`
+	Distance      float64        `json:"distance"`
+	Similarity    float64        `json:"similarity"`
+	Contributions []Contribution `json:"contributions"`
+	SharedTraits  []string       `json:"shared_traits"`
+}
+
+// Nearest returns the k candidates closest to target in Euclidean distance
+// over Features, skipping the target itself.
+func Nearest(target Vector, candidates []Vector, k int) []Neighbor {
+	maxDistance := math.Sqrt(float64(len(Features)))
+
+	neighbors := make([]Neighbor, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.ID == target.ID {
+			continue
+		}
+
+		squared := make([]float64, len(Features))
+		total := 0.0
+		for i, feature := range Features {
+			diff := target.Values[feature] - candidate.Values[feature]
+			squared[i] = diff * diff
+			total += squared[i]
+		}
+		distance := math.Sqrt(total)
+
+		contributions := make([]Contribution, 0, len(Features))
+		shared := []string{}
+		for i, feature := range Features {
+			share := 0.0
+			if total > 0 {
+				share = squared[i] / total
+			}
+			diff := math.Abs(target.Values[feature] - candidate.Values[feature])
+			isShared := diff <= sharedTraitThreshold
+			if isShared {
+				shared = append(shared, feature)
+			}
+			contributions = append(contributions, Contribution{
+				Metric:         feature,
+				TargetValue:    round(target.Values[feature]),
+				CandidateValue: round(candidate.Values[feature]),
+				AbsDifference:  round(diff),
+				DistanceShare:  round(share),
+				SharedTrait:    isShared,
+			})
+		}
+		sort.SliceStable(contributions, func(i, j int) bool {
+			return contributions[i].AbsDifference < contributions[j].AbsDifference
+		})
+
+		neighbors = append(neighbors, Neighbor{
+			ID:            candidate.ID,
+			Distance:      round(distance),
+			Similarity:    round(1 - distance/maxDistance),
+			Contributions: contributions,
+			SharedTraits:  shared,
+		})
+	}
+
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].Distance < neighbors[j].Distance
+	})
+	if k > 0 && len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	return neighbors
+}
+
+func round(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
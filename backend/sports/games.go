@@ -0,0 +1,191 @@
+package sports
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+)
+
+const (
+	GameScheduled = "scheduled"
+	GameLive      = "live"
+	GameFinal     = "final"
+)
+
+// Game is one matchup. Its ID is the Gamma event ID, so every market of the
+// event (winner, spread, total, props) links to it.
+type Game struct {
+	ID             string       `json:"id"`
+	Sport          string       `json:"sport"`
+	League         string       `json:"league"`
+	Slug           string       `json:"slug"`
+	Title          string       `json:"title"`
+	HomeTeam       string       `json:"home_team"`
+	AwayTeam       string       `json:"away_team"`
+	ScheduledStart *time.Time   `json:"scheduled_start,omitempty"`
+	Status         string       `json:"status"`
+	Score          string       `json:"score,omitempty"`
+	HomeScore      *int         `json:"home_score,omitempty"`
+	AwayScore      *int         `json:"away_score,omitempty"`
+	Period         string       `json:"period,omitempty"`
+	Markets        []GameMarket `json:"markets"`
+}
+
+// GameMarket is a market that belongs to a game.
+type GameMarket struct {
+	ConditionID string `json:"condition_id"`
+	Question    string `json:"question"`
+	Slug        string `json:"slug"`
+	MarketType  string `json:"market_type"`
+	Closed      bool   `json:"closed"`
+}
+
+var (
+	matchupPattern = regexp.MustCompile(`(?i)^(.+?)\s+(vs\.?|v\.?|@)\s+(.+?)$`)
+	scorePattern   = regexp.MustCompile(`^\s*(\d+)\s*[-:]\s*(\d+)\s*$`)
+)
+
+// GameFromEvent builds a game from a Gamma event whose title names a
+// matchup. North American league titles list the away team first ("Celtics
+// vs. Lakers" is played in Los Angeles), matching Polymarket's game slugs;
+// soccer titles list the home side first. The boolean is false for events
+// that are not a single game, such as futures.
+func GameFromEvent(event polymarket.Event, sport, league string) (Game, bool) {
+	away, home, ok := parseMatchup(event.Title)
+	if !ok || event.ID == "" {
+		return Game{}, false
+	}
+	homeFirst := sport == "soccer"
+	if entry, _ := Lookup(sport); entry.Parent == "soccer" {
+		homeFirst = true
+	}
+	if homeFirst {
+		away, home = home, away
+	}
+
+	game := Game{
+		ID:       event.ID,
+		Sport:    sport,
+		League:   league,
+		Slug:     event.Slug,
+		Title:    event.Title,
+		HomeTeam: home,
+		AwayTeam: away,
+		Status:   GameScheduled,
+		Score:    strings.TrimSpace(event.Score),
+		Period:   event.Period,
+		Markets:  []GameMarket{},
+	}
+	if event.Live {
+		game.Status = GameLive
+	}
+	if event.Ended || event.Closed {
+		game.Status = GameFinal
+	}
+	game.AwayScore, game.HomeScore = parseScore(game.Score)
+	if homeFirst {
+		game.AwayScore, game.HomeScore = game.HomeScore, game.AwayScore
+	}
+
+	start := event.StartTime
+	for _, market := range event.Markets {
+		if market.ConditionID == "" {
+			continue
+		}
+		if start == "" {
+			start = market.GameStartTime
+		}
+		game.Markets = append(game.Markets, GameMarket{
+			ConditionID: strings.ToLower(market.ConditionID),
+			Question:    market.Question,
+			Slug:        market.Slug,
+			MarketType:  MarketType(market),
+			Closed:      market.Closed,
+		})
+	}
+	if start != "" {
+		if t, ok := ParseGammaTime(start); ok {
+			game.ScheduledStart = &t
+		}
+	}
+	return game, true
+}
+
+// MarketType names the kind of game market: moneyline, spread, total or prop.
+// Gamma's sportsMarketType wins when present; otherwise the question decides.
+func MarketType(market polymarket.Market) string {
+	if market.SportsMarketType != "" {
+		switch strings.ToLower(market.SportsMarketType) {
+		case "moneyline":
+			return "moneyline"
+		case "spreads", "spread":
+			return "spread"
+		case "totals", "total":
+			return "total"
+		}
+		return strings.ToLower(market.SportsMarketType)
+	}
+
+	question := strings.ToLower(market.Question + " " + market.GroupItemTitle)
+	switch {
+	case strings.Contains(question, "spread") || strings.Contains(question, "handicap"):
+		return "spread"
+	case strings.Contains(question, "o/u") || strings.Contains(question, "over/under") || strings.Contains(question, "total"):
+		return "total"
+	case strings.Contains(question, " vs") || strings.Contains(question, " win"):
+		return "moneyline"
+	}
+	return "prop"
+}
+
+func parseMatchup(title string) (away, home string, ok bool) {
+	// Drop league prefixes and suffixes such as "NBA: " or " (Game 7)".
+	title = strings.TrimSpace(title)
+	if i := strings.Index(title, ": "); i >= 0 && i < 12 {
+		title = title[i+2:]
+	}
+	if i := strings.Index(title, " ("); i > 0 {
+		title = title[:i]
+	}
+
+	match := matchupPattern.FindStringSubmatch(title)
+	if match == nil || strings.Contains(title, "?") {
+		return "", "", false
+	}
+	away, home = strings.TrimSpace(match[1]), strings.TrimSpace(match[3])
+	if away == "" || home == "" {
+		return "", "", false
+	}
+	return away, home, true
+}
+
+func parseScore(score string) (away, home *int) {
+	match := scorePattern.FindStringSubmatch(score)
+	if match == nil {
+		return nil, nil
+	}
+	a, _ := strconv.Atoi(match[1])
+	h, _ := strconv.Atoi(match[2])
+	return &a, &h
+}
+
+// ParseGammaTime parses the timestamp formats Gamma uses across events and
+// markets.
+func ParseGammaTime(value string) (time.Time, bool) {
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05-07",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,187 @@
+package sports
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Market is the sport classification of one market, taken from the Gamma
+// event it belongs to.
+type Market struct {
+	ConditionID string `json:"condition_id"`
+	Sport       string `json:"sport"`
+	League      string `json:"league"`
+	EventID     string `json:"event_id"`
+	EventSlug   string `json:"event_slug"`
+	EventTitle  string `json:"event_title"`
+}
+
+// Index maps condition IDs to their sport and keeps the games those markets
+// belong to. It is safe for concurrent use.
+type Index struct {
+	mu          sync.RWMutex
+	markets     map[string]Market
+	games       map[string]Game
+	refreshedAt time.Time
+}
+
+func NewIndex() *Index {
+	return &Index{markets: map[string]Market{}, games: map[string]Game{}}
+}
+
+// GameFilter narrows Index.Games. Zero values are ignored; an umbrella sport
+// such as "soccer" also matches its leagues.
+type GameFilter struct {
+	Sport  string
+	Status string
+	Limit  int
+}
+
+var shared = NewIndex()
+
+// SharedIndex returns the process-wide index consulted by MatchesMarket.
+func SharedIndex() *Index {
+	return shared
+}
+
+// Get returns the classification of a market, if indexed.
+func (x *Index) Get(conditionID string) (Market, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	market, ok := x.markets[normalizeConditionID(conditionID)]
+	return market, ok
+}
+
+// Add merges markets into the index, replacing earlier entries for the same
+// condition ID.
+func (x *Index) Add(markets []Market) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, market := range markets {
+		id := normalizeConditionID(market.ConditionID)
+		if id == "" {
+			continue
+		}
+		market.ConditionID = id
+		x.markets[id] = market
+	}
+}
+
+// AddGames merges games into the index, replacing earlier entries with the
+// same ID.
+func (x *Index) AddGames(games []Game) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, game := range games {
+		if game.ID != "" {
+			x.games[game.ID] = game
+		}
+	}
+}
+
+// Game returns a game by Gamma event ID or event slug.
+func (x *Index) Game(idOrSlug string) (Game, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if game, ok := x.games[idOrSlug]; ok {
+		return game, true
+	}
+	for _, game := range x.games {
+		if game.Slug == idOrSlug {
+			return game, true
+		}
+	}
+	return Game{}, false
+}
+
+// Games returns the indexed games matching filter, latest start first.
+func (x *Index) Games(filter GameFilter) []Game {
+	x.mu.RLock()
+	games := make([]Game, 0, len(x.games))
+	for _, game := range x.games {
+		if filter.Sport != "" && game.Sport != filter.Sport {
+			if sport, _ := Lookup(game.Sport); sport.Parent != filter.Sport {
+				continue
+			}
+		}
+		if filter.Status != "" && game.Status != filter.Status {
+			continue
+		}
+		games = append(games, game)
+	}
+	x.mu.RUnlock()
+
+	sort.Slice(games, func(i, j int) bool {
+		a, b := games[i].ScheduledStart, games[j].ScheduledStart
+		if a == nil || b == nil {
+			if a == nil && b == nil {
+				return games[i].ID > games[j].ID
+			}
+			return b == nil
+		}
+		return a.After(*b)
+	})
+	if filter.Limit > 0 && len(games) > filter.Limit {
+		games = games[:filter.Limit]
+	}
+	return games
+}
+
+// MarkRefreshed records when the index was last rebuilt from Gamma.
+func (x *Index) MarkRefreshed(at time.Time) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.refreshedAt = at
+}
+
+// Len returns the number of indexed markets.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.markets)
+}
+
+// GameCount returns the number of indexed games.
+func (x *Index) GameCount() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.games)
+}
+
+// RefreshedAt returns the time of the last Gamma refresh, zero if none.
+func (x *Index) RefreshedAt() time.Time {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.refreshedAt
+}
+
+// Matches reports whether an indexed market belongs to sport, either
+// directly or through the sport's parent (a Premier League market matches
+// "soccer"). known is false when the market is not indexed.
+func (x *Index) Matches(conditionID, key string) (matched, known bool) {
+	market, ok := x.Get(conditionID)
+	if !ok {
+		return false, false
+	}
+	key = Normalize(key)
+	if market.Sport == key {
+		return true, true
+	}
+	sport, _ := Lookup(market.Sport)
+	return sport.Parent == key, true
+}
+
+// MatchesMarket classifies a market by the shared index and falls back to
+// keyword matching on its title and slug when the market is not indexed.
+func MatchesMarket(conditionID, title, slug, key string) bool {
+	if matched, known := shared.Matches(conditionID, key); known {
+		return matched
+	}
+	return MatchesText(title, key) || MatchesText(slug, key)
+}
+
+func normalizeConditionID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
@@ -0,0 +1,306 @@
+package sports
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+const eventPageSize = 100
+
+// Indexer keeps an Index in sync with Gamma's sport tags and events and
+// persists it so restarts do not start from an empty index.
+type Indexer struct {
+	client   *polymarket.Client
+	store    storage.Store
+	index    *Index
+	interval time.Duration
+	maxPages int
+}
+
+// NewIndexer builds an indexer. store may be nil, in which case the index
+// lives in memory only.
+func NewIndexer(client *polymarket.Client, store storage.Store, index *Index, interval time.Duration, maxPages int) *Indexer {
+	if index == nil {
+		index = SharedIndex()
+	}
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	if maxPages <= 0 {
+		maxPages = 20
+	}
+	return &Indexer{
+		client:   client,
+		store:    store,
+		index:    index,
+		interval: interval,
+		maxPages: maxPages,
+	}
+}
+
+func (x *Indexer) Start(ctx context.Context) {
+	go func() {
+		if err := x.Load(ctx); err != nil {
+			log.Printf("sports index load failed: %v", err)
+		}
+		x.refreshWithLogging(ctx)
+
+		ticker := time.NewTicker(x.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				x.refreshWithLogging(ctx)
+			}
+		}
+	}()
+}
+
+// Load fills the index from the persisted classifications.
+func (x *Indexer) Load(ctx context.Context) error {
+	if x.store == nil {
+		return nil
+	}
+	stored, err := x.store.ListMarketSports(ctx)
+	if err != nil {
+		return err
+	}
+	markets := make([]Market, 0, len(stored))
+	for _, market := range stored {
+		markets = append(markets, Market{
+			ConditionID: market.ConditionID,
+			Sport:       market.Sport,
+			League:      market.League,
+			EventID:     market.EventID,
+			EventSlug:   market.EventSlug,
+			EventTitle:  market.EventTitle,
+		})
+	}
+	x.index.Add(markets)
+
+	games, err := x.store.ListGames(ctx)
+	if err != nil {
+		return err
+	}
+	x.index.AddGames(fromStoredGames(games))
+	return nil
+}
+
+// RefreshOnce walks the events of every catalog sport Gamma knows about and
+// indexes their markets and games. It returns the number of markets indexed.
+func (x *Indexer) RefreshOnce(ctx context.Context) (int, error) {
+	return x.RefreshSports(ctx)
+}
+
+// RefreshSports is RefreshOnce limited to the given sports; an umbrella
+// sport such as "soccer" covers its leagues. No keys means every sport.
+func (x *Indexer) RefreshSports(ctx context.Context, keys ...string) (int, error) {
+	wanted := map[string]bool{}
+	for _, key := range keys {
+		wanted[Normalize(key)] = true
+	}
+
+	tags, err := x.client.GetSportsTags()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, league := range LeagueTags(tags) {
+		sport, _ := Lookup(league.Sport)
+		if len(wanted) > 0 && !wanted[sport.Key] && !wanted[sport.Parent] {
+			continue
+		}
+
+		markets, games, err := x.collectEvents(ctx, league.TagID, sport.Key, league.League)
+		if err != nil {
+			return total, err
+		}
+		x.index.Add(markets)
+		x.index.AddGames(games)
+		if x.store != nil {
+			if err := x.store.UpsertMarketSports(ctx, toStored(markets)); err != nil {
+				return total, err
+			}
+			if err := x.store.UpsertGames(ctx, toStoredGames(games)); err != nil {
+				return total, err
+			}
+		}
+		total += len(markets)
+	}
+
+	x.index.MarkRefreshed(time.Now().UTC())
+	return total, nil
+}
+
+func (x *Indexer) collectEvents(ctx context.Context, tagID, sport, league string) ([]Market, []Game, error) {
+	markets := []Market{}
+	games := []Game{}
+	for page := 0; page < x.maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		events, err := x.client.GetEvents(tagID, eventPageSize, page*eventPageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, event := range events {
+			if game, ok := GameFromEvent(event, sport, league); ok {
+				games = append(games, game)
+			}
+			for _, market := range event.Markets {
+				if market.ConditionID == "" {
+					continue
+				}
+				markets = append(markets, Market{
+					ConditionID: market.ConditionID,
+					Sport:       sport,
+					League:      league,
+					EventID:     event.ID,
+					EventSlug:   event.Slug,
+					EventTitle:  event.Title,
+				})
+			}
+		}
+		if len(events) < eventPageSize {
+			break
+		}
+	}
+	return markets, games, nil
+}
+
+func (x *Indexer) refreshWithLogging(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	count, err := x.RefreshOnce(runCtx)
+	if err != nil {
+		log.Printf("sports index refresh failed after %d markets: %v", count, err)
+		return
+	}
+	log.Printf("sports index refreshed %d markets in %s", count, time.Since(start).Round(time.Second))
+}
+
+// LeagueTag is a Gamma tag that belongs to exactly one league.
+type LeagueTag struct {
+	Sport  string
+	League string
+	TagID  string
+}
+
+// LeagueTags maps Gamma sports tags to the leagues they cover. Tags shared by
+// several sports (e.g. the generic "sports" tag) would pull in every league's
+// events, so only tags unique to one entry count.
+func LeagueTags(tags []polymarket.Tag) []LeagueTag {
+	usage := map[string]int{}
+	for _, tag := range tags {
+		for _, id := range tagIDs(tag) {
+			usage[id]++
+		}
+	}
+
+	leagues := []LeagueTag{}
+	for _, tag := range tags {
+		code := tag.Sport
+		if code == "" {
+			code = tag.Slug
+		}
+		sport, ok := ForGammaCode(code)
+		if !ok {
+			continue
+		}
+		for _, id := range tagIDs(tag) {
+			if usage[id] > 1 {
+				continue
+			}
+			leagues = append(leagues, LeagueTag{Sport: sport.Key, League: code, TagID: id})
+		}
+	}
+	return leagues
+}
+
+func tagIDs(tag polymarket.Tag) []string {
+	if len(tag.TagIDs) > 0 {
+		return tag.TagIDs
+	}
+	if tag.ID != "" {
+		return []string{tag.ID}
+	}
+	return nil
+}
+
+func toStored(markets []Market) []storage.MarketSport {
+	stored := make([]storage.MarketSport, 0, len(markets))
+	for _, market := range markets {
+		stored = append(stored, storage.MarketSport{
+			ConditionID: market.ConditionID,
+			Sport:       market.Sport,
+			League:      market.League,
+			EventID:     market.EventID,
+			EventSlug:   market.EventSlug,
+			EventTitle:  market.EventTitle,
+		})
+	}
+	return stored
+}
+
+func toStoredGames(games []Game) []storage.Game {
+	stored := make([]storage.Game, 0, len(games))
+	for _, game := range games {
+		markets := make([]storage.GameMarket, 0, len(game.Markets))
+		for _, market := range game.Markets {
+			markets = append(markets, storage.GameMarket(market))
+		}
+		stored = append(stored, storage.Game{
+			ID:             game.ID,
+			Sport:          game.Sport,
+			League:         game.League,
+			Slug:           game.Slug,
+			Title:          game.Title,
+			HomeTeam:       game.HomeTeam,
+			AwayTeam:       game.AwayTeam,
+			ScheduledStart: game.ScheduledStart,
+			Status:         game.Status,
+			Score:          game.Score,
+			HomeScore:      game.HomeScore,
+			AwayScore:      game.AwayScore,
+			Period:         game.Period,
+			Markets:        markets,
+		})
+	}
+	return stored
+}
+
+func fromStoredGames(stored []storage.Game) []Game {
+	games := make([]Game, 0, len(stored))
+	for _, game := range stored {
+		markets := make([]GameMarket, 0, len(game.Markets))
+		for _, market := range game.Markets {
+			markets = append(markets, GameMarket(market))
+		}
+		games = append(games, Game{
+			ID:             game.ID,
+			Sport:          game.Sport,
+			League:         game.League,
+			Slug:           game.Slug,
+			Title:          game.Title,
+			HomeTeam:       game.HomeTeam,
+			AwayTeam:       game.AwayTeam,
+			ScheduledStart: game.ScheduledStart,
+			Status:         game.Status,
+			Score:          game.Score,
+			HomeScore:      game.HomeScore,
+			AwayScore:      game.AwayScore,
+			Period:         game.Period,
+			Markets:        markets,
+		})
+	}
+	return games
+}
@@ -0,0 +1,216 @@
+package sports
+
+import (
+	"sort"
+	"strings"
+)
+
+// Sport describes a league or sport the pipeline can analyze.
+type Sport struct {
+	Key                 string   `json:"key"`
+	Name                string   `json:"name"`
+	Keywords            []string `json:"keywords"`
+	LeaderboardCategory string   `json:"leaderboard_category,omitempty"`
+	// GammaCodes are the sport codes Gamma's /sports endpoint uses for this
+	// entry; they drive the market index.
+	GammaCodes []string `json:"gamma_codes,omitempty"`
+	// Parent is an umbrella sport that also matches this one's markets.
+	Parent string `json:"parent,omitempty"`
+}
+
+// Default is the sport used when a caller does not name one.
+const Default = "nba"
+
+var catalog = map[string]Sport{
+	"nba": {
+		Key:                 "nba",
+		Name:                "NBA",
+		Keywords:            []string{"nba", "basketball"},
+		LeaderboardCategory: "NBA",
+		GammaCodes:          []string{"nba"},
+	},
+	"nfl": {
+		Key:                 "nfl",
+		Name:                "NFL",
+		Keywords:            []string{"nfl", "super bowl", "american football"},
+		LeaderboardCategory: "NFL",
+		GammaCodes:          []string{"nfl"},
+	},
+	"mlb": {
+		Key:                 "mlb",
+		Name:                "MLB",
+		Keywords:            []string{"mlb", "baseball", "world series"},
+		LeaderboardCategory: "MLB",
+		GammaCodes:          []string{"mlb"},
+	},
+	"nhl": {
+		Key:                 "nhl",
+		Name:                "NHL",
+		Keywords:            []string{"nhl", "hockey", "stanley cup"},
+		LeaderboardCategory: "NHL",
+		GammaCodes:          []string{"nhl"},
+	},
+	"epl": {
+		Key:                 "epl",
+		Name:                "Premier League",
+		Keywords:            []string{"epl", "premier league"},
+		LeaderboardCategory: "Soccer",
+		GammaCodes:          []string{"epl"},
+		Parent:              "soccer",
+	},
+	"laliga": {
+		Key:                 "laliga",
+		Name:                "La Liga",
+		Keywords:            []string{"la liga", "laliga"},
+		LeaderboardCategory: "Soccer",
+		GammaCodes:          []string{"lal"},
+		Parent:              "soccer",
+	},
+	"seriea": {
+		Key:                 "seriea",
+		Name:                "Serie A",
+		Keywords:            []string{"serie a"},
+		LeaderboardCategory: "Soccer",
+		GammaCodes:          []string{"sea"},
+		Parent:              "soccer",
+	},
+	"bundesliga": {
+		Key:                 "bundesliga",
+		Name:                "Bundesliga",
+		Keywords:            []string{"bundesliga"},
+		LeaderboardCategory: "Soccer",
+		GammaCodes:          []string{"bun"},
+		Parent:              "soccer",
+	},
+	"ucl": {
+		Key:                 "ucl",
+		Name:                "Champions League",
+		Keywords:            []string{"champions league", "ucl"},
+		LeaderboardCategory: "Soccer",
+		GammaCodes:          []string{"ucl"},
+		Parent:              "soccer",
+	},
+	"mls": {
+		Key:                 "mls",
+		Name:                "MLS",
+		Keywords:            []string{"mls", "major league soccer"},
+		LeaderboardCategory: "Soccer",
+		GammaCodes:          []string{"mls"},
+		Parent:              "soccer",
+	},
+	"soccer": {
+		Key:                 "soccer",
+		Name:                "Soccer",
+		Keywords:            []string{"soccer", "premier league", "la liga", "serie a", "bundesliga", "champions league", "mls"},
+		LeaderboardCategory: "Soccer",
+	},
+	"tennis": {
+		Key:                 "tennis",
+		Name:                "Tennis",
+		Keywords:            []string{"tennis", "atp", "wta", "wimbledon", "us open", "roland garros", "australian open"},
+		LeaderboardCategory: "Tennis",
+		GammaCodes:          []string{"atp", "wta"},
+	},
+	"mma": {
+		Key:                 "mma",
+		Name:                "MMA",
+		Keywords:            []string{"mma", "ufc"},
+		LeaderboardCategory: "UFC",
+		GammaCodes:          []string{"ufc", "mma"},
+	},
+}
+
+// Lookup returns the catalog entry for key. Unknown keys are returned as a
+// bare sport that matches its own name, so ad-hoc filters keep working.
+func Lookup(key string) (Sport, bool) {
+	normalized := Normalize(key)
+	if sport, ok := catalog[normalized]; ok {
+		return sport, true
+	}
+	return Sport{
+		Key:      normalized,
+		Name:     strings.ToUpper(normalized),
+		Keywords: []string{normalized},
+	}, false
+}
+
+// Normalize lowercases a sport key and applies the default for blanks.
+func Normalize(key string) string {
+	normalized := strings.ToLower(strings.TrimSpace(key))
+	if normalized == "" {
+		return Default
+	}
+	return normalized
+}
+
+// DisplayName returns the human-readable sport name, e.g. "NBA".
+func DisplayName(key string) string {
+	sport, _ := Lookup(key)
+	return sport.Name
+}
+
+// WithLeagues returns key followed by the catalog sports whose parent it is,
+// sorted by key, so an umbrella sport such as "soccer" also covers its
+// leagues.
+func WithLeagues(key string) []string {
+	key = Normalize(key)
+	keys := []string{key}
+	for _, sport := range All() {
+		if sport.Key != key && sport.Parent == key {
+			keys = append(keys, sport.Key)
+		}
+	}
+	return keys
+}
+
+// All returns every catalog sport sorted by key.
+func All() []Sport {
+	list := make([]Sport, 0, len(catalog))
+	for _, sport := range catalog {
+		list = append(list, sport)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// ForGammaCode returns the catalog sport Gamma labels with code, e.g. "lal"
+// for La Liga.
+func ForGammaCode(code string) (Sport, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	for _, sport := range catalog {
+		for _, candidate := range sport.GammaCodes {
+			if candidate == code {
+				return sport, true
+			}
+		}
+	}
+	return Sport{}, false
+}
+
+// MatchesText reports whether text mentions any keyword of the sport.
+func MatchesText(text, key string) bool {
+	sport, _ := Lookup(key)
+	value := strings.ToLower(text)
+	for _, keyword := range sport.Keywords {
+		if strings.Contains(value, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseList splits a comma-separated list of sport keys, dropping blanks and
+// duplicates.
+func ParseList(value string) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, part := range strings.Split(value, ",") {
+		key := strings.ToLower(strings.TrimSpace(part))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	AlertDeliveryPending   = "pending"
+	AlertDeliveryDelivered = "delivered"
+	AlertDeliveryFailed    = "failed"
+)
+
+// AlertRule is a configured alert: a rule kind with its parameters and the
+// webhook its alerts are delivered to. Params is kind-specific JSON.
+type AlertRule struct {
+	ID              int64           `json:"id"`
+	Name            string          `json:"name"`
+	Kind            string          `json:"kind"`
+	Params          json.RawMessage `json:"params"`
+	WebhookURL      string          `json:"webhook_url"`
+	Secret          string          `json:"secret,omitempty"`
+	CooldownSeconds int             `json:"cooldown_seconds"`
+	Enabled         bool            `json:"enabled"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// AlertDelivery is one alert queued for, or sent to, its rule's webhook.
+type AlertDelivery struct {
+	ID             int64           `json:"id"`
+	RuleID         int64           `json:"rule_id"`
+	DedupeKey      string          `json:"dedupe_key"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+const alertRuleColumns = `
+  id, name, kind, params, webhook_url, secret, cooldown_seconds, enabled, created_at, updated_at`
+
+const alertDeliveryColumns = `
+  id, rule_id, dedupe_key, payload, status, attempts, response_status, last_error,
+  next_attempt_at, created_at, delivered_at`
+
+func scanAlertRule(row rowScanner) (AlertRule, error) {
+	var rule AlertRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Kind,
+		&rule.Params,
+		&rule.WebhookURL,
+		&rule.Secret,
+		&rule.CooldownSeconds,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	return rule, err
+}
+
+func scanAlertDelivery(row rowScanner) (AlertDelivery, error) {
+	var delivery AlertDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.RuleID,
+		&delivery.DedupeKey,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.ResponseStatus,
+		&delivery.LastError,
+		&delivery.NextAttemptAt,
+		&delivery.CreatedAt,
+		&delivery.DeliveredAt,
+	)
+	return delivery, err
+}
+
+func (s *PostgresStore) CreateAlertRule(ctx context.Context, rule AlertRule) (AlertRule, error) {
+	const query = `
+INSERT INTO alert_rules (name, kind, params, webhook_url, secret, cooldown_seconds, enabled)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING` + alertRuleColumns
+
+	created, err := scanAlertRule(s.pool.QueryRow(ctx, query,
+		rule.Name, rule.Kind, rule.Params, rule.WebhookURL, rule.Secret, rule.CooldownSeconds, rule.Enabled,
+	))
+	if err != nil {
+		return AlertRule{}, fmt.Errorf("create alert rule: %w", err)
+	}
+	return created, nil
+}
+
+// UpdateAlertRule replaces a rule's settings; ok is false when it does not
+// exist.
+func (s *PostgresStore) UpdateAlertRule(ctx context.Context, rule AlertRule) (AlertRule, bool, error) {
+	const query = `
+UPDATE alert_rules SET
+  name = $2,
+  kind = $3,
+  params = $4,
+  webhook_url = $5,
+  secret = $6,
+  cooldown_seconds = $7,
+  enabled = $8,
+  updated_at = NOW()
+WHERE id = $1
+RETURNING` + alertRuleColumns
+
+	updated, err := scanAlertRule(s.pool.QueryRow(ctx, query,
+		rule.ID, rule.Name, rule.Kind, rule.Params, rule.WebhookURL, rule.Secret, rule.CooldownSeconds, rule.Enabled,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return AlertRule{}, false, nil
+		}
+		return AlertRule{}, false, fmt.Errorf("update alert rule %d: %w", rule.ID, err)
+	}
+	return updated, true, nil
+}
+
+// DeleteAlertRule removes a rule and its delivery log and reports whether it
+// existed.
+func (s *PostgresStore) DeleteAlertRule(ctx context.Context, id int64) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete alert rule %d: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetAlertRule returns one rule; ok is false when it does not exist.
+func (s *PostgresStore) GetAlertRule(ctx context.Context, id int64) (AlertRule, bool, error) {
+	rule, err := scanAlertRule(s.pool.QueryRow(ctx, `SELECT`+alertRuleColumns+` FROM alert_rules WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return AlertRule{}, false, nil
+		}
+		return AlertRule{}, false, fmt.Errorf("get alert rule %d: %w", id, err)
+	}
+	return rule, true, nil
+}
+
+// ListAlertRules returns the rules, oldest first, optionally only the
+// enabled ones.
+func (s *PostgresStore) ListAlertRules(ctx context.Context, enabledOnly bool) ([]AlertRule, error) {
+	rows, err := s.pool.Query(ctx, `SELECT`+alertRuleColumns+` FROM alert_rules WHERE enabled OR NOT $1 ORDER BY id`, enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("list alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	result := []AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert rule: %w", err)
+		}
+		result = append(result, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate alert rules: %w", err)
+	}
+	return result, nil
+}
+
+// InsertAlertDelivery queues an alert for delivery. inserted is false when
+// the rule already has a delivery with the same dedupe key.
+func (s *PostgresStore) InsertAlertDelivery(ctx context.Context, delivery AlertDelivery) (AlertDelivery, bool, error) {
+	const query = `
+INSERT INTO alert_deliveries (rule_id, dedupe_key, payload)
+VALUES ($1, $2, $3)
+ON CONFLICT (rule_id, dedupe_key) DO NOTHING
+RETURNING` + alertDeliveryColumns
+
+	created, err := scanAlertDelivery(s.pool.QueryRow(ctx, query, delivery.RuleID, delivery.DedupeKey, delivery.Payload))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return AlertDelivery{}, false, nil
+		}
+		return AlertDelivery{}, false, fmt.Errorf("insert alert delivery: %w", err)
+	}
+	return created, true, nil
+}
+
+// ListDueAlertDeliveries returns up to limit pending deliveries whose next
+// attempt is due, oldest first.
+func (s *PostgresStore) ListDueAlertDeliveries(ctx context.Context, now time.Time, limit int) ([]AlertDelivery, error) {
+	const query = `
+SELECT` + alertDeliveryColumns + `
+FROM alert_deliveries
+WHERE status = 'pending' AND next_attempt_at <= $1
+ORDER BY next_attempt_at, id
+LIMIT $2`
+
+	return s.queryAlertDeliveries(ctx, query, now, limit)
+}
+
+// ListAlertDeliveries returns the delivery log, newest first. A zero ruleID
+// or empty status matches every rule or status.
+func (s *PostgresStore) ListAlertDeliveries(ctx context.Context, ruleID int64, status string, limit int) ([]AlertDelivery, error) {
+	const query = `
+SELECT` + alertDeliveryColumns + `
+FROM alert_deliveries
+WHERE ($1 = 0 OR rule_id = $1) AND ($2 = '' OR status = $2)
+ORDER BY id DESC
+LIMIT NULLIF($3, 0)`
+
+	return s.queryAlertDeliveries(ctx, query, ruleID, status, limit)
+}
+
+// SaveAlertDeliveryAttempt records the outcome of a delivery attempt.
+func (s *PostgresStore) SaveAlertDeliveryAttempt(ctx context.Context, delivery AlertDelivery) error {
+	const query = `
+UPDATE alert_deliveries SET
+  status = $2,
+  attempts = $3,
+  response_status = $4,
+  last_error = $5,
+  next_attempt_at = $6,
+  delivered_at = $7
+WHERE id = $1`
+
+	_, err := s.pool.Exec(ctx, query,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.DeliveredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save alert delivery %d: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// RetryAlertDelivery queues a delivery for another attempt now and reports
+// whether it exists.
+func (s *PostgresStore) RetryAlertDelivery(ctx context.Context, id int64) (bool, error) {
+	const query = `
+UPDATE alert_deliveries SET status = 'pending', next_attempt_at = NOW()
+WHERE id = $1`
+
+	tag, err := s.pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("retry alert delivery %d: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *PostgresStore) queryAlertDeliveries(ctx context.Context, query string, args ...any) ([]AlertDelivery, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list alert deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	result := []AlertDelivery{}
+	for rows.Next() {
+		delivery, err := scanAlertDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert delivery: %w", err)
+		}
+		result = append(result, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate alert deliveries: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,407 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultWalletPageSize is the page size QueryWallets uses without a limit.
+const defaultWalletPageSize = 50
+
+// CatalogWallet is a tracked wallet in one sport with its leaderboard data
+// and, once the sync has profiled it, its stored profile.
+type CatalogWallet struct {
+	WalletAddress     string          `json:"wallet_address"`
+	Sport             string          `json:"sport"`
+	DisplayName       string          `json:"display_name"`
+	Source            string          `json:"source"`
+	SourceRank        int             `json:"source_rank"`
+	SourcePredictions int             `json:"source_predictions"`
+	SourceWins        int             `json:"source_wins"`
+	VolumeUSD         float64         `json:"volume_usd"`
+	LossUSD           float64         `json:"loss_usd"`
+	WinRate           float64         `json:"win_rate"`
+	OpenPositionsUSD  float64         `json:"open_positions_usd"`
+	PnlUSD            float64         `json:"pnl_usd"`
+	ROI               float64         `json:"roi"`
+	LastSeenAt        time.Time       `json:"last_seen_at"`
+	Profile           *CatalogProfile `json:"profile,omitempty"`
+}
+
+// CatalogProfile is the stored wallet_profiles row of a catalog wallet.
+type CatalogProfile struct {
+	SportTrades             int        `json:"sport_trades"`
+	RecentMarkets           int        `json:"recent_markets"`
+	EntryTimingHours        float64    `json:"entry_timing_hours"`
+	SizeRatioPct            float64    `json:"size_ratio_pct"`
+	Conviction              float64    `json:"conviction"`
+	DeterministicStyleLabel string     `json:"deterministic_style_label"`
+	StyleLabel              string     `json:"style_label"`
+	StyleSummary            string     `json:"style_summary"`
+	ExplanationSource       string     `json:"explanation_source"`
+	Model                   string     `json:"model"`
+	PresentationScore       float64    `json:"presentation_score"`
+	AnalyzedAt              *time.Time `json:"analyzed_at,omitempty"`
+}
+
+// WalletSort selects the metric QueryWallets orders by.
+type WalletSort string
+
+const (
+	WalletSortRank        WalletSort = "rank"
+	WalletSortPnl         WalletSort = "pnl"
+	WalletSortWinRate     WalletSort = "win_rate"
+	WalletSortVolume      WalletSort = "volume"
+	WalletSortROI         WalletSort = "roi"
+	WalletSortTrades      WalletSort = "trades"
+	WalletSortEntryTiming WalletSort = "entry_timing"
+	WalletSortSizeRatio   WalletSort = "size_ratio"
+	WalletSortConviction  WalletSort = "conviction"
+	WalletSortScore       WalletSort = "score"
+	WalletSortAnalyzedAt  WalletSort = "analyzed_at"
+)
+
+// walletSortSpec is how one sort metric is computed: expr over tracked
+// wallets (tw) and profiles (wp) for Postgres, value for the file store.
+// Both yield zero for wallets without a profile, so every row has a key a
+// cursor can hold.
+type walletSortSpec struct {
+	expr        string
+	value       func(CatalogWallet) float64
+	descDefault bool
+}
+
+var walletSorts = map[WalletSort]walletSortSpec{
+	WalletSortRank: {
+		expr:  "tw.source_rank::double precision",
+		value: func(w CatalogWallet) float64 { return float64(w.SourceRank) },
+	},
+	WalletSortPnl: {
+		expr:        "tw.pnl_usd",
+		value:       func(w CatalogWallet) float64 { return w.PnlUSD },
+		descDefault: true,
+	},
+	WalletSortWinRate: {
+		expr:        "tw.win_rate",
+		value:       func(w CatalogWallet) float64 { return w.WinRate },
+		descDefault: true,
+	},
+	WalletSortVolume: {
+		expr:        "tw.volume_usd",
+		value:       func(w CatalogWallet) float64 { return w.VolumeUSD },
+		descDefault: true,
+	},
+	WalletSortROI: {
+		expr:        "CASE WHEN tw.volume_usd > 0 THEN tw.pnl_usd / tw.volume_usd ELSE 0 END",
+		value:       func(w CatalogWallet) float64 { return w.ROI },
+		descDefault: true,
+	},
+	WalletSortTrades: {
+		expr:        "COALESCE(wp.sport_trades, 0)::double precision",
+		value:       profileValue(func(p CatalogProfile) float64 { return float64(p.SportTrades) }),
+		descDefault: true,
+	},
+	WalletSortEntryTiming: {
+		expr:        "COALESCE(wp.entry_timing_hours, 0)",
+		value:       profileValue(func(p CatalogProfile) float64 { return p.EntryTimingHours }),
+		descDefault: true,
+	},
+	WalletSortSizeRatio: {
+		expr:        "COALESCE(wp.size_ratio_pct, 0)",
+		value:       profileValue(func(p CatalogProfile) float64 { return p.SizeRatioPct }),
+		descDefault: true,
+	},
+	WalletSortConviction: {
+		expr:        "COALESCE(wp.conviction, 0)",
+		value:       profileValue(func(p CatalogProfile) float64 { return p.Conviction }),
+		descDefault: true,
+	},
+	WalletSortScore: {
+		expr:        "COALESCE(wp.presentation_score, 0)",
+		value:       profileValue(func(p CatalogProfile) float64 { return p.PresentationScore }),
+		descDefault: true,
+	},
+	WalletSortAnalyzedAt: {
+		// Microseconds since the epoch: exact in a double and matching the
+		// precision Postgres stores.
+		expr: "COALESCE(EXTRACT(EPOCH FROM wp.analyzed_at) * 1000000, 0)::double precision",
+		value: profileValue(func(p CatalogProfile) float64 {
+			if p.AnalyzedAt == nil {
+				return 0
+			}
+			return float64(p.AnalyzedAt.UnixMicro())
+		}),
+		descDefault: true,
+	},
+}
+
+func profileValue(value func(CatalogProfile) float64) func(CatalogWallet) float64 {
+	return func(w CatalogWallet) float64 {
+		if w.Profile == nil {
+			return 0
+		}
+		return value(*w.Profile)
+	}
+}
+
+// Valid reports whether s is a supported sort metric.
+func (s WalletSort) Valid() bool {
+	_, ok := walletSorts[s]
+	return ok
+}
+
+// DefaultDescending reports whether s sorts largest first by default: true
+// for every metric but rank.
+func (s WalletSort) DefaultDescending() bool {
+	return walletSorts[s].descDefault
+}
+
+// WalletCursor marks the last wallet of a page. It carries the sort it was
+// issued for, so a client cannot resume one ordering with another's cursor.
+type WalletCursor struct {
+	Sort          WalletSort `json:"sort"`
+	Descending    bool       `json:"desc"`
+	Value         float64    `json:"value"`
+	WalletAddress string     `json:"wallet"`
+	Sport         string     `json:"sport"`
+}
+
+// Encode returns the cursor as an opaque URL-safe token.
+func (c WalletCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeWalletCursor parses a token made by WalletCursor.Encode.
+func DecodeWalletCursor(token string) (WalletCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return WalletCursor{}, fmt.Errorf("decode wallet cursor: %w", err)
+	}
+	var cursor WalletCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return WalletCursor{}, fmt.Errorf("decode wallet cursor: %w", err)
+	}
+	if !cursor.Sort.Valid() {
+		return WalletCursor{}, fmt.Errorf("decode wallet cursor: unsupported sort %q", cursor.Sort)
+	}
+	return cursor, nil
+}
+
+// WalletQuery filters, orders and pages QueryWallets. Empty strings, nil
+// bounds and a zero AnalyzedSince are ignored. Filters on profile fields
+// leave out wallets that have not been profiled. Ties in the sort metric
+// are broken by wallet address, then sport.
+type WalletQuery struct {
+	Sport         string
+	StyleLabel    string
+	Search        string
+	MinTrades     int
+	MinPnlUSD     *float64
+	MaxPnlUSD     *float64
+	MinWinRate    *float64
+	MaxWinRate    *float64
+	AnalyzedSince time.Time
+	Sort          WalletSort
+	Descending    bool
+	After         *WalletCursor
+	Limit         int
+}
+
+// normalize fills in the query defaults and checks the sort.
+func (q WalletQuery) normalize() (WalletQuery, walletSortSpec, error) {
+	if q.Sort == "" {
+		q.Sort = WalletSortRank
+	}
+	spec, ok := walletSorts[q.Sort]
+	if !ok {
+		return q, walletSortSpec{}, fmt.Errorf("unsupported wallet sort: %s", q.Sort)
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultWalletPageSize
+	}
+	q.Sport = strings.ToLower(q.Sport)
+	q.StyleLabel = strings.ToLower(q.StyleLabel)
+	q.Search = strings.ToLower(q.Search)
+	return q, spec, nil
+}
+
+// nextCursor is the cursor after the last of wallets, whose sort values are
+// in values.
+func (q WalletQuery) nextCursor(wallets []CatalogWallet, values []float64) *WalletCursor {
+	last := len(wallets) - 1
+	return &WalletCursor{
+		Sort:          q.Sort,
+		Descending:    q.Descending,
+		Value:         values[last],
+		WalletAddress: wallets[last].WalletAddress,
+		Sport:         wallets[last].Sport,
+	}
+}
+
+const catalogWalletColumns = `
+  tw.wallet_address, tw.sport, tw.display_name, tw.source, tw.source_rank, tw.source_predictions,
+  tw.source_wins, tw.volume_usd, tw.loss_usd, tw.win_rate, tw.open_positions_usd, tw.pnl_usd,
+  tw.last_seen_at, wp.wallet_address IS NOT NULL,
+  COALESCE(wp.sport_trades, 0), COALESCE(wp.recent_markets, 0), COALESCE(wp.entry_timing_hours, 0),
+  COALESCE(wp.size_ratio_pct, 0), COALESCE(wp.conviction, 0), COALESCE(wp.deterministic_style_label, ''),
+  COALESCE(wp.ai_style_label, ''), COALESCE(wp.ai_style_summary, ''), COALESCE(wp.explanation_source, ''),
+  COALESCE(wp.model, ''), COALESCE(wp.presentation_score, 0), wp.analyzed_at`
+
+const catalogWalletSource = `
+FROM tracked_wallets tw
+LEFT JOIN wallet_profiles wp ON wp.wallet_address = tw.wallet_address AND wp.sport = tw.sport`
+
+// scanCatalogWallet reads catalogWalletColumns followed by any extra
+// destinations.
+func scanCatalogWallet(row rowScanner, extra ...any) (CatalogWallet, error) {
+	var (
+		w          CatalogWallet
+		p          CatalogProfile
+		hasProfile bool
+	)
+	dest := []any{
+		&w.WalletAddress, &w.Sport, &w.DisplayName, &w.Source, &w.SourceRank, &w.SourcePredictions,
+		&w.SourceWins, &w.VolumeUSD, &w.LossUSD, &w.WinRate, &w.OpenPositionsUSD, &w.PnlUSD,
+		&w.LastSeenAt, &hasProfile,
+		&p.SportTrades, &p.RecentMarkets, &p.EntryTimingHours,
+		&p.SizeRatioPct, &p.Conviction, &p.DeterministicStyleLabel,
+		&p.StyleLabel, &p.StyleSummary, &p.ExplanationSource,
+		&p.Model, &p.PresentationScore, &p.AnalyzedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return CatalogWallet{}, err
+	}
+	w.ROI = TrackedWallet{PnlUSD: w.PnlUSD, VolumeUSD: w.VolumeUSD}.ROI()
+	if hasProfile {
+		w.Profile = &p
+	}
+	return w, nil
+}
+
+// QueryWallets returns one page of the wallet catalog, one row per wallet
+// and sport. next is nil on the last page.
+func (s *PostgresStore) QueryWallets(ctx context.Context, query WalletQuery) ([]CatalogWallet, *WalletCursor, error) {
+	query, spec, err := query.normalize()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if query.Sport != "" {
+		conditions = append(conditions, "tw.sport = "+arg(query.Sport))
+	}
+	if query.StyleLabel != "" {
+		conditions = append(conditions, "LOWER(wp.ai_style_label) = "+arg(query.StyleLabel))
+	}
+	if query.Search != "" {
+		search := arg(query.Search)
+		conditions = append(conditions, fmt.Sprintf(
+			"(strpos(LOWER(tw.display_name), %[1]s) > 0 OR strpos(LOWER(tw.wallet_address), %[1]s) > 0)", search))
+	}
+	if query.MinTrades > 0 {
+		conditions = append(conditions, "wp.sport_trades >= "+arg(query.MinTrades))
+	}
+	if query.MinPnlUSD != nil {
+		conditions = append(conditions, "tw.pnl_usd >= "+arg(*query.MinPnlUSD))
+	}
+	if query.MaxPnlUSD != nil {
+		conditions = append(conditions, "tw.pnl_usd <= "+arg(*query.MaxPnlUSD))
+	}
+	if query.MinWinRate != nil {
+		conditions = append(conditions, "tw.win_rate >= "+arg(*query.MinWinRate))
+	}
+	if query.MaxWinRate != nil {
+		conditions = append(conditions, "tw.win_rate <= "+arg(*query.MaxWinRate))
+	}
+	if !query.AnalyzedSince.IsZero() {
+		conditions = append(conditions, "wp.analyzed_at >= "+arg(query.AnalyzedSince))
+	}
+
+	direction, op := "ASC", ">"
+	if query.Descending {
+		direction, op = "DESC", "<"
+	}
+	if after := query.After; after != nil {
+		value := arg(after.Value) + "::double precision"
+		conditions = append(conditions, fmt.Sprintf(
+			"(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND (tw.wallet_address, tw.sport) > (%[4]s::text, %[5]s::text)))",
+			spec.expr, op, value, arg(after.WalletAddress), arg(after.Sport)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "\nWHERE " + strings.Join(conditions, "\n  AND ")
+	}
+	sql := fmt.Sprintf(`
+SELECT%s,
+  %s AS sort_value%s%s
+ORDER BY sort_value %s, tw.wallet_address, tw.sport
+LIMIT %s`, catalogWalletColumns, spec.expr, catalogWalletSource, where, direction, arg(query.Limit+1))
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query wallets: %w", err)
+	}
+	defer rows.Close()
+
+	wallets := []CatalogWallet{}
+	values := []float64{}
+	for rows.Next() {
+		var value float64
+		wallet, err := scanCatalogWallet(rows, &value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("scan catalog wallet: %w", err)
+		}
+		wallets = append(wallets, wallet)
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate catalog wallets: %w", err)
+	}
+
+	if len(wallets) <= query.Limit {
+		return wallets, nil, nil
+	}
+	wallets, values = wallets[:query.Limit], values[:query.Limit]
+	return wallets, query.nextCursor(wallets, values), nil
+}
+
+// GetCatalogWallet returns a wallet's catalog rows, one per sport it is
+// tracked for, by sport. It is empty when the wallet is not tracked.
+func (s *PostgresStore) GetCatalogWallet(ctx context.Context, wallet string) ([]CatalogWallet, error) {
+	sql := `
+SELECT` + catalogWalletColumns + catalogWalletSource + `
+WHERE LOWER(tw.wallet_address) = $1
+ORDER BY tw.sport`
+
+	rows, err := s.pool.Query(ctx, sql, strings.ToLower(wallet))
+	if err != nil {
+		return nil, fmt.Errorf("get catalog wallet %s: %w", wallet, err)
+	}
+	defer rows.Close()
+
+	result := []CatalogWallet{}
+	for rows.Next() {
+		entry, err := scanCatalogWallet(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan catalog wallet: %w", err)
+		}
+		result = append(result, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate catalog wallets: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+// seedCatalog tracks 0x0a in two sports and four more wallets, two of them
+// without profiles, with ties in rank, PnL and trades.
+func seedCatalog(t *testing.T) *FileStore {
+	t.Helper()
+	ctx := context.Background()
+	store := openMemory(t)
+	early := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	late := early.Add(36 * time.Hour)
+
+	err := store.UpsertTrackedWallets(ctx, []TrackedWallet{
+		{WalletAddress: "0x0a", Sport: "nba", DisplayName: "Alpha", SourceRank: 1, PnlUSD: 500, VolumeUSD: 1000, WinRate: 0.6},
+		{WalletAddress: "0x0b", Sport: "nba", DisplayName: "Bravo", SourceRank: 2, PnlUSD: 500, VolumeUSD: 2000, WinRate: 0.55},
+		{WalletAddress: "0x0c", Sport: "nba", DisplayName: "Charlie", SourceRank: 2, PnlUSD: -50, VolumeUSD: 500, WinRate: 0.4},
+		{WalletAddress: "0x0d", Sport: "nba", DisplayName: "Delta", SourceRank: 4},
+		{WalletAddress: "0x0a", Sport: "nfl", DisplayName: "Alpha", SourceRank: 3, PnlUSD: 200, VolumeUSD: 400, WinRate: 0.7},
+		{WalletAddress: "0x0e", Sport: "nfl", DisplayName: "Echo", SourceRank: 1, PnlUSD: 300, VolumeUSD: 300, WinRate: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("UpsertTrackedWallets: %v", err)
+	}
+	for _, profile := range []WalletProfile{
+		{WalletAddress: "0x0a", Sport: "nba", SportTrades: 40, EntryTimingHours: 2.5, SizeRatioPct: 10, Conviction: 0.8, AIStyleLabel: "Sharp", PresentationScore: 90, AnalyzedAt: early},
+		{WalletAddress: "0x0b", Sport: "nba", SportTrades: 40, EntryTimingHours: 5, SizeRatioPct: 10, Conviction: 0.5, AIStyleLabel: "Whale", PresentationScore: 70, AnalyzedAt: late},
+		{WalletAddress: "0x0c", Sport: "nba", SportTrades: 5, EntryTimingHours: 1, SizeRatioPct: 30, Conviction: 0.9, PresentationScore: 20},
+		{WalletAddress: "0x0a", Sport: "nfl", SportTrades: 40, EntryTimingHours: 12, SizeRatioPct: 5, Conviction: 0.3, AIStyleLabel: "sharp", PresentationScore: 60, AnalyzedAt: early},
+	} {
+		if err := store.UpsertWalletProfile(ctx, profile); err != nil {
+			t.Fatalf("UpsertWalletProfile: %v", err)
+		}
+	}
+	return store
+}
+
+func catalogKeys(wallets []CatalogWallet) []string {
+	return walletAddresses(wallets, func(w CatalogWallet) string { return w.WalletAddress + "|" + w.Sport })
+}
+
+// pageAll follows QueryWallets cursors, through their encoded tokens, until
+// the last page.
+func pageAll(t *testing.T, store *FileStore, query WalletQuery) []CatalogWallet {
+	t.Helper()
+	var all []CatalogWallet
+	for pages := 0; ; pages++ {
+		if pages > 20 {
+			t.Fatalf("QueryWallets(%+v) did not reach the last page", query)
+		}
+		page, next, err := store.QueryWallets(context.Background(), query)
+		if err != nil {
+			t.Fatalf("QueryWallets: %v", err)
+		}
+		if len(page) > query.Limit && query.Limit > 0 {
+			t.Fatalf("QueryWallets returned %d wallets, limit %d", len(page), query.Limit)
+		}
+		all = append(all, page...)
+		if next == nil {
+			return all
+		}
+		sortKey := query.Sort
+		if sortKey == "" {
+			sortKey = WalletSortRank
+		}
+		if next.Sort != sortKey || next.Descending != query.Descending {
+			t.Fatalf("cursor %+v does not match query sort %s desc %v", next, sortKey, query.Descending)
+		}
+		cursor, err := DecodeWalletCursor(next.Encode())
+		if err != nil {
+			t.Fatalf("DecodeWalletCursor: %v", err)
+		}
+		query.After = &cursor
+	}
+}
+
+func TestQueryWalletsOrder(t *testing.T) {
+	store := seedCatalog(t)
+	tests := []struct {
+		name  string
+		query WalletQuery
+		want  []string
+	}{
+		{
+			name:  "rank by default",
+			query: WalletQuery{},
+			want:  []string{"0x0a|nba", "0x0e|nfl", "0x0b|nba", "0x0c|nba", "0x0a|nfl", "0x0d|nba"},
+		},
+		{
+			// Ties stay in address order when descending.
+			name:  "rank descending",
+			query: WalletQuery{Sort: WalletSortRank, Descending: true},
+			want:  []string{"0x0d|nba", "0x0a|nfl", "0x0b|nba", "0x0c|nba", "0x0a|nba", "0x0e|nfl"},
+		},
+		{
+			name:  "pnl",
+			query: WalletQuery{Sort: WalletSortPnl, Descending: true},
+			want:  []string{"0x0a|nba", "0x0b|nba", "0x0e|nfl", "0x0a|nfl", "0x0d|nba", "0x0c|nba"},
+		},
+		{
+			// A wallet tied with itself in two sports is ordered by sport;
+			// unprofiled wallets sort as zero.
+			name:  "trades",
+			query: WalletQuery{Sort: WalletSortTrades, Descending: true},
+			want:  []string{"0x0a|nba", "0x0a|nfl", "0x0b|nba", "0x0c|nba", "0x0d|nba", "0x0e|nfl"},
+		},
+		{
+			name:  "analyzed at ascending",
+			query: WalletQuery{Sort: WalletSortAnalyzedAt},
+			want:  []string{"0x0c|nba", "0x0d|nba", "0x0e|nfl", "0x0a|nba", "0x0a|nfl", "0x0b|nba"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, limit := range []int{0, 1, 4} {
+				query := tt.query
+				query.Limit = limit
+				if got := catalogKeys(pageAll(t, store, query)); !equalStrings(got, tt.want) {
+					t.Errorf("limit %d: wallets = %v, want %v", limit, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestQueryWalletsCursorEverySort pages every sort both ways and checks the
+// pages add up to the single-page result, in order and without repeats.
+func TestQueryWalletsCursorEverySort(t *testing.T) {
+	ctx := context.Background()
+	store := seedCatalog(t)
+
+	for sortKey, spec := range walletSorts {
+		for _, desc := range []bool{false, true} {
+			full, next, err := store.QueryWallets(ctx, WalletQuery{Sort: sortKey, Descending: desc})
+			if err != nil {
+				t.Fatalf("%s desc %v: QueryWallets: %v", sortKey, desc, err)
+			}
+			if next != nil || len(full) != 6 {
+				t.Fatalf("%s desc %v: single page = %v, %+v; want all 6 wallets and no cursor", sortKey, desc, catalogKeys(full), next)
+			}
+			query := WalletQuery{Sort: sortKey, Descending: desc}
+			ordered := sort.SliceIsSorted(full, func(i, j int) bool {
+				a, b := full[i], full[j]
+				return query.walletBefore(spec.value(a), a.WalletAddress, a.Sport, spec.value(b), b.WalletAddress, b.Sport)
+			})
+			if !ordered {
+				t.Errorf("%s desc %v: wallets %v out of order", sortKey, desc, catalogKeys(full))
+			}
+
+			for _, limit := range []int{1, 2, 5} {
+				query.Limit = limit
+				paged := catalogKeys(pageAll(t, store, query))
+				if want := catalogKeys(full); !equalStrings(paged, want) {
+					t.Errorf("%s desc %v limit %d: paged %v, want %v", sortKey, desc, limit, paged, want)
+				}
+			}
+		}
+	}
+}
+
+func TestQueryWalletsFilters(t *testing.T) {
+	store := seedCatalog(t)
+	float := func(v float64) *float64 { return &v }
+	since := time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query WalletQuery
+		want  []string
+	}{
+		{name: "sport", query: WalletQuery{Sport: "NBA"}, want: []string{"0x0a|nba", "0x0b|nba", "0x0c|nba", "0x0d|nba"}},
+		{name: "style label", query: WalletQuery{StyleLabel: "SHARP"}, want: []string{"0x0a|nba", "0x0a|nfl"}},
+		{name: "search by name", query: WalletQuery{Search: "ECH"}, want: []string{"0x0e|nfl"}},
+		{name: "search by address", query: WalletQuery{Search: "0x0C"}, want: []string{"0x0c|nba"}},
+		{name: "min trades", query: WalletQuery{MinTrades: 10}, want: []string{"0x0a|nba", "0x0b|nba", "0x0a|nfl"}},
+		{name: "pnl range", query: WalletQuery{MinPnlUSD: float(0), MaxPnlUSD: float(300)}, want: []string{"0x0e|nfl", "0x0a|nfl", "0x0d|nba"}},
+		{name: "win rate range", query: WalletQuery{MinWinRate: float(0.5), MaxWinRate: float(0.6)}, want: []string{"0x0a|nba", "0x0e|nfl", "0x0b|nba"}},
+		{name: "analyzed since", query: WalletQuery{AnalyzedSince: since}, want: []string{"0x0b|nba"}},
+		{
+			name:  "filters with another sort",
+			query: WalletQuery{Sport: "nba", MinTrades: 1, Sort: WalletSortConviction, Descending: true},
+			want:  []string{"0x0c|nba", "0x0a|nba", "0x0b|nba"},
+		},
+		{name: "nothing matches", query: WalletQuery{Sport: "mlb"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, limit := range []int{1, 2, 50} {
+				query := tt.query
+				query.Limit = limit
+				if got := catalogKeys(pageAll(t, store, query)); !equalStrings(got, tt.want) {
+					t.Errorf("limit %d: wallets = %v, want %v", limit, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWalletCursorEncoding(t *testing.T) {
+	cursor := WalletCursor{
+		Sort:          WalletSortAnalyzedAt,
+		Descending:    true,
+		Value:         float64(time.Date(2026, 2, 1, 9, 0, 0, 123456000, time.UTC).UnixMicro()),
+		WalletAddress: "0x0a",
+		Sport:         "nba",
+	}
+	got, err := DecodeWalletCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodeWalletCursor: %v", err)
+	}
+	if got != cursor {
+		t.Errorf("DecodeWalletCursor(Encode()) = %+v, want %+v", got, cursor)
+	}
+
+	for _, token := range []string{
+		"",
+		"not a cursor",
+		"bm90IGpzb24",          // not json
+		"eyJzb3J0IjoibHVjayJ9", // {"sort":"luck"}
+		WalletCursor{Sort: "luck"}.Encode(),
+	} {
+		if _, err := DecodeWalletCursor(token); err == nil {
+			t.Errorf("DecodeWalletCursor(%q) succeeded", token)
+		}
+	}
+}
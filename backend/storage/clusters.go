@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type StyleClusterRun struct {
+	ID          int64     `json:"id"`
+	Sport       string    `json:"sport"`
+	K           int       `json:"k"`
+	Features    []string  `json:"features"`
+	Silhouette  float64   `json:"silhouette"`
+	Inertia     float64   `json:"inertia"`
+	WalletCount int       `json:"wallet_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type StyleCluster struct {
+	ClusterID  int                `json:"cluster_id"`
+	Label      string             `json:"label"`
+	Centroid   map[string]float64 `json:"centroid"`
+	Size       int                `json:"size"`
+	Silhouette float64            `json:"silhouette"`
+}
+
+type WalletCluster struct {
+	WalletAddress string  `json:"wallet_address"`
+	ClusterID     int     `json:"cluster_id"`
+	Distance      float64 `json:"distance"`
+}
+
+// SaveStyleClusterRun records a clustering run and replaces the current
+// cluster assignment of every wallet in the run's sport.
+func (s *PostgresStore) SaveStyleClusterRun(ctx context.Context, run StyleClusterRun, clusters []StyleCluster, assignments []WalletCluster) (int64, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin style cluster run: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var runID int64
+	err = tx.QueryRow(ctx, `
+INSERT INTO style_cluster_runs (sport, k, features, silhouette, inertia, wallet_count)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id`,
+		run.Sport, run.K, run.Features, run.Silhouette, run.Inertia, run.WalletCount,
+	).Scan(&runID)
+	if err != nil {
+		return 0, fmt.Errorf("insert style cluster run: %w", err)
+	}
+
+	for _, cluster := range clusters {
+		centroid, err := json.Marshal(cluster.Centroid)
+		if err != nil {
+			return 0, fmt.Errorf("marshal centroid %d: %w", cluster.ClusterID, err)
+		}
+		if _, err := tx.Exec(ctx, `
+INSERT INTO style_clusters (run_id, cluster_id, label, centroid, size, silhouette)
+VALUES ($1, $2, $3, $4::jsonb, $5, $6)`,
+			runID, cluster.ClusterID, cluster.Label, string(centroid), cluster.Size, cluster.Silhouette,
+		); err != nil {
+			return 0, fmt.Errorf("insert style cluster %d: %w", cluster.ClusterID, err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM wallet_style_clusters WHERE sport = $1`, run.Sport); err != nil {
+		return 0, fmt.Errorf("clear wallet style clusters: %w", err)
+	}
+	for _, assignment := range assignments {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO wallet_style_clusters (wallet_address, sport, run_id, cluster_id, distance, assigned_at)
+VALUES ($1, $2, $3, $4, $5, NOW())`,
+			assignment.WalletAddress, run.Sport, runID, assignment.ClusterID, assignment.Distance,
+		); err != nil {
+			return 0, fmt.Errorf("assign wallet %s to cluster: %w", assignment.WalletAddress, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit style cluster run: %w", err)
+	}
+	return runID, nil
+}
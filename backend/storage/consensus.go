@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MarketConsensus is where the top tracked wallets of a sport stand in one
+// upcoming game market, compared with the market price.
+type MarketConsensus struct {
+	Sport                string     `json:"sport"`
+	ConditionID          string     `json:"condition_id"`
+	GameID               string     `json:"game_id"`
+	GameTitle            string     `json:"game_title"`
+	ScheduledStart       *time.Time `json:"scheduled_start,omitempty"`
+	Question             string     `json:"question"`
+	ConsensusOutcome     string     `json:"consensus_outcome"`
+	ConsensusProbability float64    `json:"consensus_probability"`
+	MarketPrice          float64    `json:"market_price"`
+	Divergence           float64    `json:"divergence"`
+	AgreementRatio       float64    `json:"agreement_ratio"`
+	AvgEntryPrice        float64    `json:"avg_entry_price"`
+	Wallets              int        `json:"wallets"`
+	StakeUSD             float64    `json:"stake_usd"`
+	ComputedAt           time.Time  `json:"computed_at"`
+}
+
+// ReplaceMarketConsensus swaps the stored consensus of a sport for rows.
+func (s *PostgresStore) ReplaceMarketConsensus(ctx context.Context, sport string, rows []MarketConsensus) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin market consensus replace: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sport = strings.ToLower(sport)
+	if _, err := tx.Exec(ctx, `DELETE FROM market_consensus WHERE sport = $1`, sport); err != nil {
+		return fmt.Errorf("clear market consensus: %w", err)
+	}
+
+	const query = `
+INSERT INTO market_consensus (
+  sport, condition_id, game_id, game_title, scheduled_start, question, consensus_outcome,
+  consensus_probability, market_price, divergence, agreement_ratio, avg_entry_price,
+  wallets, stake_usd, computed_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NOW())`
+
+	for _, row := range rows {
+		if _, err := tx.Exec(ctx, query,
+			sport, row.ConditionID, row.GameID, row.GameTitle, row.ScheduledStart, row.Question, row.ConsensusOutcome,
+			row.ConsensusProbability, row.MarketPrice, row.Divergence, row.AgreementRatio, row.AvgEntryPrice,
+			row.Wallets, row.StakeUSD,
+		); err != nil {
+			return fmt.Errorf("insert market consensus %s: %w", row.ConditionID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit market consensus replace: %w", err)
+	}
+	return nil
+}
+
+// ListMarketConsensus returns the consensus rows of sports for games that
+// have not started, largest divergence from the market price first. A
+// market computed for several of the sports is listed once, from the first.
+func (s *PostgresStore) ListMarketConsensus(ctx context.Context, sports []string, limit int) ([]MarketConsensus, error) {
+	const query = `
+SELECT
+  sport, condition_id, game_id, game_title, scheduled_start, question, consensus_outcome,
+  consensus_probability, market_price, divergence, agreement_ratio, avg_entry_price,
+  wallets, stake_usd, computed_at
+FROM (
+  SELECT DISTINCT ON (condition_id) *
+  FROM market_consensus
+  WHERE sport = ANY($1)
+    AND (scheduled_start IS NULL OR scheduled_start > NOW())
+  ORDER BY condition_id, array_position($1::text[], sport)
+) consensus
+ORDER BY ABS(divergence) DESC, stake_usd DESC
+LIMIT $2`
+
+	rows, err := s.pool.Query(ctx, query, lowerAll(sports), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list market consensus: %w", err)
+	}
+	defer rows.Close()
+
+	result := []MarketConsensus{}
+	for rows.Next() {
+		var row MarketConsensus
+		if err := rows.Scan(
+			&row.Sport,
+			&row.ConditionID,
+			&row.GameID,
+			&row.GameTitle,
+			&row.ScheduledStart,
+			&row.Question,
+			&row.ConsensusOutcome,
+			&row.ConsensusProbability,
+			&row.MarketPrice,
+			&row.Divergence,
+			&row.AgreementRatio,
+			&row.AvgEntryPrice,
+			&row.Wallets,
+			&row.StakeUSD,
+			&row.ComputedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan market consensus: %w", err)
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate market consensus: %w", err)
+	}
+	return result, nil
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
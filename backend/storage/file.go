@@ -0,0 +1,360 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileFormatVersion is written into every file store so a newer layout is
+// refused instead of misread.
+const fileFormatVersion = 1
+
+// fileFlushInterval is how often pending writes are persisted.
+const fileFlushInterval = 2 * time.Second
+
+// FileStore is the Store that keeps the whole catalog in memory and, when
+// it has a path, persists it as one JSON file. It is meant for a single
+// local process: writes reach the file within fileFlushInterval and on
+// Close, and nothing coordinates processes sharing a file.
+type FileStore struct {
+	path string
+
+	mu    sync.Mutex
+	data  *fileData
+	dirty bool
+
+	// Lookup indexes over data, rebuilt on load.
+	tradesByWallet map[string][]string
+	tradesByMarket map[string][]string
+	orderKeys      map[string]int64
+	deliveryKeys   map[string]int64
+
+	flushMu   sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// fileData is everything a FileStore holds, laid out as it is persisted.
+// Composite keys are built with fileKey.
+type fileData struct {
+	Version   int              `json:"version"`
+	Sequences map[string]int64 `json:"sequences"`
+
+	TrackedWallets map[string]TrackedWallet        `json:"tracked_wallets"`
+	Profiles       map[string]WalletProfile        `json:"wallet_profiles"`
+	ClusterRuns    map[int64]StyleClusterRun       `json:"style_cluster_runs"`
+	Clusters       map[int64][]StyleCluster        `json:"style_clusters"`
+	WalletClusters map[string]fileWalletCluster    `json:"wallet_style_clusters"`
+	SyncStates     map[string]WalletSyncState      `json:"wallet_sync_state"`
+	Snapshots      map[string][]WalletSnapshot     `json:"wallet_snapshots"`
+	SyncRuns       map[int64]SyncRun               `json:"sync_runs"`
+	Leases         map[string]SchedulerLease       `json:"scheduler_leases"`
+	MarketSports   map[string]MarketSport          `json:"market_sports"`
+	Games          map[string]Game                 `json:"games"`
+	GameMarkets    map[string]fileGameMarket       `json:"game_markets"`
+	Consensus      map[string][]MarketConsensus    `json:"market_consensus"`
+	Trades         map[string]StoredTrade          `json:"trades"`
+	TradeCoverage  map[string]WalletTradeCoverage  `json:"wallet_trade_coverage"`
+	Markets        map[string]MarketRecord         `json:"markets"`
+	Portfolios     map[int64]PaperPortfolio        `json:"paper_portfolios"`
+	Orders         map[int64]PaperOrder            `json:"paper_orders"`
+	Positions      map[string]PaperPosition        `json:"paper_positions"`
+	Equity         map[int64][]PaperEquityPoint    `json:"paper_equity"`
+	Watchlists     map[string]map[string]time.Time `json:"watchlist_wallets"`
+	AlertRules     map[int64]AlertRule             `json:"alert_rules"`
+	Deliveries     map[int64]AlertDelivery         `json:"alert_deliveries"`
+}
+
+type fileWalletCluster struct {
+	WalletAddress string    `json:"wallet_address"`
+	Sport         string    `json:"sport"`
+	RunID         int64     `json:"run_id"`
+	ClusterID     int       `json:"cluster_id"`
+	Distance      float64   `json:"distance"`
+	AssignedAt    time.Time `json:"assigned_at"`
+}
+
+type fileGameMarket struct {
+	GameID string `json:"game_id"`
+	GameMarket
+}
+
+// ensure makes every table that is missing from a loaded file.
+func (d *fileData) ensure() {
+	if d.Sequences == nil {
+		d.Sequences = map[string]int64{}
+	}
+	if d.TrackedWallets == nil {
+		d.TrackedWallets = map[string]TrackedWallet{}
+	}
+	if d.Profiles == nil {
+		d.Profiles = map[string]WalletProfile{}
+	}
+	if d.ClusterRuns == nil {
+		d.ClusterRuns = map[int64]StyleClusterRun{}
+	}
+	if d.Clusters == nil {
+		d.Clusters = map[int64][]StyleCluster{}
+	}
+	if d.WalletClusters == nil {
+		d.WalletClusters = map[string]fileWalletCluster{}
+	}
+	if d.SyncStates == nil {
+		d.SyncStates = map[string]WalletSyncState{}
+	}
+	if d.Snapshots == nil {
+		d.Snapshots = map[string][]WalletSnapshot{}
+	}
+	if d.SyncRuns == nil {
+		d.SyncRuns = map[int64]SyncRun{}
+	}
+	if d.Leases == nil {
+		d.Leases = map[string]SchedulerLease{}
+	}
+	if d.MarketSports == nil {
+		d.MarketSports = map[string]MarketSport{}
+	}
+	if d.Games == nil {
+		d.Games = map[string]Game{}
+	}
+	if d.GameMarkets == nil {
+		d.GameMarkets = map[string]fileGameMarket{}
+	}
+	if d.Consensus == nil {
+		d.Consensus = map[string][]MarketConsensus{}
+	}
+	if d.Trades == nil {
+		d.Trades = map[string]StoredTrade{}
+	}
+	if d.TradeCoverage == nil {
+		d.TradeCoverage = map[string]WalletTradeCoverage{}
+	}
+	if d.Markets == nil {
+		d.Markets = map[string]MarketRecord{}
+	}
+	if d.Portfolios == nil {
+		d.Portfolios = map[int64]PaperPortfolio{}
+	}
+	if d.Orders == nil {
+		d.Orders = map[int64]PaperOrder{}
+	}
+	if d.Positions == nil {
+		d.Positions = map[string]PaperPosition{}
+	}
+	if d.Equity == nil {
+		d.Equity = map[int64][]PaperEquityPoint{}
+	}
+	if d.Watchlists == nil {
+		d.Watchlists = map[string]map[string]time.Time{}
+	}
+	if d.AlertRules == nil {
+		d.AlertRules = map[int64]AlertRule{}
+	}
+	if d.Deliveries == nil {
+		d.Deliveries = map[int64]AlertDelivery{}
+	}
+}
+
+// nextID returns the next value of the named ID sequence.
+func (d *fileData) nextID(table string) int64 {
+	d.Sequences[table]++
+	return d.Sequences[table]
+}
+
+func fileKey(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
+
+// OpenFile opens a file store persisted at path, creating the file when it
+// does not exist. An empty path keeps the store in memory only.
+func OpenFile(path string) (*FileStore, error) {
+	s := &FileStore{path: path, data: &fileData{Version: fileFormatVersion}}
+
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("read file store %s: %w", path, err)
+		default:
+			if err := json.Unmarshal(raw, s.data); err != nil {
+				return nil, fmt.Errorf("decode file store %s: %w", path, err)
+			}
+			if s.data.Version > fileFormatVersion {
+				return nil, fmt.Errorf("file store %s has format version %d, newer than supported %d", path, s.data.Version, fileFormatVersion)
+			}
+			s.data.Version = fileFormatVersion
+		}
+	}
+	s.data.ensure()
+	s.reindex()
+
+	if path != "" {
+		if err := s.flush(); err != nil {
+			return nil, err
+		}
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.flushLoop()
+	}
+	return s, nil
+}
+
+// Close stops background flushing and writes any pending changes.
+func (s *FileStore) Close() {
+	if s == nil {
+		return
+	}
+	s.closeOnce.Do(func() {
+		if s.path == "" {
+			return
+		}
+		close(s.stop)
+		<-s.done
+		if err := s.flush(); err != nil {
+			log.Printf("file store flush failed: %v", err)
+		}
+	})
+}
+
+func (s *FileStore) reindex() {
+	s.tradesByWallet = map[string][]string{}
+	s.tradesByMarket = map[string][]string{}
+	for key, trade := range s.data.Trades {
+		s.tradesByWallet[trade.WalletAddress] = append(s.tradesByWallet[trade.WalletAddress], key)
+		s.tradesByMarket[trade.ConditionID] = append(s.tradesByMarket[trade.ConditionID], key)
+	}
+	s.orderKeys = map[string]int64{}
+	for id, order := range s.data.Orders {
+		s.orderKeys[paperOrderKey(order.PortfolioID, order.SourceTradeKey)] = id
+	}
+	s.deliveryKeys = map[string]int64{}
+	for id, delivery := range s.data.Deliveries {
+		s.deliveryKeys[alertDeliveryKey(delivery.RuleID, delivery.DedupeKey)] = id
+	}
+}
+
+func (s *FileStore) flushLoop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(fileFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			dirty := s.dirty
+			s.mu.Unlock()
+			if !dirty {
+				continue
+			}
+			if err := s.flush(); err != nil {
+				log.Printf("file store flush failed: %v", err)
+			}
+		}
+	}
+}
+
+// flush writes the catalog to a temporary file next to path and renames it
+// over path, so a crash never leaves a half-written store behind.
+func (s *FileStore) flush() (err error) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	raw, err := json.Marshal(s.data)
+	s.dirty = false
+	s.mu.Unlock()
+
+	defer func() {
+		if err != nil {
+			s.mu.Lock()
+			s.dirty = true
+			s.mu.Unlock()
+		}
+	}()
+	if err != nil {
+		return fmt.Errorf("encode file store: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create file store directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create file store temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write file store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync file store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close file store temp file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("replace file store %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// validJSON checks a value bound for a JSONB column, treating empty as the
+// column default.
+func validJSON(raw json.RawMessage, fallback string) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return json.RawMessage(fallback), nil
+	}
+	if !json.Valid(raw) {
+		return nil, errors.New("invalid JSON")
+	}
+	return append(json.RawMessage(nil), raw...), nil
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
+// applyLimit keeps the first limit items, or all of them when limit is not
+// positive, like LIMIT NULLIF($n, 0).
+func applyLimit[T any](items []T, limit int) []T {
+	if limit > 0 && len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
+
+// applyTailLimit keeps the last limit items, or all of them when limit is
+// not positive.
+func applyTailLimit[T any](items []T, limit int) []T {
+	if limit > 0 && len(items) > limit {
+		return items[len(items)-limit:]
+	}
+	return items
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+func alertDeliveryKey(ruleID int64, dedupeKey string) string {
+	return fileKey(fmt.Sprint(ruleID), dedupeKey)
+}
+
+func cloneAlertDelivery(delivery AlertDelivery) AlertDelivery {
+	delivery.DeliveredAt = cloneTime(delivery.DeliveredAt)
+	return delivery
+}
+
+func (s *FileStore) CreateAlertRule(ctx context.Context, rule AlertRule) (AlertRule, error) {
+	params, err := validJSON(rule.Params, "{}")
+	if err != nil {
+		return AlertRule{}, fmt.Errorf("create alert rule: %w", err)
+	}
+	rule.Params = params
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	rule.ID = s.data.nextID("alert_rules")
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	s.data.AlertRules[rule.ID] = rule
+	s.dirty = true
+	return rule, nil
+}
+
+// UpdateAlertRule replaces a rule's settings; ok is false when it does not
+// exist.
+func (s *FileStore) UpdateAlertRule(ctx context.Context, rule AlertRule) (AlertRule, bool, error) {
+	params, err := validJSON(rule.Params, "{}")
+	if err != nil {
+		return AlertRule{}, false, fmt.Errorf("update alert rule %d: %w", rule.ID, err)
+	}
+	rule.Params = params
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.data.AlertRules[rule.ID]
+	if !ok {
+		return AlertRule{}, false, nil
+	}
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now().UTC()
+	s.data.AlertRules[rule.ID] = rule
+	s.dirty = true
+	return rule, true, nil
+}
+
+// DeleteAlertRule removes a rule and its delivery log and reports whether it
+// existed.
+func (s *FileStore) DeleteAlertRule(ctx context.Context, id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.AlertRules[id]; !ok {
+		return false, nil
+	}
+	delete(s.data.AlertRules, id)
+	for deliveryID, delivery := range s.data.Deliveries {
+		if delivery.RuleID == id {
+			delete(s.data.Deliveries, deliveryID)
+			delete(s.deliveryKeys, alertDeliveryKey(delivery.RuleID, delivery.DedupeKey))
+		}
+	}
+	s.dirty = true
+	return true, nil
+}
+
+// GetAlertRule returns one rule; ok is false when it does not exist.
+func (s *FileStore) GetAlertRule(ctx context.Context, id int64) (AlertRule, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, ok := s.data.AlertRules[id]
+	return rule, ok, nil
+}
+
+// ListAlertRules returns the rules, oldest first, optionally only the
+// enabled ones.
+func (s *FileStore) ListAlertRules(ctx context.Context, enabledOnly bool) ([]AlertRule, error) {
+	s.mu.Lock()
+	result := []AlertRule{}
+	for _, rule := range s.data.AlertRules {
+		if rule.Enabled || !enabledOnly {
+			result = append(result, rule)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// InsertAlertDelivery queues an alert for delivery. inserted is false when
+// the rule already has a delivery with the same dedupe key.
+func (s *FileStore) InsertAlertDelivery(ctx context.Context, delivery AlertDelivery) (AlertDelivery, bool, error) {
+	payload, err := validJSON(delivery.Payload, "null")
+	if err != nil {
+		return AlertDelivery{}, false, fmt.Errorf("insert alert delivery: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.AlertRules[delivery.RuleID]; !ok {
+		return AlertDelivery{}, false, fmt.Errorf("insert alert delivery: rule %d does not exist", delivery.RuleID)
+	}
+	key := alertDeliveryKey(delivery.RuleID, delivery.DedupeKey)
+	if _, ok := s.deliveryKeys[key]; ok {
+		return AlertDelivery{}, false, nil
+	}
+
+	now := time.Now().UTC()
+	created := AlertDelivery{
+		ID:            s.data.nextID("alert_deliveries"),
+		RuleID:        delivery.RuleID,
+		DedupeKey:     delivery.DedupeKey,
+		Payload:       payload,
+		Status:        AlertDeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	s.data.Deliveries[created.ID] = created
+	s.deliveryKeys[key] = created.ID
+	s.dirty = true
+	return created, true, nil
+}
+
+// ListDueAlertDeliveries returns up to limit pending deliveries whose next
+// attempt is due, oldest first.
+func (s *FileStore) ListDueAlertDeliveries(ctx context.Context, now time.Time, limit int) ([]AlertDelivery, error) {
+	s.mu.Lock()
+	result := []AlertDelivery{}
+	for _, delivery := range s.data.Deliveries {
+		if delivery.Status == AlertDeliveryPending && !delivery.NextAttemptAt.After(now) {
+			result = append(result, cloneAlertDelivery(delivery))
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].NextAttemptAt.Equal(result[j].NextAttemptAt) {
+			return result[i].NextAttemptAt.Before(result[j].NextAttemptAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	if len(result) > limit {
+		result = result[:max(limit, 0)]
+	}
+	return result, nil
+}
+
+// ListAlertDeliveries returns the delivery log, newest first. A zero ruleID
+// or empty status matches every rule or status.
+func (s *FileStore) ListAlertDeliveries(ctx context.Context, ruleID int64, status string, limit int) ([]AlertDelivery, error) {
+	s.mu.Lock()
+	result := []AlertDelivery{}
+	for _, delivery := range s.data.Deliveries {
+		if (ruleID == 0 || delivery.RuleID == ruleID) && (status == "" || delivery.Status == status) {
+			result = append(result, cloneAlertDelivery(delivery))
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return applyLimit(result, limit), nil
+}
+
+// SaveAlertDeliveryAttempt records the outcome of a delivery attempt.
+func (s *FileStore) SaveAlertDeliveryAttempt(ctx context.Context, delivery AlertDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.data.Deliveries[delivery.ID]
+	if !ok {
+		return nil
+	}
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.ResponseStatus = delivery.ResponseStatus
+	stored.LastError = delivery.LastError
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.DeliveredAt = cloneTime(delivery.DeliveredAt)
+	s.data.Deliveries[stored.ID] = stored
+	s.dirty = true
+	return nil
+}
+
+// RetryAlertDelivery queues a delivery for another attempt now and reports
+// whether it exists.
+func (s *FileStore) RetryAlertDelivery(ctx context.Context, id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery, ok := s.data.Deliveries[id]
+	if !ok {
+		return false, nil
+	}
+	delivery.Status = AlertDeliveryPending
+	delivery.NextAttemptAt = time.Now().UTC()
+	s.data.Deliveries[id] = delivery
+	s.dirty = true
+	return true, nil
+}
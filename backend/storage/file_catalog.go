@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// catalogWallet joins a tracked wallet with its profile, if it has one; the
+// caller holds s.mu.
+func (s *FileStore) catalogWallet(key string, tracked TrackedWallet) CatalogWallet {
+	wallet := CatalogWallet{
+		WalletAddress:     tracked.WalletAddress,
+		Sport:             tracked.Sport,
+		DisplayName:       tracked.DisplayName,
+		Source:            tracked.Source,
+		SourceRank:        tracked.SourceRank,
+		SourcePredictions: tracked.SourcePredictions,
+		SourceWins:        tracked.SourceWins,
+		VolumeUSD:         tracked.VolumeUSD,
+		LossUSD:           tracked.LossUSD,
+		WinRate:           tracked.WinRate,
+		OpenPositionsUSD:  tracked.OpenPositionsUSD,
+		PnlUSD:            tracked.PnlUSD,
+		ROI:               tracked.ROI(),
+		LastSeenAt:        tracked.LastSeenAt,
+	}
+	if profile, ok := s.data.Profiles[key]; ok {
+		wallet.Profile = &CatalogProfile{
+			SportTrades:             profile.SportTrades,
+			RecentMarkets:           profile.RecentMarkets,
+			EntryTimingHours:        profile.EntryTimingHours,
+			SizeRatioPct:            profile.SizeRatioPct,
+			Conviction:              profile.Conviction,
+			DeterministicStyleLabel: profile.DeterministicStyleLabel,
+			StyleLabel:              profile.AIStyleLabel,
+			StyleSummary:            profile.AIStyleSummary,
+			ExplanationSource:       profile.ExplanationSource,
+			Model:                   profile.Model,
+			PresentationScore:       profile.PresentationScore,
+		}
+		if !profile.AnalyzedAt.IsZero() {
+			analyzedAt := profile.AnalyzedAt
+			wallet.Profile.AnalyzedAt = &analyzedAt
+		}
+	}
+	return wallet
+}
+
+// matches reports whether wallet passes every filter of a normalized query.
+func (q WalletQuery) matches(wallet CatalogWallet) bool {
+	profile := wallet.Profile
+	switch {
+	case q.Sport != "" && wallet.Sport != q.Sport:
+		return false
+	case q.StyleLabel != "" && (profile == nil || strings.ToLower(profile.StyleLabel) != q.StyleLabel):
+		return false
+	case q.Search != "" && !strings.Contains(strings.ToLower(wallet.DisplayName), q.Search) &&
+		!strings.Contains(strings.ToLower(wallet.WalletAddress), q.Search):
+		return false
+	case q.MinTrades > 0 && (profile == nil || profile.SportTrades < q.MinTrades):
+		return false
+	case q.MinPnlUSD != nil && wallet.PnlUSD < *q.MinPnlUSD:
+		return false
+	case q.MaxPnlUSD != nil && wallet.PnlUSD > *q.MaxPnlUSD:
+		return false
+	case q.MinWinRate != nil && wallet.WinRate < *q.MinWinRate:
+		return false
+	case q.MaxWinRate != nil && wallet.WinRate > *q.MaxWinRate:
+		return false
+	case !q.AnalyzedSince.IsZero() && (profile == nil || profile.AnalyzedAt == nil || profile.AnalyzedAt.Before(q.AnalyzedSince)):
+		return false
+	}
+	return true
+}
+
+// walletBefore reports whether a wallet with sort value a, address and sport
+// comes before one with value b in the query's order.
+func (q WalletQuery) walletBefore(a float64, aWallet, aSport string, b float64, bWallet, bSport string) bool {
+	if a != b {
+		return (a < b) != q.Descending
+	}
+	if aWallet != bWallet {
+		return aWallet < bWallet
+	}
+	return aSport < bSport
+}
+
+// QueryWallets returns one page of the wallet catalog, one row per wallet
+// and sport. next is nil on the last page.
+func (s *FileStore) QueryWallets(ctx context.Context, query WalletQuery) ([]CatalogWallet, *WalletCursor, error) {
+	query, spec, err := query.normalize()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type row struct {
+		wallet CatalogWallet
+		value  float64
+	}
+	var rows []row
+	s.mu.Lock()
+	for key, tracked := range s.data.TrackedWallets {
+		wallet := s.catalogWallet(key, tracked)
+		if !query.matches(wallet) {
+			continue
+		}
+		value := spec.value(wallet)
+		if after := query.After; after != nil &&
+			!query.walletBefore(after.Value, after.WalletAddress, after.Sport, value, wallet.WalletAddress, wallet.Sport) {
+			continue
+		}
+		rows = append(rows, row{wallet: wallet, value: value})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		return query.walletBefore(a.value, a.wallet.WalletAddress, a.wallet.Sport, b.value, b.wallet.WalletAddress, b.wallet.Sport)
+	})
+
+	page := applyLimit(rows, query.Limit)
+	wallets := make([]CatalogWallet, len(page))
+	values := make([]float64, len(page))
+	for i, r := range page {
+		wallets[i], values[i] = r.wallet, r.value
+	}
+	if len(rows) <= query.Limit {
+		return wallets, nil, nil
+	}
+	return wallets, query.nextCursor(wallets, values), nil
+}
+
+// GetCatalogWallet returns a wallet's catalog rows, one per sport it is
+// tracked for, by sport. It is empty when the wallet is not tracked.
+func (s *FileStore) GetCatalogWallet(ctx context.Context, wallet string) ([]CatalogWallet, error) {
+	wallet = strings.ToLower(wallet)
+
+	s.mu.Lock()
+	result := []CatalogWallet{}
+	for key, tracked := range s.data.TrackedWallets {
+		if strings.ToLower(tracked.WalletAddress) == wallet {
+			result = append(result, s.catalogWallet(key, tracked))
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Sport < result[j].Sport })
+	return result, nil
+}
@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (s *FileStore) UpsertMarketSports(ctx context.Context, markets []MarketSport) error {
+	if len(markets) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, market := range markets {
+		market.UpdatedAt = now
+		s.data.MarketSports[market.ConditionID] = market
+	}
+	s.dirty = true
+	return nil
+}
+
+// ListMarketSports returns every persisted market classification.
+func (s *FileStore) ListMarketSports(ctx context.Context) ([]MarketSport, error) {
+	s.mu.Lock()
+	markets := make([]MarketSport, 0, len(s.data.MarketSports))
+	for _, market := range s.data.MarketSports {
+		markets = append(markets, market)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(markets, func(i, j int) bool { return markets[i].ConditionID < markets[j].ConditionID })
+	return markets, nil
+}
+
+// UpsertGames writes games and links each of their markets to them.
+func (s *FileStore) UpsertGames(ctx context.Context, games []Game) error {
+	if len(games) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, game := range games {
+		for _, market := range game.Markets {
+			s.data.GameMarkets[market.ConditionID] = fileGameMarket{GameID: game.ID, GameMarket: market}
+		}
+		game.Markets = nil
+		game.ScheduledStart = cloneTime(game.ScheduledStart)
+		game.HomeScore = cloneInt(game.HomeScore)
+		game.AwayScore = cloneInt(game.AwayScore)
+		s.data.Games[game.ID] = game
+	}
+	s.dirty = true
+	return nil
+}
+
+// ListGames returns every persisted game with its markets.
+func (s *FileStore) ListGames(ctx context.Context) ([]Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	games := make([]Game, 0, len(s.data.Games))
+	byID := make(map[string]int, len(s.data.Games))
+	for _, game := range s.data.Games {
+		game.ScheduledStart = cloneTime(game.ScheduledStart)
+		game.HomeScore = cloneInt(game.HomeScore)
+		game.AwayScore = cloneInt(game.AwayScore)
+		games = append(games, game)
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].ID < games[j].ID })
+	for i, game := range games {
+		byID[game.ID] = i
+	}
+
+	conditionIDs := make([]string, 0, len(s.data.GameMarkets))
+	for conditionID := range s.data.GameMarkets {
+		conditionIDs = append(conditionIDs, conditionID)
+	}
+	sort.Strings(conditionIDs)
+	for _, conditionID := range conditionIDs {
+		market := s.data.GameMarkets[conditionID]
+		if i, ok := byID[market.GameID]; ok {
+			games[i].Markets = append(games[i].Markets, market.GameMarket)
+		}
+	}
+	return games, nil
+}
+
+// ReplaceMarketConsensus swaps the stored consensus of a sport for rows.
+func (s *FileStore) ReplaceMarketConsensus(ctx context.Context, sport string, rows []MarketConsensus) error {
+	sport = strings.ToLower(sport)
+	now := time.Now().UTC()
+
+	stored := make([]MarketConsensus, 0, len(rows))
+	seen := map[string]bool{}
+	for _, row := range rows {
+		if seen[row.ConditionID] {
+			return fmt.Errorf("insert market consensus %s: duplicate condition", row.ConditionID)
+		}
+		seen[row.ConditionID] = true
+		row.Sport = sport
+		row.ScheduledStart = cloneTime(row.ScheduledStart)
+		row.ComputedAt = now
+		stored = append(stored, row)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Consensus[sport] = stored
+	s.dirty = true
+	return nil
+}
+
+// ListMarketConsensus returns the consensus rows of sports for games that
+// have not started, largest divergence from the market price first. A
+// market computed for several of the sports is listed once, from the first.
+func (s *FileStore) ListMarketConsensus(ctx context.Context, sports []string, limit int) ([]MarketConsensus, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	result := []MarketConsensus{}
+	seen := map[string]bool{}
+	for _, sport := range lowerAll(sports) {
+		for _, row := range s.data.Consensus[sport] {
+			if seen[row.ConditionID] || (row.ScheduledStart != nil && !row.ScheduledStart.After(now)) {
+				continue
+			}
+			seen[row.ConditionID] = true
+			row.ScheduledStart = cloneTime(row.ScheduledStart)
+			result = append(result, row)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := math.Abs(result[i].Divergence), math.Abs(result[j].Divergence)
+		if a != b {
+			return a > b
+		}
+		return result[i].StakeUSD > result[j].StakeUSD
+	})
+	if len(result) > limit {
+		result = result[:max(limit, 0)]
+	}
+	return result, nil
+}
+
+// InsertTrades stores the trades not stored yet and returns how many were
+// new.
+func (s *FileStore) InsertTrades(ctx context.Context, trades []StoredTrade) (int64, error) {
+	if len(trades) == 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var inserted int64
+	for _, t := range trades {
+		if _, ok := s.data.Trades[t.Key]; ok {
+			continue
+		}
+		t.WalletAddress = strings.ToLower(t.WalletAddress)
+		t.ConditionID = strings.ToLower(t.ConditionID)
+		s.data.Trades[t.Key] = t
+		s.tradesByWallet[t.WalletAddress] = append(s.tradesByWallet[t.WalletAddress], t.Key)
+		s.tradesByMarket[t.ConditionID] = append(s.tradesByMarket[t.ConditionID], t.Key)
+		inserted++
+	}
+	if inserted > 0 {
+		s.dirty = true
+	}
+	return inserted, nil
+}
+
+// ListWalletTrades returns a wallet's stored trades, newest first, at most
+// limit when it is positive.
+func (s *FileStore) ListWalletTrades(ctx context.Context, wallet string, limit int) ([]StoredTrade, error) {
+	s.mu.Lock()
+	result := s.tradesFor(s.tradesByWallet[strings.ToLower(wallet)])
+	s.mu.Unlock()
+	return applyLimit(newestTradesFirst(result), limit), nil
+}
+
+// ListMarketTrades returns a market's stored trades, newest first, at most
+// limit when it is positive.
+func (s *FileStore) ListMarketTrades(ctx context.Context, conditionID string, limit int) ([]StoredTrade, error) {
+	s.mu.Lock()
+	result := s.tradesFor(s.tradesByMarket[strings.ToLower(conditionID)])
+	s.mu.Unlock()
+	return applyLimit(newestTradesFirst(result), limit), nil
+}
+
+// tradesFor copies the stored trades under keys. The caller holds s.mu.
+func (s *FileStore) tradesFor(keys []string) []StoredTrade {
+	result := make([]StoredTrade, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, s.data.Trades[key])
+	}
+	return result
+}
+
+func newestTradesFirst(trades []StoredTrade) []StoredTrade {
+	sort.Slice(trades, func(i, j int) bool {
+		if !trades[i].TradedAt.Equal(trades[j].TradedAt) {
+			return trades[i].TradedAt.After(trades[j].TradedAt)
+		}
+		return trades[i].Key < trades[j].Key
+	})
+	return trades
+}
+
+// GetWalletTradeCoverage returns how much of a wallet's trade feed is
+// stored; ok is false when the wallet's own feed was never synced.
+func (s *FileStore) GetWalletTradeCoverage(ctx context.Context, wallet string) (WalletTradeCoverage, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	coverage, ok := s.data.TradeCoverage[strings.ToLower(wallet)]
+	if !ok {
+		return WalletTradeCoverage{}, false, nil
+	}
+	return cloneTradeCoverage(coverage), true, nil
+}
+
+// SaveWalletTradeCoverage replaces a wallet's trade coverage and stamps it
+// with the current time.
+func (s *FileStore) SaveWalletTradeCoverage(ctx context.Context, coverage WalletTradeCoverage) error {
+	coverage = cloneTradeCoverage(coverage)
+	coverage.WalletAddress = strings.ToLower(coverage.WalletAddress)
+	coverage.SyncedAt = time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.TradeCoverage[coverage.WalletAddress] = coverage
+	s.dirty = true
+	return nil
+}
+
+func cloneTradeCoverage(coverage WalletTradeCoverage) WalletTradeCoverage {
+	coverage.NewestTradeAt = cloneTime(coverage.NewestTradeAt)
+	coverage.OldestTradeAt = cloneTime(coverage.OldestTradeAt)
+	return coverage
+}
+
+// UpsertMarkets replaces the stored copies of markets.
+func (s *FileStore) UpsertMarkets(ctx context.Context, markets []MarketRecord) error {
+	if len(markets) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	stored := make([]MarketRecord, 0, len(markets))
+	for _, m := range markets {
+		data, err := validJSON(m.Data, "null")
+		if err != nil {
+			return fmt.Errorf("upsert market %s: %w", m.ConditionID, err)
+		}
+		m.ConditionID = strings.ToLower(m.ConditionID)
+		m.Data = data
+		m.UpdatedAt = now
+		stored = append(stored, m)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range stored {
+		s.data.Markets[m.ConditionID] = m
+	}
+	s.dirty = true
+	return nil
+}
+
+// GetMarketRecords returns the stored markets among conditionIDs.
+func (s *FileStore) GetMarketRecords(ctx context.Context, conditionIDs []string) ([]MarketRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []MarketRecord{}
+	seen := map[string]bool{}
+	for _, id := range conditionIDs {
+		id = strings.ToLower(id)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if m, ok := s.data.Markets[id]; ok {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func cloneInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+func paperOrderKey(portfolioID int64, sourceTradeKey string) string {
+	return fileKey(fmt.Sprint(portfolioID), sourceTradeKey)
+}
+
+func clonePaperPortfolio(portfolio PaperPortfolio) PaperPortfolio {
+	portfolio.Wallets = cloneStrings(portfolio.Wallets)
+	portfolio.LastPolledAt = cloneTime(portfolio.LastPolledAt)
+	return portfolio
+}
+
+// CreatePaperPortfolio stores a new portfolio funded with its bankroll.
+func (s *FileStore) CreatePaperPortfolio(ctx context.Context, portfolio PaperPortfolio) (PaperPortfolio, error) {
+	wallets := make([]string, 0, len(portfolio.Wallets))
+	for _, wallet := range portfolio.Wallets {
+		wallets = append(wallets, strings.ToLower(strings.TrimSpace(wallet)))
+	}
+	portfolio.Wallets = wallets
+	portfolio.Sport = strings.ToLower(portfolio.Sport)
+	portfolio.Cash = portfolio.BankrollUSD
+	portfolio.LastPolledAt = nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	portfolio.ID = s.data.nextID("paper_portfolios")
+	portfolio.CreatedAt = time.Now().UTC()
+	s.data.Portfolios[portfolio.ID] = clonePaperPortfolio(portfolio)
+	s.dirty = true
+	return portfolio, nil
+}
+
+// ListPaperPortfolios returns every portfolio, oldest first.
+func (s *FileStore) ListPaperPortfolios(ctx context.Context) ([]PaperPortfolio, error) {
+	s.mu.Lock()
+	result := make([]PaperPortfolio, 0, len(s.data.Portfolios))
+	for _, portfolio := range s.data.Portfolios {
+		result = append(result, clonePaperPortfolio(portfolio))
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// GetPaperPortfolio returns one portfolio; ok is false when it does not
+// exist.
+func (s *FileStore) GetPaperPortfolio(ctx context.Context, id int64) (PaperPortfolio, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	portfolio, ok := s.data.Portfolios[id]
+	if !ok {
+		return PaperPortfolio{}, false, nil
+	}
+	return clonePaperPortfolio(portfolio), true, nil
+}
+
+// InsertPaperOrders stores new pending orders and returns the ones that were
+// not already recorded for their portfolio, with IDs set.
+func (s *FileStore) InsertPaperOrders(ctx context.Context, orders []PaperOrder) ([]PaperOrder, error) {
+	if len(orders) == 0 {
+		return []PaperOrder{}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, order := range orders {
+		if _, ok := s.data.Portfolios[order.PortfolioID]; !ok {
+			return nil, fmt.Errorf("insert paper order %s: portfolio %d does not exist", order.SourceTradeKey, order.PortfolioID)
+		}
+	}
+
+	inserted := []PaperOrder{}
+	for _, order := range orders {
+		key := paperOrderKey(order.PortfolioID, order.SourceTradeKey)
+		if _, ok := s.orderKeys[key]; ok {
+			continue
+		}
+		order.ID = s.data.nextID("paper_orders")
+		order.SourceWallet = strings.ToLower(order.SourceWallet)
+		order.ConditionID = strings.ToLower(order.ConditionID)
+		order.Status = PaperOrderPending
+		order.Reason = ""
+		order.FillPrice = 0
+		order.Shares = 0
+		order.NotionalUSD = 0
+		order.FilledAt = nil
+		s.data.Orders[order.ID] = order
+		s.orderKeys[key] = order.ID
+		inserted = append(inserted, order)
+	}
+	s.dirty = true
+	return inserted, nil
+}
+
+// ListPaperOrders returns a portfolio's orders, newest first. An empty status
+// matches every order and a non-positive limit returns all of them.
+func (s *FileStore) ListPaperOrders(ctx context.Context, portfolioID int64, status string, limit int) ([]PaperOrder, error) {
+	s.mu.Lock()
+	result := []PaperOrder{}
+	for _, order := range s.data.Orders {
+		if order.PortfolioID != portfolioID || (status != "" && order.Status != status) {
+			continue
+		}
+		order.FilledAt = cloneTime(order.FilledAt)
+		result = append(result, order)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].SourceTime.Equal(result[j].SourceTime) {
+			return result[i].SourceTime.After(result[j].SourceTime)
+		}
+		return result[i].ID > result[j].ID
+	})
+	return applyLimit(result, limit), nil
+}
+
+// ListPaperPositions returns a portfolio's positions, open ones first. With
+// openOnly set closed and settled positions are left out.
+func (s *FileStore) ListPaperPositions(ctx context.Context, portfolioID int64, openOnly bool) ([]PaperPosition, error) {
+	s.mu.Lock()
+	result := []PaperPosition{}
+	for _, position := range s.data.Positions {
+		if position.PortfolioID != portfolioID || (openOnly && position.Status != PaperPositionOpen) {
+			continue
+		}
+		result = append(result, position)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		iOpen, jOpen := result[i].Status == PaperPositionOpen, result[j].Status == PaperPositionOpen
+		if iOpen != jOpen {
+			return iOpen
+		}
+		if !result[i].UpdatedAt.Equal(result[j].UpdatedAt) {
+			return result[i].UpdatedAt.After(result[j].UpdatedAt)
+		}
+		if result[i].ConditionID != result[j].ConditionID {
+			return result[i].ConditionID < result[j].ConditionID
+		}
+		return result[i].Outcome < result[j].Outcome
+	})
+	return result, nil
+}
+
+// SavePaperPoll writes the orders, positions, cash and equity a poll
+// changed in one step.
+func (s *FileStore) SavePaperPoll(ctx context.Context, poll PaperPoll) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	portfolio, ok := s.data.Portfolios[poll.PortfolioID]
+	if !ok {
+		return fmt.Errorf("update paper portfolio %d: portfolio does not exist", poll.PortfolioID)
+	}
+	now := time.Now().UTC()
+
+	for _, update := range poll.Orders {
+		order, ok := s.data.Orders[update.ID]
+		if !ok {
+			continue
+		}
+		order.Status = update.Status
+		order.Reason = update.Reason
+		order.FillPrice = update.FillPrice
+		order.Shares = update.Shares
+		order.NotionalUSD = update.NotionalUSD
+		order.FilledAt = cloneTime(update.FilledAt)
+		s.data.Orders[order.ID] = order
+	}
+
+	for _, position := range poll.Positions {
+		position.PortfolioID = poll.PortfolioID
+		position.ConditionID = strings.ToLower(position.ConditionID)
+		position.UpdatedAt = now
+		s.data.Positions[fileKey(fmt.Sprint(position.PortfolioID), position.ConditionID, position.Outcome)] = position
+	}
+
+	polledAt := poll.PolledAt
+	portfolio.Cash = poll.Cash
+	portfolio.LastPolledAt = &polledAt
+	s.data.Portfolios[portfolio.ID] = portfolio
+
+	if poll.Equity != nil {
+		curve := s.data.Equity[poll.PortfolioID]
+		point := *poll.Equity
+		i := sort.Search(len(curve), func(i int) bool { return !curve[i].RecordedAt.Before(point.RecordedAt) })
+		if i == len(curve) || !curve[i].RecordedAt.Equal(point.RecordedAt) {
+			curve = append(curve, PaperEquityPoint{})
+			copy(curve[i+1:], curve[i:])
+			curve[i] = point
+			s.data.Equity[poll.PortfolioID] = curve
+		}
+	}
+
+	s.dirty = true
+	return nil
+}
+
+// ListPaperEquity returns a portfolio's equity curve, oldest first, limited
+// to the most recent limit points when limit is positive.
+func (s *FileStore) ListPaperEquity(ctx context.Context, portfolioID int64, limit int) ([]PaperEquityPoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	curve := applyTailLimit(s.data.Equity[portfolioID], limit)
+	return append([]PaperEquityPoint{}, curve...), nil
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+func cloneSyncRun(run SyncRun) SyncRun {
+	run.Sports = cloneStrings(run.Sports)
+	run.FinishedAt = cloneTime(run.FinishedAt)
+	run.WalletErrors = append([]SyncWalletError{}, run.WalletErrors...)
+	return run
+}
+
+// StartSyncRun records a run as running on instanceID and returns it with
+// its ID and start time.
+func (s *FileStore) StartSyncRun(ctx context.Context, trigger, instanceID string, sports []string) (SyncRun, error) {
+	if sports == nil {
+		sports = []string{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	run := SyncRun{
+		ID:           s.data.nextID("sync_runs"),
+		Trigger:      trigger,
+		Status:       SyncRunRunning,
+		Sports:       cloneStrings(sports),
+		InstanceID:   instanceID,
+		StartedAt:    now,
+		HeartbeatAt:  now,
+		WalletErrors: []SyncWalletError{},
+	}
+	s.data.SyncRuns[run.ID] = run
+	s.dirty = true
+	return cloneSyncRun(run), nil
+}
+
+// TouchSyncRun moves a running run's heartbeat to now.
+func (s *FileStore) TouchSyncRun(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.data.SyncRuns[id]
+	if !ok || run.Status != SyncRunRunning {
+		return nil
+	}
+	run.HeartbeatAt = time.Now().UTC()
+	s.data.SyncRuns[id] = run
+	s.dirty = true
+	return nil
+}
+
+// AbandonSyncRuns marks as failed every running run that instanceID
+// started or whose heartbeat is older than staleAfter, and returns how many
+// it marked.
+func (s *FileStore) AbandonSyncRuns(ctx context.Context, instanceID string, staleAfter time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	var abandoned int64
+	for id, run := range s.data.SyncRuns {
+		if run.Status != SyncRunRunning {
+			continue
+		}
+		if (instanceID == "" || run.InstanceID != instanceID) && !run.HeartbeatAt.Before(now.Add(-staleAfter)) {
+			continue
+		}
+		run.Status = SyncRunFailed
+		run.FinishedAt = cloneTime(&now)
+		run.Error = SyncRunAbandoned
+		s.data.SyncRuns[id] = run
+		abandoned++
+	}
+	if abandoned > 0 {
+		s.dirty = true
+	}
+	return abandoned, nil
+}
+
+// FinishSyncRun saves a run's counters, errors and final status.
+func (s *FileStore) FinishSyncRun(ctx context.Context, run SyncRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.data.SyncRuns[run.ID]
+	if !ok {
+		return nil
+	}
+	stored.Status = run.Status
+	stored.FinishedAt = cloneTime(run.FinishedAt)
+	stored.WalletsAttempted = run.WalletsAttempted
+	stored.WalletsSucceeded = run.WalletsSucceeded
+	stored.WalletsFailed = run.WalletsFailed
+	stored.WalletsSkipped = run.WalletsSkipped
+	stored.AICalls = run.AICalls
+	stored.AIFallbacks = run.AIFallbacks
+	stored.WalletErrors = append([]SyncWalletError{}, run.WalletErrors...)
+	stored.Error = run.Error
+	s.data.SyncRuns[run.ID] = stored
+	s.dirty = true
+	return nil
+}
+
+// sortedSyncRuns returns the runs matching keep, newest first; the caller
+// holds s.mu.
+func (s *FileStore) sortedSyncRuns(keep func(SyncRun) bool) []SyncRun {
+	var runs []SyncRun
+	for _, run := range s.data.SyncRuns {
+		if keep(run) {
+			runs = append(runs, cloneSyncRun(run))
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].StartedAt.After(runs[j].StartedAt)
+		}
+		return runs[i].ID > runs[j].ID
+	})
+	return runs
+}
+
+// ListSyncRuns returns sync runs newest first, optionally filtered by
+// status.
+func (s *FileStore) ListSyncRuns(ctx context.Context, status string, limit int) ([]SyncRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := s.sortedSyncRuns(func(run SyncRun) bool { return status == "" || run.Status == status })
+	return applyLimit(runs, limit), nil
+}
+
+func (s *FileStore) GetSyncRun(ctx context.Context, id int64) (SyncRun, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.data.SyncRuns[id]
+	if !ok {
+		return SyncRun{}, false, nil
+	}
+	return cloneSyncRun(run), true, nil
+}
+
+// LatestSyncRun returns the most recently started run with the given
+// trigger.
+func (s *FileStore) LatestSyncRun(ctx context.Context, trigger string) (SyncRun, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := s.sortedSyncRuns(func(run SyncRun) bool { return run.Trigger == trigger })
+	if len(runs) == 0 {
+		return SyncRun{}, false, nil
+	}
+	return runs[0], true, nil
+}
+
+// AcquireLease takes the named lease for holder, or renews it when holder
+// already has it, extending it by ttl. It reports false when another holder
+// has an unexpired lease.
+func (s *FileStore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (SchedulerLease, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	lease, ok := s.data.Leases[name]
+	switch {
+	case !ok || (lease.Holder != holder && lease.ExpiresAt.Before(now)):
+		lease = SchedulerLease{Name: name, Holder: holder, AcquiredAt: now}
+	case lease.Holder != holder:
+		return SchedulerLease{}, false, nil
+	}
+	lease.RenewedAt = now
+	lease.ExpiresAt = now.Add(ttl)
+	s.data.Leases[name] = lease
+	s.dirty = true
+	return lease, true, nil
+}
+
+// ReleaseLease gives up the named lease if holder has it.
+func (s *FileStore) ReleaseLease(ctx context.Context, name, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease, ok := s.data.Leases[name]; ok && lease.Holder == holder {
+		delete(s.data.Leases, name)
+		s.dirty = true
+	}
+	return nil
+}
+
+// GetLease returns the named lease, expired or not.
+func (s *FileStore) GetLease(ctx context.Context, name string) (SchedulerLease, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lease, ok := s.data.Leases[name]
+	return lease, ok, nil
+}
@@ -0,0 +1,504 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func openMemory(t *testing.T) *FileStore {
+	t.Helper()
+	store, err := OpenFile("")
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	t.Cleanup(store.Close)
+	return store
+}
+
+func walletAddresses[T any](rows []T, address func(T) string) []string {
+	addresses := make([]string, len(rows))
+	for i, row := range rows {
+		addresses[i] = address(row)
+	}
+	return addresses
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFileStoreTrackedWallets(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+	seen := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	err := store.UpsertTrackedWallets(ctx, []TrackedWallet{
+		{WalletAddress: "0xccc", Sport: "nba", SourceRank: 3, PnlUSD: 10, LastSeenAt: seen},
+		{WalletAddress: "0xaaa", Sport: "nba", SourceRank: 1, PnlUSD: 30, LastSeenAt: seen},
+		{WalletAddress: "0xbbb", Sport: "nba", SourceRank: 1, PnlUSD: 20, LastSeenAt: seen},
+		{WalletAddress: "0xaaa", Sport: "nfl", SourceRank: 7, PnlUSD: 5, LastSeenAt: seen},
+	})
+	if err != nil {
+		t.Fatalf("UpsertTrackedWallets: %v", err)
+	}
+	// A second sync replaces the row rather than adding one.
+	err = store.UpsertTrackedWallets(ctx, []TrackedWallet{
+		{WalletAddress: "0xccc", Sport: "nba", SourceRank: 2, PnlUSD: 40, LastSeenAt: seen.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("UpsertTrackedWallets: %v", err)
+	}
+
+	tests := []struct {
+		sport string
+		want  []string
+	}{
+		{sport: "nba", want: []string{"0xaaa", "0xbbb", "0xccc"}},
+		{sport: "NBA", want: []string{"0xaaa", "0xbbb", "0xccc"}},
+		{sport: "nfl", want: []string{"0xaaa"}},
+		{sport: "mlb", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sport, func(t *testing.T) {
+			wallets, err := store.ListTrackedWallets(ctx, tt.sport)
+			if err != nil {
+				t.Fatalf("ListTrackedWallets: %v", err)
+			}
+			got := walletAddresses(wallets, func(w TrackedWallet) string { return w.WalletAddress })
+			if !equalStrings(got, tt.want) {
+				t.Errorf("ListTrackedWallets(%q) = %v, want %v", tt.sport, got, tt.want)
+			}
+		})
+	}
+
+	rows, err := store.GetCatalogWallet(ctx, "0xCCC")
+	if err != nil {
+		t.Fatalf("GetCatalogWallet: %v", err)
+	}
+	if len(rows) != 1 || rows[0].SourceRank != 2 || rows[0].PnlUSD != 40 {
+		t.Errorf("GetCatalogWallet(0xCCC) = %+v, want the replaced row", rows)
+	}
+}
+
+func TestFileStoreTrades(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	trade := func(key, wallet string, hour int) StoredTrade {
+		return StoredTrade{Key: key, WalletAddress: wallet, ConditionID: "0xMARKET", TradedAt: base.Add(time.Duration(hour) * time.Hour)}
+	}
+
+	inserts := []struct {
+		name   string
+		trades []StoredTrade
+		want   int64
+	}{
+		{name: "new", trades: []StoredTrade{trade("a", "0xABC", 1), trade("b", "0xabc", 3), trade("c", "0xdef", 2)}, want: 3},
+		{name: "duplicates", trades: []StoredTrade{trade("a", "0xabc", 1), trade("d", "0xabc", 2)}, want: 1},
+		{name: "empty", trades: nil, want: 0},
+	}
+	for _, tt := range inserts {
+		got, err := store.InsertTrades(ctx, tt.trades)
+		if err != nil {
+			t.Fatalf("InsertTrades %s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("InsertTrades %s = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	lists := []struct {
+		wallet string
+		limit  int
+		want   []string
+	}{
+		{wallet: "0xabc", want: []string{"b", "d", "a"}},
+		{wallet: "0xABC", limit: 2, want: []string{"b", "d"}},
+		{wallet: "0xdef", want: []string{"c"}},
+		{wallet: "0x999", want: []string{}},
+	}
+	for _, tt := range lists {
+		trades, err := store.ListWalletTrades(ctx, tt.wallet, tt.limit)
+		if err != nil {
+			t.Fatalf("ListWalletTrades: %v", err)
+		}
+		got := walletAddresses(trades, func(t StoredTrade) string { return t.Key })
+		if !equalStrings(got, tt.want) {
+			t.Errorf("ListWalletTrades(%q, %d) = %v, want %v", tt.wallet, tt.limit, got, tt.want)
+		}
+		for _, stored := range trades {
+			if stored.WalletAddress != "0xabc" && stored.WalletAddress != "0xdef" || stored.ConditionID != "0xmarket" {
+				t.Errorf("trade %s stored as %s/%s, want lower case", stored.Key, stored.WalletAddress, stored.ConditionID)
+			}
+		}
+	}
+
+	if _, err := store.InsertTrades(ctx, []StoredTrade{{Key: "e", WalletAddress: "0xabc", ConditionID: "0xother", TradedAt: base}}); err != nil {
+		t.Fatalf("InsertTrades: %v", err)
+	}
+	markets := []struct {
+		conditionID string
+		limit       int
+		want        []string
+	}{
+		{conditionID: "0xmarket", want: []string{"b", "c", "d", "a"}},
+		{conditionID: "0xMARKET", limit: 3, want: []string{"b", "c", "d"}},
+		{conditionID: "0xother", want: []string{"e"}},
+		{conditionID: "0x999", want: []string{}},
+	}
+	for _, tt := range markets {
+		trades, err := store.ListMarketTrades(ctx, tt.conditionID, tt.limit)
+		if err != nil {
+			t.Fatalf("ListMarketTrades: %v", err)
+		}
+		if got := walletAddresses(trades, func(t StoredTrade) string { return t.Key }); !equalStrings(got, tt.want) {
+			t.Errorf("ListMarketTrades(%q, %d) = %v, want %v", tt.conditionID, tt.limit, got, tt.want)
+		}
+	}
+
+	if _, ok, err := store.GetWalletTradeCoverage(ctx, "0xabc"); err != nil || ok {
+		t.Fatalf("GetWalletTradeCoverage before sync = %v, %v; want not found", ok, err)
+	}
+	newest, oldest := base.Add(3*time.Hour), base.Add(time.Hour)
+	err := store.SaveWalletTradeCoverage(ctx, WalletTradeCoverage{WalletAddress: "0xABC", NewestTradeAt: &newest, OldestTradeAt: &oldest, Complete: true})
+	if err != nil {
+		t.Fatalf("SaveWalletTradeCoverage: %v", err)
+	}
+	newest = base // the store keeps its own copy
+	coverage, ok, err := store.GetWalletTradeCoverage(ctx, "0xAbC")
+	if err != nil || !ok {
+		t.Fatalf("GetWalletTradeCoverage = %v, %v; want found", ok, err)
+	}
+	if !coverage.NewestTradeAt.Equal(base.Add(3*time.Hour)) || !coverage.OldestTradeAt.Equal(oldest) || !coverage.Complete || coverage.SyncedAt.IsZero() {
+		t.Errorf("GetWalletTradeCoverage = %+v", coverage)
+	}
+}
+
+func TestFileStoreConsensus(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+	soon, past := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+	row := func(conditionID string, divergence float64, start time.Time) MarketConsensus {
+		return MarketConsensus{ConditionID: conditionID, Divergence: divergence, ScheduledStart: &start}
+	}
+
+	for sport, rows := range map[string][]MarketConsensus{
+		"soccer": {row("shared", 0.05, soon), row("cup", -0.02, soon)},
+		"epl":    {row("shared", 0.30, soon), row("derby", -0.20, soon), row("started", 0.50, past)},
+		"nba":    {row("finals", 0.40, soon)},
+	} {
+		if err := store.ReplaceMarketConsensus(ctx, sport, rows); err != nil {
+			t.Fatalf("ReplaceMarketConsensus %s: %v", sport, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		sports []string
+		limit  int
+		want   []string
+	}{
+		{name: "one sport", sports: []string{"EPL"}, limit: 10, want: []string{"epl|shared", "epl|derby"}},
+		{name: "first sport wins a shared market", sports: []string{"soccer", "epl"}, limit: 10, want: []string{"epl|derby", "soccer|shared", "soccer|cup"}},
+		{name: "limit", sports: []string{"soccer", "epl"}, limit: 1, want: []string{"epl|derby"}},
+		{name: "no rows", sports: []string{"nfl"}, limit: 10, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := store.ListMarketConsensus(ctx, tt.sports, tt.limit)
+			if err != nil {
+				t.Fatalf("ListMarketConsensus: %v", err)
+			}
+			got := walletAddresses(rows, func(r MarketConsensus) string { return r.Sport + "|" + r.ConditionID })
+			if !equalStrings(got, tt.want) {
+				t.Errorf("ListMarketConsensus(%v, %d) = %v, want %v", tt.sports, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileStoreSyncRuns(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+
+	start := func(trigger, instance string) SyncRun {
+		t.Helper()
+		run, err := store.StartSyncRun(ctx, trigger, instance, []string{"nba"})
+		if err != nil {
+			t.Fatalf("StartSyncRun: %v", err)
+		}
+		if run.Status != SyncRunRunning || run.InstanceID != instance || run.HeartbeatAt.IsZero() {
+			t.Fatalf("StartSyncRun = %+v", run)
+		}
+		return run
+	}
+	finished := start("scheduled", "a")
+	ownRun := start("manual", "a")
+	staleRun := start("scheduled", "b")
+	liveRun := start("manual", "b")
+
+	finishedAt := time.Now().UTC()
+	finished.Status = SyncRunCompleted
+	finished.FinishedAt = &finishedAt
+	finished.WalletsSucceeded = 4
+	if err := store.FinishSyncRun(ctx, finished); err != nil {
+		t.Fatalf("FinishSyncRun: %v", err)
+	}
+
+	// Age the heartbeat of b's first run past the stale limit.
+	store.mu.Lock()
+	stale := store.data.SyncRuns[staleRun.ID]
+	stale.HeartbeatAt = stale.HeartbeatAt.Add(-time.Hour)
+	store.data.SyncRuns[staleRun.ID] = stale
+	store.mu.Unlock()
+	if err := store.TouchSyncRun(ctx, liveRun.ID); err != nil {
+		t.Fatalf("TouchSyncRun: %v", err)
+	}
+
+	abandoned, err := store.AbandonSyncRuns(ctx, "a", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("AbandonSyncRuns: %v", err)
+	}
+	if abandoned != 2 {
+		t.Errorf("AbandonSyncRuns = %d, want 2", abandoned)
+	}
+
+	tests := []struct {
+		name      string
+		id        int64
+		status    string
+		error     string
+		succeeded int
+	}{
+		{name: "finished run is kept", id: finished.ID, status: SyncRunCompleted, succeeded: 4},
+		{name: "run of the restarted instance", id: ownRun.ID, status: SyncRunFailed, error: SyncRunAbandoned},
+		{name: "run with a stale heartbeat", id: staleRun.ID, status: SyncRunFailed, error: SyncRunAbandoned},
+		{name: "live run of another instance", id: liveRun.ID, status: SyncRunRunning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, ok, err := store.GetSyncRun(ctx, tt.id)
+			if err != nil || !ok {
+				t.Fatalf("GetSyncRun(%d) = %v, %v", tt.id, ok, err)
+			}
+			if run.Status != tt.status || run.Error != tt.error || run.WalletsSucceeded != tt.succeeded {
+				t.Errorf("run %d = %s %q %d, want %s %q %d", tt.id, run.Status, run.Error, run.WalletsSucceeded, tt.status, tt.error, tt.succeeded)
+			}
+			if (run.Status != SyncRunRunning) != (run.FinishedAt != nil) {
+				t.Errorf("run %d has status %s and finished_at %v", tt.id, run.Status, run.FinishedAt)
+			}
+		})
+	}
+
+	running, err := store.ListSyncRuns(ctx, SyncRunRunning, 0)
+	if err != nil || len(running) != 1 || running[0].ID != liveRun.ID {
+		t.Errorf("ListSyncRuns(running) = %v, %v; want only run %d", running, err, liveRun.ID)
+	}
+	all, err := store.ListSyncRuns(ctx, "", 3)
+	if err != nil || len(all) != 3 || all[0].ID != liveRun.ID {
+		t.Errorf("ListSyncRuns(\"\", 3) = %v, %v; want 3 runs newest first", all, err)
+	}
+	latest, ok, err := store.LatestSyncRun(ctx, "scheduled")
+	if err != nil || !ok || latest.ID != staleRun.ID {
+		t.Errorf("LatestSyncRun = %d, %v, %v; want %d", latest.ID, ok, err, staleRun.ID)
+	}
+	if _, ok, _ := store.LatestSyncRun(ctx, "unknown"); ok {
+		t.Error("LatestSyncRun(unknown) found a run")
+	}
+}
+
+func TestFileStoreLeases(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+
+	steps := []struct {
+		name       string
+		release    bool
+		holder     string
+		ttl        time.Duration
+		wantHeld   bool
+		wantHolder string
+	}{
+		{name: "free lease is taken", holder: "a", ttl: time.Minute, wantHeld: true, wantHolder: "a"},
+		{name: "held lease is refused", holder: "b", ttl: time.Minute, wantHeld: false, wantHolder: "a"},
+		{name: "holder renews", holder: "a", ttl: -time.Second, wantHeld: true, wantHolder: "a"},
+		{name: "expired lease is taken over", holder: "b", ttl: time.Minute, wantHeld: true, wantHolder: "b"},
+		{name: "release by another holder is ignored", release: true, holder: "a", wantHolder: "b"},
+		{name: "holder releases", release: true, holder: "b"},
+		{name: "released lease is taken", holder: "a", ttl: time.Minute, wantHeld: true, wantHolder: "a"},
+	}
+	var acquiredAt time.Time
+	for _, step := range steps {
+		if step.release {
+			if err := store.ReleaseLease(ctx, "wallet_sync", step.holder); err != nil {
+				t.Fatalf("%s: ReleaseLease: %v", step.name, err)
+			}
+		} else {
+			lease, held, err := store.AcquireLease(ctx, "wallet_sync", step.holder, step.ttl)
+			if err != nil {
+				t.Fatalf("%s: AcquireLease: %v", step.name, err)
+			}
+			if held != step.wantHeld {
+				t.Errorf("%s: held = %v, want %v", step.name, held, step.wantHeld)
+			}
+			if held && step.name == "holder renews" && !lease.AcquiredAt.Equal(acquiredAt) {
+				t.Errorf("%s: acquired_at moved from %s to %s", step.name, acquiredAt, lease.AcquiredAt)
+			}
+			if held {
+				acquiredAt = lease.AcquiredAt
+			}
+		}
+
+		lease, ok, err := store.GetLease(ctx, "wallet_sync")
+		if err != nil {
+			t.Fatalf("%s: GetLease: %v", step.name, err)
+		}
+		if ok != (step.wantHolder != "") || lease.Holder != step.wantHolder {
+			t.Errorf("%s: lease holder = %q (found %v), want %q", step.name, lease.Holder, ok, step.wantHolder)
+		}
+	}
+}
+
+func TestFileStoreAlertDeliveryDedupe(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+
+	first, err := store.CreateAlertRule(ctx, AlertRule{Name: "first", Kind: "large_trade", Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+	second, err := store.CreateAlertRule(ctx, AlertRule{Name: "second", Kind: "large_trade", Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		ruleID       int64
+		key          string
+		payload      string
+		wantInserted bool
+		wantErr      bool
+	}{
+		{name: "new key", ruleID: first.ID, key: "trade-1", payload: `{"n":1}`, wantInserted: true},
+		{name: "same rule and key", ruleID: first.ID, key: "trade-1", payload: `{"n":2}`},
+		{name: "same key on another rule", ruleID: second.ID, key: "trade-1", wantInserted: true},
+		{name: "another key", ruleID: first.ID, key: "trade-2", wantInserted: true},
+		{name: "unknown rule", ruleID: 999, key: "trade-1", wantErr: true},
+		{name: "invalid payload", ruleID: first.ID, key: "trade-3", payload: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery, inserted, err := store.InsertAlertDelivery(ctx, AlertDelivery{RuleID: tt.ruleID, DedupeKey: tt.key, Payload: json.RawMessage(tt.payload)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InsertAlertDelivery err = %v, want error %v", err, tt.wantErr)
+			}
+			if inserted != tt.wantInserted {
+				t.Errorf("inserted = %v, want %v", inserted, tt.wantInserted)
+			}
+			if inserted && (delivery.ID == 0 || delivery.Status != AlertDeliveryPending || delivery.NextAttemptAt.IsZero()) {
+				t.Errorf("inserted delivery = %+v, want a pending delivery due now", delivery)
+			}
+		})
+	}
+
+	deliveries, err := store.ListAlertDeliveries(ctx, first.ID, "", 0)
+	if err != nil || len(deliveries) != 2 {
+		t.Fatalf("ListAlertDeliveries(first) = %d, %v; want 2", len(deliveries), err)
+	}
+	if got := string(deliveries[1].Payload); got != `{"n":1}` {
+		t.Errorf("deduplicated delivery payload = %s, want the first one", got)
+	}
+
+	due, err := store.ListDueAlertDeliveries(ctx, time.Now().Add(time.Second), 10)
+	if err != nil || len(due) != 3 {
+		t.Fatalf("ListDueAlertDeliveries = %d, %v; want 3", len(due), err)
+	}
+	sent := due[0]
+	deliveredAt := time.Now().UTC()
+	sent.Status, sent.Attempts, sent.DeliveredAt = AlertDeliveryDelivered, 1, &deliveredAt
+	if err := store.SaveAlertDeliveryAttempt(ctx, sent); err != nil {
+		t.Fatalf("SaveAlertDeliveryAttempt: %v", err)
+	}
+	if due, _ := store.ListDueAlertDeliveries(ctx, time.Now().Add(time.Second), 10); len(due) != 2 {
+		t.Errorf("ListDueAlertDeliveries after delivery = %d, want 2", len(due))
+	}
+	// A retried delivery is still the same row, so its key stays taken.
+	if ok, err := store.RetryAlertDelivery(ctx, sent.ID); err != nil || !ok {
+		t.Fatalf("RetryAlertDelivery = %v, %v", ok, err)
+	}
+	if _, inserted, _ := store.InsertAlertDelivery(ctx, AlertDelivery{RuleID: sent.RuleID, DedupeKey: sent.DedupeKey}); inserted {
+		t.Error("InsertAlertDelivery after retry inserted a duplicate")
+	}
+
+	// Deleting a rule drops its log, freeing its keys.
+	if ok, err := store.DeleteAlertRule(ctx, second.ID); err != nil || !ok {
+		t.Fatalf("DeleteAlertRule = %v, %v", ok, err)
+	}
+	if _, _, err := store.InsertAlertDelivery(ctx, AlertDelivery{RuleID: second.ID, DedupeKey: "trade-1"}); err == nil {
+		t.Error("InsertAlertDelivery for a deleted rule succeeded")
+	}
+}
+
+func TestFileStoreCatalogCursor(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+
+	var wallets []TrackedWallet
+	for i, address := range []string{"0x01", "0x02", "0x03", "0x04", "0x05"} {
+		// Ranks tie in pairs, so pages must break ties by address.
+		wallets = append(wallets, TrackedWallet{WalletAddress: address, Sport: "nba", SourceRank: 1 + i/2, PnlUSD: float64(100 - 10*i)})
+	}
+	if err := store.UpsertTrackedWallets(ctx, wallets); err != nil {
+		t.Fatalf("UpsertTrackedWallets: %v", err)
+	}
+
+	var (
+		got    []string
+		after  *WalletCursor
+		tokens int
+	)
+	for {
+		page, next, err := store.QueryWallets(ctx, WalletQuery{Sport: "nba", Limit: 2, After: after})
+		if err != nil {
+			t.Fatalf("QueryWallets: %v", err)
+		}
+		got = append(got, walletAddresses(page, func(w CatalogWallet) string { return w.WalletAddress })...)
+		if next == nil {
+			break
+		}
+		// Cursors reach clients as tokens, so resume from the decoded one.
+		cursor, err := DecodeWalletCursor(next.Encode())
+		if err != nil {
+			t.Fatalf("DecodeWalletCursor: %v", err)
+		}
+		after = &cursor
+		if tokens++; tokens > len(wallets) {
+			t.Fatal("QueryWallets did not reach the last page")
+		}
+	}
+	want := []string{"0x01", "0x02", "0x03", "0x04", "0x05"}
+	if !equalStrings(got, want) {
+		t.Errorf("paged wallets = %v, want %v", got, want)
+	}
+
+	if _, _, err := store.QueryWallets(ctx, WalletQuery{Sort: "unknown"}); err == nil {
+		t.Error("QueryWallets with an unknown sort succeeded")
+	}
+	if _, err := DecodeWalletCursor("not a cursor"); err == nil {
+		t.Error("DecodeWalletCursor accepted a malformed token")
+	}
+	if _, err := DecodeWalletCursor("e30"); err == nil { // {}
+		t.Error("DecodeWalletCursor accepted a cursor without a sort")
+	}
+}
@@ -0,0 +1,458 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (s *FileStore) UpsertTrackedWallets(ctx context.Context, wallets []TrackedWallet) error {
+	if len(wallets) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, wallet := range wallets {
+		s.data.TrackedWallets[fileKey(wallet.WalletAddress, wallet.Sport)] = wallet
+	}
+	s.dirty = true
+	return nil
+}
+
+// ListTrackedWallets returns the leaderboard wallets tracked for sport,
+// best rank first.
+func (s *FileStore) ListTrackedWallets(ctx context.Context, sport string) ([]TrackedWallet, error) {
+	sport = strings.ToLower(sport)
+
+	s.mu.Lock()
+	wallets := []TrackedWallet{}
+	for _, wallet := range s.data.TrackedWallets {
+		if wallet.Sport == sport {
+			wallets = append(wallets, wallet)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(wallets, func(i, j int) bool {
+		if wallets[i].SourceRank != wallets[j].SourceRank {
+			return wallets[i].SourceRank < wallets[j].SourceRank
+		}
+		return wallets[i].WalletAddress < wallets[j].WalletAddress
+	})
+	return wallets, nil
+}
+
+func (s *FileStore) UpsertWalletProfile(ctx context.Context, profile WalletProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Profiles[fileKey(profile.WalletAddress, profile.Sport)] = profile
+	s.dirty = true
+	return nil
+}
+
+type fileStyleRow struct {
+	score   float64
+	analyze time.Time
+	wallet  StyleWallet
+}
+
+func (s *FileStore) ListStyleGroups(ctx context.Context, sport string, limitPerGroup int, groupBy GroupBy) ([]StyleGroup, error) {
+	if limitPerGroup <= 0 {
+		limitPerGroup = 6
+	}
+	sport = strings.ToLower(sport)
+
+	s.mu.Lock()
+	byGroup := map[string][]fileStyleRow{}
+	for key, profile := range s.data.Profiles {
+		if profile.Sport != sport {
+			continue
+		}
+		tracked, ok := s.data.TrackedWallets[key]
+		if !ok {
+			continue
+		}
+
+		group := profile.AIStyleLabel
+		if groupBy == GroupByCluster {
+			assignment, ok := s.data.WalletClusters[key]
+			if !ok {
+				continue
+			}
+			label, ok := s.clusterLabel(assignment.RunID, assignment.ClusterID)
+			if !ok {
+				continue
+			}
+			group = label
+		} else if group == "" {
+			continue
+		}
+
+		byGroup[group] = append(byGroup[group], fileStyleRow{
+			score:   profile.PresentationScore,
+			analyze: profile.AnalyzedAt,
+			wallet: StyleWallet{
+				WalletAddress:     tracked.WalletAddress,
+				Sport:             tracked.Sport,
+				DisplayName:       tracked.DisplayName,
+				SourceRank:        tracked.SourceRank,
+				WinRate:           tracked.WinRate,
+				PnlUSD:            tracked.PnlUSD,
+				SportTrades:       profile.SportTrades,
+				EntryTimingHours:  profile.EntryTimingHours,
+				SizeRatioPct:      profile.SizeRatioPct,
+				Conviction:        profile.Conviction,
+				StyleLabel:        profile.AIStyleLabel,
+				StyleSummary:      profile.AIStyleSummary,
+				ExplanationSource: profile.ExplanationSource,
+			},
+		})
+	}
+	s.mu.Unlock()
+
+	labels := make([]string, 0, len(byGroup))
+	for label := range byGroup {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	groups := make([]StyleGroup, 0, len(labels))
+	for _, label := range labels {
+		rows := byGroup[label]
+		sort.Slice(rows, func(i, j int) bool {
+			a, b := rows[i], rows[j]
+			if a.wallet.SourceRank != b.wallet.SourceRank {
+				return a.wallet.SourceRank < b.wallet.SourceRank
+			}
+			if a.score != b.score {
+				return a.score > b.score
+			}
+			if !a.analyze.Equal(b.analyze) {
+				return a.analyze.After(b.analyze)
+			}
+			return a.wallet.WalletAddress < b.wallet.WalletAddress
+		})
+		rows = applyLimit(rows, limitPerGroup)
+
+		wallets := make([]StyleWallet, 0, len(rows))
+		for _, row := range rows {
+			wallets = append(wallets, row.wallet)
+		}
+		groups = append(groups, StyleGroup{Label: label, Wallets: wallets})
+	}
+	return groups, nil
+}
+
+// clusterLabel looks up a cluster's label; the caller holds s.mu.
+func (s *FileStore) clusterLabel(runID int64, clusterID int) (string, bool) {
+	for _, cluster := range s.data.Clusters[runID] {
+		if cluster.ClusterID == clusterID {
+			return cluster.Label, true
+		}
+	}
+	return "", false
+}
+
+// profileVector joins a profile with its tracked wallet; the caller holds
+// s.mu. The boolean is false when the wallet is not tracked or the profile
+// was never analyzed.
+func (s *FileStore) profileVector(key string, profile WalletProfile) (ProfileVector, bool) {
+	tracked, ok := s.data.TrackedWallets[key]
+	if !ok || profile.AnalyzedAt.IsZero() {
+		return ProfileVector{}, false
+	}
+	return ProfileVector{
+		WalletAddress:    profile.WalletAddress,
+		Sport:            profile.Sport,
+		DisplayName:      tracked.DisplayName,
+		SourceRank:       tracked.SourceRank,
+		PnlUSD:           tracked.PnlUSD,
+		WinRate:          tracked.WinRate,
+		SportTrades:      profile.SportTrades,
+		EntryTimingHours: profile.EntryTimingHours,
+		SizeRatioPct:     profile.SizeRatioPct,
+		Conviction:       profile.Conviction,
+		StyleLabel:       profile.AIStyleLabel,
+		ROI:              profile.ROI(),
+	}, true
+}
+
+// ListProfileVectors returns the persisted style metrics of every analyzed
+// wallet matching filter. An empty sport matches profiles of every sport.
+func (s *FileStore) ListProfileVectors(ctx context.Context, filter ProfileFilter) ([]ProfileVector, error) {
+	sport := strings.ToLower(filter.Sport)
+
+	s.mu.Lock()
+	var vectors []ProfileVector
+	for key, profile := range s.data.Profiles {
+		if sport != "" && profile.Sport != sport {
+			continue
+		}
+		vector, ok := s.profileVector(key, profile)
+		if !ok || vector.SportTrades < filter.MinTrades {
+			continue
+		}
+		if filter.MinPnlUSD != nil && vector.PnlUSD < *filter.MinPnlUSD {
+			continue
+		}
+		if filter.MaxPnlUSD != nil && vector.PnlUSD > *filter.MaxPnlUSD {
+			continue
+		}
+		vectors = append(vectors, vector)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(vectors, func(i, j int) bool {
+		if vectors[i].Sport != vectors[j].Sport {
+			return vectors[i].Sport < vectors[j].Sport
+		}
+		return vectors[i].WalletAddress < vectors[j].WalletAddress
+	})
+	return vectors, nil
+}
+
+// GetProfileVector returns the stored style metrics for one wallet in one
+// sport. The boolean is false when that profile has not been analyzed.
+func (s *FileStore) GetProfileVector(ctx context.Context, wallet, sport string) (ProfileVector, bool, error) {
+	key := fileKey(strings.ToLower(wallet), strings.ToLower(sport))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile, ok := s.data.Profiles[key]
+	if !ok {
+		return ProfileVector{}, false, nil
+	}
+	vector, ok := s.profileVector(key, profile)
+	return vector, ok, nil
+}
+
+// SaveStyleClusterRun records a clustering run and replaces the current
+// cluster assignment of every wallet in the run's sport.
+func (s *FileStore) SaveStyleClusterRun(ctx context.Context, run StyleClusterRun, clusters []StyleCluster, assignments []WalletCluster) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	run.ID = s.data.nextID("style_cluster_runs")
+	run.Features = cloneStrings(run.Features)
+	run.CreatedAt = now
+	s.data.ClusterRuns[run.ID] = run
+
+	stored := make([]StyleCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		centroid := make(map[string]float64, len(cluster.Centroid))
+		for feature, value := range cluster.Centroid {
+			centroid[feature] = value
+		}
+		cluster.Centroid = centroid
+		stored = append(stored, cluster)
+	}
+	s.data.Clusters[run.ID] = stored
+
+	for key, assignment := range s.data.WalletClusters {
+		if assignment.Sport == run.Sport {
+			delete(s.data.WalletClusters, key)
+		}
+	}
+	for _, assignment := range assignments {
+		s.data.WalletClusters[fileKey(assignment.WalletAddress, run.Sport)] = fileWalletCluster{
+			WalletAddress: assignment.WalletAddress,
+			Sport:         run.Sport,
+			RunID:         run.ID,
+			ClusterID:     assignment.ClusterID,
+			Distance:      assignment.Distance,
+			AssignedAt:    now,
+		}
+	}
+	s.dirty = true
+	return run.ID, nil
+}
+
+func (s *FileStore) ListWalletSyncStates(ctx context.Context, sport string) ([]WalletSyncState, error) {
+	sport = strings.ToLower(sport)
+
+	s.mu.Lock()
+	result := []WalletSyncState{}
+	for _, state := range s.data.SyncStates {
+		if state.Sport == sport {
+			state.LastTradeAt = cloneTime(state.LastTradeAt)
+			state.LastAnalyzedAt = cloneTime(state.LastAnalyzedAt)
+			result = append(result, state)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].WalletAddress < result[j].WalletAddress })
+	return result, nil
+}
+
+func (s *FileStore) SaveWalletSyncState(ctx context.Context, state WalletSyncState) error {
+	state.Sport = strings.ToLower(state.Sport)
+	state.LastTradeAt = cloneTime(state.LastTradeAt)
+	state.LastAnalyzedAt = cloneTime(state.LastAnalyzedAt)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.SyncStates[fileKey(state.WalletAddress, state.Sport)] = state
+	s.dirty = true
+	return nil
+}
+
+// SnapshotWallets appends the current tracked row and profile of each
+// wallet under one capture time. Capturing the same wallet twice at the
+// same time is a no-op.
+func (s *FileStore) SnapshotWallets(ctx context.Context, sport string, wallets []string, capturedAt time.Time) error {
+	if len(wallets) == 0 {
+		return nil
+	}
+	sport = strings.ToLower(sport)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, wallet := range wallets {
+		key := fileKey(wallet, sport)
+		tracked, ok := s.data.TrackedWallets[key]
+		if !ok {
+			continue
+		}
+
+		snapshot := WalletSnapshot{
+			WalletAddress: strings.ToLower(tracked.WalletAddress),
+			Sport:         tracked.Sport,
+			CapturedAt:    capturedAt,
+			DisplayName:   tracked.DisplayName,
+			SourceRank:    tracked.SourceRank,
+			WinRate:       tracked.WinRate,
+			PnlUSD:        tracked.PnlUSD,
+			VolumeUSD:     tracked.VolumeUSD,
+		}
+		if profile, ok := s.data.Profiles[key]; ok {
+			sportTrades, timing, sizeRatio, conviction, label := profile.SportTrades, profile.EntryTimingHours, profile.SizeRatioPct, profile.Conviction, profile.AIStyleLabel
+			snapshot.SportTrades = &sportTrades
+			snapshot.EntryTimingHours = &timing
+			snapshot.SizeRatioPct = &sizeRatio
+			snapshot.Conviction = &conviction
+			snapshot.StyleLabel = &label
+			if !profile.AnalyzedAt.IsZero() {
+				analyzedAt := profile.AnalyzedAt
+				snapshot.AnalyzedAt = &analyzedAt
+			}
+		}
+
+		snapshotKey := fileKey(snapshot.WalletAddress, snapshot.Sport)
+		history := s.data.Snapshots[snapshotKey]
+		i := sort.Search(len(history), func(i int) bool { return !history[i].CapturedAt.Before(capturedAt) })
+		if i < len(history) && history[i].CapturedAt.Equal(capturedAt) {
+			continue
+		}
+		history = append(history, WalletSnapshot{})
+		copy(history[i+1:], history[i:])
+		history[i] = snapshot
+		s.data.Snapshots[snapshotKey] = history
+	}
+	s.dirty = true
+	return nil
+}
+
+// ListWalletSnapshots returns a wallet's snapshots for a sport captured at
+// or after since, oldest first, keeping the most recent limit when limit is
+// positive.
+func (s *FileStore) ListWalletSnapshots(ctx context.Context, wallet, sport string, since time.Time, limit int) ([]WalletSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var snapshots []WalletSnapshot
+	for _, snapshot := range s.data.Snapshots[fileKey(strings.ToLower(wallet), strings.ToLower(sport))] {
+		if !snapshot.CapturedAt.Before(since) {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return applyTailLimit(snapshots, limit), nil
+}
+
+// ListWalletMovers compares each wallet's snapshot from the sport's latest
+// sync with its latest snapshot at or before filter.Since and returns the
+// wallets that moved most by the chosen metric. Wallets missing from the
+// latest sync have left the leaderboard, and wallets first captured after
+// Since have no baseline; both are left out.
+func (s *FileStore) ListWalletMovers(ctx context.Context, filter MoverFilter) ([]WalletMover, error) {
+	metric := filter.Metric
+	if metric == "" {
+		metric = MoverByRank
+	}
+	if _, ok := moverOrder[metric]; !ok {
+		return nil, fmt.Errorf("unsupported mover metric: %s", metric)
+	}
+	sport := strings.ToLower(filter.Sport)
+
+	s.mu.Lock()
+	var batch time.Time
+	for _, history := range s.data.Snapshots {
+		if len(history) > 0 && history[0].Sport == sport && history[len(history)-1].CapturedAt.After(batch) {
+			batch = history[len(history)-1].CapturedAt
+		}
+	}
+	var movers []WalletMover
+	for _, history := range s.data.Snapshots {
+		if len(history) == 0 || history[0].Sport != sport {
+			continue
+		}
+		latest := history[len(history)-1]
+		if !latest.CapturedAt.Equal(batch) {
+			continue
+		}
+		i := sort.Search(len(history), func(i int) bool { return history[i].CapturedAt.After(filter.Since) })
+		if i == 0 {
+			continue
+		}
+		baseline := history[i-1]
+		if !latest.CapturedAt.After(baseline.CapturedAt) {
+			continue
+		}
+
+		mover := WalletMover{
+			WalletAddress:      latest.WalletAddress,
+			Sport:              latest.Sport,
+			DisplayName:        latest.DisplayName,
+			CapturedAt:         latest.CapturedAt,
+			SourceRank:         latest.SourceRank,
+			PnlUSD:             latest.PnlUSD,
+			PreviousCapturedAt: baseline.CapturedAt,
+			PreviousRank:       baseline.SourceRank,
+			PreviousPnlUSD:     baseline.PnlUSD,
+		}
+		if latest.StyleLabel != nil {
+			mover.StyleLabel = *latest.StyleLabel
+		}
+		if baseline.StyleLabel != nil {
+			mover.PreviousStyleLabel = *baseline.StyleLabel
+		}
+		mover.RankChange = mover.PreviousRank - mover.SourceRank
+		mover.PnlChangeUSD = mover.PnlUSD - mover.PreviousPnlUSD
+		mover.StyleChanged = mover.StyleLabel != "" && mover.PreviousStyleLabel != "" && mover.StyleLabel != mover.PreviousStyleLabel
+		movers = append(movers, mover)
+	}
+	s.mu.Unlock()
+
+	magnitude := func(m WalletMover) float64 {
+		if metric == MoverByPnl {
+			return math.Abs(m.PnlChangeUSD)
+		}
+		return math.Abs(float64(m.RankChange))
+	}
+	sort.Slice(movers, func(i, j int) bool {
+		a, b := magnitude(movers[i]), magnitude(movers[j])
+		if a != b {
+			return a > b
+		}
+		if movers[i].SourceRank != movers[j].SourceRank {
+			return movers[i].SourceRank < movers[j].SourceRank
+		}
+		return movers[i].WalletAddress < movers[j].WalletAddress
+	})
+	return applyLimit(movers, filter.Limit), nil
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AddWatchlistWallets adds wallets to the named watchlist, creating it when
+// it does not exist yet.
+func (s *FileStore) AddWatchlistWallets(ctx context.Context, name string, wallets []string) error {
+	if len(wallets) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watchlist := s.data.Watchlists[name]
+	if watchlist == nil {
+		watchlist = map[string]time.Time{}
+		s.data.Watchlists[name] = watchlist
+	}
+	for _, wallet := range wallets {
+		wallet = strings.ToLower(wallet)
+		if _, ok := watchlist[wallet]; !ok {
+			watchlist[wallet] = now
+		}
+	}
+	s.dirty = true
+	return nil
+}
+
+// RemoveWatchlistWallet removes one wallet from the named watchlist and
+// reports whether it was on it.
+func (s *FileStore) RemoveWatchlistWallet(ctx context.Context, name, wallet string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watchlist := s.data.Watchlists[name]
+	wallet = strings.ToLower(wallet)
+	if _, ok := watchlist[wallet]; !ok {
+		return false, nil
+	}
+	delete(watchlist, wallet)
+	if len(watchlist) == 0 {
+		delete(s.data.Watchlists, name)
+	}
+	s.dirty = true
+	return true, nil
+}
+
+// ListWatchlists returns every watchlist with its wallets, by name.
+func (s *FileStore) ListWatchlists(ctx context.Context) ([]Watchlist, error) {
+	s.mu.Lock()
+	result := make([]Watchlist, 0, len(s.data.Watchlists))
+	for name, wallets := range s.data.Watchlists {
+		watchlist := Watchlist{Name: name, Wallets: make([]string, 0, len(wallets))}
+		for wallet, addedAt := range wallets {
+			watchlist.Wallets = append(watchlist.Wallets, wallet)
+			if addedAt.After(watchlist.UpdatedAt) {
+				watchlist.UpdatedAt = addedAt
+			}
+		}
+		sort.Strings(watchlist.Wallets)
+		result = append(result, watchlist)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// ListWatchedWallets returns every wallet that is tracked for a sport or on
+// a watchlist, by address.
+func (s *FileStore) ListWatchedWallets(ctx context.Context) ([]WatchedWallet, error) {
+	type membership struct {
+		sports     map[string]bool
+		watchlists map[string]bool
+	}
+	watched := map[string]*membership{}
+	entry := func(wallet string) *membership {
+		m, ok := watched[wallet]
+		if !ok {
+			m = &membership{sports: map[string]bool{}, watchlists: map[string]bool{}}
+			watched[wallet] = m
+		}
+		return m
+	}
+
+	s.mu.Lock()
+	for _, tracked := range s.data.TrackedWallets {
+		m := entry(strings.ToLower(tracked.WalletAddress))
+		if tracked.Sport != "" {
+			m.sports[tracked.Sport] = true
+		}
+	}
+	for name, wallets := range s.data.Watchlists {
+		for wallet := range wallets {
+			m := entry(wallet)
+			if name != "" {
+				m.watchlists[name] = true
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	result := make([]WatchedWallet, 0, len(watched))
+	for wallet, m := range watched {
+		result = append(result, WatchedWallet{
+			WalletAddress: wallet,
+			Sports:        sortedKeys(m.sports),
+			Watchlists:    sortedKeys(m.watchlists),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].WalletAddress < result[j].WalletAddress })
+	return result, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Game is a persisted matchup keyed by its Gamma event ID.
+type Game struct {
+	ID             string
+	Sport          string
+	League         string
+	Slug           string
+	Title          string
+	HomeTeam       string
+	AwayTeam       string
+	ScheduledStart *time.Time
+	Status         string
+	Score          string
+	HomeScore      *int
+	AwayScore      *int
+	Period         string
+	Markets        []GameMarket
+}
+
+type GameMarket struct {
+	ConditionID string
+	Question    string
+	Slug        string
+	MarketType  string
+	Closed      bool
+}
+
+// UpsertGames writes games and links each of their markets to them.
+func (s *PostgresStore) UpsertGames(ctx context.Context, games []Game) error {
+	if len(games) == 0 {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin game upsert: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const gameQuery = `
+INSERT INTO games (
+  id, sport, league, slug, title, home_team, away_team, scheduled_start,
+  status, score, home_score, away_score, period, updated_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())
+ON CONFLICT (id) DO UPDATE SET
+  sport = EXCLUDED.sport,
+  league = EXCLUDED.league,
+  slug = EXCLUDED.slug,
+  title = EXCLUDED.title,
+  home_team = EXCLUDED.home_team,
+  away_team = EXCLUDED.away_team,
+  scheduled_start = EXCLUDED.scheduled_start,
+  status = EXCLUDED.status,
+  score = EXCLUDED.score,
+  home_score = EXCLUDED.home_score,
+  away_score = EXCLUDED.away_score,
+  period = EXCLUDED.period,
+  updated_at = NOW()`
+
+	const marketQuery = `
+INSERT INTO game_markets (condition_id, game_id, question, slug, market_type, closed)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (condition_id) DO UPDATE SET
+  game_id = EXCLUDED.game_id,
+  question = EXCLUDED.question,
+  slug = EXCLUDED.slug,
+  market_type = EXCLUDED.market_type,
+  closed = EXCLUDED.closed`
+
+	for _, game := range games {
+		if _, err := tx.Exec(ctx, gameQuery,
+			game.ID, game.Sport, game.League, game.Slug, game.Title, game.HomeTeam, game.AwayTeam,
+			game.ScheduledStart, game.Status, game.Score, game.HomeScore, game.AwayScore, game.Period,
+		); err != nil {
+			return fmt.Errorf("upsert game %s: %w", game.ID, err)
+		}
+		for _, market := range game.Markets {
+			if _, err := tx.Exec(ctx, marketQuery,
+				market.ConditionID, game.ID, market.Question, market.Slug, market.MarketType, market.Closed,
+			); err != nil {
+				return fmt.Errorf("upsert game market %s: %w", market.ConditionID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit game upsert: %w", err)
+	}
+	return nil
+}
+
+// ListGames returns every persisted game with its markets.
+func (s *PostgresStore) ListGames(ctx context.Context) ([]Game, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT id, sport, league, slug, title, home_team, away_team, scheduled_start,
+       status, score, home_score, away_score, period
+FROM games`)
+	if err != nil {
+		return nil, fmt.Errorf("query games: %w", err)
+	}
+	defer rows.Close()
+
+	games := []Game{}
+	byID := map[string]int{}
+	for rows.Next() {
+		var game Game
+		if err := rows.Scan(
+			&game.ID, &game.Sport, &game.League, &game.Slug, &game.Title, &game.HomeTeam, &game.AwayTeam,
+			&game.ScheduledStart, &game.Status, &game.Score, &game.HomeScore, &game.AwayScore, &game.Period,
+		); err != nil {
+			return nil, fmt.Errorf("scan game: %w", err)
+		}
+		byID[game.ID] = len(games)
+		games = append(games, game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate games: %w", err)
+	}
+
+	marketRows, err := s.pool.Query(ctx, `
+SELECT game_id, condition_id, question, slug, market_type, closed
+FROM game_markets
+ORDER BY game_id, condition_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query game markets: %w", err)
+	}
+	defer marketRows.Close()
+
+	for marketRows.Next() {
+		var (
+			gameID string
+			market GameMarket
+		)
+		if err := marketRows.Scan(&gameID, &market.ConditionID, &market.Question, &market.Slug, &market.MarketType, &market.Closed); err != nil {
+			return nil, fmt.Errorf("scan game market: %w", err)
+		}
+		if i, ok := byID[gameID]; ok {
+			games[i].Markets = append(games[i].Markets, market)
+		}
+	}
+	if err := marketRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate game markets: %w", err)
+	}
+	return games, nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SchedulerLease is a named lease that at most one instance holds until it
+// expires.
+type SchedulerLease struct {
+	Name       string    `json:"name"`
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// AcquireLease takes the named lease for holder, or renews it when holder
+// already has it, extending it by ttl. It reports false when another holder
+// has an unexpired lease. Expiry is judged by the database clock, so
+// instances with skewed clocks agree.
+func (s *PostgresStore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (SchedulerLease, bool, error) {
+	const query = `
+INSERT INTO scheduler_leases (name, holder, acquired_at, renewed_at, expires_at)
+VALUES ($1, $2, NOW(), NOW(), NOW() + make_interval(secs => $3))
+ON CONFLICT (name) DO UPDATE SET
+  holder = EXCLUDED.holder,
+  acquired_at = CASE
+    WHEN scheduler_leases.holder = EXCLUDED.holder THEN scheduler_leases.acquired_at
+    ELSE EXCLUDED.acquired_at
+  END,
+  renewed_at = EXCLUDED.renewed_at,
+  expires_at = EXCLUDED.expires_at
+WHERE scheduler_leases.holder = EXCLUDED.holder OR scheduler_leases.expires_at < NOW()
+RETURNING name, holder, acquired_at, renewed_at, expires_at`
+
+	var lease SchedulerLease
+	err := s.pool.QueryRow(ctx, query, name, holder, ttl.Seconds()).Scan(
+		&lease.Name,
+		&lease.Holder,
+		&lease.AcquiredAt,
+		&lease.RenewedAt,
+		&lease.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SchedulerLease{}, false, nil
+	}
+	if err != nil {
+		return SchedulerLease{}, false, fmt.Errorf("acquire lease %s: %w", name, err)
+	}
+	return lease, true, nil
+}
+
+// ReleaseLease gives up the named lease if holder has it, so another
+// instance can take over without waiting for it to expire.
+func (s *PostgresStore) ReleaseLease(ctx context.Context, name, holder string) error {
+	if _, err := s.pool.Exec(ctx, `DELETE FROM scheduler_leases WHERE name = $1 AND holder = $2`, name, holder); err != nil {
+		return fmt.Errorf("release lease %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetLease returns the named lease, expired or not.
+func (s *PostgresStore) GetLease(ctx context.Context, name string) (SchedulerLease, bool, error) {
+	const query = `
+SELECT name, holder, acquired_at, renewed_at, expires_at
+FROM scheduler_leases
+WHERE name = $1`
+
+	var lease SchedulerLease
+	err := s.pool.QueryRow(ctx, query, name).Scan(
+		&lease.Name,
+		&lease.Holder,
+		&lease.AcquiredAt,
+		&lease.RenewedAt,
+		&lease.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SchedulerLease{}, false, nil
+	}
+	if err != nil {
+		return SchedulerLease{}, false, fmt.Errorf("get lease %s: %w", name, err)
+	}
+	return lease, true, nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MarketSport is the persisted sport classification of one market.
+type MarketSport struct {
+	ConditionID string    `json:"condition_id"`
+	Sport       string    `json:"sport"`
+	League      string    `json:"league"`
+	EventID     string    `json:"event_id"`
+	EventSlug   string    `json:"event_slug"`
+	EventTitle  string    `json:"event_title"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (s *PostgresStore) UpsertMarketSports(ctx context.Context, markets []MarketSport) error {
+	if len(markets) == 0 {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin market sport upsert: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const query = `
+INSERT INTO market_sports (condition_id, sport, league, event_id, event_slug, event_title, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (condition_id) DO UPDATE SET
+  sport = EXCLUDED.sport,
+  league = EXCLUDED.league,
+  event_id = EXCLUDED.event_id,
+  event_slug = EXCLUDED.event_slug,
+  event_title = EXCLUDED.event_title,
+  updated_at = NOW()`
+
+	for _, market := range markets {
+		if _, err := tx.Exec(ctx, query,
+			market.ConditionID, market.Sport, market.League, market.EventID, market.EventSlug, market.EventTitle,
+		); err != nil {
+			return fmt.Errorf("upsert market sport %s: %w", market.ConditionID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit market sport upsert: %w", err)
+	}
+	return nil
+}
+
+// ListMarketSports returns every persisted market classification.
+func (s *PostgresStore) ListMarketSports(ctx context.Context) ([]MarketSport, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT condition_id, sport, league, event_id, event_slug, event_title, updated_at
+FROM market_sports`)
+	if err != nil {
+		return nil, fmt.Errorf("query market sports: %w", err)
+	}
+	defer rows.Close()
+
+	markets := []MarketSport{}
+	for rows.Next() {
+		var market MarketSport
+		if err := rows.Scan(
+			&market.ConditionID, &market.Sport, &market.League,
+			&market.EventID, &market.EventSlug, &market.EventTitle, &market.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan market sport: %w", err)
+		}
+		markets = append(markets, market)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate market sports: %w", err)
+	}
+	return markets, nil
+}
@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS wallet_sync_state;
+DROP TABLE IF EXISTS markets;
+DROP TABLE IF EXISTS trades;
+DROP TABLE IF EXISTS alert_deliveries;
+DROP TABLE IF EXISTS alert_rules;
+DROP TABLE IF EXISTS watchlist_wallets;
+DROP TABLE IF EXISTS paper_equity;
+DROP TABLE IF EXISTS paper_positions;
+DROP TABLE IF EXISTS paper_orders;
+DROP TABLE IF EXISTS paper_portfolios;
+DROP TABLE IF EXISTS market_consensus;
+DROP TABLE IF EXISTS game_markets;
+DROP TABLE IF EXISTS games;
+DROP TABLE IF EXISTS market_sports;
+DROP TABLE IF EXISTS wallet_style_clusters;
+DROP TABLE IF EXISTS style_clusters;
+DROP TABLE IF EXISTS style_cluster_runs;
+DROP TABLE IF EXISTS wallet_profiles;
+DROP TABLE IF EXISTS tracked_wallets;
@@ -0,0 +1,323 @@
+CREATE TABLE IF NOT EXISTS tracked_wallets (
+  wallet_address TEXT NOT NULL,
+  sport TEXT NOT NULL DEFAULT 'nba',
+  display_name TEXT NOT NULL,
+  source TEXT NOT NULL,
+  source_rank INTEGER NOT NULL DEFAULT 0,
+  source_predictions INTEGER NOT NULL DEFAULT 0,
+  source_wins INTEGER NOT NULL DEFAULT 0,
+  volume_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  loss_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  win_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+  open_positions_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  pnl_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (wallet_address, sport)
+);
+
+CREATE TABLE IF NOT EXISTS wallet_profiles (
+  wallet_address TEXT NOT NULL,
+  sport TEXT NOT NULL DEFAULT 'nba',
+  sport_trades INTEGER NOT NULL DEFAULT 0,
+  recent_markets INTEGER NOT NULL DEFAULT 0,
+  entry_timing_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+  size_ratio_pct DOUBLE PRECISION NOT NULL DEFAULT 0,
+  conviction DOUBLE PRECISION NOT NULL DEFAULT 0,
+  deterministic_style_label TEXT NOT NULL DEFAULT '',
+  ai_style_label TEXT NOT NULL DEFAULT '',
+  ai_style_summary TEXT NOT NULL DEFAULT '',
+  explanation_source TEXT NOT NULL DEFAULT 'fallback',
+  model TEXT NOT NULL DEFAULT '',
+  presentation_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+  analyzed_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (wallet_address, sport),
+  FOREIGN KEY (wallet_address, sport) REFERENCES tracked_wallets(wallet_address, sport) ON DELETE CASCADE
+);
+
+-- Upgrade single-sport deployments where both tables were keyed by
+-- wallet_address alone and profiles counted nba_trades.
+ALTER TABLE tracked_wallets ADD COLUMN IF NOT EXISTS sport TEXT NOT NULL DEFAULT 'nba';
+ALTER TABLE wallet_profiles ADD COLUMN IF NOT EXISTS sport TEXT NOT NULL DEFAULT 'nba';
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'wallet_profiles' AND column_name = 'nba_trades'
+  ) THEN
+    ALTER TABLE wallet_profiles RENAME COLUMN nba_trades TO sport_trades;
+  END IF;
+
+  IF EXISTS (
+    SELECT 1 FROM pg_constraint
+    WHERE conname = 'tracked_wallets_pkey' AND array_length(conkey, 1) = 1
+  ) THEN
+    ALTER TABLE wallet_profiles DROP CONSTRAINT IF EXISTS wallet_profiles_wallet_address_fkey;
+    ALTER TABLE wallet_profiles DROP CONSTRAINT IF EXISTS wallet_profiles_pkey;
+    ALTER TABLE tracked_wallets DROP CONSTRAINT tracked_wallets_pkey;
+    ALTER TABLE tracked_wallets ADD PRIMARY KEY (wallet_address, sport);
+    ALTER TABLE wallet_profiles ADD PRIMARY KEY (wallet_address, sport);
+    ALTER TABLE wallet_profiles ADD FOREIGN KEY (wallet_address, sport)
+      REFERENCES tracked_wallets(wallet_address, sport) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_wallet_profiles_ai_style_label;
+CREATE INDEX IF NOT EXISTS idx_wallet_profiles_sport_ai_style_label
+  ON wallet_profiles (sport, ai_style_label, analyzed_at DESC);
+
+DROP INDEX IF EXISTS idx_tracked_wallets_source_rank;
+CREATE INDEX IF NOT EXISTS idx_tracked_wallets_sport_source_rank
+  ON tracked_wallets (sport, source_rank ASC);
+
+CREATE TABLE IF NOT EXISTS style_cluster_runs (
+  id BIGSERIAL PRIMARY KEY,
+  sport TEXT NOT NULL DEFAULT 'nba',
+  k INTEGER NOT NULL,
+  features TEXT[] NOT NULL,
+  silhouette DOUBLE PRECISION NOT NULL DEFAULT 0,
+  inertia DOUBLE PRECISION NOT NULL DEFAULT 0,
+  wallet_count INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS style_clusters (
+  run_id BIGINT NOT NULL REFERENCES style_cluster_runs(id) ON DELETE CASCADE,
+  cluster_id INTEGER NOT NULL,
+  label TEXT NOT NULL DEFAULT '',
+  centroid JSONB NOT NULL DEFAULT '{}'::jsonb,
+  size INTEGER NOT NULL DEFAULT 0,
+  silhouette DOUBLE PRECISION NOT NULL DEFAULT 0,
+  PRIMARY KEY (run_id, cluster_id)
+);
+
+CREATE TABLE IF NOT EXISTS wallet_style_clusters (
+  wallet_address TEXT NOT NULL,
+  sport TEXT NOT NULL,
+  run_id BIGINT NOT NULL REFERENCES style_cluster_runs(id) ON DELETE CASCADE,
+  cluster_id INTEGER NOT NULL,
+  distance DOUBLE PRECISION NOT NULL DEFAULT 0,
+  assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (wallet_address, sport),
+  FOREIGN KEY (wallet_address, sport) REFERENCES tracked_wallets(wallet_address, sport) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS market_sports (
+  condition_id TEXT PRIMARY KEY,
+  sport TEXT NOT NULL,
+  league TEXT NOT NULL DEFAULT '',
+  event_id TEXT NOT NULL DEFAULT '',
+  event_slug TEXT NOT NULL DEFAULT '',
+  event_title TEXT NOT NULL DEFAULT '',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_sports_sport
+  ON market_sports (sport);
+
+CREATE TABLE IF NOT EXISTS games (
+  id TEXT PRIMARY KEY,
+  sport TEXT NOT NULL,
+  league TEXT NOT NULL DEFAULT '',
+  slug TEXT NOT NULL DEFAULT '',
+  title TEXT NOT NULL DEFAULT '',
+  home_team TEXT NOT NULL DEFAULT '',
+  away_team TEXT NOT NULL DEFAULT '',
+  scheduled_start TIMESTAMPTZ,
+  status TEXT NOT NULL DEFAULT 'scheduled',
+  score TEXT NOT NULL DEFAULT '',
+  home_score INTEGER,
+  away_score INTEGER,
+  period TEXT NOT NULL DEFAULT '',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_games_sport_scheduled_start
+  ON games (sport, scheduled_start DESC);
+
+CREATE TABLE IF NOT EXISTS game_markets (
+  condition_id TEXT PRIMARY KEY,
+  game_id TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+  question TEXT NOT NULL DEFAULT '',
+  slug TEXT NOT NULL DEFAULT '',
+  market_type TEXT NOT NULL DEFAULT '',
+  closed BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_markets_game_id
+  ON game_markets (game_id);
+
+CREATE TABLE IF NOT EXISTS market_consensus (
+  sport TEXT NOT NULL,
+  condition_id TEXT NOT NULL,
+  game_id TEXT NOT NULL DEFAULT '',
+  game_title TEXT NOT NULL DEFAULT '',
+  scheduled_start TIMESTAMPTZ,
+  question TEXT NOT NULL DEFAULT '',
+  consensus_outcome TEXT NOT NULL DEFAULT '',
+  consensus_probability DOUBLE PRECISION NOT NULL DEFAULT 0,
+  market_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+  divergence DOUBLE PRECISION NOT NULL DEFAULT 0,
+  agreement_ratio DOUBLE PRECISION NOT NULL DEFAULT 0,
+  avg_entry_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+  wallets INTEGER NOT NULL DEFAULT 0,
+  stake_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (sport, condition_id)
+);
+
+CREATE TABLE IF NOT EXISTS paper_portfolios (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  sport TEXT NOT NULL DEFAULT '',
+  wallets TEXT[] NOT NULL,
+  mode TEXT NOT NULL DEFAULT 'fixed',
+  stake_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  ratio DOUBLE PRECISION NOT NULL DEFAULT 0,
+  delay_seconds INTEGER NOT NULL DEFAULT 0,
+  slippage_bps DOUBLE PRECISION NOT NULL DEFAULT 0,
+  max_exposure_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  bankroll_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  ignore_sells BOOLEAN NOT NULL DEFAULT FALSE,
+  cash DOUBLE PRECISION NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_polled_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS paper_orders (
+  id BIGSERIAL PRIMARY KEY,
+  portfolio_id BIGINT NOT NULL REFERENCES paper_portfolios(id) ON DELETE CASCADE,
+  source_wallet TEXT NOT NULL,
+  source_trade_key TEXT NOT NULL,
+  source_time TIMESTAMPTZ NOT NULL,
+  condition_id TEXT NOT NULL,
+  token_id TEXT NOT NULL DEFAULT '',
+  question TEXT NOT NULL DEFAULT '',
+  outcome TEXT NOT NULL DEFAULT '',
+  side TEXT NOT NULL,
+  wallet_size DOUBLE PRECISION NOT NULL DEFAULT 0,
+  wallet_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+  fill_after TIMESTAMPTZ NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  reason TEXT NOT NULL DEFAULT '',
+  fill_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+  shares DOUBLE PRECISION NOT NULL DEFAULT 0,
+  notional_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  filled_at TIMESTAMPTZ,
+  UNIQUE (portfolio_id, source_trade_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_paper_orders_portfolio_status
+  ON paper_orders (portfolio_id, status, fill_after);
+
+CREATE TABLE IF NOT EXISTS paper_positions (
+  portfolio_id BIGINT NOT NULL REFERENCES paper_portfolios(id) ON DELETE CASCADE,
+  condition_id TEXT NOT NULL,
+  outcome TEXT NOT NULL,
+  token_id TEXT NOT NULL DEFAULT '',
+  question TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'open',
+  shares DOUBLE PRECISION NOT NULL DEFAULT 0,
+  cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  source_shares DOUBLE PRECISION NOT NULL DEFAULT 0,
+  realized_pnl DOUBLE PRECISION NOT NULL DEFAULT 0,
+  mark_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+  opened_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (portfolio_id, condition_id, outcome)
+);
+
+CREATE TABLE IF NOT EXISTS paper_equity (
+  portfolio_id BIGINT NOT NULL REFERENCES paper_portfolios(id) ON DELETE CASCADE,
+  recorded_at TIMESTAMPTZ NOT NULL,
+  cash DOUBLE PRECISION NOT NULL DEFAULT 0,
+  positions_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+  equity DOUBLE PRECISION NOT NULL DEFAULT 0,
+  PRIMARY KEY (portfolio_id, recorded_at)
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_wallets (
+  name TEXT NOT NULL,
+  wallet_address TEXT NOT NULL,
+  added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (name, wallet_address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_wallets_wallet ON watchlist_wallets (wallet_address);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  params JSONB NOT NULL DEFAULT '{}'::jsonb,
+  webhook_url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS alert_deliveries (
+  id BIGSERIAL PRIMARY KEY,
+  rule_id BIGINT NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+  dedupe_key TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  response_status INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  delivered_at TIMESTAMPTZ,
+  UNIQUE (rule_id, dedupe_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_due ON alert_deliveries (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS trades (
+  trade_key TEXT PRIMARY KEY,
+  trade_id TEXT NOT NULL DEFAULT '',
+  transaction_hash TEXT NOT NULL DEFAULT '',
+  wallet_address TEXT NOT NULL,
+  condition_id TEXT NOT NULL,
+  asset TEXT NOT NULL DEFAULT '',
+  side TEXT NOT NULL,
+  size DOUBLE PRECISION NOT NULL,
+  price DOUBLE PRECISION NOT NULL,
+  traded_at TIMESTAMPTZ NOT NULL,
+  outcome TEXT NOT NULL DEFAULT '',
+  title TEXT NOT NULL DEFAULT '',
+  slug TEXT NOT NULL DEFAULT '',
+  fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_trades_wallet_time ON trades (wallet_address, traded_at DESC);
+CREATE INDEX IF NOT EXISTS idx_trades_condition_time ON trades (condition_id, traded_at DESC);
+
+CREATE TABLE IF NOT EXISTS markets (
+  condition_id TEXT PRIMARY KEY,
+  question TEXT NOT NULL DEFAULT '',
+  slug TEXT NOT NULL DEFAULT '',
+  volume_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  start_date TEXT NOT NULL DEFAULT '',
+  end_date TEXT NOT NULL DEFAULT '',
+  closed BOOLEAN NOT NULL DEFAULT FALSE,
+  data JSONB NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS wallet_sync_state (
+  wallet_address TEXT NOT NULL,
+  sport TEXT NOT NULL,
+  last_trade_at TIMESTAMPTZ,
+  last_trade_key TEXT NOT NULL DEFAULT '',
+  last_analyzed_at TIMESTAMPTZ,
+  last_attempt_at TIMESTAMPTZ NOT NULL,
+  last_error TEXT NOT NULL DEFAULT '',
+  consecutive_failures INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (wallet_address, sport)
+);
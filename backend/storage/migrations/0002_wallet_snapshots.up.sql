@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS wallet_snapshots (
+  wallet_address TEXT NOT NULL,
+  sport TEXT NOT NULL,
+  captured_at TIMESTAMPTZ NOT NULL,
+  display_name TEXT NOT NULL DEFAULT '',
+  source_rank INTEGER NOT NULL DEFAULT 0,
+  win_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+  pnl_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  volume_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+  sport_trades INTEGER,
+  entry_timing_hours DOUBLE PRECISION,
+  size_ratio_pct DOUBLE PRECISION,
+  conviction DOUBLE PRECISION,
+  style_label TEXT,
+  analyzed_at TIMESTAMPTZ,
+  PRIMARY KEY (wallet_address, sport, captured_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_snapshots_sport_captured
+  ON wallet_snapshots (sport, captured_at DESC);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS sync_runs (
+  id BIGSERIAL PRIMARY KEY,
+  trigger TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'running',
+  sports TEXT[] NOT NULL DEFAULT '{}',
+  started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMPTZ,
+  wallets_attempted INTEGER NOT NULL DEFAULT 0,
+  wallets_succeeded INTEGER NOT NULL DEFAULT 0,
+  wallets_failed INTEGER NOT NULL DEFAULT 0,
+  wallets_skipped INTEGER NOT NULL DEFAULT 0,
+  ai_calls INTEGER NOT NULL DEFAULT 0,
+  ai_fallbacks INTEGER NOT NULL DEFAULT 0,
+  wallet_errors JSONB NOT NULL DEFAULT '[]',
+  error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_runs_started ON sync_runs (started_at DESC);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS wallet_trade_coverage (
+  wallet_address TEXT PRIMARY KEY,
+  newest_trade_at TIMESTAMPTZ,
+  oldest_trade_at TIMESTAMPTZ,
+  complete BOOLEAN NOT NULL DEFAULT FALSE,
+  synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+ALTER TABLE wallet_sync_state DROP COLUMN IF EXISTS volume_usd;
+ALTER TABLE wallet_sync_state DROP COLUMN IF EXISTS source_predictions;
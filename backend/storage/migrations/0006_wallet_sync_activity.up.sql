@@ -0,0 +1,2 @@
+ALTER TABLE wallet_sync_state ADD COLUMN IF NOT EXISTS source_predictions INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE wallet_sync_state ADD COLUMN IF NOT EXISTS volume_usd DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_sync_runs_running;
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS heartbeat_at;
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS instance_id;
@@ -0,0 +1,3 @@
+-- The sport-keyed cluster tables are the shape 0001_baseline creates, so
+-- rolling back leaves them as they are.
+SELECT 1;
@@ -0,0 +1,26 @@
+-- Upgrade cluster tables created before sport, where runs had no sport and
+-- assignments were keyed by wallet_address alone.
+ALTER TABLE style_cluster_runs ADD COLUMN IF NOT EXISTS sport TEXT NOT NULL DEFAULT 'nba';
+ALTER TABLE wallet_style_clusters ADD COLUMN IF NOT EXISTS sport TEXT NOT NULL DEFAULT 'nba';
+
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM pg_constraint
+    WHERE conname = 'wallet_style_clusters_pkey' AND array_length(conkey, 1) = 1
+  ) THEN
+    ALTER TABLE wallet_style_clusters DROP CONSTRAINT IF EXISTS wallet_style_clusters_wallet_address_fkey;
+    ALTER TABLE wallet_style_clusters DROP CONSTRAINT wallet_style_clusters_pkey;
+    ALTER TABLE wallet_style_clusters ADD PRIMARY KEY (wallet_address, sport);
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint
+    WHERE conrelid = 'wallet_style_clusters'::regclass
+      AND confrelid = 'tracked_wallets'::regclass
+      AND contype = 'f'
+  ) THEN
+    ALTER TABLE wallet_style_clusters ADD FOREIGN KEY (wallet_address, sport)
+      REFERENCES tracked_wallets(wallet_address, sport) ON DELETE CASCADE;
+  END IF;
+END $$;
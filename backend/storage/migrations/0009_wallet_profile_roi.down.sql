@@ -0,0 +1,2 @@
+ALTER TABLE wallet_profiles DROP COLUMN IF EXISTS volume_usd;
+ALTER TABLE wallet_profiles DROP COLUMN IF EXISTS pnl_usd;
@@ -0,0 +1,9 @@
+-- Profiles keep the PnL and volume they were analyzed with, so tools can
+-- weight wallets by the ROI of their stored profile.
+ALTER TABLE wallet_profiles ADD COLUMN IF NOT EXISTS pnl_usd DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE wallet_profiles ADD COLUMN IF NOT EXISTS volume_usd DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+UPDATE wallet_profiles wp
+SET pnl_usd = tw.pnl_usd, volume_usd = tw.volume_usd
+FROM tracked_wallets tw
+WHERE tw.wallet_address = wp.wallet_address AND tw.sport = wp.sport;
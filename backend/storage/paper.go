@@ -0,0 +1,398 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	PaperOrderPending = "pending"
+	PaperOrderFilled  = "filled"
+	PaperOrderSkipped = "skipped"
+
+	PaperPositionOpen    = "open"
+	PaperPositionClosed  = "closed"
+	PaperPositionSettled = "settled"
+)
+
+// PaperPortfolio is a virtual portfolio that mirrors the trades of a set of
+// wallets with sizing rules.
+type PaperPortfolio struct {
+	ID           int64      `json:"id"`
+	Name         string     `json:"name"`
+	Sport        string     `json:"sport"`
+	Wallets      []string   `json:"wallets"`
+	Mode         string     `json:"mode"`
+	StakeUSD     float64    `json:"stake_usd"`
+	Ratio        float64    `json:"ratio"`
+	DelaySeconds int        `json:"delay_seconds"`
+	SlippageBps  float64    `json:"slippage_bps"`
+	MaxExposure  float64    `json:"max_exposure_usd"`
+	BankrollUSD  float64    `json:"bankroll_usd"`
+	IgnoreSells  bool       `json:"ignore_sells"`
+	Cash         float64    `json:"cash"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastPolledAt *time.Time `json:"last_polled_at,omitempty"`
+}
+
+// PaperOrder is a simulated copy of one followed-wallet fill.
+type PaperOrder struct {
+	ID             int64      `json:"id"`
+	PortfolioID    int64      `json:"portfolio_id"`
+	SourceWallet   string     `json:"source_wallet"`
+	SourceTradeKey string     `json:"source_trade_key"`
+	SourceTime     time.Time  `json:"source_time"`
+	ConditionID    string     `json:"condition_id"`
+	TokenID        string     `json:"token_id"`
+	Question       string     `json:"question"`
+	Outcome        string     `json:"outcome"`
+	Side           string     `json:"side"`
+	WalletSize     float64    `json:"wallet_size"`
+	WalletPrice    float64    `json:"wallet_price"`
+	FillAfter      time.Time  `json:"fill_after"`
+	Status         string     `json:"status"`
+	Reason         string     `json:"reason,omitempty"`
+	FillPrice      float64    `json:"fill_price"`
+	Shares         float64    `json:"shares"`
+	NotionalUSD    float64    `json:"notional_usd"`
+	FilledAt       *time.Time `json:"filled_at,omitempty"`
+}
+
+// PaperPosition is a portfolio's holding in one outcome.
+type PaperPosition struct {
+	PortfolioID  int64     `json:"portfolio_id"`
+	ConditionID  string    `json:"condition_id"`
+	Outcome      string    `json:"outcome"`
+	TokenID      string    `json:"token_id"`
+	Question     string    `json:"question"`
+	Status       string    `json:"status"`
+	Shares       float64   `json:"shares"`
+	CostUSD      float64   `json:"cost_usd"`
+	SourceShares float64   `json:"source_shares"`
+	RealizedPnL  float64   `json:"realized_pnl"`
+	MarkPrice    float64   `json:"mark_price"`
+	OpenedAt     time.Time `json:"opened_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PaperEquityPoint is a portfolio's value at one poll.
+type PaperEquityPoint struct {
+	RecordedAt     time.Time `json:"recorded_at"`
+	Cash           float64   `json:"cash"`
+	PositionsValue float64   `json:"positions_value"`
+	Equity         float64   `json:"equity"`
+}
+
+// PaperPoll is everything one poll changed in a portfolio.
+type PaperPoll struct {
+	PortfolioID int64
+	Cash        float64
+	PolledAt    time.Time
+	Orders      []PaperOrder
+	Positions   []PaperPosition
+	Equity      *PaperEquityPoint
+}
+
+// CreatePaperPortfolio stores a new portfolio funded with its bankroll.
+func (s *PostgresStore) CreatePaperPortfolio(ctx context.Context, portfolio PaperPortfolio) (PaperPortfolio, error) {
+	wallets := make([]string, 0, len(portfolio.Wallets))
+	for _, wallet := range portfolio.Wallets {
+		wallets = append(wallets, strings.ToLower(strings.TrimSpace(wallet)))
+	}
+	portfolio.Wallets = wallets
+	portfolio.Sport = strings.ToLower(portfolio.Sport)
+	portfolio.Cash = portfolio.BankrollUSD
+
+	err := s.pool.QueryRow(ctx, `
+INSERT INTO paper_portfolios (
+  name, sport, wallets, mode, stake_usd, ratio, delay_seconds, slippage_bps,
+  max_exposure_usd, bankroll_usd, ignore_sells, cash
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, created_at`,
+		portfolio.Name, portfolio.Sport, portfolio.Wallets, portfolio.Mode, portfolio.StakeUSD, portfolio.Ratio,
+		portfolio.DelaySeconds, portfolio.SlippageBps, portfolio.MaxExposure, portfolio.BankrollUSD,
+		portfolio.IgnoreSells, portfolio.Cash,
+	).Scan(&portfolio.ID, &portfolio.CreatedAt)
+	if err != nil {
+		return PaperPortfolio{}, fmt.Errorf("insert paper portfolio: %w", err)
+	}
+	return portfolio, nil
+}
+
+const paperPortfolioColumns = `
+  id, name, sport, wallets, mode, stake_usd, ratio, delay_seconds, slippage_bps,
+  max_exposure_usd, bankroll_usd, ignore_sells, cash, created_at, last_polled_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPaperPortfolio(row rowScanner) (PaperPortfolio, error) {
+	var p PaperPortfolio
+	err := row.Scan(
+		&p.ID, &p.Name, &p.Sport, &p.Wallets, &p.Mode, &p.StakeUSD, &p.Ratio, &p.DelaySeconds, &p.SlippageBps,
+		&p.MaxExposure, &p.BankrollUSD, &p.IgnoreSells, &p.Cash, &p.CreatedAt, &p.LastPolledAt,
+	)
+	return p, err
+}
+
+// ListPaperPortfolios returns every portfolio, oldest first.
+func (s *PostgresStore) ListPaperPortfolios(ctx context.Context) ([]PaperPortfolio, error) {
+	rows, err := s.pool.Query(ctx, `SELECT`+paperPortfolioColumns+` FROM paper_portfolios ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list paper portfolios: %w", err)
+	}
+	defer rows.Close()
+
+	result := []PaperPortfolio{}
+	for rows.Next() {
+		portfolio, err := scanPaperPortfolio(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan paper portfolio: %w", err)
+		}
+		result = append(result, portfolio)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate paper portfolios: %w", err)
+	}
+	return result, nil
+}
+
+// GetPaperPortfolio returns one portfolio; ok is false when it does not
+// exist.
+func (s *PostgresStore) GetPaperPortfolio(ctx context.Context, id int64) (PaperPortfolio, bool, error) {
+	portfolio, err := scanPaperPortfolio(s.pool.QueryRow(ctx, `SELECT`+paperPortfolioColumns+` FROM paper_portfolios WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PaperPortfolio{}, false, nil
+		}
+		return PaperPortfolio{}, false, fmt.Errorf("get paper portfolio %d: %w", id, err)
+	}
+	return portfolio, true, nil
+}
+
+// InsertPaperOrders stores new pending orders and returns the ones that were
+// not already recorded for their portfolio, with IDs set.
+func (s *PostgresStore) InsertPaperOrders(ctx context.Context, orders []PaperOrder) ([]PaperOrder, error) {
+	if len(orders) == 0 {
+		return []PaperOrder{}, nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin paper orders insert: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const query = `
+INSERT INTO paper_orders (
+  portfolio_id, source_wallet, source_trade_key, source_time, condition_id, token_id, question,
+  outcome, side, wallet_size, wallet_price, fill_after, status
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+ON CONFLICT (portfolio_id, source_trade_key) DO NOTHING
+RETURNING id`
+
+	inserted := []PaperOrder{}
+	for _, order := range orders {
+		order.Status = PaperOrderPending
+		err := tx.QueryRow(ctx, query,
+			order.PortfolioID, strings.ToLower(order.SourceWallet), order.SourceTradeKey, order.SourceTime,
+			strings.ToLower(order.ConditionID), order.TokenID, order.Question, order.Outcome, order.Side,
+			order.WalletSize, order.WalletPrice, order.FillAfter, order.Status,
+		).Scan(&order.ID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return nil, fmt.Errorf("insert paper order %s: %w", order.SourceTradeKey, err)
+		}
+		inserted = append(inserted, order)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit paper orders insert: %w", err)
+	}
+	return inserted, nil
+}
+
+const paperOrderColumns = `
+  id, portfolio_id, source_wallet, source_trade_key, source_time, condition_id, token_id, question,
+  outcome, side, wallet_size, wallet_price, fill_after, status, reason, fill_price, shares,
+  notional_usd, filled_at`
+
+// ListPaperOrders returns a portfolio's orders, newest first. An empty status
+// matches every order and a non-positive limit returns all of them.
+func (s *PostgresStore) ListPaperOrders(ctx context.Context, portfolioID int64, status string, limit int) ([]PaperOrder, error) {
+	query := `SELECT` + paperOrderColumns + `
+FROM paper_orders
+WHERE portfolio_id = $1 AND ($2 = '' OR status = $2)
+ORDER BY source_time DESC, id DESC`
+	args := []any{portfolioID, status}
+	if limit > 0 {
+		query += ` LIMIT $3`
+		args = append(args, limit)
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list paper orders: %w", err)
+	}
+	defer rows.Close()
+
+	result := []PaperOrder{}
+	for rows.Next() {
+		var o PaperOrder
+		if err := rows.Scan(
+			&o.ID, &o.PortfolioID, &o.SourceWallet, &o.SourceTradeKey, &o.SourceTime, &o.ConditionID, &o.TokenID,
+			&o.Question, &o.Outcome, &o.Side, &o.WalletSize, &o.WalletPrice, &o.FillAfter, &o.Status, &o.Reason,
+			&o.FillPrice, &o.Shares, &o.NotionalUSD, &o.FilledAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan paper order: %w", err)
+		}
+		result = append(result, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate paper orders: %w", err)
+	}
+	return result, nil
+}
+
+// ListPaperPositions returns a portfolio's positions, open ones first. With
+// openOnly set closed and settled positions are left out.
+func (s *PostgresStore) ListPaperPositions(ctx context.Context, portfolioID int64, openOnly bool) ([]PaperPosition, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT
+  portfolio_id, condition_id, outcome, token_id, question, status, shares, cost_usd,
+  source_shares, realized_pnl, mark_price, opened_at, updated_at
+FROM paper_positions
+WHERE portfolio_id = $1 AND (NOT $2 OR status = 'open')
+ORDER BY status = 'open' DESC, updated_at DESC`, portfolioID, openOnly)
+	if err != nil {
+		return nil, fmt.Errorf("list paper positions: %w", err)
+	}
+	defer rows.Close()
+
+	result := []PaperPosition{}
+	for rows.Next() {
+		var p PaperPosition
+		if err := rows.Scan(
+			&p.PortfolioID, &p.ConditionID, &p.Outcome, &p.TokenID, &p.Question, &p.Status, &p.Shares, &p.CostUSD,
+			&p.SourceShares, &p.RealizedPnL, &p.MarkPrice, &p.OpenedAt, &p.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan paper position: %w", err)
+		}
+		result = append(result, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate paper positions: %w", err)
+	}
+	return result, nil
+}
+
+// SavePaperPoll writes the orders, positions, cash and equity a poll
+// changed in one transaction.
+func (s *PostgresStore) SavePaperPoll(ctx context.Context, poll PaperPoll) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin paper poll: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, order := range poll.Orders {
+		if _, err := tx.Exec(ctx, `
+UPDATE paper_orders
+SET status = $2, reason = $3, fill_price = $4, shares = $5, notional_usd = $6, filled_at = $7
+WHERE id = $1`,
+			order.ID, order.Status, order.Reason, order.FillPrice, order.Shares, order.NotionalUSD, order.FilledAt,
+		); err != nil {
+			return fmt.Errorf("update paper order %d: %w", order.ID, err)
+		}
+	}
+
+	for _, position := range poll.Positions {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO paper_positions (
+  portfolio_id, condition_id, outcome, token_id, question, status, shares, cost_usd,
+  source_shares, realized_pnl, mark_price, opened_at, updated_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW())
+ON CONFLICT (portfolio_id, condition_id, outcome) DO UPDATE SET
+  token_id = EXCLUDED.token_id,
+  question = EXCLUDED.question,
+  status = EXCLUDED.status,
+  shares = EXCLUDED.shares,
+  cost_usd = EXCLUDED.cost_usd,
+  source_shares = EXCLUDED.source_shares,
+  realized_pnl = EXCLUDED.realized_pnl,
+  mark_price = EXCLUDED.mark_price,
+  opened_at = EXCLUDED.opened_at,
+  updated_at = NOW()`,
+			poll.PortfolioID, strings.ToLower(position.ConditionID), position.Outcome, position.TokenID, position.Question,
+			position.Status, position.Shares, position.CostUSD, position.SourceShares, position.RealizedPnL,
+			position.MarkPrice, position.OpenedAt,
+		); err != nil {
+			return fmt.Errorf("upsert paper position %s: %w", position.ConditionID, err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE paper_portfolios SET cash = $2, last_polled_at = $3 WHERE id = $1`,
+		poll.PortfolioID, poll.Cash, poll.PolledAt,
+	); err != nil {
+		return fmt.Errorf("update paper portfolio %d: %w", poll.PortfolioID, err)
+	}
+
+	if poll.Equity != nil {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO paper_equity (portfolio_id, recorded_at, cash, positions_value, equity)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (portfolio_id, recorded_at) DO NOTHING`,
+			poll.PortfolioID, poll.Equity.RecordedAt, poll.Equity.Cash, poll.Equity.PositionsValue, poll.Equity.Equity,
+		); err != nil {
+			return fmt.Errorf("insert paper equity: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit paper poll: %w", err)
+	}
+	return nil
+}
+
+// ListPaperEquity returns a portfolio's equity curve, oldest first, limited
+// to the most recent limit points when limit is positive.
+func (s *PostgresStore) ListPaperEquity(ctx context.Context, portfolioID int64, limit int) ([]PaperEquityPoint, error) {
+	if limit < 0 {
+		limit = 0
+	}
+	rows, err := s.pool.Query(ctx, `
+SELECT recorded_at, cash, positions_value, equity
+FROM (
+  SELECT recorded_at, cash, positions_value, equity
+  FROM paper_equity
+  WHERE portfolio_id = $1
+  ORDER BY recorded_at DESC
+  LIMIT NULLIF($2, 0)
+) recent
+ORDER BY recorded_at`, portfolioID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list paper equity: %w", err)
+	}
+	defer rows.Close()
+
+	result := []PaperEquityPoint{}
+	for rows.Next() {
+		var point PaperEquityPoint
+		if err := rows.Scan(&point.RecordedAt, &point.Cash, &point.PositionsValue, &point.Equity); err != nil {
+			return nil, fmt.Errorf("scan paper equity: %w", err)
+		}
+		result = append(result, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate paper equity: %w", err)
+	}
+	return result, nil
+}
@@ -3,17 +3,20 @@ package storage
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type Store struct {
+// PostgresStore is the Store backed by a Postgres connection pool.
+type PostgresStore struct {
 	pool *pgxpool.Pool
 }
 
 type TrackedWallet struct {
 	WalletAddress     string
+	Sport             string
 	DisplayName       string
 	Source            string
 	SourceRank        int
@@ -27,13 +30,23 @@ type TrackedWallet struct {
 	LastSeenAt        time.Time
 }
 
+// ROI is leaderboard PnL over traded volume, zero without volume.
+func (w TrackedWallet) ROI() float64 {
+	if w.VolumeUSD <= 0 {
+		return 0
+	}
+	return w.PnlUSD / w.VolumeUSD
+}
+
 type WalletProfile struct {
 	WalletAddress           string
+	Sport                   string
 	DisplayName             string
 	SourceRank              int
 	WinRate                 float64
 	PnlUSD                  float64
-	NbaTrades               int
+	VolumeUSD               float64
+	SportTrades             int
 	RecentMarkets           int
 	EntryTimingHours        float64
 	SizeRatioPct            float64
@@ -47,13 +60,23 @@ type WalletProfile struct {
 	AnalyzedAt              time.Time
 }
 
+// ROI is the PnL over volume the profile was analyzed with, zero without
+// volume.
+func (p WalletProfile) ROI() float64 {
+	if p.VolumeUSD <= 0 {
+		return 0
+	}
+	return p.PnlUSD / p.VolumeUSD
+}
+
 type StyleWallet struct {
 	WalletAddress     string  `json:"wallet_address"`
+	Sport             string  `json:"sport"`
 	DisplayName       string  `json:"display_name"`
 	SourceRank        int     `json:"source_rank"`
 	WinRate           float64 `json:"win_rate"`
 	PnlUSD            float64 `json:"pnl_usd"`
-	NbaTrades         int     `json:"nba_trades"`
+	SportTrades       int     `json:"sport_trades"`
 	EntryTimingHours  float64 `json:"entry_timing_hours"`
 	SizeRatioPct      float64 `json:"size_ratio_pct"`
 	Conviction        float64 `json:"conviction"`
@@ -67,7 +90,22 @@ type StyleGroup struct {
 	Wallets []StyleWallet `json:"wallets"`
 }
 
-func Open(ctx context.Context, databaseURL string) (*Store, error) {
+// openPostgres connects to Postgres and applies any pending schema
+// migrations.
+func openPostgres(ctx context.Context, databaseURL string) (*PostgresStore, error) {
+	store, err := Connect(ctx, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.MigrateUp(ctx, 0); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Connect connects to Postgres without touching the schema.
+func Connect(ctx context.Context, databaseURL string) (*PostgresStore, error) {
 	pool, err := pgxpool.New(ctx, databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("open postgres pool: %w", err)
@@ -77,72 +115,16 @@ func Open(ctx context.Context, databaseURL string) (*Store, error) {
 		pool.Close()
 		return nil, fmt.Errorf("ping postgres: %w", err)
 	}
-
-	store := &Store{pool: pool}
-	if err := store.initSchema(ctx); err != nil {
-		pool.Close()
-		return nil, err
-	}
-	return store, nil
+	return &PostgresStore{pool: pool}, nil
 }
 
-func (s *Store) Close() {
+func (s *PostgresStore) Close() {
 	if s != nil && s.pool != nil {
 		s.pool.Close()
 	}
 }
 
-func (s *Store) initSchema(ctx context.Context) error {
-	const schema = `
-CREATE TABLE IF NOT EXISTS tracked_wallets (
-  wallet_address TEXT PRIMARY KEY,
-  display_name TEXT NOT NULL,
-  source TEXT NOT NULL,
-  source_rank INTEGER NOT NULL DEFAULT 0,
-  source_predictions INTEGER NOT NULL DEFAULT 0,
-  source_wins INTEGER NOT NULL DEFAULT 0,
-  volume_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
-  loss_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
-  win_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
-  open_positions_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
-  pnl_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
-  first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-);
-
-CREATE TABLE IF NOT EXISTS wallet_profiles (
-  wallet_address TEXT PRIMARY KEY REFERENCES tracked_wallets(wallet_address) ON DELETE CASCADE,
-  nba_trades INTEGER NOT NULL DEFAULT 0,
-  recent_markets INTEGER NOT NULL DEFAULT 0,
-  entry_timing_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
-  size_ratio_pct DOUBLE PRECISION NOT NULL DEFAULT 0,
-  conviction DOUBLE PRECISION NOT NULL DEFAULT 0,
-  deterministic_style_label TEXT NOT NULL DEFAULT '',
-  ai_style_label TEXT NOT NULL DEFAULT '',
-  ai_style_summary TEXT NOT NULL DEFAULT '',
-  explanation_source TEXT NOT NULL DEFAULT 'fallback',
-  model TEXT NOT NULL DEFAULT '',
-  presentation_score DOUBLE PRECISION NOT NULL DEFAULT 0,
-  analyzed_at TIMESTAMPTZ,
-  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-);
-
-CREATE INDEX IF NOT EXISTS idx_wallet_profiles_ai_style_label
-  ON wallet_profiles (ai_style_label, analyzed_at DESC);
-
-CREATE INDEX IF NOT EXISTS idx_tracked_wallets_source_rank
-  ON tracked_wallets (source_rank ASC);`
-
-	_, err := s.pool.Exec(ctx, schema)
-	if err != nil {
-		return fmt.Errorf("init schema: %w", err)
-	}
-	return nil
-}
-
-func (s *Store) UpsertTrackedWallets(ctx context.Context, wallets []TrackedWallet) error {
+func (s *PostgresStore) UpsertTrackedWallets(ctx context.Context, wallets []TrackedWallet) error {
 	if len(wallets) == 0 {
 		return nil
 	}
@@ -155,13 +137,13 @@ func (s *Store) UpsertTrackedWallets(ctx context.Context, wallets []TrackedWalle
 
 	const query = `
 INSERT INTO tracked_wallets (
-  wallet_address, display_name, source, source_rank, source_predictions, source_wins,
+  wallet_address, sport, display_name, source, source_rank, source_predictions, source_wins,
   volume_usd, loss_usd, win_rate, open_positions_usd, pnl_usd, last_seen_at, updated_at
 ) VALUES (
-  $1, $2, $3, $4, $5, $6,
-  $7, $8, $9, $10, $11, $12, NOW()
+  $1, $2, $3, $4, $5, $6, $7,
+  $8, $9, $10, $11, $12, $13, NOW()
 )
-ON CONFLICT (wallet_address) DO UPDATE SET
+ON CONFLICT (wallet_address, sport) DO UPDATE SET
   display_name = EXCLUDED.display_name,
   source = EXCLUDED.source,
   source_rank = EXCLUDED.source_rank,
@@ -178,6 +160,7 @@ ON CONFLICT (wallet_address) DO UPDATE SET
 	for _, wallet := range wallets {
 		_, err := tx.Exec(ctx, query,
 			wallet.WalletAddress,
+			wallet.Sport,
 			wallet.DisplayName,
 			wallet.Source,
 			wallet.SourceRank,
@@ -191,7 +174,7 @@ ON CONFLICT (wallet_address) DO UPDATE SET
 			wallet.LastSeenAt,
 		)
 		if err != nil {
-			return fmt.Errorf("upsert tracked wallet %s: %w", wallet.WalletAddress, err)
+			return fmt.Errorf("upsert tracked wallet %s/%s: %w", wallet.WalletAddress, wallet.Sport, err)
 		}
 	}
 
@@ -201,19 +184,64 @@ ON CONFLICT (wallet_address) DO UPDATE SET
 	return nil
 }
 
-func (s *Store) UpsertWalletProfile(ctx context.Context, profile WalletProfile) error {
+// ListTrackedWallets returns the leaderboard wallets tracked for sport,
+// best rank first.
+func (s *PostgresStore) ListTrackedWallets(ctx context.Context, sport string) ([]TrackedWallet, error) {
+	const query = `
+SELECT
+  wallet_address, sport, display_name, source, source_rank, source_predictions, source_wins,
+  volume_usd, loss_usd, win_rate, open_positions_usd, pnl_usd, last_seen_at
+FROM tracked_wallets
+WHERE sport = $1
+ORDER BY source_rank ASC, wallet_address ASC`
+
+	rows, err := s.pool.Query(ctx, query, strings.ToLower(sport))
+	if err != nil {
+		return nil, fmt.Errorf("list tracked wallets: %w", err)
+	}
+	defer rows.Close()
+
+	wallets := []TrackedWallet{}
+	for rows.Next() {
+		var wallet TrackedWallet
+		if err := rows.Scan(
+			&wallet.WalletAddress,
+			&wallet.Sport,
+			&wallet.DisplayName,
+			&wallet.Source,
+			&wallet.SourceRank,
+			&wallet.SourcePredictions,
+			&wallet.SourceWins,
+			&wallet.VolumeUSD,
+			&wallet.LossUSD,
+			&wallet.WinRate,
+			&wallet.OpenPositionsUSD,
+			&wallet.PnlUSD,
+			&wallet.LastSeenAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan tracked wallet: %w", err)
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tracked wallets: %w", err)
+	}
+	return wallets, nil
+}
+
+func (s *PostgresStore) UpsertWalletProfile(ctx context.Context, profile WalletProfile) error {
 	const query = `
 INSERT INTO wallet_profiles (
-  wallet_address, nba_trades, recent_markets, entry_timing_hours, size_ratio_pct, conviction,
+  wallet_address, sport, sport_trades, recent_markets, entry_timing_hours, size_ratio_pct, conviction,
   deterministic_style_label, ai_style_label, ai_style_summary, explanation_source, model,
-  presentation_score, analyzed_at, updated_at
+  presentation_score, pnl_usd, volume_usd, analyzed_at, updated_at
 ) VALUES (
-  $1, $2, $3, $4, $5, $6,
-  $7, $8, $9, $10, $11,
-  $12, $13, NOW()
+  $1, $2, $3, $4, $5, $6, $7,
+  $8, $9, $10, $11, $12,
+  $13, $14, $15, $16, NOW()
 )
-ON CONFLICT (wallet_address) DO UPDATE SET
-  nba_trades = EXCLUDED.nba_trades,
+ON CONFLICT (wallet_address, sport) DO UPDATE SET
+  sport_trades = EXCLUDED.sport_trades,
   recent_markets = EXCLUDED.recent_markets,
   entry_timing_hours = EXCLUDED.entry_timing_hours,
   size_ratio_pct = EXCLUDED.size_ratio_pct,
@@ -224,12 +252,15 @@ ON CONFLICT (wallet_address) DO UPDATE SET
   explanation_source = EXCLUDED.explanation_source,
   model = EXCLUDED.model,
   presentation_score = EXCLUDED.presentation_score,
+  pnl_usd = EXCLUDED.pnl_usd,
+  volume_usd = EXCLUDED.volume_usd,
   analyzed_at = EXCLUDED.analyzed_at,
   updated_at = NOW()`
 
 	_, err := s.pool.Exec(ctx, query,
 		profile.WalletAddress,
-		profile.NbaTrades,
+		profile.Sport,
+		profile.SportTrades,
 		profile.RecentMarkets,
 		profile.EntryTimingHours,
 		profile.SizeRatioPct,
@@ -240,50 +271,76 @@ ON CONFLICT (wallet_address) DO UPDATE SET
 		profile.ExplanationSource,
 		profile.Model,
 		profile.PresentationScore,
+		profile.PnlUSD,
+		profile.VolumeUSD,
 		profile.AnalyzedAt,
 	)
 	if err != nil {
-		return fmt.Errorf("upsert wallet profile %s: %w", profile.WalletAddress, err)
+		return fmt.Errorf("upsert wallet profile %s/%s: %w", profile.WalletAddress, profile.Sport, err)
 	}
 	return nil
 }
 
-func (s *Store) ListStyleGroups(ctx context.Context, limitPerGroup int) ([]StyleGroup, error) {
+// GroupBy selects how ListStyleGroups partitions wallets.
+type GroupBy string
+
+const (
+	GroupByStyleLabel GroupBy = "style_label"
+	GroupByCluster    GroupBy = "cluster"
+)
+
+func (s *PostgresStore) ListStyleGroups(ctx context.Context, sport string, limitPerGroup int, groupBy GroupBy) ([]StyleGroup, error) {
 	if limitPerGroup <= 0 {
 		limitPerGroup = 6
 	}
 
-	const query = `
+	groupColumn := "wp.ai_style_label"
+	source := `
+  FROM wallet_profiles wp
+  JOIN tracked_wallets tw ON tw.wallet_address = wp.wallet_address AND tw.sport = wp.sport
+  WHERE wp.sport = $2 AND wp.ai_style_label <> ''`
+	if groupBy == GroupByCluster {
+		groupColumn = "sc.label"
+		source = `
+  FROM wallet_profiles wp
+  JOIN tracked_wallets tw ON tw.wallet_address = wp.wallet_address AND tw.sport = wp.sport
+  JOIN wallet_style_clusters wsc ON wsc.wallet_address = wp.wallet_address AND wsc.sport = wp.sport
+  JOIN style_clusters sc ON sc.run_id = wsc.run_id AND sc.cluster_id = wsc.cluster_id
+  WHERE wp.sport = $2`
+	}
+
+	query := fmt.Sprintf(`
 WITH ranked AS (
   SELECT
+    %[1]s AS group_label,
     wp.ai_style_label,
     tw.wallet_address,
+    tw.sport,
     tw.display_name,
     tw.source_rank,
     tw.win_rate,
     tw.pnl_usd,
-    wp.nba_trades,
+    wp.sport_trades,
     wp.entry_timing_hours,
     wp.size_ratio_pct,
     wp.conviction,
     wp.ai_style_summary,
     wp.explanation_source,
     ROW_NUMBER() OVER (
-      PARTITION BY wp.ai_style_label
+      PARTITION BY %[1]s
       ORDER BY tw.source_rank ASC, wp.presentation_score DESC, wp.analyzed_at DESC
-    ) AS row_num
-  FROM wallet_profiles wp
-  JOIN tracked_wallets tw ON tw.wallet_address = wp.wallet_address
-  WHERE wp.ai_style_label <> ''
+    ) AS row_num%[2]s
 )
 SELECT
+  group_label,
   ai_style_label,
   wallet_address,
+  sport,
   display_name,
   source_rank,
   win_rate,
   pnl_usd,
-  nba_trades,
+  sport_trades,
   entry_timing_hours,
   size_ratio_pct,
   conviction,
@@ -291,9 +348,9 @@ SELECT
   explanation_source
 FROM ranked
 WHERE row_num <= $1
-ORDER BY ai_style_label ASC, source_rank ASC`
+ORDER BY group_label ASC, source_rank ASC`, groupColumn, source)
 
-	rows, err := s.pool.Query(ctx, query, limitPerGroup)
+	rows, err := s.pool.Query(ctx, query, limitPerGroup, strings.ToLower(sport))
 	if err != nil {
 		return nil, fmt.Errorf("list style groups: %w", err)
 	}
@@ -306,12 +363,14 @@ ORDER BY ai_style_label ASC, source_rank ASC`
 		var wallet StyleWallet
 		if err := rows.Scan(
 			&label,
+			&wallet.StyleLabel,
 			&wallet.WalletAddress,
+			&wallet.Sport,
 			&wallet.DisplayName,
 			&wallet.SourceRank,
 			&wallet.WinRate,
 			&wallet.PnlUSD,
-			&wallet.NbaTrades,
+			&wallet.SportTrades,
 			&wallet.EntryTimingHours,
 			&wallet.SizeRatioPct,
 			&wallet.Conviction,
@@ -320,7 +379,6 @@ ORDER BY ai_style_label ASC, source_rank ASC`
 		); err != nil {
 			return nil, fmt.Errorf("scan style group row: %w", err)
 		}
-		wallet.StyleLabel = label
 		if _, ok := groupMap[label]; !ok {
 			order = append(order, label)
 		}
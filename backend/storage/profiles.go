@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type ProfileVector struct {
+	WalletAddress    string
+	Sport            string
+	DisplayName      string
+	SourceRank       int
+	PnlUSD           float64
+	WinRate          float64
+	SportTrades      int
+	EntryTimingHours float64
+	SizeRatioPct     float64
+	Conviction       float64
+	StyleLabel       string
+	// ROI is the PnL over volume the profile was analyzed with.
+	ROI float64
+}
+
+// ProfileFilter narrows ListProfileVectors. Nil bounds are ignored.
+type ProfileFilter struct {
+	Sport     string
+	MinTrades int
+	MinPnlUSD *float64
+	MaxPnlUSD *float64
+}
+
+// ListProfileVectors returns the persisted style metrics of every analyzed
+// wallet matching filter. An empty sport matches profiles of every sport.
+func (s *PostgresStore) ListProfileVectors(ctx context.Context, filter ProfileFilter) ([]ProfileVector, error) {
+	const query = `
+SELECT
+  wp.wallet_address,
+  wp.sport,
+  tw.display_name,
+  tw.source_rank,
+  tw.pnl_usd,
+  tw.win_rate,
+  wp.sport_trades,
+  wp.entry_timing_hours,
+  wp.size_ratio_pct,
+  wp.conviction,
+  wp.ai_style_label,
+  COALESCE(wp.pnl_usd / NULLIF(wp.volume_usd, 0), 0)
+FROM wallet_profiles wp
+JOIN tracked_wallets tw ON tw.wallet_address = wp.wallet_address AND tw.sport = wp.sport
+WHERE wp.analyzed_at IS NOT NULL
+  AND ($1 = '' OR wp.sport = $1)
+  AND wp.sport_trades >= $2
+  AND ($3::double precision IS NULL OR tw.pnl_usd >= $3)
+  AND ($4::double precision IS NULL OR tw.pnl_usd <= $4)
+ORDER BY wp.sport ASC, wp.wallet_address ASC`
+
+	rows, err := s.pool.Query(ctx, query, strings.ToLower(filter.Sport), filter.MinTrades, filter.MinPnlUSD, filter.MaxPnlUSD)
+	if err != nil {
+		return nil, fmt.Errorf("list profile vectors: %w", err)
+	}
+	defer rows.Close()
+
+	var vectors []ProfileVector
+	for rows.Next() {
+		var vector ProfileVector
+		if err := rows.Scan(
+			&vector.WalletAddress,
+			&vector.Sport,
+			&vector.DisplayName,
+			&vector.SourceRank,
+			&vector.PnlUSD,
+			&vector.WinRate,
+			&vector.SportTrades,
+			&vector.EntryTimingHours,
+			&vector.SizeRatioPct,
+			&vector.Conviction,
+			&vector.StyleLabel,
+			&vector.ROI,
+		); err != nil {
+			return nil, fmt.Errorf("scan profile vector: %w", err)
+		}
+		vectors = append(vectors, vector)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate profile vectors: %w", err)
+	}
+	return vectors, nil
+}
+
+// GetProfileVector returns the stored style metrics for one wallet in one
+// sport. The boolean is false when that profile has not been analyzed.
+func (s *PostgresStore) GetProfileVector(ctx context.Context, wallet, sport string) (ProfileVector, bool, error) {
+	const query = `
+SELECT
+  wp.wallet_address,
+  wp.sport,
+  tw.display_name,
+  tw.source_rank,
+  tw.pnl_usd,
+  tw.win_rate,
+  wp.sport_trades,
+  wp.entry_timing_hours,
+  wp.size_ratio_pct,
+  wp.conviction,
+  wp.ai_style_label,
+  COALESCE(wp.pnl_usd / NULLIF(wp.volume_usd, 0), 0)
+FROM wallet_profiles wp
+JOIN tracked_wallets tw ON tw.wallet_address = wp.wallet_address AND tw.sport = wp.sport
+WHERE wp.wallet_address = $1 AND wp.sport = $2 AND wp.analyzed_at IS NOT NULL`
+
+	var vector ProfileVector
+	err := s.pool.QueryRow(ctx, query, strings.ToLower(wallet), strings.ToLower(sport)).Scan(
+		&vector.WalletAddress,
+		&vector.Sport,
+		&vector.DisplayName,
+		&vector.SourceRank,
+		&vector.PnlUSD,
+		&vector.WinRate,
+		&vector.SportTrades,
+		&vector.EntryTimingHours,
+		&vector.SizeRatioPct,
+		&vector.Conviction,
+		&vector.StyleLabel,
+		&vector.ROI,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ProfileVector{}, false, nil
+	}
+	if err != nil {
+		return ProfileVector{}, false, fmt.Errorf("get profile vector %s/%s: %w", wallet, sport, err)
+	}
+	return vector, true, nil
+}
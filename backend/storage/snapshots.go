@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WalletSnapshot is a wallet's leaderboard standing and, once analyzed, its
+// profile metrics as captured by one sync run. Profile fields are nil for
+// wallets that had no profile at the time.
+type WalletSnapshot struct {
+	WalletAddress    string     `json:"wallet_address"`
+	Sport            string     `json:"sport"`
+	CapturedAt       time.Time  `json:"captured_at"`
+	DisplayName      string     `json:"display_name"`
+	SourceRank       int        `json:"source_rank"`
+	WinRate          float64    `json:"win_rate"`
+	PnlUSD           float64    `json:"pnl_usd"`
+	VolumeUSD        float64    `json:"volume_usd"`
+	SportTrades      *int       `json:"sport_trades,omitempty"`
+	EntryTimingHours *float64   `json:"entry_timing_hours,omitempty"`
+	SizeRatioPct     *float64   `json:"size_ratio_pct,omitempty"`
+	Conviction       *float64   `json:"conviction,omitempty"`
+	StyleLabel       *string    `json:"style_label,omitempty"`
+	AnalyzedAt       *time.Time `json:"analyzed_at,omitempty"`
+}
+
+// MoverMetric selects what ListWalletMovers ranks by.
+type MoverMetric string
+
+const (
+	MoverByRank MoverMetric = "rank"
+	MoverByPnl  MoverMetric = "pnl"
+)
+
+// moverOrder maps each metric to its ORDER BY expression over the latest
+// (l) and baseline (b) snapshots.
+var moverOrder = map[MoverMetric]string{
+	MoverByRank: "ABS(b.source_rank - l.source_rank) DESC, l.source_rank ASC",
+	MoverByPnl:  "ABS(l.pnl_usd - b.pnl_usd) DESC, l.source_rank ASC",
+}
+
+// WalletMover compares a wallet's latest snapshot with its latest one at or
+// before the baseline time. RankChange is positive when the wallet climbed.
+type WalletMover struct {
+	WalletAddress      string    `json:"wallet_address"`
+	Sport              string    `json:"sport"`
+	DisplayName        string    `json:"display_name"`
+	CapturedAt         time.Time `json:"captured_at"`
+	SourceRank         int       `json:"source_rank"`
+	PnlUSD             float64   `json:"pnl_usd"`
+	StyleLabel         string    `json:"style_label"`
+	PreviousCapturedAt time.Time `json:"previous_captured_at"`
+	PreviousRank       int       `json:"previous_rank"`
+	PreviousPnlUSD     float64   `json:"previous_pnl_usd"`
+	PreviousStyleLabel string    `json:"previous_style_label"`
+	RankChange         int       `json:"rank_change"`
+	PnlChangeUSD       float64   `json:"pnl_change_usd"`
+	StyleChanged       bool      `json:"style_changed"`
+}
+
+type MoverFilter struct {
+	Sport  string
+	Since  time.Time
+	Metric MoverMetric
+	Limit  int
+}
+
+// SnapshotWallets appends the current tracked row and profile of each
+// wallet to wallet_snapshots under one capture time. Capturing the same
+// wallet twice at the same time is a no-op.
+func (s *PostgresStore) SnapshotWallets(ctx context.Context, sport string, wallets []string, capturedAt time.Time) error {
+	if len(wallets) == 0 {
+		return nil
+	}
+
+	const query = `
+INSERT INTO wallet_snapshots (
+  wallet_address, sport, captured_at, display_name, source_rank, win_rate, pnl_usd, volume_usd,
+  sport_trades, entry_timing_hours, size_ratio_pct, conviction, style_label, analyzed_at
+)
+SELECT
+  LOWER(tw.wallet_address), tw.sport, $3, tw.display_name, tw.source_rank, tw.win_rate, tw.pnl_usd, tw.volume_usd,
+  wp.sport_trades, wp.entry_timing_hours, wp.size_ratio_pct, wp.conviction, wp.ai_style_label, wp.analyzed_at
+FROM tracked_wallets tw
+LEFT JOIN wallet_profiles wp
+  ON wp.wallet_address = tw.wallet_address AND wp.sport = tw.sport
+WHERE tw.sport = $1 AND tw.wallet_address = ANY($2)
+ON CONFLICT (wallet_address, sport, captured_at) DO NOTHING`
+
+	if _, err := s.pool.Exec(ctx, query, strings.ToLower(sport), wallets, capturedAt); err != nil {
+		return fmt.Errorf("snapshot %s wallets: %w", sport, err)
+	}
+	return nil
+}
+
+// ListWalletSnapshots returns a wallet's snapshots for a sport captured at
+// or after since, oldest first, keeping the most recent limit when limit is
+// positive.
+func (s *PostgresStore) ListWalletSnapshots(ctx context.Context, wallet, sport string, since time.Time, limit int) ([]WalletSnapshot, error) {
+	const query = `
+SELECT * FROM (
+  SELECT
+    wallet_address, sport, captured_at, display_name, source_rank, win_rate, pnl_usd, volume_usd,
+    sport_trades, entry_timing_hours, size_ratio_pct, conviction, style_label, analyzed_at
+  FROM wallet_snapshots
+  WHERE wallet_address = $1 AND sport = $2 AND captured_at >= $3
+  ORDER BY captured_at DESC
+  LIMIT NULLIF($4, 0)
+) recent
+ORDER BY captured_at ASC`
+
+	rows, err := s.pool.Query(ctx, query, strings.ToLower(wallet), strings.ToLower(sport), since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list wallet snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []WalletSnapshot
+	for rows.Next() {
+		var snapshot WalletSnapshot
+		if err := rows.Scan(
+			&snapshot.WalletAddress,
+			&snapshot.Sport,
+			&snapshot.CapturedAt,
+			&snapshot.DisplayName,
+			&snapshot.SourceRank,
+			&snapshot.WinRate,
+			&snapshot.PnlUSD,
+			&snapshot.VolumeUSD,
+			&snapshot.SportTrades,
+			&snapshot.EntryTimingHours,
+			&snapshot.SizeRatioPct,
+			&snapshot.Conviction,
+			&snapshot.StyleLabel,
+			&snapshot.AnalyzedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan wallet snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate wallet snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// ListWalletMovers compares each wallet's snapshot from the sport's latest
+// sync with its latest snapshot at or before filter.Since and returns the
+// wallets that moved most by the chosen metric. Wallets missing from the
+// latest sync have left the leaderboard, and wallets first captured after
+// Since have no baseline; both are left out.
+func (s *PostgresStore) ListWalletMovers(ctx context.Context, filter MoverFilter) ([]WalletMover, error) {
+	metric := filter.Metric
+	if metric == "" {
+		metric = MoverByRank
+	}
+	order, ok := moverOrder[metric]
+	if !ok {
+		return nil, fmt.Errorf("unsupported mover metric: %s", metric)
+	}
+
+	query := fmt.Sprintf(`
+WITH latest AS (
+  SELECT *
+  FROM wallet_snapshots
+  WHERE sport = $1
+    AND captured_at = (SELECT MAX(captured_at) FROM wallet_snapshots WHERE sport = $1)
+), baseline AS (
+  SELECT DISTINCT ON (wallet_address) *
+  FROM wallet_snapshots
+  WHERE sport = $1 AND captured_at <= $2
+  ORDER BY wallet_address, captured_at DESC
+)
+SELECT
+  l.wallet_address, l.sport, l.display_name,
+  l.captured_at, l.source_rank, l.pnl_usd, COALESCE(l.style_label, ''),
+  b.captured_at, b.source_rank, b.pnl_usd, COALESCE(b.style_label, '')
+FROM latest l
+JOIN baseline b ON b.wallet_address = l.wallet_address
+WHERE l.captured_at > b.captured_at
+ORDER BY %s
+LIMIT NULLIF($3, 0)`, order)
+
+	rows, err := s.pool.Query(ctx, query, strings.ToLower(filter.Sport), filter.Since, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list wallet movers: %w", err)
+	}
+	defer rows.Close()
+
+	var movers []WalletMover
+	for rows.Next() {
+		var mover WalletMover
+		if err := rows.Scan(
+			&mover.WalletAddress,
+			&mover.Sport,
+			&mover.DisplayName,
+			&mover.CapturedAt,
+			&mover.SourceRank,
+			&mover.PnlUSD,
+			&mover.StyleLabel,
+			&mover.PreviousCapturedAt,
+			&mover.PreviousRank,
+			&mover.PreviousPnlUSD,
+			&mover.PreviousStyleLabel,
+		); err != nil {
+			return nil, fmt.Errorf("scan wallet mover: %w", err)
+		}
+		mover.RankChange = mover.PreviousRank - mover.SourceRank
+		mover.PnlChangeUSD = mover.PnlUSD - mover.PreviousPnlUSD
+		mover.StyleChanged = mover.StyleLabel != "" && mover.PreviousStyleLabel != "" && mover.StyleLabel != mover.PreviousStyleLabel
+		movers = append(movers, mover)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate wallet movers: %w", err)
+	}
+	return movers, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Store is the catalog the sync, watcher, paper trading, alerts and HTTP
+// handlers read and write. PostgresStore is the production implementation;
+// FileStore keeps the same data in memory for local runs and tests.
+type Store interface {
+	Close()
+
+	UpsertTrackedWallets(ctx context.Context, wallets []TrackedWallet) error
+	ListTrackedWallets(ctx context.Context, sport string) ([]TrackedWallet, error)
+	UpsertWalletProfile(ctx context.Context, profile WalletProfile) error
+	ListStyleGroups(ctx context.Context, sport string, limitPerGroup int, groupBy GroupBy) ([]StyleGroup, error)
+	ListProfileVectors(ctx context.Context, filter ProfileFilter) ([]ProfileVector, error)
+	GetProfileVector(ctx context.Context, wallet, sport string) (ProfileVector, bool, error)
+	SaveStyleClusterRun(ctx context.Context, run StyleClusterRun, clusters []StyleCluster, assignments []WalletCluster) (int64, error)
+	QueryWallets(ctx context.Context, query WalletQuery) ([]CatalogWallet, *WalletCursor, error)
+	GetCatalogWallet(ctx context.Context, wallet string) ([]CatalogWallet, error)
+
+	UpsertMarketSports(ctx context.Context, markets []MarketSport) error
+	ListMarketSports(ctx context.Context) ([]MarketSport, error)
+	UpsertGames(ctx context.Context, games []Game) error
+	ListGames(ctx context.Context) ([]Game, error)
+	ReplaceMarketConsensus(ctx context.Context, sport string, rows []MarketConsensus) error
+	ListMarketConsensus(ctx context.Context, sports []string, limit int) ([]MarketConsensus, error)
+
+	CreatePaperPortfolio(ctx context.Context, portfolio PaperPortfolio) (PaperPortfolio, error)
+	ListPaperPortfolios(ctx context.Context) ([]PaperPortfolio, error)
+	GetPaperPortfolio(ctx context.Context, id int64) (PaperPortfolio, bool, error)
+	InsertPaperOrders(ctx context.Context, orders []PaperOrder) ([]PaperOrder, error)
+	ListPaperOrders(ctx context.Context, portfolioID int64, status string, limit int) ([]PaperOrder, error)
+	ListPaperPositions(ctx context.Context, portfolioID int64, openOnly bool) ([]PaperPosition, error)
+	SavePaperPoll(ctx context.Context, poll PaperPoll) error
+	ListPaperEquity(ctx context.Context, portfolioID int64, limit int) ([]PaperEquityPoint, error)
+
+	AddWatchlistWallets(ctx context.Context, name string, wallets []string) error
+	RemoveWatchlistWallet(ctx context.Context, name, wallet string) (bool, error)
+	ListWatchlists(ctx context.Context) ([]Watchlist, error)
+	ListWatchedWallets(ctx context.Context) ([]WatchedWallet, error)
+
+	CreateAlertRule(ctx context.Context, rule AlertRule) (AlertRule, error)
+	UpdateAlertRule(ctx context.Context, rule AlertRule) (AlertRule, bool, error)
+	DeleteAlertRule(ctx context.Context, id int64) (bool, error)
+	GetAlertRule(ctx context.Context, id int64) (AlertRule, bool, error)
+	ListAlertRules(ctx context.Context, enabledOnly bool) ([]AlertRule, error)
+	InsertAlertDelivery(ctx context.Context, delivery AlertDelivery) (AlertDelivery, bool, error)
+	ListDueAlertDeliveries(ctx context.Context, now time.Time, limit int) ([]AlertDelivery, error)
+	ListAlertDeliveries(ctx context.Context, ruleID int64, status string, limit int) ([]AlertDelivery, error)
+	SaveAlertDeliveryAttempt(ctx context.Context, delivery AlertDelivery) error
+	RetryAlertDelivery(ctx context.Context, id int64) (bool, error)
+
+	InsertTrades(ctx context.Context, trades []StoredTrade) (int64, error)
+	ListWalletTrades(ctx context.Context, wallet string, limit int) ([]StoredTrade, error)
+	ListMarketTrades(ctx context.Context, conditionID string, limit int) ([]StoredTrade, error)
+	GetWalletTradeCoverage(ctx context.Context, wallet string) (WalletTradeCoverage, bool, error)
+	SaveWalletTradeCoverage(ctx context.Context, coverage WalletTradeCoverage) error
+	UpsertMarkets(ctx context.Context, markets []MarketRecord) error
+	GetMarketRecords(ctx context.Context, conditionIDs []string) ([]MarketRecord, error)
+
+	ListWalletSyncStates(ctx context.Context, sport string) ([]WalletSyncState, error)
+	SaveWalletSyncState(ctx context.Context, state WalletSyncState) error
+	SnapshotWallets(ctx context.Context, sport string, wallets []string, capturedAt time.Time) error
+	ListWalletSnapshots(ctx context.Context, wallet, sport string, since time.Time, limit int) ([]WalletSnapshot, error)
+	ListWalletMovers(ctx context.Context, filter MoverFilter) ([]WalletMover, error)
+
+	StartSyncRun(ctx context.Context, trigger, instanceID string, sports []string) (SyncRun, error)
+	TouchSyncRun(ctx context.Context, id int64) error
+	AbandonSyncRuns(ctx context.Context, instanceID string, staleAfter time.Duration) (int64, error)
+	FinishSyncRun(ctx context.Context, run SyncRun) error
+	ListSyncRuns(ctx context.Context, status string, limit int) ([]SyncRun, error)
+	GetSyncRun(ctx context.Context, id int64) (SyncRun, bool, error)
+	LatestSyncRun(ctx context.Context, trigger string) (SyncRun, bool, error)
+
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (SchedulerLease, bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+	GetLease(ctx context.Context, name string) (SchedulerLease, bool, error)
+}
+
+var (
+	_ Store = (*PostgresStore)(nil)
+	_ Store = (*FileStore)(nil)
+)
+
+// Open opens the store databaseURL names, picking the backend by scheme:
+//
+//	postgres://..., postgresql://...  Postgres, with pending migrations applied
+//	file:///path/to/catalog.json       in memory, persisted to a JSON file
+//	memory://                          in memory only, lost on Close
+//
+// Anything without one of these schemes is handed to Postgres as a
+// connection string.
+func Open(ctx context.Context, databaseURL string) (Store, error) {
+	scheme := ""
+	if u, err := url.Parse(databaseURL); err == nil {
+		scheme = strings.ToLower(u.Scheme)
+	}
+
+	switch scheme {
+	case "file":
+		path := strings.TrimPrefix(databaseURL[len("file:"):], "//")
+		if path == "" {
+			return nil, fmt.Errorf("open file store: %q has no path", databaseURL)
+		}
+		return OpenFile(path)
+	case "memory":
+		return OpenFile("")
+	default:
+		return openPostgres(ctx, databaseURL)
+	}
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	SyncRunRunning   = "running"
+	SyncRunCompleted = "completed"
+	SyncRunFailed    = "failed"
+)
+
+// SyncRunAbandoned is the error recorded on a run whose process stopped
+// before finishing it.
+const SyncRunAbandoned = "abandoned"
+
+// SyncRun is the audit record of one wallet sync run.
+type SyncRun struct {
+	ID               int64             `json:"id"`
+	Trigger          string            `json:"trigger"`
+	Status           string            `json:"status"`
+	Sports           []string          `json:"sports"`
+	InstanceID       string            `json:"instance_id,omitempty"`
+	StartedAt        time.Time         `json:"started_at"`
+	HeartbeatAt      time.Time         `json:"heartbeat_at"`
+	FinishedAt       *time.Time        `json:"finished_at,omitempty"`
+	WalletsAttempted int               `json:"wallets_attempted"`
+	WalletsSucceeded int               `json:"wallets_succeeded"`
+	WalletsFailed    int               `json:"wallets_failed"`
+	WalletsSkipped   int               `json:"wallets_skipped"`
+	AICalls          int               `json:"ai_calls"`
+	AIFallbacks      int               `json:"ai_fallbacks"`
+	WalletErrors     []SyncWalletError `json:"wallet_errors"`
+	Error            string            `json:"error,omitempty"`
+}
+
+type SyncWalletError struct {
+	WalletAddress string `json:"wallet_address"`
+	Sport         string `json:"sport"`
+	Error         string `json:"error"`
+}
+
+const syncRunColumns = `
+  id, trigger, status, sports, instance_id, started_at, heartbeat_at, finished_at, wallets_attempted, wallets_succeeded,
+  wallets_failed, wallets_skipped, ai_calls, ai_fallbacks, wallet_errors, error`
+
+func scanSyncRun(row rowScanner) (SyncRun, error) {
+	var run SyncRun
+	err := row.Scan(
+		&run.ID,
+		&run.Trigger,
+		&run.Status,
+		&run.Sports,
+		&run.InstanceID,
+		&run.StartedAt,
+		&run.HeartbeatAt,
+		&run.FinishedAt,
+		&run.WalletsAttempted,
+		&run.WalletsSucceeded,
+		&run.WalletsFailed,
+		&run.WalletsSkipped,
+		&run.AICalls,
+		&run.AIFallbacks,
+		&run.WalletErrors,
+		&run.Error,
+	)
+	return run, err
+}
+
+// StartSyncRun records a run as running on instanceID and returns it with
+// its ID and start time.
+func (s *PostgresStore) StartSyncRun(ctx context.Context, trigger, instanceID string, sports []string) (SyncRun, error) {
+	const query = `
+INSERT INTO sync_runs (trigger, status, sports, instance_id)
+VALUES ($1, $2, $3, $4)
+RETURNING` + syncRunColumns
+
+	run, err := scanSyncRun(s.pool.QueryRow(ctx, query, trigger, SyncRunRunning, sports, instanceID))
+	if err != nil {
+		return SyncRun{}, fmt.Errorf("start sync run: %w", err)
+	}
+	return run, nil
+}
+
+// TouchSyncRun moves a running run's heartbeat to now.
+func (s *PostgresStore) TouchSyncRun(ctx context.Context, id int64) error {
+	const query = `UPDATE sync_runs SET heartbeat_at = NOW() WHERE id = $1 AND status = $2`
+
+	if _, err := s.pool.Exec(ctx, query, id, SyncRunRunning); err != nil {
+		return fmt.Errorf("touch sync run %d: %w", id, err)
+	}
+	return nil
+}
+
+// AbandonSyncRuns marks as failed every running run that instanceID
+// started or whose heartbeat is older than staleAfter, and returns how many
+// it marked. Heartbeat age uses the database clock.
+func (s *PostgresStore) AbandonSyncRuns(ctx context.Context, instanceID string, staleAfter time.Duration) (int64, error) {
+	const query = `
+UPDATE sync_runs SET
+  status = $1,
+  finished_at = NOW(),
+  error = $2
+WHERE status = $3
+  AND ((instance_id <> '' AND instance_id = $4) OR heartbeat_at < NOW() - make_interval(secs => $5))`
+
+	tag, err := s.pool.Exec(ctx, query, SyncRunFailed, SyncRunAbandoned, SyncRunRunning, instanceID, staleAfter.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("abandon sync runs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// FinishSyncRun saves a run's counters, errors and final status.
+func (s *PostgresStore) FinishSyncRun(ctx context.Context, run SyncRun) error {
+	const query = `
+UPDATE sync_runs SET
+  status = $2,
+  finished_at = $3,
+  wallets_attempted = $4,
+  wallets_succeeded = $5,
+  wallets_failed = $6,
+  wallets_skipped = $7,
+  ai_calls = $8,
+  ai_fallbacks = $9,
+  wallet_errors = $10,
+  error = $11
+WHERE id = $1`
+
+	walletErrors := run.WalletErrors
+	if walletErrors == nil {
+		walletErrors = []SyncWalletError{}
+	}
+	_, err := s.pool.Exec(ctx, query,
+		run.ID,
+		run.Status,
+		run.FinishedAt,
+		run.WalletsAttempted,
+		run.WalletsSucceeded,
+		run.WalletsFailed,
+		run.WalletsSkipped,
+		run.AICalls,
+		run.AIFallbacks,
+		walletErrors,
+		run.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("finish sync run %d: %w", run.ID, err)
+	}
+	return nil
+}
+
+// ListSyncRuns returns sync runs newest first, optionally filtered by
+// status.
+func (s *PostgresStore) ListSyncRuns(ctx context.Context, status string, limit int) ([]SyncRun, error) {
+	query := `
+SELECT` + syncRunColumns + `
+FROM sync_runs
+WHERE ($1 = '' OR status = $1)
+ORDER BY started_at DESC, id DESC
+LIMIT NULLIF($2, 0)`
+
+	rows, err := s.pool.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []SyncRun
+	for rows.Next() {
+		run, err := scanSyncRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan sync run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sync runs: %w", err)
+	}
+	return runs, nil
+}
+
+func (s *PostgresStore) GetSyncRun(ctx context.Context, id int64) (SyncRun, bool, error) {
+	query := `
+SELECT` + syncRunColumns + `
+FROM sync_runs
+WHERE id = $1`
+
+	run, err := scanSyncRun(s.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SyncRun{}, false, nil
+	}
+	if err != nil {
+		return SyncRun{}, false, fmt.Errorf("get sync run %d: %w", id, err)
+	}
+	return run, true, nil
+}
+
+// LatestSyncRun returns the most recently started run with the given
+// trigger, from any instance.
+func (s *PostgresStore) LatestSyncRun(ctx context.Context, trigger string) (SyncRun, bool, error) {
+	query := `
+SELECT` + syncRunColumns + `
+FROM sync_runs
+WHERE trigger = $1
+ORDER BY started_at DESC, id DESC
+LIMIT 1`
+
+	run, err := scanSyncRun(s.pool.QueryRow(ctx, query, trigger))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SyncRun{}, false, nil
+	}
+	if err != nil {
+		return SyncRun{}, false, fmt.Errorf("get latest %s sync run: %w", trigger, err)
+	}
+	return run, true, nil
+}
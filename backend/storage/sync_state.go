@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WalletSyncState is the wallet sync's high-water mark for one wallet and
+// sport: the newest stored trade and the leaderboard activity when it was
+// last analyzed, and how its recent attempts went.
+type WalletSyncState struct {
+	WalletAddress       string     `json:"wallet_address"`
+	Sport               string     `json:"sport"`
+	LastTradeAt         *time.Time `json:"last_trade_at,omitempty"`
+	LastTradeKey        string     `json:"last_trade_key,omitempty"`
+	SourcePredictions   int        `json:"source_predictions"`
+	VolumeUSD           float64    `json:"volume_usd"`
+	LastAnalyzedAt      *time.Time `json:"last_analyzed_at,omitempty"`
+	LastAttemptAt       time.Time  `json:"last_attempt_at"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// ListWalletSyncStates returns the sync state of every wallet synced for
+// sport.
+func (s *PostgresStore) ListWalletSyncStates(ctx context.Context, sport string) ([]WalletSyncState, error) {
+	const query = `
+SELECT wallet_address, sport, last_trade_at, last_trade_key, source_predictions, volume_usd,
+  last_analyzed_at, last_attempt_at, last_error, consecutive_failures
+FROM wallet_sync_state
+WHERE sport = $1
+ORDER BY wallet_address`
+
+	rows, err := s.pool.Query(ctx, query, strings.ToLower(sport))
+	if err != nil {
+		return nil, fmt.Errorf("list wallet sync states: %w", err)
+	}
+	defer rows.Close()
+
+	result := []WalletSyncState{}
+	for rows.Next() {
+		var state WalletSyncState
+		if err := rows.Scan(
+			&state.WalletAddress,
+			&state.Sport,
+			&state.LastTradeAt,
+			&state.LastTradeKey,
+			&state.SourcePredictions,
+			&state.VolumeUSD,
+			&state.LastAnalyzedAt,
+			&state.LastAttemptAt,
+			&state.LastError,
+			&state.ConsecutiveFailures,
+		); err != nil {
+			return nil, fmt.Errorf("scan wallet sync state: %w", err)
+		}
+		result = append(result, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate wallet sync states: %w", err)
+	}
+	return result, nil
+}
+
+func (s *PostgresStore) SaveWalletSyncState(ctx context.Context, state WalletSyncState) error {
+	const query = `
+INSERT INTO wallet_sync_state (
+  wallet_address, sport, last_trade_at, last_trade_key, source_predictions, volume_usd,
+  last_analyzed_at, last_attempt_at, last_error, consecutive_failures
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (wallet_address, sport) DO UPDATE SET
+  last_trade_at = EXCLUDED.last_trade_at,
+  last_trade_key = EXCLUDED.last_trade_key,
+  source_predictions = EXCLUDED.source_predictions,
+  volume_usd = EXCLUDED.volume_usd,
+  last_analyzed_at = EXCLUDED.last_analyzed_at,
+  last_attempt_at = EXCLUDED.last_attempt_at,
+  last_error = EXCLUDED.last_error,
+  consecutive_failures = EXCLUDED.consecutive_failures`
+
+	_, err := s.pool.Exec(ctx, query,
+		state.WalletAddress,
+		strings.ToLower(state.Sport),
+		state.LastTradeAt,
+		state.LastTradeKey,
+		state.SourcePredictions,
+		state.VolumeUSD,
+		state.LastAnalyzedAt,
+		state.LastAttemptAt,
+		state.LastError,
+		state.ConsecutiveFailures,
+	)
+	if err != nil {
+		return fmt.Errorf("save wallet sync state %s/%s: %w", state.WalletAddress, state.Sport, err)
+	}
+	return nil
+}
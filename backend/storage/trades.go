@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StoredTrade is one raw Data API fill. Key is the trade ID, or a key built
+// from the fill's transaction and details when the API has no ID.
+type StoredTrade struct {
+	Key             string
+	ID              string
+	TransactionHash string
+	WalletAddress   string
+	ConditionID     string
+	Asset           string
+	Side            string
+	Size            float64
+	Price           float64
+	TradedAt        time.Time
+	Outcome         string
+	Title           string
+	Slug            string
+}
+
+// WalletTradeCoverage is how much of a wallet's own trade feed the store
+// holds: every trade from OldestTradeAt through NewestTradeAt, and, once
+// Complete, everything older as well. Trades stored while paging through a
+// market's feed do not extend it, so they are never mistaken for a wallet's
+// full history. Both times are nil when the feed was empty.
+type WalletTradeCoverage struct {
+	WalletAddress string
+	NewestTradeAt *time.Time
+	OldestTradeAt *time.Time
+	Complete      bool
+	SyncedAt      time.Time
+}
+
+// MarketRecord is a Gamma market with its full JSON in Data and the fields
+// worth querying pulled out.
+type MarketRecord struct {
+	ConditionID string
+	Question    string
+	Slug        string
+	VolumeUSD   float64
+	StartDate   string
+	EndDate     string
+	Closed      bool
+	Data        json.RawMessage
+	UpdatedAt   time.Time
+}
+
+var tradeColumns = []string{
+	"trade_key", "trade_id", "transaction_hash", "wallet_address", "condition_id", "asset",
+	"side", "size", "price", "traded_at", "outcome", "title", "slug",
+}
+
+var marketColumns = []string{
+	"condition_id", "question", "slug", "volume_usd", "start_date", "end_date", "closed", "data", "updated_at",
+}
+
+// InsertTrades bulk-loads trades with COPY into a staging table and keeps
+// the ones not stored yet. It returns how many were new.
+func (s *PostgresStore) InsertTrades(ctx context.Context, trades []StoredTrade) (int64, error) {
+	if len(trades) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin trade insert: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `CREATE TEMP TABLE trades_staging (LIKE trades INCLUDING DEFAULTS) ON COMMIT DROP`); err != nil {
+		return 0, fmt.Errorf("create trade staging table: %w", err)
+	}
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"trades_staging"}, tradeColumns,
+		pgx.CopyFromSlice(len(trades), func(i int) ([]any, error) {
+			t := trades[i]
+			return []any{
+				t.Key, t.ID, t.TransactionHash, strings.ToLower(t.WalletAddress), strings.ToLower(t.ConditionID), t.Asset,
+				t.Side, t.Size, t.Price, t.TradedAt, t.Outcome, t.Title, t.Slug,
+			}, nil
+		}))
+	if err != nil {
+		return 0, fmt.Errorf("copy trades: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+INSERT INTO trades
+SELECT DISTINCT ON (trade_key) * FROM trades_staging
+ON CONFLICT (trade_key) DO NOTHING`)
+	if err != nil {
+		return 0, fmt.Errorf("insert trades: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit trade insert: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListWalletTrades returns a wallet's stored trades, newest first, at most
+// limit when it is positive.
+func (s *PostgresStore) ListWalletTrades(ctx context.Context, wallet string, limit int) ([]StoredTrade, error) {
+	const query = `
+SELECT trade_key, trade_id, transaction_hash, wallet_address, condition_id, asset,
+  side, size, price, traded_at, outcome, title, slug
+FROM trades
+WHERE wallet_address = $1
+ORDER BY traded_at DESC, trade_key
+LIMIT NULLIF($2, 0)`
+
+	rows, err := s.pool.Query(ctx, query, strings.ToLower(wallet), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list wallet trades: %w", err)
+	}
+	return scanStoredTrades(rows, "wallet")
+}
+
+// ListMarketTrades returns a market's stored trades, newest first, at most
+// limit when it is positive.
+func (s *PostgresStore) ListMarketTrades(ctx context.Context, conditionID string, limit int) ([]StoredTrade, error) {
+	const query = `
+SELECT trade_key, trade_id, transaction_hash, wallet_address, condition_id, asset,
+  side, size, price, traded_at, outcome, title, slug
+FROM trades
+WHERE condition_id = $1
+ORDER BY traded_at DESC, trade_key
+LIMIT NULLIF($2, 0)`
+
+	rows, err := s.pool.Query(ctx, query, strings.ToLower(conditionID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list market trades: %w", err)
+	}
+	return scanStoredTrades(rows, "market")
+}
+
+// scanStoredTrades reads and closes rows selected in tradeColumns order;
+// kind names them in errors.
+func scanStoredTrades(rows pgx.Rows, kind string) ([]StoredTrade, error) {
+	defer rows.Close()
+
+	result := []StoredTrade{}
+	for rows.Next() {
+		var t StoredTrade
+		if err := rows.Scan(
+			&t.Key, &t.ID, &t.TransactionHash, &t.WalletAddress, &t.ConditionID, &t.Asset,
+			&t.Side, &t.Size, &t.Price, &t.TradedAt, &t.Outcome, &t.Title, &t.Slug,
+		); err != nil {
+			return nil, fmt.Errorf("scan %s trade: %w", kind, err)
+		}
+		result = append(result, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s trades: %w", kind, err)
+	}
+	return result, nil
+}
+
+// GetWalletTradeCoverage returns how much of a wallet's trade feed is
+// stored; ok is false when the wallet's own feed was never synced.
+func (s *PostgresStore) GetWalletTradeCoverage(ctx context.Context, wallet string) (WalletTradeCoverage, bool, error) {
+	const query = `
+SELECT wallet_address, newest_trade_at, oldest_trade_at, complete, synced_at
+FROM wallet_trade_coverage
+WHERE wallet_address = $1`
+
+	var coverage WalletTradeCoverage
+	err := s.pool.QueryRow(ctx, query, strings.ToLower(wallet)).Scan(
+		&coverage.WalletAddress,
+		&coverage.NewestTradeAt,
+		&coverage.OldestTradeAt,
+		&coverage.Complete,
+		&coverage.SyncedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return WalletTradeCoverage{}, false, nil
+	}
+	if err != nil {
+		return WalletTradeCoverage{}, false, fmt.Errorf("get wallet trade coverage %s: %w", wallet, err)
+	}
+	return coverage, true, nil
+}
+
+// SaveWalletTradeCoverage replaces a wallet's trade coverage and stamps it
+// with the current time.
+func (s *PostgresStore) SaveWalletTradeCoverage(ctx context.Context, coverage WalletTradeCoverage) error {
+	const query = `
+INSERT INTO wallet_trade_coverage (wallet_address, newest_trade_at, oldest_trade_at, complete, synced_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (wallet_address) DO UPDATE SET
+  newest_trade_at = EXCLUDED.newest_trade_at,
+  oldest_trade_at = EXCLUDED.oldest_trade_at,
+  complete = EXCLUDED.complete,
+  synced_at = EXCLUDED.synced_at`
+
+	_, err := s.pool.Exec(ctx, query,
+		strings.ToLower(coverage.WalletAddress),
+		coverage.NewestTradeAt,
+		coverage.OldestTradeAt,
+		coverage.Complete,
+	)
+	if err != nil {
+		return fmt.Errorf("save wallet trade coverage %s: %w", coverage.WalletAddress, err)
+	}
+	return nil
+}
+
+// UpsertMarkets bulk-loads markets with COPY into a staging table and
+// replaces the stored copies.
+func (s *PostgresStore) UpsertMarkets(ctx context.Context, markets []MarketRecord) error {
+	if len(markets) == 0 {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin market upsert: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `CREATE TEMP TABLE markets_staging (LIKE markets INCLUDING DEFAULTS) ON COMMIT DROP`); err != nil {
+		return fmt.Errorf("create market staging table: %w", err)
+	}
+	now := time.Now().UTC()
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"markets_staging"}, marketColumns,
+		pgx.CopyFromSlice(len(markets), func(i int) ([]any, error) {
+			m := markets[i]
+			return []any{
+				strings.ToLower(m.ConditionID), m.Question, m.Slug, m.VolumeUSD, m.StartDate, m.EndDate, m.Closed, m.Data, now,
+			}, nil
+		}))
+	if err != nil {
+		return fmt.Errorf("copy markets: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+INSERT INTO markets
+SELECT DISTINCT ON (condition_id) * FROM markets_staging
+ON CONFLICT (condition_id) DO UPDATE SET
+  question = EXCLUDED.question,
+  slug = EXCLUDED.slug,
+  volume_usd = EXCLUDED.volume_usd,
+  start_date = EXCLUDED.start_date,
+  end_date = EXCLUDED.end_date,
+  closed = EXCLUDED.closed,
+  data = EXCLUDED.data,
+  updated_at = EXCLUDED.updated_at`)
+	if err != nil {
+		return fmt.Errorf("upsert markets: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit market upsert: %w", err)
+	}
+	return nil
+}
+
+// GetMarketRecords returns the stored markets among conditionIDs.
+func (s *PostgresStore) GetMarketRecords(ctx context.Context, conditionIDs []string) ([]MarketRecord, error) {
+	if len(conditionIDs) == 0 {
+		return []MarketRecord{}, nil
+	}
+	ids := make([]string, len(conditionIDs))
+	for i, id := range conditionIDs {
+		ids[i] = strings.ToLower(id)
+	}
+
+	const query = `
+SELECT condition_id, question, slug, volume_usd, start_date, end_date, closed, data, updated_at
+FROM markets
+WHERE condition_id = ANY($1)`
+
+	rows, err := s.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get markets: %w", err)
+	}
+	defer rows.Close()
+
+	result := []MarketRecord{}
+	for rows.Next() {
+		var m MarketRecord
+		if err := rows.Scan(&m.ConditionID, &m.Question, &m.Slug, &m.VolumeUSD, &m.StartDate, &m.EndDate, &m.Closed, &m.Data, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan market: %w", err)
+		}
+		result = append(result, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate markets: %w", err)
+	}
+	return result, nil
+}
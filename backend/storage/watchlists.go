@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Watchlist is a user-named set of wallets the trade watcher follows in
+// addition to the tracked leaderboard wallets.
+type Watchlist struct {
+	Name      string    `json:"name"`
+	Wallets   []string  `json:"wallets"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WatchedWallet is a wallet the trade watcher follows, with the sports it is
+// tracked for and the watchlists it is on.
+type WatchedWallet struct {
+	WalletAddress string   `json:"wallet_address"`
+	Sports        []string `json:"sports"`
+	Watchlists    []string `json:"watchlists"`
+}
+
+// AddWatchlistWallets adds wallets to the named watchlist, creating it when
+// it does not exist yet.
+func (s *PostgresStore) AddWatchlistWallets(ctx context.Context, name string, wallets []string) error {
+	if len(wallets) == 0 {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin watchlist insert: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const query = `
+INSERT INTO watchlist_wallets (name, wallet_address)
+VALUES ($1, $2)
+ON CONFLICT (name, wallet_address) DO NOTHING`
+
+	for _, wallet := range wallets {
+		if _, err := tx.Exec(ctx, query, name, strings.ToLower(wallet)); err != nil {
+			return fmt.Errorf("add %s to watchlist %s: %w", wallet, name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit watchlist insert: %w", err)
+	}
+	return nil
+}
+
+// RemoveWatchlistWallet removes one wallet from the named watchlist and
+// reports whether it was on it.
+func (s *PostgresStore) RemoveWatchlistWallet(ctx context.Context, name, wallet string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM watchlist_wallets WHERE name = $1 AND wallet_address = $2`, name, strings.ToLower(wallet))
+	if err != nil {
+		return false, fmt.Errorf("remove %s from watchlist %s: %w", wallet, name, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListWatchlists returns every watchlist with its wallets, by name.
+func (s *PostgresStore) ListWatchlists(ctx context.Context) ([]Watchlist, error) {
+	const query = `
+SELECT name, array_agg(wallet_address ORDER BY wallet_address), MAX(added_at)
+FROM watchlist_wallets
+GROUP BY name
+ORDER BY name`
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list watchlists: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Watchlist{}
+	for rows.Next() {
+		var watchlist Watchlist
+		if err := rows.Scan(&watchlist.Name, &watchlist.Wallets, &watchlist.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan watchlist: %w", err)
+		}
+		result = append(result, watchlist)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watchlists: %w", err)
+	}
+	return result, nil
+}
+
+// ListWatchedWallets returns every wallet that is tracked for a sport or on
+// a watchlist, by address.
+func (s *PostgresStore) ListWatchedWallets(ctx context.Context) ([]WatchedWallet, error) {
+	const query = `
+SELECT
+  wallet_address,
+  COALESCE(array_agg(DISTINCT sport) FILTER (WHERE sport <> ''), '{}'),
+  COALESCE(array_agg(DISTINCT watchlist) FILTER (WHERE watchlist <> ''), '{}')
+FROM (
+  SELECT LOWER(wallet_address) AS wallet_address, sport, ''::TEXT AS watchlist FROM tracked_wallets
+  UNION ALL
+  SELECT wallet_address, ''::TEXT, name FROM watchlist_wallets
+) watched
+GROUP BY wallet_address
+ORDER BY wallet_address`
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list watched wallets: %w", err)
+	}
+	defer rows.Close()
+
+	result := []WatchedWallet{}
+	for rows.Next() {
+		var wallet WatchedWallet
+		if err := rows.Scan(&wallet.WalletAddress, &wallet.Sports, &wallet.Watchlists); err != nil {
+			return nil, fmt.Errorf("scan watched wallet: %w", err)
+		}
+		result = append(result, wallet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watched wallets: %w", err)
+	}
+	return result, nil
+}
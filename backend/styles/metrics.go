@@ -0,0 +1,80 @@
+package styles
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Metric describes a value that label conditions can reference.
+type Metric struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Values holds the metric readings a rule set is evaluated against.
+type Values map[string]float64
+
+var (
+	metricsMu sync.RWMutex
+	metrics   = map[string]Metric{
+		"entry_timing":       {Name: "entry_timing", Description: "Normalized entry timing (0-1); higher means trades land closer to market start"},
+		"size_ratio":         {Name: "size_ratio", Description: "Normalized position size (0-1); 1 means 10% or more of market volume"},
+		"conviction":         {Name: "conviction", Description: "Average BUY price (0-1); high backs favorites, low backs underdogs"},
+		"entry_timing_hours": {Name: "entry_timing_hours", Description: "Average hours between market start and trade execution"},
+		"size_ratio_pct":     {Name: "size_ratio_pct", Description: "Average trade notional as a percentage of market volume"},
+		"sample_size":        {Name: "sample_size", Description: "Number of sport trades the metrics were computed from"},
+	}
+)
+
+// RegisterMetric makes a metric available to label conditions. Registering
+// an existing name replaces its description.
+func RegisterMetric(name, description string) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	metrics[name] = Metric{Name: name, Description: description}
+}
+
+// Metrics returns all registered metrics sorted by name.
+func Metrics() []Metric {
+	metricsMu.RLock()
+	defer metricsMu.RUnlock()
+
+	list := make([]Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		list = append(list, metric)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func isRegisteredMetric(name string) bool {
+	metricsMu.RLock()
+	defer metricsMu.RUnlock()
+	_, ok := metrics[name]
+	return ok
+}
+
+// NormalizeEntryTiming maps average entry hours onto 0-1, where 1 means the
+// wallet trades right at market start and 0 means a day or more later.
+func NormalizeEntryTiming(hours float64) float64 {
+	return math.Max(0, 1-hours/24)
+}
+
+// NormalizeSizeRatio maps a size ratio percentage onto 0-1, capping at 10%.
+func NormalizeSizeRatio(pct float64) float64 {
+	return math.Min(1, pct/10)
+}
+
+// FromStyleMetrics builds the raw and normalized values for the three
+// deterministic style metrics.
+func FromStyleMetrics(entryTimingHours, sizeRatioPct, conviction float64, sampleSize int) Values {
+	return Values{
+		"entry_timing":       NormalizeEntryTiming(entryTimingHours),
+		"size_ratio":         NormalizeSizeRatio(sizeRatioPct),
+		"conviction":         conviction,
+		"entry_timing_hours": entryTimingHours,
+		"size_ratio_pct":     sizeRatioPct,
+		"sample_size":        float64(sampleSize),
+	}
+}
@@ -0,0 +1,180 @@
+package styles
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default_rules.yaml
+var defaultRules []byte
+
+// Condition compares one registered metric against a threshold.
+type Condition struct {
+	Metric string  `yaml:"metric" json:"metric"`
+	Op     string  `yaml:"op" json:"op"`
+	Value  float64 `yaml:"value" json:"value"`
+}
+
+// Label is a named style with the conditions that select it.
+type Label struct {
+	Name        string      `yaml:"name" json:"name"`
+	Priority    int         `yaml:"priority" json:"priority"`
+	Description string      `yaml:"description" json:"description"`
+	When        []Condition `yaml:"when" json:"when"`
+}
+
+// RuleSet is an ordered style label taxonomy.
+type RuleSet struct {
+	labels   []Label
+	fallback string
+}
+
+type ruleFile struct {
+	Labels []Label `yaml:"labels"`
+}
+
+var (
+	activeMu sync.RWMutex
+	active   *RuleSet
+)
+
+func init() {
+	rules, err := Parse(defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("parse default style rules: %v", err))
+	}
+	active = rules
+}
+
+// Active returns the rule set used by the report builder, discovery and AI
+// tagging.
+func Active() *RuleSet {
+	activeMu.RLock()
+	defer activeMu.RUnlock()
+	return active
+}
+
+// SetActive replaces the process-wide rule set.
+func SetActive(rules *RuleSet) {
+	if rules == nil {
+		return
+	}
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	active = rules
+}
+
+// Load reads a rule set from a YAML or JSON file.
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read style rules: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a rule set. JSON input is accepted because it
+// is a subset of YAML.
+func Parse(data []byte) (*RuleSet, error) {
+	var file ruleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode style rules: %w", err)
+	}
+	if len(file.Labels) == 0 {
+		return nil, fmt.Errorf("style rules define no labels")
+	}
+
+	seen := map[string]bool{}
+	fallback := ""
+	for _, label := range file.Labels {
+		if label.Name == "" {
+			return nil, fmt.Errorf("style label missing name")
+		}
+		if seen[label.Name] {
+			return nil, fmt.Errorf("duplicate style label %q", label.Name)
+		}
+		seen[label.Name] = true
+
+		if len(label.When) == 0 {
+			if fallback != "" {
+				return nil, fmt.Errorf("style labels %q and %q both lack conditions", fallback, label.Name)
+			}
+			fallback = label.Name
+			continue
+		}
+		for _, cond := range label.When {
+			if !isRegisteredMetric(cond.Metric) {
+				return nil, fmt.Errorf("style label %q references unknown metric %q", label.Name, cond.Metric)
+			}
+			if _, ok := operators[cond.Op]; !ok {
+				return nil, fmt.Errorf("style label %q uses unknown operator %q", label.Name, cond.Op)
+			}
+		}
+	}
+	if fallback == "" {
+		return nil, fmt.Errorf("style rules need one label without conditions as the fallback")
+	}
+
+	labels := append([]Label(nil), file.Labels...)
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].Priority < labels[j].Priority })
+	return &RuleSet{labels: labels, fallback: fallback}, nil
+}
+
+// Classify returns the first label whose conditions all hold. Conditions on
+// metrics missing from values never match.
+func (r *RuleSet) Classify(values Values) string {
+	for _, label := range r.labels {
+		if len(label.When) > 0 && matches(label.When, values) {
+			return label.Name
+		}
+	}
+	return r.fallback
+}
+
+// Labels returns the taxonomy in evaluation order.
+func (r *RuleSet) Labels() []Label {
+	return append([]Label(nil), r.labels...)
+}
+
+// Names returns the label names in evaluation order.
+func (r *RuleSet) Names() []string {
+	names := make([]string, 0, len(r.labels))
+	for _, label := range r.labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// Has reports whether name is part of the taxonomy.
+func (r *RuleSet) Has(name string) bool {
+	for _, label := range r.labels {
+		if label.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+var operators = map[string]func(a, b float64) bool{
+	"gt":  func(a, b float64) bool { return a > b },
+	"gte": func(a, b float64) bool { return a >= b },
+	"lt":  func(a, b float64) bool { return a < b },
+	"lte": func(a, b float64) bool { return a <= b },
+	"eq":  func(a, b float64) bool { return a == b },
+	"neq": func(a, b float64) bool { return a != b },
+}
+
+func matches(conditions []Condition, values Values) bool {
+	for _, cond := range conditions {
+		value, ok := values[cond.Metric]
+		if !ok || !operators[cond.Op](value, cond.Value) {
+			return false
+		}
+	}
+	return true
+}
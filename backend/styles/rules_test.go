@@ -0,0 +1,138 @@
+package styles
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+		want    []string
+	}{
+		{
+			name: "sorted by priority",
+			input: `
+labels:
+  - { name: Fallback, priority: 100 }
+  - { name: Late, priority: 20, when: [{ metric: entry_timing, op: lt, value: 0.3 }] }
+  - { name: Early, priority: 10, when: [{ metric: entry_timing, op: gt, value: 0.7 }] }
+`,
+			want: []string{"Early", "Late", "Fallback"},
+		},
+		{
+			name:  "json",
+			input: `{"labels": [{"name": "Big", "when": [{"metric": "size_ratio", "op": "gte", "value": 0.5}]}, {"name": "Other", "priority": 1}]}`,
+			want:  []string{"Big", "Other"},
+		},
+		{
+			name:  "equal priorities keep file order",
+			input: "labels:\n  - { name: B, when: [{ metric: conviction, op: gt, value: 0.5 }] }\n  - { name: A }\n",
+			want:  []string{"B", "A"},
+		},
+		{name: "malformed", input: "labels: [", wantErr: "decode style rules"},
+		{name: "no labels", input: "labels: []", wantErr: "no labels"},
+		{name: "missing name", input: "labels:\n  - { priority: 1 }\n", wantErr: "missing name"},
+		{name: "duplicate name", input: "labels:\n  - { name: A }\n  - { name: A }\n", wantErr: "duplicate"},
+		{name: "two fallbacks", input: "labels:\n  - { name: A }\n  - { name: B }\n", wantErr: "both lack conditions"},
+		{
+			name:    "no fallback",
+			input:   "labels:\n  - { name: A, when: [{ metric: conviction, op: gt, value: 0.5 }] }\n",
+			wantErr: "fallback",
+		},
+		{
+			name:    "unknown metric",
+			input:   "labels:\n  - { name: A, when: [{ metric: luck, op: gt, value: 0.5 }] }\n  - { name: B }\n",
+			wantErr: `unknown metric "luck"`,
+		},
+		{
+			name:    "unknown operator",
+			input:   "labels:\n  - { name: A, when: [{ metric: conviction, op: between, value: 0.5 }] }\n  - { name: B }\n",
+			wantErr: `unknown operator "between"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := Parse([]byte(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := strings.Join(rules.Names(), ","); got != strings.Join(tt.want, ",") {
+				t.Errorf("Names() = %s, want %s", got, strings.Join(tt.want, ","))
+			}
+		})
+	}
+}
+
+func TestClassifyDefaultRules(t *testing.T) {
+	rules, err := Parse(defaultRules)
+	if err != nil {
+		t.Fatalf("Parse(default rules) error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		hours   float64
+		sizePct float64
+		conv    float64
+		want    string
+	}{
+		{name: "early and large", hours: 2, sizePct: 8, conv: 0.5, want: "Early Whale"},
+		{name: "early and small", hours: 2, sizePct: 1, conv: 0.5, want: "Quick Scout"},
+		{name: "late and large", hours: 20, sizePct: 8, conv: 0.5, want: "Late Whale"},
+		{name: "favorites", hours: 12, sizePct: 1, conv: 0.8, want: "Favorite Backer"},
+		{name: "underdogs", hours: 12, sizePct: 1, conv: 0.2, want: "Contrarian Hunter"},
+		{name: "no buys is not contrarian", hours: 20, sizePct: 1, conv: 0, want: "Steady Player"},
+		{name: "mid timing, very large", hours: 12, sizePct: 9, conv: 0.5, want: "Heavy Hitter"},
+		{name: "earlier than average", hours: 10, sizePct: 1, conv: 0.5, want: "Early Bird"},
+		{name: "nothing stands out", hours: 20, sizePct: 1, conv: 0.5, want: "Steady Player"},
+		// entry_timing of exactly 0.7 is not "gt 0.7", so the early labels
+		// are skipped.
+		{name: "threshold is exclusive", hours: 7.2, sizePct: 8, conv: 0.5, want: "Heavy Hitter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.Classify(FromStyleMetrics(tt.hours, tt.sizePct, tt.conv, 20))
+			if got != tt.want {
+				t.Errorf("Classify(%v h, %v%%, %v) = %q, want %q", tt.hours, tt.sizePct, tt.conv, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyMissingMetric(t *testing.T) {
+	rules, err := Parse([]byte(`
+labels:
+  - { name: Veteran, priority: 1, when: [{ metric: sample_size, op: gte, value: 50 }] }
+  - { name: Newcomer, priority: 2 }
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		values Values
+		want   string
+	}{
+		{name: "condition holds", values: Values{"sample_size": 80}, want: "Veteran"},
+		{name: "condition fails", values: Values{"sample_size": 10}, want: "Newcomer"},
+		{name: "metric missing", values: Values{"conviction": 0.9}, want: "Newcomer"},
+		{name: "no values", values: nil, want: "Newcomer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.Classify(tt.values); got != tt.want {
+				t.Errorf("Classify(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,26 +2,83 @@ package profilesync
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/brucexwang/easy-arbitra/backend/consensus"
 	"github.com/brucexwang/easy-arbitra/backend/discovery"
+	"github.com/brucexwang/easy-arbitra/backend/events"
+	"github.com/brucexwang/easy-arbitra/backend/jobs"
+	"github.com/brucexwang/easy-arbitra/backend/leader"
 	"github.com/brucexwang/easy-arbitra/backend/leaderboard"
 	"github.com/brucexwang/easy-arbitra/backend/polymarket"
 	"github.com/brucexwang/easy-arbitra/backend/profileai"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
 	"github.com/brucexwang/easy-arbitra/backend/storage"
 )
 
+const (
+	// maxProfileAge is how long an unchanged wallet's profile is kept before
+	// it is analyzed again, so rule and model changes reach every wallet.
+	maxProfileAge = 7 * 24 * time.Hour
+	// maxFailureBackoff caps how long a failing wallet is skipped.
+	maxFailureBackoff = 24 * time.Hour
+	// scheduleCheckInterval is how often the scheduler checks whether a
+	// sync is due.
+	scheduleCheckInterval = time.Minute
+	// maxRunWalletErrors caps the per-wallet errors kept on a run record.
+	maxRunWalletErrors = 200
+	// runHeartbeatInterval is how often a run in progress touches its
+	// record.
+	runHeartbeatInterval = time.Minute
+	// staleRunAfter is how long a running record may go without a heartbeat
+	// before it is taken to be abandoned.
+	staleRunAfter = 5 * runHeartbeatInterval
+)
+
+// JobKind is the job kind sync runs are submitted under.
+const JobKind = "style_wallet_sync"
+
+// Run triggers recorded in sync_runs.
+const (
+	TriggerScheduled = "scheduled"
+	TriggerManual    = "manual"
+)
+
+// Status is what the service is doing now and when it runs next.
+type Status struct {
+	Running   []storage.SyncRun `json:"running"`
+	Interval  string            `json:"interval"`
+	NextRunAt *time.Time        `json:"next_run_at,omitempty"`
+}
+
 type Service struct {
 	client      *polymarket.Client
-	store       *storage.Store
+	store       storage.Store
 	ai          *profileai.Client
+	sports      []string
 	interval    time.Duration
 	topLimit    int
 	walletLimit int
+	consensus   consensus.Options
+	bus         *events.Bus
+	jobs        *jobs.Manager
+	elector     *leader.Elector
+	instanceID  string
+
+	mu        sync.Mutex
+	active    map[int64]storage.SyncRun
+	nextRunAt time.Time
 }
 
-func NewService(client *polymarket.Client, store *storage.Store, ai *profileai.Client, interval time.Duration, topLimit, walletLimit int) *Service {
+func NewService(client *polymarket.Client, store storage.Store, ai *profileai.Client, sportKeys []string, interval time.Duration, topLimit, walletLimit int) *Service {
+	if len(sportKeys) == 0 {
+		sportKeys = []string{sports.Default}
+	}
 	if interval <= 0 {
 		interval = 4 * time.Hour
 	}
@@ -35,34 +92,222 @@ func NewService(client *polymarket.Client, store *storage.Store, ai *profileai.C
 		client:      client,
 		store:       store,
 		ai:          ai,
+		sports:      sportKeys,
 		interval:    interval,
 		topLimit:    topLimit,
 		walletLimit: walletLimit,
+		instanceID:  leader.DefaultInstanceID(),
+		active:      map[int64]storage.SyncRun{},
 	}
 }
 
+// Start checks every minute, or every interval when that is shorter,
+// whether a scheduled sync is due. The schedule follows the start of the
+// last scheduled run recorded in sync_runs, so it survives restarts and
+// leader changes. Runs left running by a stopped process are marked failed
+// first.
 func (s *Service) Start(ctx context.Context) {
+	s.abandonRuns(ctx, s.instanceID)
+
 	go func() {
-		s.runOnceWithLogging(ctx)
+		s.scheduledRun(ctx)
 
-		ticker := time.NewTicker(s.interval)
+		ticker := time.NewTicker(min(s.interval, scheduleCheckInterval))
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				s.runOnceWithLogging(ctx)
+				s.scheduledRun(ctx)
 			}
 		}
 	}()
 }
 
-func (s *Service) RunOnce(ctx context.Context) error {
-	entries, err := leaderboard.FetchNBALeaderboard(ctx, s.topLimit)
+// Sports returns the sports synced on every run.
+func (s *Service) Sports() []string {
+	return append([]string(nil), s.sports...)
+}
+
+// SetConsensusOptions tunes the consensus refresh that follows each sport's
+// wallet sync.
+func (s *Service) SetConsensusOptions(opts consensus.Options) {
+	s.consensus = opts
+}
+
+// SetJobManager runs scheduled and submitted syncs as jobs, so they never
+// overlap.
+func (s *Service) SetJobManager(manager *jobs.Manager) {
+	s.jobs = manager
+}
+
+// SetElector makes only the holder of the elector's lease run scheduled
+// syncs. Manual syncs run on any instance. Taking over the lease marks the
+// runs the previous holder left running as abandoned.
+func (s *Service) SetElector(elector *leader.Elector) {
+	s.elector = elector
+	s.instanceID = elector.InstanceID()
+	elector.OnAcquire(func(ctx context.Context, previous string) {
+		s.abandonRuns(ctx, previous)
+	})
+}
+
+// SetEventBus makes the sync publish its progress and profile changes.
+func (s *Service) SetEventBus(bus *events.Bus) {
+	s.bus = bus
+}
+
+// Status reports the runs in progress and the next scheduled run.
+func (s *Service) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{Running: []storage.SyncRun{}, Interval: s.interval.String()}
+	for _, run := range s.active {
+		status.Running = append(status.Running, run)
+	}
+	sort.Slice(status.Running, func(i, j int) bool { return status.Running[i].ID < status.Running[j].ID })
+	if !s.nextRunAt.IsZero() {
+		next := s.nextRunAt
+		status.NextRunAt = &next
+	}
+	return status
+}
+
+// RunOnce syncs every sport and records the run in sync_runs under the
+// given trigger. The returned run holds the final counters even when the
+// sync failed.
+func (s *Service) RunOnce(ctx context.Context, trigger string) (storage.SyncRun, error) {
+	run, err := s.store.StartSyncRun(ctx, trigger, s.instanceID, s.sports)
+	if err != nil {
+		return storage.SyncRun{}, err
+	}
+	s.mu.Lock()
+	s.active[run.ID] = run
+	s.mu.Unlock()
+	stopHeartbeat := s.heartbeat(ctx, run.ID)
+	defer func() {
+		stopHeartbeat()
+		s.mu.Lock()
+		delete(s.active, run.ID)
+		s.mu.Unlock()
+	}()
+
+	var errs []error
+	s.publishSync(ctx, events.SyncEvent{Stage: "started", Message: "wallet sync started"})
+	for _, sport := range s.sports {
+		if err := s.runSport(ctx, sport, &run); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sport, err))
+		}
+		s.publishSync(ctx, events.SyncEvent{Sport: sport, Stage: "consensus"})
+		if err := s.RefreshConsensus(ctx, sport); err != nil {
+			errs = append(errs, fmt.Errorf("%s consensus: %w", sport, err))
+		}
+	}
+
+	err = errors.Join(errs...)
+	finishedAt := time.Now().UTC()
+	run.FinishedAt = &finishedAt
+	run.Status = storage.SyncRunCompleted
+	if err != nil {
+		run.Status = storage.SyncRunFailed
+		run.Error = err.Error()
+		s.publishSync(ctx, events.SyncEvent{Stage: "failed", Error: err.Error()})
+	} else {
+		s.publishSync(ctx, events.SyncEvent{Stage: "completed", Message: "wallet sync completed"})
+	}
+
+	// Record the outcome even when the run was cut short by its context.
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if saveErr := s.store.FinishSyncRun(saveCtx, run); saveErr != nil {
+		err = errors.Join(err, saveErr)
+	}
+	return run, err
+}
+
+// heartbeat touches the run's record every runHeartbeatInterval until the
+// returned stop function is called, so other instances can tell it from an
+// abandoned one.
+func (s *Service) heartbeat(ctx context.Context, id int64) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(runHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.store.TouchSyncRun(ctx, id); err != nil && ctx.Err() == nil {
+					log.Printf("wallet sync run %d heartbeat: %v", id, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// abandonRuns marks as failed the runs instanceID left running and any
+// run whose heartbeat has gone stale.
+func (s *Service) abandonRuns(ctx context.Context, instanceID string) {
+	abandoned, err := s.store.AbandonSyncRuns(ctx, instanceID, staleRunAfter)
+	if err != nil {
+		log.Printf("wallet sync: %v", err)
+		return
+	}
+	if abandoned > 0 {
+		log.Printf("wallet sync: marked %d abandoned run(s) failed", abandoned)
+	}
+}
+
+func (s *Service) setNextRun(at time.Time) {
+	s.mu.Lock()
+	s.nextRunAt = at
+	s.mu.Unlock()
+}
+
+// RefreshConsensus recomputes the top-wallet consensus for a sport's
+// upcoming games and replaces the stored rows.
+func (s *Service) RefreshConsensus(ctx context.Context, sport string) error {
+	wallets, err := s.store.ListTrackedWallets(ctx, sport)
 	if err != nil {
 		return err
 	}
+	games := sports.SharedIndex().Games(sports.GameFilter{Sport: sport, Status: sports.GameScheduled})
+
+	rows, err := consensus.Compute(ctx, s.client, wallets, games, s.consensus)
+	if err != nil {
+		return err
+	}
+	return s.store.ReplaceMarketConsensus(ctx, sport, rows)
+}
+
+func (s *Service) runSport(ctx context.Context, sport string, run *storage.SyncRun) error {
+	startedAt := time.Now().UTC()
+	s.publishSync(ctx, events.SyncEvent{Sport: sport, Stage: "leaderboard"})
+	entries, err := leaderboard.FetchLeaderboard(ctx, sport, s.topLimit)
+	if err != nil {
+		return err
+	}
+
+	// Profiles as of the previous sync, read before the ranks are replaced.
+	previous := map[string]storage.ProfileVector{}
+	if s.bus != nil {
+		vectors, err := s.store.ListProfileVectors(ctx, storage.ProfileFilter{Sport: sport})
+		if err != nil {
+			return err
+		}
+		for _, vector := range vectors {
+			previous[vector.WalletAddress] = vector
+		}
+	}
 
 	tracked := make([]storage.TrackedWallet, 0, len(entries))
 	wallets := make([]string, 0, len(entries))
@@ -70,8 +315,9 @@ func (s *Service) RunOnce(ctx context.Context) error {
 	for _, entry := range entries {
 		tracked = append(tracked, storage.TrackedWallet{
 			WalletAddress:     entry.WalletAddress,
+			Sport:             sport,
 			DisplayName:       entry.DisplayName,
-			Source:            "polymarketanalytics_nba",
+			Source:            leaderboard.Source(sport),
 			SourceRank:        entry.Rank,
 			SourcePredictions: entry.Predictions,
 			SourceWins:        entry.Wins,
@@ -90,84 +336,317 @@ func (s *Service) RunOnce(ctx context.Context) error {
 		return err
 	}
 
-	candidates, err := discovery.ScoreWallets(ctx, s.client, wallets, discovery.Options{
-		Sport:       "nba",
-		OutputLimit: len(wallets),
-		WalletLimit: s.walletLimit,
-	})
+	stored, err := s.store.ListWalletSyncStates(ctx, sport)
 	if err != nil {
 		return err
 	}
-
-	candidateByWallet := map[string]discovery.Candidate{}
-	for _, candidate := range candidates {
-		candidateByWallet[candidate.Wallet] = candidate
+	states := make(map[string]storage.WalletSyncState, len(stored))
+	for _, state := range stored {
+		states[state.WalletAddress] = state
 	}
 
-	for _, wallet := range wallets {
+	analyzed, unchanged, backingOff, failed := 0, 0, 0, 0
+	for i, wallet := range wallets {
 		entry := metaByWallet[wallet]
-		candidate, ok := candidateByWallet[wallet]
+		s.publishSync(ctx, events.SyncEvent{Sport: sport, Stage: "profiles", Done: i, Total: len(wallets)})
+
+		now := time.Now().UTC()
+		state, ok := states[wallet]
 		if !ok {
+			state = storage.WalletSyncState{WalletAddress: wallet, Sport: sport}
+		}
+		if s.backingOff(state, now) {
+			backingOff++
 			continue
 		}
 
-		styleResult, err := s.ai.Classify(ctx, profileai.Input{
-			Wallet:                  wallet,
-			DisplayName:             candidate.DisplayName,
-			SourceRank:              entry.Rank,
-			WinRate:                 entry.WinRate,
-			PnlUSD:                  entry.PnlUSD,
-			NbaTrades:               candidate.NbaTrades,
-			RecentMarkets:           candidate.RecentMarkets,
-			EntryTimingHours:        candidate.EntryTimingHours,
-			SizeRatioPct:            candidate.SizeRatioPct,
-			Conviction:              candidate.Conviction,
-			DeterministicStyleLabel: candidate.StyleLabel,
-			PresentationScore:       candidate.PresentationScore,
-		})
+		coverage, _, err := s.store.GetWalletTradeCoverage(ctx, wallet)
 		if err != nil {
-			styleResult = profileai.Result{
-				StyleLabel: candidate.StyleLabel,
-				Summary:    "AI tagging failed; using deterministic style label.",
-				Source:     "fallback",
-				Model:      "",
-			}
+			return err
+		}
+		if !needsAnalysis(state, entry, coverage.NewestTradeAt, now) {
+			unchanged++
+			s.publishProfileChange(previous, wallet, sport, entry, previous[wallet].DisplayName, previous[wallet].StyleLabel)
+			continue
+		}
+		err = s.analyzeWallet(ctx, sport, wallet, entry, previous, run)
+		if errors.Is(err, discovery.ErrNoSportTrades) {
+			err = nil
 		}
 
-		if err := s.store.UpsertWalletProfile(ctx, storage.WalletProfile{
-			WalletAddress:           wallet,
-			DisplayName:             candidate.DisplayName,
-			SourceRank:              entry.Rank,
-			WinRate:                 entry.WinRate,
-			PnlUSD:                  entry.PnlUSD,
-			NbaTrades:               candidate.NbaTrades,
-			RecentMarkets:           candidate.RecentMarkets,
-			EntryTimingHours:        candidate.EntryTimingHours,
-			SizeRatioPct:            candidate.SizeRatioPct,
-			Conviction:              candidate.Conviction,
-			DeterministicStyleLabel: candidate.StyleLabel,
-			AIStyleLabel:            styleResult.StyleLabel,
-			AIStyleSummary:          styleResult.Summary,
-			ExplanationSource:       styleResult.Source,
-			Model:                   styleResult.Model,
-			PresentationScore:       candidate.PresentationScore,
-			AnalyzedAt:              time.Now().UTC(),
-		}); err != nil {
+		state.LastAttemptAt = now
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failed++
+			if len(run.WalletErrors) < maxRunWalletErrors {
+				run.WalletErrors = append(run.WalletErrors, storage.SyncWalletError{WalletAddress: wallet, Sport: sport, Error: err.Error()})
+			}
+			state.LastError = err.Error()
+			state.ConsecutiveFailures++
+		} else {
+			newest, err := s.store.ListWalletTrades(ctx, wallet, 1)
+			if err != nil {
+				return err
+			}
+			analyzed++
+			state.LastError = ""
+			state.ConsecutiveFailures = 0
+			state.LastAnalyzedAt = &now
+			state.SourcePredictions, state.VolumeUSD = entry.Predictions, entry.VolumeUSD
+			state.LastTradeAt, state.LastTradeKey = nil, ""
+			if len(newest) > 0 {
+				at := newest[0].TradedAt.UTC()
+				state.LastTradeAt, state.LastTradeKey = &at, newest[0].Key
+			}
+		}
+		if err := s.store.SaveWalletSyncState(ctx, state); err != nil {
 			return err
 		}
 	}
 
+	run.WalletsAttempted += len(wallets)
+	run.WalletsSucceeded += analyzed
+	run.WalletsFailed += failed
+	run.WalletsSkipped += unchanged + backingOff
+	log.Printf("%s wallet sync: %d analyzed, %d unchanged, %d backing off, %d failed", sport, analyzed, unchanged, backingOff, failed)
+	return s.store.SnapshotWallets(ctx, sport, wallets, startedAt)
+}
+
+// needsAnalysis reports whether a wallet may have traded since it was last
+// analyzed, judged without calling the API: its last attempt failed, its
+// leaderboard activity changed, or the trade store has synced trades newer
+// than the ones analyzed. Profiles old enough are refreshed anyway.
+func needsAnalysis(state storage.WalletSyncState, entry leaderboard.Entry, newestStored *time.Time, now time.Time) bool {
+	if state.LastAnalyzedAt == nil || now.Sub(*state.LastAnalyzedAt) >= maxProfileAge {
+		return true
+	}
+	if state.LastError != "" {
+		return true
+	}
+	if entry.Predictions != state.SourcePredictions || entry.VolumeUSD != state.VolumeUSD {
+		return true
+	}
+	return newestStored != nil && (state.LastTradeAt == nil || newestStored.After(*state.LastTradeAt))
+}
+
+// backingOff reports whether a wallet whose recent attempts failed should
+// sit this run out. A first failure is retried on the next run; after that
+// the wait doubles per failure, up to a day.
+func (s *Service) backingOff(state storage.WalletSyncState, now time.Time) bool {
+	if state.ConsecutiveFailures < 2 {
+		return false
+	}
+	wait := s.interval << minInt(state.ConsecutiveFailures-1, 5)
+	if wait > maxFailureBackoff {
+		wait = maxFailureBackoff
+	}
+	return now.Sub(state.LastAttemptAt) < wait-s.interval/2
+}
+
+// analyzeWallet rescores a wallet, tags its style and saves the profile.
+// Its trades come from the trade store, which first syncs the ones newer
+// than its coverage and backfills until the coverage is deep enough, so the
+// metrics are only computed from a stored history known to be complete.
+func (s *Service) analyzeWallet(ctx context.Context, sport, wallet string, entry leaderboard.Entry, previous map[string]storage.ProfileVector, run *storage.SyncRun) error {
+	candidate, err := discovery.ScoreNamedWallet(ctx, s.client, s.store, wallet, entry.DisplayName, discovery.Options{
+		Sport:       sport,
+		WalletLimit: s.walletLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	styleResult, err := s.ai.Classify(ctx, profileai.Input{
+		Wallet:                  wallet,
+		Sport:                   sport,
+		DisplayName:             candidate.DisplayName,
+		SourceRank:              entry.Rank,
+		WinRate:                 entry.WinRate,
+		PnlUSD:                  entry.PnlUSD,
+		SportTrades:             candidate.SportTrades,
+		RecentMarkets:           candidate.RecentMarkets,
+		EntryTimingHours:        candidate.EntryTimingHours,
+		SizeRatioPct:            candidate.SizeRatioPct,
+		Conviction:              candidate.Conviction,
+		DeterministicStyleLabel: candidate.StyleLabel,
+		PresentationScore:       candidate.PresentationScore,
+	})
+	if err != nil {
+		styleResult = profileai.Result{
+			StyleLabel: candidate.StyleLabel,
+			Summary:    "AI tagging failed; using deterministic style label.",
+			Source:     "fallback",
+			Model:      "",
+		}
+	}
+	if s.ai.Configured() {
+		run.AICalls++
+	}
+	if styleResult.Source == "fallback" {
+		run.AIFallbacks++
+	}
+
+	if err := s.store.UpsertWalletProfile(ctx, storage.WalletProfile{
+		WalletAddress:           wallet,
+		Sport:                   sport,
+		DisplayName:             candidate.DisplayName,
+		SourceRank:              entry.Rank,
+		WinRate:                 entry.WinRate,
+		PnlUSD:                  entry.PnlUSD,
+		VolumeUSD:               entry.VolumeUSD,
+		SportTrades:             candidate.SportTrades,
+		RecentMarkets:           candidate.RecentMarkets,
+		EntryTimingHours:        candidate.EntryTimingHours,
+		SizeRatioPct:            candidate.SizeRatioPct,
+		Conviction:              candidate.Conviction,
+		DeterministicStyleLabel: candidate.StyleLabel,
+		AIStyleLabel:            styleResult.StyleLabel,
+		AIStyleSummary:          styleResult.Summary,
+		ExplanationSource:       styleResult.Source,
+		Model:                   styleResult.Model,
+		PresentationScore:       candidate.PresentationScore,
+		AnalyzedAt:              time.Now().UTC(),
+	}); err != nil {
+		return err
+	}
+
+	s.publishProfileChange(previous, wallet, sport, entry, candidate.DisplayName, styleResult.StyleLabel)
 	return nil
 }
 
-func (s *Service) runOnceWithLogging(ctx context.Context) {
+// publishProfileChange publishes a profile event when the wallet is new to
+// the sport's profiles or its rank or style label changed.
+func (s *Service) publishProfileChange(previous map[string]storage.ProfileVector, wallet, sport string, entry leaderboard.Entry, displayName, styleLabel string) {
+	if s.bus == nil {
+		return
+	}
+	prior, seen := previous[wallet]
+	if seen && prior.SourceRank == entry.Rank && prior.StyleLabel == styleLabel {
+		return
+	}
+	if !seen && styleLabel == "" {
+		return
+	}
+	change := events.ProfileEvent{
+		Wallet:      wallet,
+		Sport:       sport,
+		DisplayName: displayName,
+		SourceRank:  entry.Rank,
+		StyleLabel:  styleLabel,
+		New:         !seen,
+	}
+	if seen {
+		change.PreviousRank = prior.SourceRank
+		change.PreviousStyleLabel = prior.StyleLabel
+	}
+	s.publish(events.Event{Type: events.TypeProfile, Profile: &change})
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// publishSync publishes sync progress on the bus and, when the sync runs
+// as a job, as the job's progress.
+func (s *Service) publishSync(ctx context.Context, progress events.SyncEvent) {
+	s.publish(events.Event{Type: events.TypeSync, Sync: &progress})
+	jobs.Report(ctx, jobs.Progress{
+		Stage:   progress.Stage,
+		Message: fallbackString(progress.Message, progress.Sport),
+		Done:    progress.Done,
+		Total:   progress.Total,
+	})
+}
+
+func (s *Service) publish(event events.Event) {
+	if s.bus != nil {
+		s.bus.Publish(event)
+	}
+}
+
+// Submit queues a sync run as a job. Only one sync job is in flight at a
+// time; while one is, Submit returns it with created false.
+func (s *Service) Submit(trigger string) (jobs.Job, bool, error) {
+	if s.jobs == nil {
+		return jobs.Job{}, false, errors.New("wallet sync has no job manager")
+	}
+	return s.submit(trigger, nil)
+}
+
+// submit queues a sync job. When lead is set, the run is also canceled
+// once lead is.
+func (s *Service) submit(trigger string, lead context.Context) (jobs.Job, bool, error) {
+	return s.jobs.Submit(JobKind, "", func(ctx context.Context) (any, error) {
+		if lead != nil {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			defer cancel()
+			stop := context.AfterFunc(lead, cancel)
+			defer stop()
+		}
+		return s.runWithLogging(ctx, trigger)
+	})
+}
+
+// scheduledRun starts a scheduled sync when this instance holds the
+// scheduler lease and the last scheduled run, from any instance, started at
+// least one interval ago. The run is canceled if the lease is lost.
+func (s *Service) scheduledRun(ctx context.Context) {
+	var lead context.Context
+	if s.elector != nil {
+		lead = s.elector.Leadership()
+		if lead.Err() != nil {
+			s.setNextRun(time.Time{})
+			return
+		}
+		ctx = lead
+	}
+
+	last, ok, err := s.store.LatestSyncRun(ctx, TriggerScheduled)
+	if err != nil {
+		log.Printf("wallet sync schedule: %v", err)
+		return
+	}
+	if ok {
+		next := last.StartedAt.Add(s.interval)
+		s.setNextRun(next)
+		if time.Now().Before(next) {
+			return
+		}
+	}
+
+	if s.jobs == nil {
+		s.runWithLogging(ctx, TriggerScheduled)
+		return
+	}
+	// A sync job still in flight is returned rather than queued again.
+	if _, _, err := s.submit(TriggerScheduled, lead); err != nil {
+		log.Printf("wallet sync not scheduled: %v", err)
+	}
+}
+
+func (s *Service) runWithLogging(ctx context.Context, trigger string) (storage.SyncRun, error) {
 	runCtx, cancel := context.WithTimeout(ctx, 2*time.Hour)
 	defer cancel()
 
 	start := time.Now()
-	if err := s.RunOnce(runCtx); err != nil {
-		log.Printf("wallet sync failed: %v", err)
-		return
+	run, err := s.RunOnce(runCtx, trigger)
+	if err != nil {
+		log.Printf("%s wallet sync failed: %v", trigger, err)
+		return run, err
+	}
+	log.Printf("%s wallet sync completed in %s", trigger, time.Since(start).Round(time.Second))
+	return run, nil
+}
+
+func fallbackString(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
 	}
-	log.Printf("wallet sync completed in %s", time.Since(start).Round(time.Second))
+	return value
 }
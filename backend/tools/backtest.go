@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/backtest"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type BacktestQuery struct {
+	Input      string
+	Sport      string
+	TradeLimit int
+	Strategy   backtest.Strategy
+}
+
+func BacktestCopyTrading(client *polymarket.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		input, ok := args["input"].(string)
+		if !ok || input == "" {
+			return mcp.NewToolResultError("input parameter is required"), nil
+		}
+
+		query := BacktestQuery{Input: input, Sport: sports.Default, Strategy: backtest.DefaultStrategy()}
+		if s, ok := args["sport"].(string); ok && s != "" {
+			query.Sport = s
+		}
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			query.TradeLimit = int(l)
+		}
+		if s, ok := args["mode"].(string); ok && s != "" {
+			query.Strategy.Mode = s
+		}
+		if v, ok := args["stake_usd"].(float64); ok && v > 0 {
+			query.Strategy.Stake = v
+		}
+		if v, ok := args["ratio"].(float64); ok && v > 0 {
+			query.Strategy.Ratio = v
+		}
+		if v, ok := args["delay_seconds"].(float64); ok && v >= 0 {
+			query.Strategy.DelaySeconds = int(v)
+		}
+		if s, ok := args["slippage"].(string); ok && s != "" {
+			query.Strategy.Slippage = s
+		}
+		if v, ok := args["slippage_bps"].(float64); ok && v >= 0 {
+			query.Strategy.SlippageBps = v
+		}
+		if v, ok := args["max_exposure_usd"].(float64); ok && v >= 0 {
+			query.Strategy.MaxExposure = v
+		}
+		if v, ok := args["bankroll_usd"].(float64); ok && v > 0 {
+			query.Strategy.Bankroll = v
+		}
+		if v, ok := args["ignore_sells"].(bool); ok {
+			query.Strategy.IgnoreSells = v
+		}
+
+		result, err := BacktestData(ctx, client, query)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// BacktestData replays a wallet's sport trades under a copy strategy.
+func BacktestData(ctx context.Context, client *polymarket.Client, query BacktestQuery) (backtest.Result, error) {
+	input, err := LoadBacktestInput(ctx, client, query)
+	if err != nil {
+		return backtest.Result{}, err
+	}
+
+	LogToolf(ctx, "Replaying %d trades", len(input.Trades))
+	result := backtest.Run(input, query.Strategy)
+	LogToolf(ctx, "Backtest complete: %d copied, final equity $%.2f", result.Summary.Copied, result.Summary.FinalEquity)
+	return result, nil
+}
+
+// LoadBacktestInput resolves the wallet and loads its trades, their markets
+// and, unless the strategy fills at the wallet's prices, CLOB price history.
+func LoadBacktestInput(ctx context.Context, client *polymarket.Client, query BacktestQuery) (backtest.Input, error) {
+	sport := sports.Normalize(query.Sport)
+	if query.TradeLimit <= 0 {
+		query.TradeLimit = 3000
+	}
+
+	resolved, err := ResolveWalletTargetData(ctx, client, query.Input)
+	if err != nil {
+		return backtest.Input{}, err
+	}
+
+	LogToolf(ctx, "Loading up to %d trades of %s for %s", query.TradeLimit, resolved.WalletAddress, strings.ToUpper(sport))
+	input, err := backtest.Load(ctx, client, resolved.WalletAddress, sport, query.TradeLimit)
+	if err != nil {
+		return backtest.Input{}, err
+	}
+	LogToolf(ctx, "Found %d %s trades across %d markets", len(input.Trades), strings.ToUpper(sport), len(input.Markets))
+
+	if !strings.EqualFold(query.Strategy.Slippage, backtest.SlippageNone) {
+		LogToolf(ctx, "Fetching CLOB price history for the copied outcomes")
+		delay := time.Duration(query.Strategy.DelaySeconds) * time.Second
+		if err := backtest.LoadPriceHistory(ctx, client, &input, delay); err != nil {
+			return backtest.Input{}, err
+		}
+	}
+	return input, nil
+}
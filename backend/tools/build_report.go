@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/styles"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -48,6 +50,7 @@ func BuildReportPayload() func(ctx context.Context, request mcp.CallToolRequest)
 		}
 
 		tradesSummaryJSON, _ := args["trades_summary"].(string)
+		sport, _ := args["sport"].(string)
 
 		// Parse wallet info
 		var walletInfo ResolveResult
@@ -61,14 +64,12 @@ func BuildReportPayload() func(ctx context.Context, request mcp.CallToolRequest)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to parse metrics_json: %v", err)), nil
 		}
 
-		// Normalize radar chart values (0-1)
-		entryTiming := math.Max(0, 1-metricsData.Metrics.EntryTimingHours/24)
-		sizeRatio := math.Min(1, metricsData.Metrics.SizeRatioPct/10)
-		// Conviction is already 0-1 (average buy price)
-		conviction := metricsData.Metrics.Conviction
+		if sport == "" {
+			sport = metricsData.Sport
+		}
 
-		// Determine style label
-		styleLabel := DetermineStyleLabel(entryTiming, sizeRatio, conviction)
+		// Normalize radar chart values (0-1) and determine style label
+		radarChart, styleLabel := RadarAndLabel(metricsData)
 
 		// Build summary context
 		summaryContext := fmt.Sprintf(
@@ -88,14 +89,10 @@ func BuildReportPayload() func(ctx context.Context, request mcp.CallToolRequest)
 				Address:      walletInfo.WalletAddress,
 				DisplayName:  walletInfo.DisplayName,
 				ProfileImage: walletInfo.ProfileImage,
-				Sport:        "NBA",
+				Sport:        sports.DisplayName(sport),
 				TotalTrades:  metricsData.SampleSize,
 			},
-			RadarChart: RadarChart{
-				EntryTiming: math.Round(entryTiming*100) / 100,
-				SizeRatio:   math.Round(sizeRatio*100) / 100,
-				Conviction:  math.Round(conviction*100) / 100,
-			},
+			RadarChart: radarChart,
 			Report: Report{
 				StyleLabel:     styleLabel,
 				SummaryContext: summaryContext,
@@ -107,27 +104,21 @@ func BuildReportPayload() func(ctx context.Context, request mcp.CallToolRequest)
 	}
 }
 
-func DetermineStyleLabel(entryTiming, sizeRatio, conviction float64) string {
-	if entryTiming > 0.7 && sizeRatio > 0.5 {
-		return "Early Whale"
-	}
-	if entryTiming > 0.7 && sizeRatio <= 0.5 {
-		return "Quick Scout"
-	}
-	if entryTiming <= 0.3 && sizeRatio > 0.5 {
-		return "Late Whale"
-	}
-	if conviction > 0.75 {
-		return "Favorite Backer"
-	}
-	if conviction < 0.35 && conviction > 0 {
-		return "Contrarian Hunter"
-	}
-	if sizeRatio > 0.7 {
-		return "Heavy Hitter"
-	}
-	if entryTiming > 0.5 {
-		return "Early Bird"
+// RadarAndLabel normalizes metrics onto the 0-1 radar axes and classifies
+// them with the active style rule set.
+func RadarAndLabel(metricsData MetricsResult) (RadarChart, string) {
+	values := styles.FromStyleMetrics(
+		metricsData.Metrics.EntryTimingHours,
+		metricsData.Metrics.SizeRatioPct,
+		metricsData.Metrics.Conviction,
+		metricsData.SampleSize,
+	)
+
+	// Conviction is already 0-1 (average buy price)
+	radar := RadarChart{
+		EntryTiming: math.Round(values["entry_timing"]*100) / 100,
+		SizeRatio:   math.Round(values["size_ratio"]*100) / 100,
+		Conviction:  math.Round(values["conviction"]*100) / 100,
 	}
-	return "Steady Player"
+	return radar, styles.Active().Classify(values)
 }
@@ -7,11 +7,13 @@ import (
 
 	"github.com/brucexwang/easy-arbitra/backend/metrics"
 	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 type MetricsResult struct {
 	Wallet     string       `json:"wallet"`
+	Sport      string       `json:"sport,omitempty"`
 	Metrics    StyleMetrics `json:"metrics"`
 	SampleSize int          `json:"sample_size"`
 	Warning    string       `json:"warning,omitempty"`
@@ -36,40 +38,49 @@ func CalculateStyleMetrics() func(ctx context.Context, request mcp.CallToolReque
 			return mcp.NewToolResultError("trades_json parameter is required"), nil
 		}
 
+		sport, _ := args["sport"].(string)
+
 		// Accept both the full FetchTradesResult object and a plain []EnrichedTrade array
 		var trades []polymarket.EnrichedTrade
 		var wrapped FetchTradesResult
 		if err := json.Unmarshal([]byte(tradesJSON), &wrapped); err == nil && wrapped.Wallet != "" {
 			trades = wrapped.Trades
+			if sport == "" {
+				sport = wrapped.Sport
+			}
 		} else if err := json.Unmarshal([]byte(tradesJSON), &trades); err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to parse trades_json: %v", err)), nil
 		}
 
-		if len(trades) == 0 {
-			result := MetricsResult{
-				Wallet:     wallet,
-				Metrics:    StyleMetrics{},
-				SampleSize: 0,
-			}
-			data, _ := json.Marshal(result)
-			return mcp.NewToolResultText(string(data)), nil
-		}
+		result := ComputeMetrics(wallet, trades)
+		result.Sport = sports.Normalize(sport)
+		data, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
 
-		result := MetricsResult{
-			Wallet: wallet,
-			Metrics: StyleMetrics{
-				EntryTimingHours: metrics.EntryTimingHours(trades),
-				SizeRatioPct:     metrics.SizeRatioPct(trades),
-				Conviction:       metrics.Conviction(trades),
-			},
-			SampleSize: len(trades),
+// ComputeMetrics derives the deterministic style metrics from enriched trades.
+func ComputeMetrics(wallet string, trades []polymarket.EnrichedTrade) MetricsResult {
+	if len(trades) == 0 {
+		return MetricsResult{
+			Wallet:     wallet,
+			Metrics:    StyleMetrics{},
+			SampleSize: 0,
 		}
+	}
 
-		if len(trades) < 3 {
-			result.Warning = fmt.Sprintf("Small sample size (%d trades). Metrics may not be representative.", len(trades))
-		}
+	result := MetricsResult{
+		Wallet: wallet,
+		Metrics: StyleMetrics{
+			EntryTimingHours: metrics.EntryTimingHours(trades),
+			SizeRatioPct:     metrics.SizeRatioPct(trades),
+			Conviction:       metrics.Conviction(trades),
+		},
+		SampleSize: len(trades),
+	}
 
-		data, _ := json.Marshal(result)
-		return mcp.NewToolResultText(string(data)), nil
+	if len(trades) < 3 {
+		result.Warning = fmt.Sprintf("Small sample size (%d trades). Metrics may not be representative.", len(trades))
 	}
+	return result
 }
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/arbitrage"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type ConsistencyQuery struct {
+	Sport        string
+	MinEdge      float64
+	MinLiquidity float64
+	Constraints  []string
+	MaxEvents    int
+}
+
+type ConsistencyResult struct {
+	Sport         string                       `json:"sport"`
+	Options       arbitrage.ConsistencyOptions `json:"options"`
+	EventsChecked int                          `json:"events_checked"`
+	MarketsQuoted int                          `json:"markets_quoted"`
+	CheckedAt     time.Time                    `json:"checked_at"`
+	Constraints   []arbitrage.Constraint       `json:"constraints"`
+	Violations    []arbitrage.Violation        `json:"violations"`
+}
+
+func CheckConsistency(client *polymarket.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		query := ConsistencyQuery{Sport: sports.Default, Constraints: stringSliceArg(args["constraints"])}
+		if s, ok := args["sport"].(string); ok && s != "" {
+			query.Sport = s
+		}
+		if v, ok := args["min_edge"].(float64); ok && v >= 0 {
+			query.MinEdge = v
+		}
+		if v, ok := args["min_liquidity"].(float64); ok && v >= 0 {
+			query.MinLiquidity = v
+		}
+		if v, ok := args["max_events"].(float64); ok && v > 0 {
+			query.MaxEvents = int(v)
+		}
+
+		result, err := CheckConsistencyData(ctx, client, query)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// CheckConsistencyData groups a sport's open markets by event and reports
+// quotes that break the registered consistency constraints.
+func CheckConsistencyData(ctx context.Context, client *polymarket.Client, query ConsistencyQuery) (ConsistencyResult, error) {
+	sport := sports.Normalize(query.Sport)
+	if query.MaxEvents <= 0 {
+		query.MaxEvents = 300
+	}
+	opts := arbitrage.ConsistencyOptions{MinEdge: query.MinEdge, MinLiquidity: query.MinLiquidity, Constraints: query.Constraints}
+
+	LogToolf(ctx, "Loading open %s events", strings.ToUpper(sport))
+	groups, err := arbitrage.FetchGroups(ctx, client, sport, query.MaxEvents)
+	if err != nil {
+		return ConsistencyResult{}, err
+	}
+
+	quoted := 0
+	for _, group := range groups {
+		quoted += len(group.Markets)
+	}
+	LogToolf(ctx, "Checking %d markets across %d events", quoted, len(groups))
+
+	violations := arbitrage.CheckConsistency(groups, opts)
+	LogToolf(ctx, "Found %d consistency violations", len(violations))
+
+	return ConsistencyResult{
+		Sport:         sport,
+		Options:       opts,
+		EventsChecked: len(groups),
+		MarketsQuoted: quoted,
+		CheckedAt:     time.Now().UTC(),
+		Constraints:   arbitrage.Constraints(),
+		Violations:    violations,
+	}, nil
+}
@@ -0,0 +1,357 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const maxCompareWallets = 6
+
+type CompareResult struct {
+	Sport         string             `json:"sport"`
+	Wallets       []ComparedWallet   `json:"wallets"`
+	SharedMarkets []SharedMarket     `json:"shared_markets"`
+	PriceEdge     []PriceEdgeSummary `json:"price_edge"`
+}
+
+type ComparedWallet struct {
+	Input      string        `json:"input"`
+	Wallet     ResolveResult `json:"wallet"`
+	Metrics    MetricsResult `json:"metrics"`
+	RadarChart RadarChart    `json:"radar_chart"`
+	StyleLabel string        `json:"style_label"`
+	Markets    int           `json:"markets"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// SharedMarket is a market at least two compared wallets bought into.
+type SharedMarket struct {
+	ConditionID string           `json:"condition_id"`
+	Question    string           `json:"question"`
+	Alignment   string           `json:"alignment"`
+	Positions   []MarketPosition `json:"positions"`
+	BestPrice   []OutcomeBest    `json:"best_price"`
+}
+
+// MarketPosition is one wallet's buying in a market, keyed by the outcome
+// it put the most notional behind.
+type MarketPosition struct {
+	Wallet      string  `json:"wallet"`
+	Outcome     string  `json:"outcome"`
+	Shares      float64 `json:"shares"`
+	NotionalUSD float64 `json:"notional_usd"`
+	AvgPrice    float64 `json:"avg_price"`
+}
+
+// OutcomeBest names the wallet that bought an outcome cheapest when several
+// wallets bought it.
+type OutcomeBest struct {
+	Outcome   string  `json:"outcome"`
+	Wallet    string  `json:"wallet"`
+	AvgPrice  float64 `json:"avg_price"`
+	Advantage float64 `json:"advantage"`
+}
+
+type PriceEdgeSummary struct {
+	Wallet            string  `json:"wallet"`
+	ComparableMarkets int     `json:"comparable_markets"`
+	BetterPriceCount  int     `json:"better_price_count"`
+	AvgPriceAdvantage float64 `json:"avg_price_advantage"`
+}
+
+func CompareWallets(client *polymarket.Client, store storage.Store) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		inputs := stringSliceArg(args["inputs"])
+		if len(inputs) < 2 {
+			return mcp.NewToolResultError("inputs parameter needs at least two wallets or profile URLs"), nil
+		}
+
+		sport := sports.Default
+		if s, ok := args["sport"].(string); ok && s != "" {
+			sport = sports.Normalize(s)
+		}
+
+		tradeLimit := 3000
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			tradeLimit = int(l)
+		}
+
+		result, err := CompareWalletsData(ctx, client, store, inputs, sport, tradeLimit)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// CompareWalletsData resolves each input, fetches and scores its trades in
+// parallel, then lines wallets up on the markets they have in common.
+func CompareWalletsData(ctx context.Context, client *polymarket.Client, store storage.Store, inputs []string, sport string, tradeLimit int) (CompareResult, error) {
+	if len(inputs) < 2 {
+		return CompareResult{}, fmt.Errorf("need at least two wallets to compare")
+	}
+	if len(inputs) > maxCompareWallets {
+		return CompareResult{}, fmt.Errorf("can compare at most %d wallets, got %d", maxCompareWallets, len(inputs))
+	}
+
+	LogToolf(ctx, "Comparing %d wallets on %s trades", len(inputs), strings.ToUpper(sport))
+
+	wallets := make([]ComparedWallet, len(inputs))
+	tradesByWallet := make([][]polymarket.EnrichedTrade, len(inputs))
+	var wg sync.WaitGroup
+	for i, input := range inputs {
+		wg.Add(1)
+		go func(i int, input string) {
+			defer wg.Done()
+			wallets[i].Input = input
+
+			resolved, err := ResolveWalletTargetData(ctx, client, input)
+			if err != nil {
+				wallets[i].Error = err.Error()
+				return
+			}
+			wallets[i].Wallet = resolved
+
+			fetched, err := FetchSportsTradesData(ctx, client, store, resolved.WalletAddress, sport, tradeLimit)
+			if err != nil {
+				wallets[i].Error = err.Error()
+				return
+			}
+			tradesByWallet[i] = fetched.Trades
+
+			metricsResult := ComputeMetrics(resolved.WalletAddress, fetched.Trades)
+			metricsResult.Sport = sport
+			wallets[i].Metrics = metricsResult
+			wallets[i].RadarChart, wallets[i].StyleLabel = RadarAndLabel(metricsResult)
+			wallets[i].Markets = countMarkets(fetched.Trades)
+			LogToolf(ctx, "Scored %s with %d trades", resolved.DisplayName, metricsResult.SampleSize)
+		}(i, input)
+	}
+	wg.Wait()
+
+	// Inputs naming the same wallet, such as a username and its address,
+	// are compared once.
+	ok := 0
+	firstInput := map[string]string{}
+	for i, wallet := range wallets {
+		if wallet.Error != "" {
+			continue
+		}
+		address := strings.ToLower(wallet.Wallet.WalletAddress)
+		if first, seen := firstInput[address]; seen {
+			wallets[i].Error = fmt.Sprintf("same wallet as %q", first)
+			tradesByWallet[i] = nil
+			continue
+		}
+		firstInput[address] = wallet.Input
+		ok++
+	}
+	if ok < 2 {
+		return CompareResult{}, fmt.Errorf("fewer than two wallets could be analyzed")
+	}
+
+	shared, edges := compareSharedMarkets(wallets, tradesByWallet)
+	LogToolf(ctx, "Found %d shared markets", len(shared))
+
+	return CompareResult{
+		Sport:         sport,
+		Wallets:       wallets,
+		SharedMarkets: shared,
+		PriceEdge:     edges,
+	}, nil
+}
+
+func compareSharedMarkets(wallets []ComparedWallet, tradesByWallet [][]polymarket.EnrichedTrade) ([]SharedMarket, []PriceEdgeSummary) {
+	type outcomeTotals struct {
+		shares   float64
+		notional float64
+	}
+
+	questions := map[string]string{}
+	positions := map[string][]MarketPosition{}
+	for i, trades := range tradesByWallet {
+		if wallets[i].Error != "" {
+			continue
+		}
+		address := wallets[i].Wallet.WalletAddress
+
+		byMarket := map[string]map[string]*outcomeTotals{}
+		for _, trade := range trades {
+			if trade.Side != "BUY" || trade.ConditionID == "" {
+				continue
+			}
+			questions[trade.ConditionID] = trade.MarketQuestion
+			outcomes, ok := byMarket[trade.ConditionID]
+			if !ok {
+				outcomes = map[string]*outcomeTotals{}
+				byMarket[trade.ConditionID] = outcomes
+			}
+			totals, ok := outcomes[trade.Outcome]
+			if !ok {
+				totals = &outcomeTotals{}
+				outcomes[trade.Outcome] = totals
+			}
+			totals.shares += trade.Size
+			totals.notional += trade.Size * trade.Price
+		}
+
+		for conditionID, outcomes := range byMarket {
+			var (
+				primary string
+				best    *outcomeTotals
+			)
+			for outcome, totals := range outcomes {
+				if best == nil || totals.notional > best.notional {
+					primary, best = outcome, totals
+				}
+			}
+			if best == nil || best.shares <= 0 {
+				continue
+			}
+			positions[conditionID] = append(positions[conditionID], MarketPosition{
+				Wallet:      address,
+				Outcome:     primary,
+				Shares:      roundTo(best.shares, 2),
+				NotionalUSD: roundTo(best.notional, 2),
+				AvgPrice:    roundTo(best.notional/best.shares, 4),
+			})
+		}
+	}
+
+	edgeByWallet := map[string]*PriceEdgeSummary{}
+	advantageSum := map[string]float64{}
+	for _, wallet := range wallets {
+		if wallet.Error != "" {
+			continue
+		}
+		address := wallet.Wallet.WalletAddress
+		edgeByWallet[address] = &PriceEdgeSummary{Wallet: address}
+	}
+
+	shared := []SharedMarket{}
+	for conditionID, marketPositions := range positions {
+		if len(marketPositions) < 2 {
+			continue
+		}
+		sort.Slice(marketPositions, func(i, j int) bool { return marketPositions[i].Wallet < marketPositions[j].Wallet })
+
+		byOutcome := map[string][]MarketPosition{}
+		for _, position := range marketPositions {
+			byOutcome[position.Outcome] = append(byOutcome[position.Outcome], position)
+		}
+		alignment := "same"
+		if len(byOutcome) > 1 {
+			alignment = "opposite"
+		}
+
+		bestPrices := []OutcomeBest{}
+		for outcome, group := range byOutcome {
+			if len(group) < 2 {
+				continue
+			}
+			sort.Slice(group, func(i, j int) bool { return group[i].AvgPrice < group[j].AvgPrice })
+			winner := group[0]
+			advantage := group[1].AvgPrice - winner.AvgPrice
+			bestPrices = append(bestPrices, OutcomeBest{
+				Outcome:   outcome,
+				Wallet:    winner.Wallet,
+				AvgPrice:  winner.AvgPrice,
+				Advantage: roundTo(advantage, 4),
+			})
+			for _, position := range group {
+				edgeByWallet[position.Wallet].ComparableMarkets++
+				// Positive when this wallet paid less than the group average.
+				advantageSum[position.Wallet] += averagePrice(group) - position.AvgPrice
+			}
+			edgeByWallet[winner.Wallet].BetterPriceCount++
+		}
+		sort.Slice(bestPrices, func(i, j int) bool { return bestPrices[i].Outcome < bestPrices[j].Outcome })
+
+		shared = append(shared, SharedMarket{
+			ConditionID: conditionID,
+			Question:    questions[conditionID],
+			Alignment:   alignment,
+			Positions:   marketPositions,
+			BestPrice:   bestPrices,
+		})
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if len(shared[i].Positions) != len(shared[j].Positions) {
+			return len(shared[i].Positions) > len(shared[j].Positions)
+		}
+		return shared[i].ConditionID < shared[j].ConditionID
+	})
+
+	edges := make([]PriceEdgeSummary, 0, len(edgeByWallet))
+	for _, wallet := range wallets {
+		if wallet.Error != "" {
+			continue
+		}
+		edge := edgeByWallet[wallet.Wallet.WalletAddress]
+		if edge.ComparableMarkets > 0 {
+			edge.AvgPriceAdvantage = roundTo(advantageSum[edge.Wallet]/float64(edge.ComparableMarkets), 4)
+		}
+		edges = append(edges, *edge)
+	}
+	return shared, edges
+}
+
+func averagePrice(group []MarketPosition) float64 {
+	total := 0.0
+	for _, position := range group {
+		total += position.AvgPrice
+	}
+	return total / float64(len(group))
+}
+
+func countMarkets(trades []polymarket.EnrichedTrade) int {
+	seen := map[string]bool{}
+	for _, trade := range trades {
+		if trade.ConditionID != "" {
+			seen[trade.ConditionID] = true
+		}
+	}
+	return len(seen)
+}
+
+func stringSliceArg(value any) []string {
+	switch v := value.(type) {
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimSpace(s))
+			}
+		}
+		return out
+	case []string:
+		return v
+	case string:
+		out := []string{}
+		for _, part := range strings.Split(v, ",") {
+			if trimmed := strings.TrimSpace(part); trimmed != "" {
+				out = append(out, trimmed)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -18,7 +20,7 @@ type FetchTradesResult struct {
 	Trades      []polymarket.EnrichedTrade `json:"trades"`
 }
 
-func FetchSportsTrades(client *polymarket.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func FetchSportsTrades(client *polymarket.Client, store storage.Store) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := request.GetArguments()
 		wallet, ok := args["wallet"].(string)
@@ -27,9 +29,9 @@ func FetchSportsTrades(client *polymarket.Client) func(ctx context.Context, requ
 		}
 
 		// Parse optional sport param (default: nba)
-		sport := "nba"
+		sport := sports.Default
 		if s, ok := args["sport"].(string); ok && s != "" {
-			sport = strings.ToLower(s)
+			sport = sports.Normalize(s)
 		}
 
 		// Parse optional limit param (default: 3000 scanned trades)
@@ -38,7 +40,7 @@ func FetchSportsTrades(client *polymarket.Client) func(ctx context.Context, requ
 			tradeLimit = int(l)
 		}
 
-		result, err := FetchSportsTradesData(ctx, client, wallet, sport, tradeLimit)
+		result, err := FetchSportsTradesData(ctx, client, store, wallet, sport, tradeLimit)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
@@ -51,6 +53,7 @@ func FetchSportsTrades(client *polymarket.Client) func(ctx context.Context, requ
 func FetchSportsTradesData(
 	ctx context.Context,
 	client *polymarket.Client,
+	store storage.Store,
 	wallet, sport string,
 	tradeLimit int,
 ) (FetchTradesResult, error) {
@@ -63,6 +66,7 @@ func FetchSportsTradesData(
 
 	LogToolf(ctx, "Scanning up to %d recent trades for %s signals", tradeLimit, strings.ToUpper(sport))
 
+	source := newWalletTradeSource(client, store, wallet, tradeLimit)
 	allTrades := make([]polymarket.Trade, 0, minInt(tradeLimit, pageSize))
 	sportTrades := make([]polymarket.Trade, 0, 64)
 	scanned := 0
@@ -71,7 +75,7 @@ func FetchSportsTradesData(
 		pageLimit := minInt(pageSize, tradeLimit-offset)
 		LogToolf(ctx, "Fetching trades page offset=%d limit=%d", offset, pageLimit)
 
-		pageTrades, err := client.GetTrades(wallet, pageLimit, offset)
+		pageTrades, err := source.page(ctx, offset, pageLimit)
 		if err != nil {
 			return FetchTradesResult{}, fmt.Errorf("failed to get trades: %v", err)
 		}
@@ -118,25 +122,8 @@ func FetchSportsTradesData(
 	}
 	LogToolf(ctx, "Resolving metadata for %d %s markets", len(conditionIDList), strings.ToUpper(sport))
 
-	marketMap := make(map[string]polymarket.Market)
-	for i := 0; i < len(conditionIDList); i += 20 {
-		end := i + 20
-		if end > len(conditionIDList) {
-			end = len(conditionIDList)
-		}
-		batch := conditionIDList[i:end]
-		LogToolf(ctx, "Fetching market metadata batch %d-%d", i+1, end)
-
-		markets, err := client.GetMarkets(batch)
-		if err != nil {
-			LogToolf(ctx, "Skipping market batch %d-%d after error: %v", i+1, end, err)
-			continue
-		}
-
-		for _, m := range markets {
-			marketMap[m.ConditionID] = m
-		}
-	}
+	// Batches that fail are logged and their trades left unenriched.
+	marketMap, _ := loadMarkets(ctx, client, store, conditionIDList)
 
 	LogToolf(ctx, "Building enriched response for %d %s trades", len(sportTrades), strings.ToUpper(sport))
 	enriched := make([]polymarket.EnrichedTrade, 0, len(sportTrades))
@@ -152,7 +139,7 @@ func FetchSportsTradesData(
 			MarketVolume:    0,
 			MarketStartTime: "",
 		}
-		if m, ok := marketMap[t.ConditionID]; ok {
+		if m, ok := marketMap[strings.ToLower(t.ConditionID)]; ok {
 			et.MarketQuestion = m.Question
 			et.MarketVolume = m.VolumeNum
 			et.MarketStartTime = m.StartDate
@@ -169,21 +156,8 @@ func FetchSportsTradesData(
 	}, nil
 }
 
-func isSportMarket(m polymarket.Market, sport string) bool {
-	return isSportText(m.Question, sport) || isSportText(m.Slug, sport)
-}
-
 func isSportTrade(t polymarket.Trade, sport string) bool {
-	return isSportText(t.Title, sport) || isSportText(t.Slug, sport)
-}
-
-func isSportText(text, sport string) bool {
-	value := strings.ToLower(text)
-	if strings.Contains(value, sport) {
-		return true
-	}
-
-	return sport == "nba" && strings.Contains(value, "basketball")
+	return sports.MatchesMarket(t.ConditionID, t.Title, t.Slug, sport)
 }
 
 func minInt(a, b int) int {
@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/brucexwang/easy-arbitra/backend/metrics"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/similarity"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+	"github.com/brucexwang/easy-arbitra/backend/styles"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type SimilarWalletsQuery struct {
+	Wallet     string
+	K          int
+	Sport      string
+	MinTrades  int
+	MinPnlUSD  *float64
+	MaxPnlUSD  *float64
+	TradeLimit int
+}
+
+type SimilarWalletsResult struct {
+	Wallet       string             `json:"wallet"`
+	Sport        string             `json:"sport"`
+	TargetSource string             `json:"target_source"`
+	Target       map[string]float64 `json:"target"`
+	Candidates   int                `json:"candidates"`
+	Results      []SimilarWallet    `json:"results"`
+}
+
+type SimilarWallet struct {
+	WalletAddress string                    `json:"wallet_address"`
+	DisplayName   string                    `json:"display_name"`
+	SourceRank    int                       `json:"source_rank"`
+	PnlUSD        float64                   `json:"pnl_usd"`
+	WinRate       float64                   `json:"win_rate"`
+	Trades        int                       `json:"trades"`
+	StyleLabel    string                    `json:"style_label"`
+	Distance      float64                   `json:"distance"`
+	Similarity    float64                   `json:"similarity"`
+	SharedTraits  []string                  `json:"shared_traits"`
+	Contributions []similarity.Contribution `json:"contributions"`
+}
+
+func FindSimilarWallets(client *polymarket.Client, store storage.Store) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		wallet, ok := args["wallet"].(string)
+		if !ok || wallet == "" {
+			return mcp.NewToolResultError("wallet parameter is required"), nil
+		}
+		if store == nil {
+			return mcp.NewToolResultError("similar wallet search requires the wallet catalog (DATABASE_URL)"), nil
+		}
+
+		query := SimilarWalletsQuery{Wallet: wallet, K: 10, Sport: sports.Default}
+		if s, ok := args["sport"].(string); ok && s != "" {
+			query.Sport = s
+		}
+		if k, ok := args["k"].(float64); ok && k > 0 {
+			query.K = int(k)
+		}
+		if n, ok := args["min_trades"].(float64); ok && n > 0 {
+			query.MinTrades = int(n)
+		}
+		if v, ok := args["min_pnl_usd"].(float64); ok {
+			query.MinPnlUSD = &v
+		}
+		if v, ok := args["max_pnl_usd"].(float64); ok {
+			query.MaxPnlUSD = &v
+		}
+
+		result, err := FindSimilarWalletsData(ctx, client, store, query)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// FindSimilarWalletsData ranks tracked wallets by distance to the target in
+// normalized style space. Wallets missing from the catalog are analyzed live.
+func FindSimilarWalletsData(
+	ctx context.Context,
+	client *polymarket.Client,
+	store storage.Store,
+	query SimilarWalletsQuery,
+) (SimilarWalletsResult, error) {
+	wallet := strings.ToLower(strings.TrimSpace(query.Wallet))
+	sport := sports.Normalize(query.Sport)
+
+	target, targetSource, err := similarityTarget(ctx, client, store, wallet, sport, query.TradeLimit)
+	if err != nil {
+		return SimilarWalletsResult{}, err
+	}
+
+	LogToolf(ctx, "Loading tracked %s wallet profiles", strings.ToUpper(sport))
+	profiles, err := store.ListProfileVectors(ctx, storage.ProfileFilter{
+		Sport:     sport,
+		MinTrades: query.MinTrades,
+		MinPnlUSD: query.MinPnlUSD,
+		MaxPnlUSD: query.MaxPnlUSD,
+	})
+	if err != nil {
+		return SimilarWalletsResult{}, err
+	}
+
+	byWallet := make(map[string]storage.ProfileVector, len(profiles))
+	candidates := make([]similarity.Vector, 0, len(profiles))
+	for _, profile := range profiles {
+		byWallet[profile.WalletAddress] = profile
+		candidates = append(candidates, similarity.Vector{
+			ID:     profile.WalletAddress,
+			Values: styles.FromStyleMetrics(profile.EntryTimingHours, profile.SizeRatioPct, profile.Conviction, profile.SportTrades),
+		})
+	}
+
+	LogToolf(ctx, "Ranking %d candidate wallets by style distance", len(candidates))
+	neighbors := similarity.Nearest(target, candidates, query.K)
+
+	results := make([]SimilarWallet, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		profile := byWallet[neighbor.ID]
+		results = append(results, SimilarWallet{
+			WalletAddress: profile.WalletAddress,
+			DisplayName:   profile.DisplayName,
+			SourceRank:    profile.SourceRank,
+			PnlUSD:        profile.PnlUSD,
+			WinRate:       profile.WinRate,
+			Trades:        profile.SportTrades,
+			StyleLabel:    profile.StyleLabel,
+			Distance:      neighbor.Distance,
+			Similarity:    neighbor.Similarity,
+			SharedTraits:  neighbor.SharedTraits,
+			Contributions: neighbor.Contributions,
+		})
+	}
+
+	targetValues := map[string]float64{}
+	for _, feature := range similarity.Features {
+		targetValues[feature] = target.Values[feature]
+	}
+
+	return SimilarWalletsResult{
+		Wallet:       wallet,
+		Sport:        sport,
+		TargetSource: targetSource,
+		Target:       targetValues,
+		Candidates:   len(candidates),
+		Results:      results,
+	}, nil
+}
+
+func similarityTarget(
+	ctx context.Context,
+	client *polymarket.Client,
+	store storage.Store,
+	wallet, sport string,
+	tradeLimit int,
+) (similarity.Vector, string, error) {
+	stored, ok, err := store.GetProfileVector(ctx, wallet, sport)
+	if err != nil {
+		return similarity.Vector{}, "", err
+	}
+	if ok {
+		LogToolf(ctx, "Using stored profile for %s", wallet)
+		return similarity.Vector{
+			ID:     wallet,
+			Values: styles.FromStyleMetrics(stored.EntryTimingHours, stored.SizeRatioPct, stored.Conviction, stored.SportTrades),
+		}, "stored", nil
+	}
+
+	LogToolf(ctx, "%s is not in the catalog; analyzing recent trades", wallet)
+	fetched, err := FetchSportsTradesData(ctx, client, store, wallet, sport, tradeLimit)
+	if err != nil {
+		return similarity.Vector{}, "", err
+	}
+	if fetched.TotalTrades == 0 {
+		return similarity.Vector{}, "", fmt.Errorf("no %s trades found for %s", strings.ToUpper(sport), wallet)
+	}
+
+	return similarity.Vector{
+		ID: wallet,
+		Values: styles.FromStyleMetrics(
+			metrics.EntryTimingHours(fetched.Trades),
+			metrics.SizeRatioPct(fetched.Trades),
+			metrics.Conviction(fetched.Trades),
+			fetched.TotalTrades,
+		),
+	}, "live", nil
+}
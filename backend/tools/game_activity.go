@@ -0,0 +1,289 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const gameTradeWorkers = 4
+
+type GameActivityResult struct {
+	Game           sports.Game          `json:"game"`
+	TrackedWallets int                  `json:"tracked_wallets"`
+	TradesScanned  int                  `json:"trades_scanned"`
+	Wallets        []GameWalletActivity `json:"wallets"`
+}
+
+// GameWalletActivity is everything one tracked wallet did across a game's
+// markets.
+type GameWalletActivity struct {
+	WalletAddress string         `json:"wallet_address"`
+	DisplayName   string         `json:"display_name"`
+	SourceRank    int            `json:"source_rank"`
+	Trades        int            `json:"trades"`
+	BuyUSD        float64        `json:"buy_usd"`
+	SellUSD       float64        `json:"sell_usd"`
+	NetUSD        float64        `json:"net_usd"`
+	FirstTradeAt  string         `json:"first_trade_at"`
+	LastTradeAt   string         `json:"last_trade_at"`
+	Positions     []GamePosition `json:"positions"`
+}
+
+// GamePosition is a wallet's net position in one outcome of a game market.
+type GamePosition struct {
+	ConditionID  string  `json:"condition_id"`
+	Question     string  `json:"question"`
+	MarketType   string  `json:"market_type"`
+	Outcome      string  `json:"outcome"`
+	BoughtShares float64 `json:"bought_shares"`
+	SoldShares   float64 `json:"sold_shares"`
+	NetShares    float64 `json:"net_shares"`
+	AvgBuyPrice  float64 `json:"avg_buy_price"`
+}
+
+func GetGameActivity(client *polymarket.Client, store storage.Store) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		game, ok := args["game"].(string)
+		if !ok || strings.TrimSpace(game) == "" {
+			return mcp.NewToolResultError("game parameter is required"), nil
+		}
+
+		tradeLimit := 1000
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			tradeLimit = int(l)
+		}
+
+		result, err := GameActivityData(ctx, client, store, game, tradeLimit)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// GameActivityData returns a game with all of its markets and what every
+// tracked wallet of the game's sport traded in them. Without a store the
+// markets are still returned but no wallets are tracked.
+func GameActivityData(ctx context.Context, client *polymarket.Client, store storage.Store, idOrSlug string, tradeLimit int) (GameActivityResult, error) {
+	game, ok := sports.SharedIndex().Game(strings.TrimSpace(idOrSlug))
+	if !ok {
+		return GameActivityResult{}, fmt.Errorf("game %s not found in the sports index", idOrSlug)
+	}
+
+	result := GameActivityResult{Game: game, Wallets: []GameWalletActivity{}}
+	if store == nil {
+		return result, nil
+	}
+
+	tracked, err := trackedWalletsFor(ctx, store, game.Sport)
+	if err != nil {
+		return GameActivityResult{}, err
+	}
+	result.TrackedWallets = len(tracked)
+	if len(tracked) == 0 || len(game.Markets) == 0 {
+		return result, nil
+	}
+
+	LogToolf(ctx, "Scanning %d markets of %s for %d tracked wallets", len(game.Markets), game.Title, len(tracked))
+	conditionIDs := make([]string, 0, len(game.Markets))
+	for _, market := range game.Markets {
+		conditionIDs = append(conditionIDs, market.ConditionID)
+	}
+	trades, scanned, err := fetchMarketTrades(ctx, client, store, conditionIDs, tradeLimit)
+	if err != nil {
+		return GameActivityResult{}, err
+	}
+	result.TradesScanned = scanned
+
+	markets := map[string]sports.GameMarket{}
+	for _, market := range game.Markets {
+		markets[market.ConditionID] = market
+	}
+
+	type outcomeKey struct{ conditionID, outcome string }
+	type walletState struct {
+		activity  GameWalletActivity
+		positions map[outcomeKey]*GamePosition
+		buyCost   map[outcomeKey]float64
+		first     int64
+		last      int64
+	}
+	states := map[string]*walletState{}
+	for _, trade := range trades {
+		wallet := strings.ToLower(trade.ProxyWallet)
+		meta, ok := tracked[wallet]
+		if !ok {
+			continue
+		}
+		state, ok := states[wallet]
+		if !ok {
+			state = &walletState{
+				activity: GameWalletActivity{
+					WalletAddress: wallet,
+					DisplayName:   meta.DisplayName,
+					SourceRank:    meta.SourceRank,
+				},
+				positions: map[outcomeKey]*GamePosition{},
+				buyCost:   map[outcomeKey]float64{},
+			}
+			states[wallet] = state
+		}
+
+		conditionID := strings.ToLower(trade.ConditionID)
+		key := outcomeKey{conditionID, trade.Outcome}
+		position, ok := state.positions[key]
+		if !ok {
+			market := markets[conditionID]
+			position = &GamePosition{
+				ConditionID: conditionID,
+				Question:    market.Question,
+				MarketType:  market.MarketType,
+				Outcome:     trade.Outcome,
+			}
+			state.positions[key] = position
+		}
+
+		notional := trade.Size * trade.Price
+		state.activity.Trades++
+		if trade.Side == "SELL" {
+			position.SoldShares += trade.Size
+			state.activity.SellUSD += notional
+		} else {
+			position.BoughtShares += trade.Size
+			state.buyCost[key] += notional
+			state.activity.BuyUSD += notional
+		}
+		if state.first == 0 || trade.Timestamp < state.first {
+			state.first = trade.Timestamp
+		}
+		if trade.Timestamp > state.last {
+			state.last = trade.Timestamp
+		}
+	}
+
+	for _, state := range states {
+		activity := state.activity
+		activity.BuyUSD = roundTo(activity.BuyUSD, 2)
+		activity.SellUSD = roundTo(activity.SellUSD, 2)
+		activity.NetUSD = roundTo(activity.BuyUSD-activity.SellUSD, 2)
+		activity.FirstTradeAt = time.Unix(state.first, 0).UTC().Format(time.RFC3339)
+		activity.LastTradeAt = time.Unix(state.last, 0).UTC().Format(time.RFC3339)
+		for key, position := range state.positions {
+			if position.BoughtShares > 0 {
+				position.AvgBuyPrice = roundTo(state.buyCost[key]/position.BoughtShares, 4)
+			}
+			position.NetShares = roundTo(position.BoughtShares-position.SoldShares, 2)
+			position.BoughtShares = roundTo(position.BoughtShares, 2)
+			position.SoldShares = roundTo(position.SoldShares, 2)
+			activity.Positions = append(activity.Positions, *position)
+		}
+		sort.Slice(activity.Positions, func(i, j int) bool {
+			a, b := activity.Positions[i], activity.Positions[j]
+			if a.ConditionID != b.ConditionID {
+				return a.ConditionID < b.ConditionID
+			}
+			return a.Outcome < b.Outcome
+		})
+		result.Wallets = append(result.Wallets, activity)
+	}
+	sort.Slice(result.Wallets, func(i, j int) bool {
+		a, b := result.Wallets[i], result.Wallets[j]
+		if a.BuyUSD+a.SellUSD != b.BuyUSD+b.SellUSD {
+			return a.BuyUSD+a.SellUSD > b.BuyUSD+b.SellUSD
+		}
+		return a.WalletAddress < b.WalletAddress
+	})
+
+	LogToolf(ctx, "Found %d tracked wallets active in %s", len(result.Wallets), game.Title)
+	return result, nil
+}
+
+// trackedWalletsFor returns the wallets tracked for sport and for its
+// umbrella sport, keyed by address.
+func trackedWalletsFor(ctx context.Context, store storage.Store, sport string) (map[string]storage.TrackedWallet, error) {
+	keys := []string{sport}
+	if entry, _ := sports.Lookup(sport); entry.Parent != "" {
+		keys = append(keys, entry.Parent)
+	}
+
+	tracked := map[string]storage.TrackedWallet{}
+	for _, key := range keys {
+		wallets, err := store.ListTrackedWallets(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, wallet := range wallets {
+			if _, ok := tracked[wallet.WalletAddress]; !ok {
+				tracked[wallet.WalletAddress] = wallet
+			}
+		}
+	}
+	return tracked, nil
+}
+
+// fetchMarketTrades pages through the trades of each market in parallel,
+// up to tradeLimit per market, and stores them when store is set.
+func fetchMarketTrades(ctx context.Context, client *polymarket.Client, store storage.Store, conditionIDs []string, tradeLimit int) ([]polymarket.Trade, int, error) {
+	const pageSize = 500
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		all      []polymarket.Trade
+		firstErr error
+	)
+	slots := make(chan struct{}, gameTradeWorkers)
+	for _, conditionID := range conditionIDs {
+		wg.Add(1)
+		go func(conditionID string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			for offset := 0; offset < tradeLimit; offset += pageSize {
+				if ctx.Err() != nil {
+					return
+				}
+				limit := minInt(pageSize, tradeLimit-offset)
+				page, err := client.GetMarketTrades(conditionID, limit, offset)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				mu.Lock()
+				all = append(all, page...)
+				mu.Unlock()
+				if len(page) < limit {
+					return
+				}
+			}
+		}(conditionID)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, 0, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	persistTrades(ctx, store, all)
+	return all, len(all), nil
+}
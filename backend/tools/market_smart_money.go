@@ -0,0 +1,481 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const maxSmartMoneyMarkets = 20
+
+type SmartMoneyQuery struct {
+	Market      string
+	Sport       string
+	TradeLimit  int
+	HolderLimit int
+	Bucket      string
+}
+
+type SmartMoneyResult struct {
+	Query   string             `json:"query"`
+	Sport   string             `json:"sport"`
+	Bucket  string             `json:"bucket"`
+	Markets []SmartMoneyMarket `json:"markets"`
+}
+
+// SmartMoneyMarket is tracked-wallet positioning and flow in one market.
+// SmartSide is the outcome with the largest ROI-weighted net inflow.
+type SmartMoneyMarket struct {
+	ConditionID   string             `json:"condition_id"`
+	Question      string             `json:"question"`
+	SmartSide     string             `json:"smart_side"`
+	TradesScanned int                `json:"trades_scanned"`
+	Outcomes      []OutcomeFlow      `json:"outcomes"`
+	Wallets       []SmartMoneyWallet `json:"wallets"`
+	Flow          []FlowBucket       `json:"flow"`
+}
+
+type OutcomeFlow struct {
+	Outcome         string  `json:"outcome"`
+	Wallets         int     `json:"wallets"`
+	HeldShares      float64 `json:"held_shares"`
+	NetFlowUSD      float64 `json:"net_flow_usd"`
+	WeightedFlowUSD float64 `json:"weighted_flow_usd"`
+}
+
+// SmartMoneyWallet is one tracked wallet's side in a market. Side is the
+// outcome it holds most of, or bought most of when it no longer holds any.
+type SmartMoneyWallet struct {
+	WalletAddress string  `json:"wallet_address"`
+	DisplayName   string  `json:"display_name"`
+	SourceRank    int     `json:"source_rank"`
+	StyleLabel    string  `json:"style_label"`
+	ROI           float64 `json:"roi"`
+	Weight        float64 `json:"weight"`
+	Side          string  `json:"side"`
+	HeldShares    float64 `json:"held_shares"`
+	BoughtShares  float64 `json:"bought_shares"`
+	SoldShares    float64 `json:"sold_shares"`
+	AvgPrice      float64 `json:"avg_price"`
+	NetFlowUSD    float64 `json:"net_flow_usd"`
+	Trades        int     `json:"trades"`
+}
+
+// FlowBucket is the net USD tracked wallets moved into one outcome during
+// one time bucket; sells count negative.
+type FlowBucket struct {
+	Start           string  `json:"start"`
+	Outcome         string  `json:"outcome"`
+	NetFlowUSD      float64 `json:"net_flow_usd"`
+	WeightedFlowUSD float64 `json:"weighted_flow_usd"`
+}
+
+type smartMoneyTarget struct {
+	conditionID string
+	question    string
+	outcomes    []string
+}
+
+func MarketSmartMoney(client *polymarket.Client, store storage.Store) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		market, ok := args["market"].(string)
+		if !ok || strings.TrimSpace(market) == "" {
+			return mcp.NewToolResultError("market parameter is required"), nil
+		}
+		if store == nil {
+			return mcp.NewToolResultError("smart money view requires the wallet catalog (DATABASE_URL)"), nil
+		}
+
+		query := SmartMoneyQuery{Market: market}
+		if s, ok := args["sport"].(string); ok {
+			query.Sport = s
+		}
+		if b, ok := args["bucket"].(string); ok {
+			query.Bucket = b
+		}
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			query.TradeLimit = int(l)
+		}
+
+		result, err := MarketSmartMoneyData(ctx, client, store, query)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// MarketSmartMoneyData shows which tracked wallets hold or traded a market
+// (a condition ID) or every market of an event (an event slug), on which
+// side, at what price, and how their money flowed over time.
+//
+// Trades already in the store are read first and the market's recent feed
+// is added to them. Flow is weighted by the ROI (PnL over volume) of each
+// wallet's stored profile. Losing or unprofiled wallets weigh zero and
+// positive ROIs are scaled to average 1 across the market's wallets, so
+// weighted flow stays in USD but tilts toward the best traders.
+func MarketSmartMoneyData(ctx context.Context, client *polymarket.Client, store storage.Store, query SmartMoneyQuery) (SmartMoneyResult, error) {
+	if query.TradeLimit <= 0 {
+		query.TradeLimit = 2000
+	}
+	if query.HolderLimit <= 0 {
+		query.HolderLimit = 100
+	}
+	bucket := time.Hour
+	query.Bucket = strings.ToLower(strings.TrimSpace(query.Bucket))
+	switch query.Bucket {
+	case "", "hour":
+		query.Bucket = "hour"
+	case "day":
+		bucket = 24 * time.Hour
+	default:
+		return SmartMoneyResult{}, fmt.Errorf("unsupported bucket %q: use hour or day", query.Bucket)
+	}
+
+	markets, err := resolveSmartMoneyMarkets(ctx, client, store, strings.TrimSpace(query.Market))
+	if err != nil {
+		return SmartMoneyResult{}, err
+	}
+
+	sport := sports.Normalize(query.Sport)
+	if indexed, ok := sports.SharedIndex().Get(markets[0].conditionID); ok && query.Sport == "" {
+		sport = indexed.Sport
+	}
+
+	tracked, err := trackedWalletsFor(ctx, store, sport)
+	if err != nil {
+		return SmartMoneyResult{}, err
+	}
+	profiles, err := store.ListProfileVectors(ctx, storage.ProfileFilter{Sport: sport})
+	if err != nil {
+		return SmartMoneyResult{}, err
+	}
+	profileByWallet := make(map[string]storage.ProfileVector, len(profiles))
+	for _, profile := range profiles {
+		profileByWallet[profile.WalletAddress] = profile
+	}
+	LogToolf(ctx, "Matching %d markets against %d tracked %s wallets", len(markets), len(tracked), strings.ToUpper(sport))
+
+	result := SmartMoneyResult{
+		Query:   query.Market,
+		Sport:   sport,
+		Bucket:  query.Bucket,
+		Markets: make([]SmartMoneyMarket, 0, len(markets)),
+	}
+	for _, market := range markets {
+		view, err := smartMoneyForMarket(ctx, client, store, market, tracked, profileByWallet, query, bucket)
+		if err != nil {
+			return SmartMoneyResult{}, err
+		}
+		result.Markets = append(result.Markets, view)
+	}
+	return result, nil
+}
+
+func resolveSmartMoneyMarkets(ctx context.Context, client *polymarket.Client, store storage.Store, market string) ([]smartMoneyTarget, error) {
+	var conditionIDs []string
+	if strings.HasPrefix(strings.ToLower(market), "0x") {
+		conditionIDs = []string{strings.ToLower(market)}
+	} else if game, ok := sports.SharedIndex().Game(market); ok {
+		for _, gameMarket := range game.Markets {
+			conditionIDs = append(conditionIDs, gameMarket.ConditionID)
+		}
+	} else {
+		LogToolf(ctx, "Looking up event %s", market)
+		event, err := client.GetEventBySlug(market)
+		if err != nil {
+			return nil, err
+		}
+		if event == nil {
+			return nil, fmt.Errorf("no market or event found for %s", market)
+		}
+		for _, eventMarket := range event.Markets {
+			if eventMarket.ConditionID != "" {
+				conditionIDs = append(conditionIDs, strings.ToLower(eventMarket.ConditionID))
+			}
+		}
+	}
+	if len(conditionIDs) == 0 {
+		return nil, fmt.Errorf("no markets found for %s", market)
+	}
+	if len(conditionIDs) > maxSmartMoneyMarkets {
+		conditionIDs = conditionIDs[:maxSmartMoneyMarkets]
+	}
+
+	byID, err := loadMarkets(ctx, client, store, conditionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]smartMoneyTarget, 0, len(conditionIDs))
+	for _, conditionID := range conditionIDs {
+		detail := byID[conditionID]
+		markets = append(markets, smartMoneyTarget{
+			conditionID: conditionID,
+			question:    detail.Question,
+			outcomes:    detail.OutcomeNames(),
+		})
+	}
+	return markets, nil
+}
+
+func smartMoneyForMarket(
+	ctx context.Context,
+	client *polymarket.Client,
+	store storage.Store,
+	market smartMoneyTarget,
+	tracked map[string]storage.TrackedWallet,
+	profiles map[string]storage.ProfileVector,
+	query SmartMoneyQuery,
+	bucket time.Duration,
+) (SmartMoneyMarket, error) {
+	type walletState struct {
+		wallet     SmartMoneyWallet
+		held       map[string]float64
+		bought     map[string]float64
+		boughtCost map[string]float64
+		flows      []polymarket.Trade
+	}
+	states := map[string]*walletState{}
+	stateFor := func(address string) *walletState {
+		state, ok := states[address]
+		if !ok {
+			meta, profile := tracked[address], profiles[address]
+			state = &walletState{
+				wallet: SmartMoneyWallet{
+					WalletAddress: address,
+					DisplayName:   meta.DisplayName,
+					SourceRank:    meta.SourceRank,
+					StyleLabel:    profile.StyleLabel,
+					ROI:           roundTo(profile.ROI, 4),
+				},
+				held:       map[string]float64{},
+				bought:     map[string]float64{},
+				boughtCost: map[string]float64{},
+			}
+			states[address] = state
+		}
+		return state
+	}
+
+	holders, err := client.GetHolders(market.conditionID, query.HolderLimit)
+	if err != nil {
+		return SmartMoneyMarket{}, err
+	}
+	for _, token := range holders {
+		for _, holder := range token.Holders {
+			address := strings.ToLower(holder.ProxyWallet)
+			if _, ok := tracked[address]; !ok {
+				continue
+			}
+			outcome := outcomeName(market.outcomes, holder.OutcomeIndex)
+			stateFor(address).held[outcome] += holder.Amount
+		}
+	}
+
+	trades, err := smartMoneyTrades(ctx, client, store, market.conditionID, query.TradeLimit)
+	if err != nil {
+		return SmartMoneyMarket{}, err
+	}
+	for _, trade := range trades {
+		address := strings.ToLower(trade.ProxyWallet)
+		if _, ok := tracked[address]; !ok {
+			continue
+		}
+		state := stateFor(address)
+		state.wallet.Trades++
+		notional := trade.Size * trade.Price
+		if trade.Side == "SELL" {
+			state.wallet.SoldShares += trade.Size
+			state.wallet.NetFlowUSD -= notional
+		} else {
+			state.wallet.BoughtShares += trade.Size
+			state.bought[trade.Outcome] += trade.Size
+			state.boughtCost[trade.Outcome] += notional
+			state.wallet.NetFlowUSD += notional
+		}
+		state.flows = append(state.flows, trade)
+	}
+
+	// Scale positive ROIs to a mean weight of 1 across this market's wallets.
+	positive, roiSum := 0, 0.0
+	for _, state := range states {
+		if state.wallet.ROI > 0 {
+			positive++
+			roiSum += state.wallet.ROI
+		}
+	}
+	for _, state := range states {
+		if state.wallet.ROI > 0 && roiSum > 0 {
+			state.wallet.Weight = roundTo(state.wallet.ROI*float64(positive)/roiSum, 4)
+		}
+	}
+
+	outcomes := map[string]*OutcomeFlow{}
+	outcomeFor := func(name string) *OutcomeFlow {
+		flow, ok := outcomes[name]
+		if !ok {
+			flow = &OutcomeFlow{Outcome: name}
+			outcomes[name] = flow
+		}
+		return flow
+	}
+	for _, name := range market.outcomes {
+		outcomeFor(name)
+	}
+
+	type bucketKey struct {
+		start   int64
+		outcome string
+	}
+	buckets := map[bucketKey]*FlowBucket{}
+	view := SmartMoneyMarket{
+		ConditionID:   market.conditionID,
+		Question:      market.question,
+		TradesScanned: len(trades),
+		Wallets:       make([]SmartMoneyWallet, 0, len(states)),
+	}
+	for _, state := range states {
+		wallet := state.wallet
+		wallet.Side = largestKey(state.held)
+		if wallet.Side == "" {
+			wallet.Side = largestKey(state.bought)
+		}
+		for _, shares := range state.held {
+			wallet.HeldShares += shares
+		}
+		if shares := state.bought[wallet.Side]; shares > 0 {
+			wallet.AvgPrice = roundTo(state.boughtCost[wallet.Side]/shares, 4)
+		}
+
+		for outcome, shares := range state.held {
+			outcomeFor(outcome).HeldShares += shares
+		}
+		if wallet.Side != "" {
+			outcomeFor(wallet.Side).Wallets++
+		}
+		for _, trade := range state.flows {
+			flow := trade.Size * trade.Price
+			if trade.Side == "SELL" {
+				flow = -flow
+			}
+			outcome := outcomeFor(trade.Outcome)
+			outcome.NetFlowUSD += flow
+			outcome.WeightedFlowUSD += flow * wallet.Weight
+
+			start := time.Unix(trade.Timestamp, 0).UTC().Truncate(bucket)
+			key := bucketKey{start.Unix(), trade.Outcome}
+			entry, ok := buckets[key]
+			if !ok {
+				entry = &FlowBucket{Start: start.Format(time.RFC3339), Outcome: trade.Outcome}
+				buckets[key] = entry
+			}
+			entry.NetFlowUSD += flow
+			entry.WeightedFlowUSD += flow * wallet.Weight
+		}
+
+		wallet.HeldShares = roundTo(wallet.HeldShares, 2)
+		wallet.BoughtShares = roundTo(wallet.BoughtShares, 2)
+		wallet.SoldShares = roundTo(wallet.SoldShares, 2)
+		wallet.NetFlowUSD = roundTo(wallet.NetFlowUSD, 2)
+		view.Wallets = append(view.Wallets, wallet)
+	}
+	sort.Slice(view.Wallets, func(i, j int) bool {
+		a, b := view.Wallets[i], view.Wallets[j]
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		return a.WalletAddress < b.WalletAddress
+	})
+
+	best := math.Inf(-1)
+	for _, outcome := range outcomes {
+		outcome.HeldShares = roundTo(outcome.HeldShares, 2)
+		outcome.NetFlowUSD = roundTo(outcome.NetFlowUSD, 2)
+		outcome.WeightedFlowUSD = roundTo(outcome.WeightedFlowUSD, 2)
+		view.Outcomes = append(view.Outcomes, *outcome)
+		if outcome.WeightedFlowUSD > 0 && outcome.WeightedFlowUSD > best {
+			best = outcome.WeightedFlowUSD
+			view.SmartSide = outcome.Outcome
+		}
+	}
+	sort.Slice(view.Outcomes, func(i, j int) bool { return view.Outcomes[i].Outcome < view.Outcomes[j].Outcome })
+
+	view.Flow = make([]FlowBucket, 0, len(buckets))
+	for _, entry := range buckets {
+		entry.NetFlowUSD = roundTo(entry.NetFlowUSD, 2)
+		entry.WeightedFlowUSD = roundTo(entry.WeightedFlowUSD, 2)
+		view.Flow = append(view.Flow, *entry)
+	}
+	sort.Slice(view.Flow, func(i, j int) bool {
+		if view.Flow[i].Start != view.Flow[j].Start {
+			return view.Flow[i].Start < view.Flow[j].Start
+		}
+		return view.Flow[i].Outcome < view.Flow[j].Outcome
+	})
+
+	LogToolf(ctx, "%s: %d tracked wallets, smart side %q", market.conditionID, len(view.Wallets), view.SmartSide)
+	return view, nil
+}
+
+// smartMoneyTrades returns a market's newest stored trades, up to
+// maxTradeSync, followed by the ones from its recent feed that were not
+// stored yet, which fetchMarketTrades stores.
+func smartMoneyTrades(ctx context.Context, client *polymarket.Client, store storage.Store, conditionID string, limit int) ([]polymarket.Trade, error) {
+	records, err := store.ListMarketTrades(ctx, conditionID, maxTradeSync)
+	if err != nil {
+		return nil, err
+	}
+	trades := make([]polymarket.Trade, 0, len(records))
+	seen := make(map[string]bool, len(records))
+	for _, record := range records {
+		seen[record.Key] = true
+		trades = append(trades, tradeFromRecord(record))
+	}
+
+	recent, _, err := fetchMarketTrades(ctx, client, store, []string{conditionID}, limit)
+	if err != nil {
+		return nil, err
+	}
+	added := 0
+	for _, trade := range recent {
+		if key := trade.Key(); !seen[key] {
+			seen[key] = true
+			trades = append(trades, trade)
+			added++
+		}
+	}
+	if len(records) > 0 {
+		LogToolf(ctx, "%s: %d stored trades and %d new from the feed", conditionID, len(records), added)
+	}
+	return trades, nil
+}
+
+func outcomeName(outcomes []string, index int) string {
+	if index >= 0 && index < len(outcomes) {
+		return outcomes[index]
+	}
+	return fmt.Sprintf("Outcome %d", index)
+}
+
+func largestKey(values map[string]float64) string {
+	best, bestValue := "", 0.0
+	for key, value := range values {
+		if value > bestValue || (value == bestValue && best != "" && key < best) {
+			best, bestValue = key, value
+		}
+	}
+	return best
+}
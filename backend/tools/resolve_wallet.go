@@ -34,58 +34,67 @@ func ResolveWalletTarget(client *polymarket.Client) func(ctx context.Context, re
 			return mcp.NewToolResultError("input parameter is required"), nil
 		}
 
-		var address string
-		var inputType string
-
-		input = strings.TrimSpace(input)
-
-		if ethAddressRegex.MatchString(input) {
-			address = input
-			inputType = "wallet_address"
-		} else if matches := profileURLRegex.FindStringSubmatch(input); len(matches) > 1 {
-			address = matches[1]
-			inputType = "polymarket_url"
-		} else if matches := profileSlugURLRegex.FindStringSubmatch(input); len(matches) > 1 {
-			resolved, err := resolveProfileSlugAddress(ctx, client, matches[1])
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to resolve Polymarket profile slug: %v", err)), nil
-			}
-			address = resolved
-			inputType = "polymarket_slug"
-		} else if slugInputRegex.MatchString(input) {
-			resolved, err := resolveProfileSlugAddress(ctx, client, input)
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to resolve Polymarket profile slug: %v", err)), nil
-			}
-			address = resolved
-			inputType = "polymarket_slug"
-		} else {
-			return mcp.NewToolResultError(fmt.Sprintf("cannot parse input: must be an Ethereum address (0x...) or a Polymarket profile URL, got: %s", input)), nil
+		result, err := ResolveWalletTargetData(ctx, client, input)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		displayName := address[:6] + "..." + address[len(address)-4:]
-		profileImage := ""
-
-		profile, err := client.GetPublicProfile(address)
-		if err == nil && profile != nil {
-			if profile.Pseudonym != "" {
-				displayName = profile.Pseudonym
-			} else if profile.Name != "" {
-				displayName = profile.Name
-			}
-			profileImage = profile.ProfileImage
-		}
+		data, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
 
-		result := ResolveResult{
-			WalletAddress: address,
-			DisplayName:   displayName,
-			InputType:     inputType,
-			ProfileImage:  profileImage,
+// ResolveWalletTargetData resolves an address, profile URL or @slug to a
+// wallet address with display name and profile image.
+func ResolveWalletTargetData(ctx context.Context, client *polymarket.Client, input string) (ResolveResult, error) {
+	var address string
+	var inputType string
+
+	input = strings.TrimSpace(input)
+
+	if ethAddressRegex.MatchString(input) {
+		address = input
+		inputType = "wallet_address"
+	} else if matches := profileURLRegex.FindStringSubmatch(input); len(matches) > 1 {
+		address = matches[1]
+		inputType = "polymarket_url"
+	} else if matches := profileSlugURLRegex.FindStringSubmatch(input); len(matches) > 1 {
+		resolved, err := resolveProfileSlugAddress(ctx, client, matches[1])
+		if err != nil {
+			return ResolveResult{}, fmt.Errorf("failed to resolve Polymarket profile slug: %v", err)
+		}
+		address = resolved
+		inputType = "polymarket_slug"
+	} else if slugInputRegex.MatchString(input) {
+		resolved, err := resolveProfileSlugAddress(ctx, client, input)
+		if err != nil {
+			return ResolveResult{}, fmt.Errorf("failed to resolve Polymarket profile slug: %v", err)
 		}
+		address = resolved
+		inputType = "polymarket_slug"
+	} else {
+		return ResolveResult{}, fmt.Errorf("cannot parse input: must be an Ethereum address (0x...) or a Polymarket profile URL, got: %s", input)
+	}
 
-		data, _ := json.Marshal(result)
-		return mcp.NewToolResultText(string(data)), nil
+	displayName := address[:6] + "..." + address[len(address)-4:]
+	profileImage := ""
+
+	profile, err := client.GetPublicProfile(address)
+	if err == nil && profile != nil {
+		if profile.Pseudonym != "" {
+			displayName = profile.Pseudonym
+		} else if profile.Name != "" {
+			displayName = profile.Name
+		}
+		profileImage = profile.ProfileImage
 	}
+
+	return ResolveResult{
+		WalletAddress: address,
+		DisplayName:   displayName,
+		InputType:     inputType,
+		ProfileImage:  profileImage,
+	}, nil
 }
 
 func resolveProfileSlugAddress(ctx context.Context, client *polymarket.Client, slug string) (string, error) {
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/arbitrage"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/sports"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type ArbitrageQuery struct {
+	Sport      string
+	FeeBps     float64
+	MinEdge    float64
+	MaxMarkets int
+}
+
+type ArbitrageResult struct {
+	Sport          string                  `json:"sport"`
+	Options        arbitrage.Options       `json:"options"`
+	MarketsScanned int                     `json:"markets_scanned"`
+	ScannedAt      time.Time               `json:"scanned_at"`
+	Opportunities  []arbitrage.Opportunity `json:"opportunities"`
+}
+
+func ScanArbitrage(client *polymarket.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		query := ArbitrageQuery{Sport: sports.Default}
+		if s, ok := args["sport"].(string); ok && s != "" {
+			query.Sport = s
+		}
+		if v, ok := args["fee_bps"].(float64); ok && v >= 0 {
+			query.FeeBps = v
+		}
+		if v, ok := args["min_edge"].(float64); ok && v >= 0 {
+			query.MinEdge = v
+		}
+		if v, ok := args["max_markets"].(float64); ok && v > 0 {
+			query.MaxMarkets = int(v)
+		}
+
+		result, err := ScanArbitrageData(ctx, client, query)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// ScanArbitrageData reads live order books for the open markets of a sport's
+// unfinished games and reports complementary-outcome arbitrage.
+func ScanArbitrageData(ctx context.Context, client *polymarket.Client, query ArbitrageQuery) (ArbitrageResult, error) {
+	sport := sports.Normalize(query.Sport)
+	if query.MaxMarkets <= 0 {
+		query.MaxMarkets = 200
+	}
+	opts := arbitrage.Options{FeeBps: query.FeeBps, MinEdge: query.MinEdge}
+
+	ids := arbitrage.SportMarketIDs(sports.SharedIndex(), sport)
+	if len(ids) == 0 {
+		return ArbitrageResult{}, fmt.Errorf("no open %s game markets in the sports index yet", strings.ToUpper(sport))
+	}
+	if len(ids) > query.MaxMarkets {
+		ids = ids[:query.MaxMarkets]
+	}
+
+	LogToolf(ctx, "Loading %d %s markets", len(ids), strings.ToUpper(sport))
+	markets, err := arbitrage.ActiveMarkets(client, ids)
+	if err != nil {
+		return ArbitrageResult{}, err
+	}
+
+	LogToolf(ctx, "Reading order books for %d active markets", len(markets))
+	snapshots, err := arbitrage.Fetch(ctx, client, markets)
+	if err != nil {
+		return ArbitrageResult{}, err
+	}
+
+	opportunities := arbitrage.Scan(snapshots, opts)
+	LogToolf(ctx, "Found %d arbitrage opportunities", len(opportunities))
+
+	return ArbitrageResult{
+		Sport:          sport,
+		Options:        opts,
+		MarketsScanned: len(snapshots),
+		ScannedAt:      time.Now().UTC(),
+		Opportunities:  opportunities,
+	}, nil
+}
@@ -0,0 +1,325 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+const (
+	// marketTTL is how long a stored open market is used before Gamma is
+	// asked again; closed markets do not change and are always used.
+	marketTTL = 10 * time.Minute
+	// maxTradeSync caps how many new trades one call pulls ahead of a
+	// wallet's stored history.
+	maxTradeSync      = 10000
+	tradeSyncPageSize = 500
+	marketBatchSize   = 20
+)
+
+// walletTradeSource pages through a wallet's trades, newest first. With a
+// store it first brings the wallet's stored history up to date and at
+// least limit trades deep, then serves every page from the store. The API
+// is only asked directly for windows older than the stored history, so a
+// page never mixes the two.
+type walletTradeSource struct {
+	client *polymarket.Client
+	store  storage.Store
+	wallet string
+	limit  int
+
+	loaded   bool
+	stored   []polymarket.Trade
+	complete bool
+}
+
+// newWalletTradeSource reads straight from the API when store is nil.
+func newWalletTradeSource(client *polymarket.Client, store storage.Store, wallet string, limit int) *walletTradeSource {
+	return &walletTradeSource{client: client, store: store, wallet: wallet, limit: limit}
+}
+
+func (s *walletTradeSource) page(ctx context.Context, offset, limit int) ([]polymarket.Trade, error) {
+	if s.store == nil {
+		return s.client.GetTrades(s.wallet, limit, offset)
+	}
+	if !s.loaded {
+		if err := s.load(ctx); err != nil {
+			return nil, err
+		}
+		s.loaded = true
+	}
+	if s.complete || offset+limit <= len(s.stored) {
+		end := minInt(offset+limit, len(s.stored))
+		if offset >= end {
+			return []polymarket.Trade{}, nil
+		}
+		return s.stored[offset:end], nil
+	}
+
+	page, err := s.client.GetTrades(s.wallet, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	persistTrades(ctx, s.store, page)
+	return page, nil
+}
+
+// load syncs the wallet's trades into the store and reads back the newest
+// limit trades its coverage vouches for.
+func (s *walletTradeSource) load(ctx context.Context) error {
+	coverage, err := s.catchUp(ctx)
+	if err != nil {
+		return err
+	}
+	stored, err := s.covered(ctx, coverage)
+	if err != nil {
+		return err
+	}
+	if len(stored) < s.limit && !coverage.Complete {
+		if err := s.backfill(ctx, &coverage, len(stored)); err != nil {
+			return err
+		}
+		if stored, err = s.covered(ctx, coverage); err != nil {
+			return err
+		}
+	}
+	if err := s.store.SaveWalletTradeCoverage(ctx, coverage); err != nil {
+		return err
+	}
+
+	s.stored, s.complete = stored, coverage.Complete
+	if len(s.stored) > 0 {
+		LogToolf(ctx, "Loaded %d stored trades of %s", len(s.stored), s.wallet)
+	}
+	return nil
+}
+
+// catchUp downloads the trades newer than the wallet's stored coverage and
+// returns the coverage extended to them. Without stored coverage it reads
+// only the newest page and leaves the rest to backfill. When the newest
+// trades do not reach back to the stored ones within maxTradeSync, the gap
+// makes the old coverage unusable and it is started over.
+func (s *walletTradeSource) catchUp(ctx context.Context) (storage.WalletTradeCoverage, error) {
+	prior, ok, err := s.store.GetWalletTradeCoverage(ctx, s.wallet)
+	if err != nil {
+		return storage.WalletTradeCoverage{}, err
+	}
+
+	coverage := storage.WalletTradeCoverage{WalletAddress: strings.ToLower(s.wallet)}
+	fetched, joined := 0, false
+	for offset := 0; offset < maxTradeSync; offset += tradeSyncPageSize {
+		page, err := s.client.GetTrades(s.wallet, tradeSyncPageSize, offset)
+		if err != nil {
+			return storage.WalletTradeCoverage{}, err
+		}
+		persistTrades(ctx, s.store, page)
+		fetched += len(page)
+		if len(page) > 0 {
+			if coverage.NewestTradeAt == nil {
+				newest := page[0].Time().UTC()
+				coverage.NewestTradeAt = &newest
+			}
+			oldest := page[len(page)-1].Time().UTC()
+			coverage.OldestTradeAt = &oldest
+		}
+		if len(page) < tradeSyncPageSize {
+			coverage.Complete = true
+			break
+		}
+		if !ok {
+			break
+		}
+		if prior.NewestTradeAt != nil && coverage.OldestTradeAt.Before(*prior.NewestTradeAt) {
+			joined = true
+			break
+		}
+	}
+
+	switch {
+	case coverage.Complete:
+	case joined:
+		coverage.OldestTradeAt, coverage.Complete = prior.OldestTradeAt, prior.Complete
+	case ok:
+		LogToolf(ctx, "More than %d new trades of %s; rebuilding its stored history", maxTradeSync, s.wallet)
+	}
+	if ok {
+		LogToolf(ctx, "Synced %d recent trades into the stored history of %s", fetched, s.wallet)
+	}
+	return coverage, nil
+}
+
+// backfill extends coverage back in time from offset, the number of trades
+// it already holds, until it holds limit trades or the feed runs out.
+func (s *walletTradeSource) backfill(ctx context.Context, coverage *storage.WalletTradeCoverage, offset int) error {
+	fetched := 0
+	for ; offset < s.limit; offset += tradeSyncPageSize {
+		page, err := s.client.GetTrades(s.wallet, tradeSyncPageSize, offset)
+		if err != nil {
+			return err
+		}
+		persistTrades(ctx, s.store, page)
+		fetched += len(page)
+		if len(page) > 0 {
+			oldest := page[len(page)-1].Time().UTC()
+			coverage.OldestTradeAt = &oldest
+		}
+		if len(page) < tradeSyncPageSize {
+			coverage.Complete = true
+			break
+		}
+	}
+	LogToolf(ctx, "Backfilled %d older trades of %s", fetched, s.wallet)
+	return nil
+}
+
+// covered reads back the newest limit stored trades of the wallet that
+// coverage vouches for. Older rows stored from market feeds are left out,
+// since the trades between them may be missing.
+func (s *walletTradeSource) covered(ctx context.Context, coverage storage.WalletTradeCoverage) ([]polymarket.Trade, error) {
+	records, err := s.store.ListWalletTrades(ctx, s.wallet, s.limit)
+	if err != nil {
+		return nil, err
+	}
+	trades := make([]polymarket.Trade, 0, len(records))
+	for _, record := range records {
+		if !coverage.Complete && (coverage.OldestTradeAt == nil || record.TradedAt.Before(*coverage.OldestTradeAt)) {
+			break
+		}
+		trades = append(trades, tradeFromRecord(record))
+	}
+	return trades, nil
+}
+
+// persistTrades stores trades when store is set. Failures are logged and
+// otherwise ignored, since the trades were already fetched.
+func persistTrades(ctx context.Context, store storage.Store, trades []polymarket.Trade) {
+	if store == nil || len(trades) == 0 {
+		return
+	}
+	records := make([]storage.StoredTrade, 0, len(trades))
+	for _, trade := range trades {
+		records = append(records, storage.StoredTrade{
+			Key:             trade.Key(),
+			ID:              trade.ID,
+			TransactionHash: trade.TransactionHash,
+			WalletAddress:   trade.ProxyWallet,
+			ConditionID:     trade.ConditionID,
+			Asset:           trade.Asset,
+			Side:            trade.Side,
+			Size:            trade.Size,
+			Price:           trade.Price,
+			TradedAt:        trade.Time().UTC(),
+			Outcome:         trade.Outcome,
+			Title:           trade.Title,
+			Slug:            trade.Slug,
+		})
+	}
+	if _, err := store.InsertTrades(ctx, records); err != nil {
+		log.Printf("persist trades failed: %v", err)
+	}
+}
+
+func tradeFromRecord(record storage.StoredTrade) polymarket.Trade {
+	return polymarket.Trade{
+		ID:              record.ID,
+		TransactionHash: record.TransactionHash,
+		ProxyWallet:     record.WalletAddress,
+		Side:            record.Side,
+		Asset:           record.Asset,
+		ConditionID:     record.ConditionID,
+		Slug:            record.Slug,
+		Size:            record.Size,
+		Price:           record.Price,
+		Timestamp:       record.TradedAt.Unix(),
+		Title:           record.Title,
+		Outcome:         record.Outcome,
+	}
+}
+
+// loadMarkets returns the markets among conditionIDs keyed by lowercase
+// condition ID. With a store, stored markets that are closed or fresh are
+// used as is; the rest come from Gamma in batches and are stored. Failed batches are logged
+// and skipped, and their errors returned together with what did load.
+func loadMarkets(ctx context.Context, client *polymarket.Client, store storage.Store, conditionIDs []string) (map[string]polymarket.Market, error) {
+	byID := make(map[string]polymarket.Market, len(conditionIDs))
+	missing := make([]string, 0, len(conditionIDs))
+	seen := map[string]bool{}
+	for _, id := range conditionIDs {
+		id = strings.ToLower(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			missing = append(missing, id)
+		}
+	}
+
+	if store != nil && len(missing) > 0 {
+		records, err := store.GetMarketRecords(ctx, missing)
+		if err != nil {
+			log.Printf("load stored markets failed: %v", err)
+		}
+		for _, record := range records {
+			if !record.Closed && time.Since(record.UpdatedAt) > marketTTL {
+				continue
+			}
+			var market polymarket.Market
+			if err := json.Unmarshal(record.Data, &market); err != nil {
+				continue
+			}
+			byID[strings.ToLower(record.ConditionID)] = market
+		}
+		remaining := missing[:0]
+		for _, id := range missing {
+			if _, ok := byID[id]; !ok {
+				remaining = append(remaining, id)
+			}
+		}
+		if stored := len(missing) - len(remaining); stored > 0 {
+			LogToolf(ctx, "Using %d stored markets", stored)
+		}
+		missing = remaining
+	}
+
+	var errs []error
+	for start := 0; start < len(missing); start += marketBatchSize {
+		end := minInt(start+marketBatchSize, len(missing))
+		LogToolf(ctx, "Fetching market metadata batch %d-%d", start+1, end)
+
+		markets, err := client.GetMarkets(missing[start:end])
+		if err != nil {
+			LogToolf(ctx, "Skipping market batch %d-%d after error: %v", start+1, end, err)
+			errs = append(errs, fmt.Errorf("markets %d-%d: %w", start+1, end, err))
+			continue
+		}
+		records := make([]storage.MarketRecord, 0, len(markets))
+		for _, market := range markets {
+			byID[strings.ToLower(market.ConditionID)] = market
+			data, err := json.Marshal(market)
+			if err != nil {
+				continue
+			}
+			records = append(records, storage.MarketRecord{
+				ConditionID: market.ConditionID,
+				Question:    market.Question,
+				Slug:        market.Slug,
+				VolumeUSD:   market.VolumeNum,
+				StartDate:   market.StartDate,
+				EndDate:     market.EndDate,
+				Closed:      market.Closed,
+				Data:        data,
+			})
+		}
+		if store != nil {
+			if err := store.UpsertMarkets(ctx, records); err != nil {
+				log.Printf("persist markets failed: %v", err)
+			}
+		}
+	}
+	return byID, errors.Join(errs...)
+}
@@ -0,0 +1,290 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brucexwang/easy-arbitra/backend/events"
+	"github.com/brucexwang/easy-arbitra/backend/polymarket"
+	"github.com/brucexwang/easy-arbitra/backend/storage"
+)
+
+const (
+	// walletsRefresh is how often the watched wallet set is reloaded from
+	// the store.
+	walletsRefresh = 5 * time.Minute
+	// retention bounds how old a trade may be and still be published, and
+	// how long its key is remembered for de-duplication.
+	retention = 24 * time.Hour
+)
+
+type Options struct {
+	Interval time.Duration
+	// RecentLimit is how many trades of the global recent feed each poll
+	// reads.
+	RecentLimit int
+	// WalletsPerPoll is how many watched wallets have their own feed read
+	// each poll, in rotation, to catch trades the global feed missed.
+	WalletsPerPoll int
+	// WalletTradeLimit is how many of a wallet's latest trades are read when
+	// its own feed is polled.
+	WalletTradeLimit int
+}
+
+func (o Options) withDefaults() Options {
+	if o.Interval <= 0 {
+		o.Interval = 15 * time.Second
+	}
+	if o.RecentLimit <= 0 {
+		o.RecentLimit = 500
+	}
+	if o.WalletsPerPoll < 0 {
+		o.WalletsPerPoll = 0
+	}
+	if o.WalletTradeLimit <= 0 {
+		o.WalletTradeLimit = 20
+	}
+	return o
+}
+
+// Status describes the watcher for the status endpoint.
+type Status struct {
+	StartedAt      time.Time  `json:"started_at"`
+	LastPollAt     *time.Time `json:"last_poll_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	Interval       string     `json:"interval"`
+	WatchedWallets int        `json:"watched_wallets"`
+	Published      int64      `json:"published"`
+	Subscribers    int        `json:"subscribers"`
+}
+
+// Service polls Polymarket's trade feeds at short intervals and publishes
+// every new trade by a tracked or watchlisted wallet to the event bus.
+type Service struct {
+	client  *polymarket.Client
+	store   storage.Store
+	bus     *events.Bus
+	options Options
+
+	startedAt time.Time
+	refresh   atomic.Bool
+
+	// pollMu serializes polls and guards the poll state below.
+	pollMu         sync.Mutex
+	wallets        map[string]storage.WatchedWallet
+	rotation       []string
+	cursor         int
+	walletsUpdated time.Time
+	seen           map[string]time.Time
+
+	// mu guards the counters reported by Status.
+	mu        sync.Mutex
+	watched   int
+	lastPoll  time.Time
+	lastErr   error
+	published int64
+}
+
+func NewService(client *polymarket.Client, store storage.Store, bus *events.Bus, options Options) *Service {
+	return &Service{
+		client:    client,
+		store:     store,
+		bus:       bus,
+		options:   options.withDefaults(),
+		startedAt: time.Now().UTC(),
+		wallets:   map[string]storage.WatchedWallet{},
+		seen:      map[string]time.Time{},
+	}
+}
+
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		s.pollWithLogging(ctx)
+
+		ticker := time.NewTicker(s.options.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.pollWithLogging(ctx)
+			}
+		}
+	}()
+}
+
+// PollOnce reads the global recent feed and the next wallets' own feeds,
+// then publishes the trades of watched wallets not published before. Trades
+// made before the watcher started are never published.
+func (s *Service) PollOnce(ctx context.Context) error {
+	s.pollMu.Lock()
+	defer s.pollMu.Unlock()
+
+	now := time.Now().UTC()
+	if s.refresh.Swap(false) || len(s.wallets) == 0 || now.Sub(s.walletsUpdated) >= walletsRefresh {
+		if err := s.loadWallets(ctx); err != nil {
+			return err
+		}
+		s.walletsUpdated = now
+	}
+	if len(s.wallets) == 0 {
+		s.mu.Lock()
+		s.watched, s.lastPoll = 0, now
+		s.mu.Unlock()
+		return nil
+	}
+
+	var errs []error
+	trades, err := s.client.GetRecentTrades(s.options.RecentLimit, 0)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("recent trades: %w", err))
+	}
+	for _, wallet := range s.nextWallets() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := s.client.GetTrades(wallet, s.options.WalletTradeLimit, 0)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trades of %s: %w", wallet, err))
+			continue
+		}
+		trades = append(trades, page...)
+	}
+
+	cutoff := now.Add(-retention)
+	if s.startedAt.After(cutoff) {
+		cutoff = s.startedAt
+	}
+	fresh := []events.TradeEvent{}
+	for _, trade := range trades {
+		wallet, ok := s.wallets[strings.ToLower(trade.ProxyWallet)]
+		if !ok {
+			continue
+		}
+		at := trade.Time().UTC()
+		key := trade.Key()
+		if at.Before(cutoff) {
+			continue
+		}
+		if _, ok := s.seen[key]; ok {
+			continue
+		}
+		s.seen[key] = at
+		fresh = append(fresh, events.TradeEvent{
+			Key:        key,
+			Wallet:     wallet.WalletAddress,
+			Sports:     wallet.Sports,
+			Watchlists: wallet.Watchlists,
+			Trade:      trade,
+		})
+	}
+	sort.SliceStable(fresh, func(i, j int) bool { return fresh[i].Trade.Timestamp < fresh[j].Trade.Timestamp })
+	for i := range fresh {
+		s.bus.Publish(events.Event{Type: events.TypeTrade, Trade: &fresh[i]})
+	}
+	for key, at := range s.seen {
+		if at.Before(cutoff) {
+			delete(s.seen, key)
+		}
+	}
+
+	s.mu.Lock()
+	s.watched = len(s.wallets)
+	s.published += int64(len(fresh))
+	s.lastPoll = now
+	s.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Status reports the watcher's last poll and counters.
+func (s *Service) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		StartedAt:      s.startedAt,
+		Interval:       s.options.Interval.String(),
+		WatchedWallets: s.watched,
+		Published:      s.published,
+		Subscribers:    s.bus.Subscribers(),
+	}
+	if !s.lastPoll.IsZero() {
+		lastPoll := s.lastPoll
+		status.LastPollAt = &lastPoll
+	}
+	if s.lastErr != nil {
+		status.LastError = s.lastErr.Error()
+	}
+	return status
+}
+
+// Refresh makes the next poll reload the watched wallets, so watchlist
+// changes apply without waiting for the periodic reload.
+func (s *Service) Refresh() {
+	s.refresh.Store(true)
+}
+
+func (s *Service) loadWallets(ctx context.Context) error {
+	watched, err := s.store.ListWatchedWallets(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.wallets = make(map[string]storage.WatchedWallet, len(watched))
+	s.rotation = s.rotation[:0]
+	// Watchlisted wallets come first so they are polled soonest.
+	for _, wallet := range watched {
+		s.wallets[wallet.WalletAddress] = wallet
+		if len(wallet.Watchlists) > 0 {
+			s.rotation = append(s.rotation, wallet.WalletAddress)
+		}
+	}
+	for _, wallet := range watched {
+		if len(wallet.Watchlists) == 0 {
+			s.rotation = append(s.rotation, wallet.WalletAddress)
+		}
+	}
+	if s.cursor >= len(s.rotation) {
+		s.cursor = 0
+	}
+	return nil
+}
+
+// nextWallets returns the next WalletsPerPoll wallets in rotation.
+func (s *Service) nextWallets() []string {
+	n := minInt(s.options.WalletsPerPoll, len(s.rotation))
+	batch := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		batch = append(batch, s.rotation[s.cursor])
+		s.cursor = (s.cursor + 1) % len(s.rotation)
+	}
+	return batch
+}
+
+func (s *Service) pollWithLogging(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	err := s.PollOnce(runCtx)
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("trade watcher poll failed: %v", err)
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -4,7 +4,7 @@ import { analyzeWalletStream } from "@/lib/ai";
 export async function POST(request: Request) {
   try {
     const body = await request.json();
-    const { walletInput } = body;
+    const { walletInput, sport } = body;
 
     if (!walletInput || typeof walletInput !== "string") {
       return NextResponse.json(
@@ -12,12 +12,18 @@ export async function POST(request: Request) {
         { status: 400 }
       );
     }
+    if (sport !== undefined && typeof sport !== "string") {
+      return NextResponse.json(
+        { error: "sport must be a string" },
+        { status: 400 }
+      );
+    }
 
     const encoder = new TextEncoder();
     const stream = new ReadableStream({
       async start(controller) {
         try {
-          await analyzeWalletStream(walletInput, (event) => {
+          await analyzeWalletStream(walletInput, sport, (event) => {
             const line = `data: ${JSON.stringify(event)}\n\n`;
             controller.enqueue(encoder.encode(line));
           });
@@ -7,11 +7,12 @@ import { SettingsPanel } from "@/components/settings-panel";
 
 interface StyleWallet {
   wallet_address: string;
+  sport: string;
   display_name: string;
   source_rank: number;
   win_rate: number;
   pnl_usd: number;
-  nba_trades: number;
+  sport_trades: number;
   style_label: string;
   style_summary: string;
   explanation_source: "ai" | "fallback";
@@ -172,7 +173,7 @@ export default function Home() {
                                 </div>
 
                                 <div className="mt-4 grid grid-cols-2 gap-2 text-xs text-white/55">
-                                  <p>NBA trades: {wallet.nba_trades}</p>
+                                  <p>{wallet.sport.toUpperCase()} trades: {wallet.sport_trades}</p>
                                   <p>Win rate: {wallet.win_rate.toFixed(1)}%</p>
                                   <p>PnL: ${wallet.pnl_usd.toLocaleString()}</p>
                                   <p>
@@ -10,10 +10,11 @@ const ETH_ADDRESS = /^0x[a-fA-F0-9]{40}$/;
 
 interface CandidateResult {
   wallet: string;
+  sport: string;
   display_name: string;
   recent_trades: number;
   recent_markets: number;
-  nba_trades: number;
+  sport_trades: number;
   entry_timing_hours: number;
   size_ratio_pct: number;
   conviction: number;
@@ -279,7 +280,7 @@ export function SettingsPanel({ onAnalyzeWallet }: SettingsPanelProps) {
                     </div>
                   </div>
                   <div className="mt-3 grid gap-2 text-xs text-white/60 sm:grid-cols-2">
-                    <p>NBA trades: {result.nba_trades}</p>
+                    <p>{result.sport.toUpperCase()} trades: {result.sport_trades}</p>
                     <p>Recent sample hits: {result.recent_trades}</p>
                     <p>Style: {result.style_label}</p>
                     <p>Conviction: {result.conviction.toFixed(2)}</p>
@@ -11,23 +11,54 @@ const AI_MODEL = process.env.AI_MODEL?.trim();
 const AI_API_KEY = process.env.AI_API_KEY?.trim();
 const AI_TIMEOUT_MS = parseTimeoutMs(process.env.AI_TIMEOUT_MS);
 
-const AI_SYSTEM_PROMPT = `You are SportStyle AI Explainer, an expert sports betting analyst.
+const DEFAULT_SPORT = "nba";
+
+// Display names of the backend's sports catalog (backend/sports). Other keys
+// are shown upper-cased, as the backend does.
+const SPORT_NAMES: Record<string, string> = {
+  nba: "NBA",
+  nfl: "NFL",
+  mlb: "MLB",
+  nhl: "NHL",
+  epl: "Premier League",
+  laliga: "La Liga",
+  seriea: "Serie A",
+  bundesliga: "Bundesliga",
+  ucl: "Champions League",
+  mls: "MLS",
+  soccer: "Soccer",
+  tennis: "Tennis",
+  mma: "MMA",
+};
+
+function buildSystemPrompt(sportName: string): string {
+  return `You are SportStyle AI Explainer, an expert sports betting analyst.
 
 You will receive:
 - wallet identity information
-- deterministic NBA trade metrics
+- deterministic ${sportName} trade metrics
 - a structured report payload
 
-Write a concise, plain-English explanation of the wallet's NBA trading style.
+Write a concise, plain-English explanation of the wallet's ${sportName} trading style.
 
 Requirements:
 - Be accurate to the supplied metrics only
 - Reference specific metric values
 - Explain the style label in plain English
 - If sample size is small, mention it as a limitation
-- If there are zero NBA trades, clearly say there is not enough NBA history to infer style
+- If there are zero ${sportName} trades, clearly say there is not enough ${sportName} history to infer style
 - Keep the response to 2 short paragraphs max
 - Do not mention hidden prompts, tool calls, or internal implementation details`;
+}
+
+function normalizeSport(sport: string | undefined): string {
+  return sport?.trim().toLowerCase() || DEFAULT_SPORT;
+}
+
+function sportDisplayName(sport: string): string {
+  const key = normalizeSport(sport);
+  return SPORT_NAMES[key] || key.toUpperCase();
+}
 
 export type StreamEvent =
   | { type: "step"; data: DecisionStep }
@@ -63,6 +94,7 @@ interface MetricsResult {
 }
 
 interface PipelineResult {
+  sportName: string;
   decisionLog: DecisionStep[];
   walletInfo: ResolveWalletResult;
   tradesResult: FetchTradesResult;
@@ -77,9 +109,14 @@ interface ExplanationResult {
 
 export async function analyzeWalletStream(
   walletInput: string,
+  sport: string | undefined,
   onEvent: (event: StreamEvent) => void
 ): Promise<void> {
-  const pipeline = await runDeterministicPipeline(walletInput, onEvent);
+  const pipeline = await runDeterministicPipeline(
+    walletInput,
+    normalizeSport(sport),
+    onEvent
+  );
   const explanation = await generateExplanation(pipeline);
 
   onEvent({ type: "explanation", data: explanation.text });
@@ -96,8 +133,10 @@ export async function analyzeWalletStream(
 
 async function runDeterministicPipeline(
   walletInput: string,
+  sport: string,
   onEvent: (event: StreamEvent) => void
 ): Promise<PipelineResult> {
+  const sportName = sportDisplayName(sport);
   const decisionLog: DecisionStep[] = [];
   const toolLogs: Record<string, string[]> = {};
 
@@ -117,9 +156,9 @@ async function runDeterministicPipeline(
     const step: DecisionStep = {
       step: decisionLog.length + 1,
       tool,
-      reasoning: getToolReasoning(tool),
+      reasoning: getToolReasoning(tool, sportName),
       timestamp: new Date().toISOString(),
-      result_summary: summarizeResult(tool, resultText),
+      result_summary: summarizeResult(tool, resultText, sportName),
       logs: toolLogs[tool] || [],
     };
 
@@ -139,7 +178,7 @@ async function runDeterministicPipeline(
     "fetch_sports_trades",
     {
       wallet: walletInfo.wallet_address,
-      sport: "nba",
+      sport,
     },
     (message) => emitToolLog("fetch_sports_trades", message)
   );
@@ -175,6 +214,7 @@ async function runDeterministicPipeline(
   onEvent({ type: "report", data: reportPayload });
 
   return {
+    sportName,
     decisionLog,
     walletInfo,
     tradesResult,
@@ -204,7 +244,10 @@ async function generateExplanation(pipeline: PipelineResult): Promise<Explanatio
         model: AI_MODEL,
         temperature: 0.4,
         messages: [
-          { role: "system", content: AI_SYSTEM_PROMPT },
+          {
+            role: "system",
+            content: buildSystemPrompt(pipeline.sportName),
+          },
           {
             role: "user",
             content: buildExplanationPrompt(pipeline),
@@ -316,16 +359,16 @@ function getErrorMessage(error: unknown): string {
 }
 
 function buildFallbackExplanation(
-  { reportPayload, walletInfo, metricsResult }: PipelineResult,
+  { sportName, reportPayload, walletInfo, metricsResult }: PipelineResult,
   cause: string
 ): string {
   if (metricsResult.sample_size === 0) {
-    return `${walletInfo.display_name} has no detected NBA trading activity in the fetched Polymarket history. The structured report is still generated from the deterministic pipeline, but there is not enough NBA data to infer a reliable style. AI explanation generation was unavailable for this request (${cause}).`;
+    return `${walletInfo.display_name} has no detected ${sportName} trading activity in the fetched Polymarket history. The structured report is still generated from the deterministic pipeline, but there is not enough ${sportName} data to infer a reliable style. AI explanation generation was unavailable for this request (${cause}).`;
   }
 
   const metrics = metricsResult.metrics;
   const explanationParts = [
-    `${walletInfo.display_name} profiles as a ${reportPayload.report.style_label} based on ${metricsResult.sample_size} NBA trades. Average entry timing is ${metrics.entry_timing_hours.toFixed(1)} hours before market resolution, average position size is ${metrics.size_ratio_pct.toFixed(4)}% of market volume, and conviction is ${metrics.conviction.toFixed(2)} on the 0-1 scale.`,
+    `${walletInfo.display_name} profiles as a ${reportPayload.report.style_label} based on ${metricsResult.sample_size} ${sportName} trades. Average entry timing is ${metrics.entry_timing_hours.toFixed(1)} hours before market resolution, average position size is ${metrics.size_ratio_pct.toFixed(4)}% of market volume, and conviction is ${metrics.conviction.toFixed(2)} on the 0-1 scale.`,
     metrics.conviction > 0.75
       ? "That conviction score suggests a strong bias toward favorites or higher-confidence entries."
       : metrics.conviction > 0 && metrics.conviction < 0.35
@@ -344,12 +387,12 @@ function buildFallbackExplanation(
   return explanationParts.join(" ");
 }
 
-function getToolReasoning(toolName: string): string {
+function getToolReasoning(toolName: string, sportName: string): string {
   switch (toolName) {
     case "resolve_wallet_target":
       return "Standardizing the wallet input to a verified address with profile info";
     case "fetch_sports_trades":
-      return "Fetching NBA-specific trade history from Polymarket";
+      return `Fetching ${sportName}-specific trade history from Polymarket`;
     case "calculate_style_metrics":
       return "Computing entry timing, position sizing, and conviction metrics";
     case "build_report_payload":
@@ -359,14 +402,18 @@ function getToolReasoning(toolName: string): string {
   }
 }
 
-function summarizeResult(toolName: string, resultText: string): string {
+function summarizeResult(
+  toolName: string,
+  resultText: string,
+  sportName: string
+): string {
   try {
     const data = JSON.parse(resultText);
     switch (toolName) {
       case "resolve_wallet_target":
         return `Resolved: ${data.display_name} (${data.input_type})`;
       case "fetch_sports_trades":
-        return `Found ${data.total_trades} NBA trades`;
+        return `Found ${data.total_trades} ${sportName} trades`;
       case "calculate_style_metrics":
         return `Metrics: timing=${data.metrics?.entry_timing_hours}h, size=${data.metrics?.size_ratio_pct}%, conviction=${data.metrics?.conviction}`;
       case "build_report_payload":